@@ -5,13 +5,18 @@ package prometheusremotewriteexporter // import "github.com/open-telemetry/opent
 
 import (
 	"fmt"
+	"net/http"
+	"time"
 
+	"github.com/prometheus/prometheus/model/relabel"
 	"go.opentelemetry.io/collector/component"
 	"go.opentelemetry.io/collector/config/confighttp"
 	"go.opentelemetry.io/collector/config/configretry"
+	"go.opentelemetry.io/collector/confmap"
 	"go.opentelemetry.io/collector/exporter/exporterhelper"
 
 	"github.com/open-telemetry/opentelemetry-collector-contrib/pkg/resourcetotelemetry"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/pkg/translator/prometheusremotewrite"
 )
 
 // Config defines configuration for Remote Write exporter.
@@ -23,6 +28,10 @@ type Config struct {
 	// See: https://prometheus.io/docs/practices/naming/#metric-names
 	Namespace string `mapstructure:"namespace"`
 
+	// RetryOnHTTP429 retries requests rejected with HTTP 429 Too Many Requests
+	// instead of dropping them, waiting for the delay of the Retry-After header.
+	RetryOnHTTP429 bool `mapstructure:"retry_on_http_429"`
+
 	// QueueConfig allows users to fine tune the queues
 	// that handle outgoing requests.
 	RemoteWriteQueue RemoteWriteQueue `mapstructure:"remote_write_queue"`
@@ -50,15 +59,81 @@ type Config struct {
 	// AddMetricSuffixes controls whether unit and type suffixes are added to metrics on export
 	AddMetricSuffixes bool `mapstructure:"add_metric_suffixes"`
 
+	// TranslationStrategy controls how the metric and attribute names are translated:
+	// "UnderscoreEscapingWithSuffixes" (default), "NoUTF8EscapingWithSuffixes" or "NoTranslation".
+	// The last two keep UTF-8 names, which the backend must accept.
+	TranslationStrategy prometheusremotewrite.TranslationStrategy `mapstructure:"translation_strategy"`
+
 	// SendMetadata controls whether prometheus metadata will be generated and sent
 	SendMetadata bool `mapstructure:"send_metadata"`
+
+	// ProtocolVersion is the version of the Remote Write protocol to use, "1.0" or "2.0".
+	// Remote Write 2.0 always sends metadata and created timestamps.
+	ProtocolVersion string `mapstructure:"protocol_version"`
+
+	// WriteRelabelConfigs are Prometheus relabel configs applied to the
+	// series before they are sent. They are decoded by Unmarshal.
+	WriteRelabelConfigs []*relabel.Config `mapstructure:"-"`
+
+	// AdditionalEndpoints are other endpoints the same series are sent to,
+	// translated once, each with its own client, queue, WAL and retries.
+	AdditionalEndpoints []RemoteWriteEndpoint `mapstructure:"additional_endpoints"`
+
+	// DeltaToCumulative allows exporting delta sums and histograms, which are
+	// dropped otherwise, by accumulating them into cumulative series.
+	DeltaToCumulative DeltaToCumulative `mapstructure:"delta_to_cumulative"`
+
+	// Tenant routes the series to the tenant of their resource or client
+	// metadata, sending separate requests to each tenant. Disabled if nil.
+	Tenant *TenantConfig `mapstructure:"tenant"`
+
+	// Staleness sends staleness markers for the series which are no longer
+	// reported, so that they end without waiting for the lookback delta.
+	Staleness StalenessConfig `mapstructure:"staleness"`
 }
 
+// RemoteWriteEndpoint is an additional endpoint of the exporter. The queue,
+// retry and timeout settings it doesn't set are inherited from the exporter.
+type RemoteWriteEndpoint struct {
+	// Name identifies the endpoint in the telemetry and storage of the exporter.
+	Name string `mapstructure:"name"`
+
+	ClientConfig confighttp.ClientConfig `mapstructure:",squash"` // squash ensures fields are correctly decoded in embedded struct.
+
+	BackOffConfig *configretry.BackOffConfig `mapstructure:"retry_on_failure"`
+
+	RemoteWriteQueue *RemoteWriteQueue `mapstructure:"remote_write_queue"`
+
+	RetryOnHTTP429 *bool `mapstructure:"retry_on_http_429"`
+
+	WAL *WALConfig `mapstructure:"wal"`
+}
+
+const (
+	writeRelabelConfigsKey = "write_relabel_configs"
+
+	protocolVersion1 = "1.0"
+	protocolVersion2 = "2.0"
+
+	defaultDeltaToCumulativeMaxStale = 5 * time.Minute
+)
+
 type CreatedMetric struct {
 	// Enabled if true the _created metrics could be exported
 	Enabled bool `mapstructure:"enabled"`
 }
 
+// DeltaToCumulative allows to configure the conversion of delta metrics.
+type DeltaToCumulative struct {
+	// Enabled if true the delta sums, histograms and exponential histograms
+	// are converted to cumulative ones.
+	Enabled bool `mapstructure:"enabled"`
+
+	// MaxStale is the time after which a series receiving no point is
+	// forgotten, its next point starting a new cumulative series.
+	MaxStale time.Duration `mapstructure:"max_stale"`
+}
+
 type TargetInfo struct {
 	// Enabled if false the target_info metric is not generated by the exporter
 	Enabled bool `mapstructure:"enabled"`
@@ -74,15 +149,113 @@ type RemoteWriteQueue struct {
 	// in the queue at a given time. Ignored if Enabled is false.
 	QueueSize int `mapstructure:"queue_size"`
 
-	// NumWorkers configures the number of workers used by
-	// the collector to fan out remote write requests.
+	// NumConsumers configures the number of shards used by the collector
+	// to fan out remote write requests at start. The number of shards then
+	// follows the send latency and backlog, between MinShards and MaxShards.
 	NumConsumers int `mapstructure:"num_consumers"`
+
+	// MinShards is the minimum number of shards.
+	MinShards int `mapstructure:"min_shards"`
+
+	// MaxShards is the maximum number of shards.
+	MaxShards int `mapstructure:"max_shards"`
+
+	// Capacity is the number of series each shard buffers before
+	// blocking the export.
+	Capacity int `mapstructure:"capacity"`
+
+	// MaxSamplesPerSend is the maximum number of samples per request.
+	MaxSamplesPerSend int `mapstructure:"max_samples_per_send"`
+
+	// BatchSendDeadline is the maximum time a series waits in a shard
+	// before being sent.
+	BatchSendDeadline time.Duration `mapstructure:"batch_send_deadline"`
 }
 
-// TODO(jbd): Add capacity, max_samples_per_send to QueueConfig.
+func (queue RemoteWriteQueue) validateShards() error {
+	if queue.MinShards < 0 || queue.MaxShards < 0 || queue.Capacity < 0 || queue.MaxSamplesPerSend < 0 || queue.BatchSendDeadline < 0 {
+		return fmt.Errorf("remote write queue shard settings can't be negative")
+	}
+
+	if queue.MaxShards != 0 && queue.MinShards > queue.MaxShards {
+		return fmt.Errorf("remote write queue min_shards can't be greater than max_shards")
+	}
+	return nil
+}
+
+func (cfg *Config) validateAdditionalEndpoints() error {
+	names := map[string]bool{}
+	walDirectories := map[string]bool{}
+	if cfg.WAL != nil && cfg.WAL.StorageID == nil {
+		walDirectories[cfg.WAL.Directory] = true
+	}
+	for _, endpoint := range cfg.AdditionalEndpoints {
+		if endpoint.Name == "" {
+			return fmt.Errorf("additional endpoint name can't be empty")
+		}
+		if names[endpoint.Name] {
+			return fmt.Errorf("additional endpoint name %q is used more than once", endpoint.Name)
+		}
+		names[endpoint.Name] = true
+		if endpoint.ClientConfig.Endpoint == "" {
+			return fmt.Errorf("additional endpoint %q has no endpoint", endpoint.Name)
+		}
+		if endpoint.RemoteWriteQueue != nil {
+			if err := endpoint.RemoteWriteQueue.validateShards(); err != nil {
+				return fmt.Errorf("additional endpoint %q: %w", endpoint.Name, err)
+			}
+		}
+		if endpoint.WAL != nil && endpoint.WAL.StorageID == nil {
+			if walDirectories[endpoint.WAL.Directory] {
+				return fmt.Errorf("additional endpoint %q shares its WAL directory with another endpoint", endpoint.Name)
+			}
+			walDirectories[endpoint.WAL.Directory] = true
+		}
+	}
+	return nil
+}
+
+// endpointConfig returns the configuration of the exporter sending to an
+// additional endpoint.
+func (cfg *Config) endpointConfig(endpoint RemoteWriteEndpoint) *Config {
+	endpointCfg := *cfg
+	endpointCfg.AdditionalEndpoints = nil
+	endpointCfg.ClientConfig = endpoint.ClientConfig
+	if endpointCfg.ClientConfig.Timeout == 0 {
+		endpointCfg.ClientConfig.Timeout = cfg.ClientConfig.Timeout
+	}
+	if endpoint.BackOffConfig != nil {
+		endpointCfg.BackOffConfig = *endpoint.BackOffConfig
+	}
+	if endpoint.RemoteWriteQueue != nil {
+		endpointCfg.RemoteWriteQueue = *endpoint.RemoteWriteQueue
+	}
+	if endpoint.RetryOnHTTP429 != nil {
+		endpointCfg.RetryOnHTTP429 = *endpoint.RetryOnHTTP429
+	}
+	endpointCfg.WAL = endpoint.WAL
+	return &endpointCfg
+}
 
 var _ component.Config = (*Config)(nil)
 
+var _ confmap.Unmarshaler = (*Config)(nil)
+
+// Unmarshal decodes write_relabel_configs with the yaml unmarshalers of
+// Prometheus, as the relabel configs use `yaml` tags, and the other settings
+// as usual.
+func (cfg *Config) Unmarshal(componentParser *confmap.Conf) error {
+	cfgMap := componentParser.ToStringMap()
+	if relabelConfigs, ok := cfgMap[writeRelabelConfigsKey]; ok {
+		delete(cfgMap, writeRelabelConfigsKey)
+		var err error
+		if cfg.WriteRelabelConfigs, err = unmarshalRelabelConfigs(relabelConfigs); err != nil {
+			return err
+		}
+	}
+	return confmap.NewFromStringMap(cfgMap).Unmarshal(cfg)
+}
+
 // Validate checks if the exporter configuration is valid
 func (cfg *Config) Validate() error {
 	if cfg.RemoteWriteQueue.QueueSize < 0 {
@@ -97,6 +270,23 @@ func (cfg *Config) Validate() error {
 		return fmt.Errorf("remote write consumer number can't be negative")
 	}
 
+	if err := cfg.RemoteWriteQueue.validateShards(); err != nil {
+		return err
+	}
+
+	if err := cfg.validateAdditionalEndpoints(); err != nil {
+		return err
+	}
+
+	if cfg.Tenant != nil {
+		header := http.CanonicalHeaderKey(cfg.Tenant.header())
+		for name := range cfg.ClientConfig.Headers {
+			if http.CanonicalHeaderKey(name) == header {
+				return fmt.Errorf("tenant header %q can't be set in headers", header)
+			}
+		}
+	}
+
 	if cfg.TargetInfo == nil {
 		cfg.TargetInfo = &TargetInfo{
 			Enabled: true,
@@ -107,6 +297,21 @@ func (cfg *Config) Validate() error {
 			Enabled: false,
 		}
 	}
+	switch cfg.ProtocolVersion {
+	case "":
+		cfg.ProtocolVersion = protocolVersion1
+	case protocolVersion1, protocolVersion2:
+	default:
+		return fmt.Errorf("protocol_version must be %q or %q, got %q", protocolVersion1, protocolVersion2, cfg.ProtocolVersion)
+	}
+	if !cfg.TranslationStrategy.IsValid() {
+		return fmt.Errorf("translation_strategy must be %q, %q or %q, got %q",
+			prometheusremotewrite.UnderscoreEscapingWithSuffixes, prometheusremotewrite.NoUTF8EscapingWithSuffixes,
+			prometheusremotewrite.NoTranslation, cfg.TranslationStrategy)
+	}
+	if cfg.DeltaToCumulative.MaxStale < 0 {
+		return fmt.Errorf("delta_to_cumulative max_stale can't be negative")
+	}
 	if cfg.MaxBatchSizeBytes < 0 {
 		return fmt.Errorf("max_batch_byte_size must be greater than 0")
 	}
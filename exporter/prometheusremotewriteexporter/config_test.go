@@ -52,10 +52,16 @@ func TestLoadConfig(t *testing.T) {
 					Multiplier:          backoff.DefaultMultiplier,
 				},
 				RemoteWriteQueue: RemoteWriteQueue{
-					Enabled:      true,
-					QueueSize:    2000,
-					NumConsumers: 10,
+					Enabled:           true,
+					QueueSize:         2000,
+					NumConsumers:      10,
+					MinShards:         defaultMinShards,
+					MaxShards:         20,
+					Capacity:          defaultShardCapacity,
+					MaxSamplesPerSend: 500,
+					BatchSendDeadline: time.Second,
 				},
+				RetryOnHTTP429:    true,
 				AddMetricSuffixes: false,
 				Namespace:         "test-space",
 				ExternalLabels:    map[string]string{"key1": "value1", "key2": "value2"},
@@ -78,7 +84,17 @@ func TestLoadConfig(t *testing.T) {
 				TargetInfo: &TargetInfo{
 					Enabled: true,
 				},
-				CreatedMetric: &CreatedMetric{Enabled: true},
+				CreatedMetric:   &CreatedMetric{Enabled: true},
+				ProtocolVersion: "2.0",
+				DeltaToCumulative: DeltaToCumulative{
+					Enabled:  true,
+					MaxStale: 10 * time.Minute,
+				},
+				Staleness: StalenessConfig{
+					Enabled:          true,
+					StaleAfterPushes: 2,
+					StaleAfter:       defaultStaleAfter,
+				},
 			},
 		},
 		{
@@ -89,6 +105,34 @@ func TestLoadConfig(t *testing.T) {
 			id:           component.NewIDWithName(metadata.Type, "negative_num_consumers"),
 			errorMessage: "remote write consumer number can't be negative",
 		},
+		{
+			id:           component.NewIDWithName(metadata.Type, "min_shards_above_max"),
+			errorMessage: "remote write queue min_shards can't be greater than max_shards",
+		},
+		{
+			id:           component.NewIDWithName(metadata.Type, "invalid_protocol_version"),
+			errorMessage: `protocol_version must be "1.0" or "2.0", got "3.0"`,
+		},
+		{
+			id:           component.NewIDWithName(metadata.Type, "invalid_translation_strategy"),
+			errorMessage: `translation_strategy must be "UnderscoreEscapingWithSuffixes", "NoUTF8EscapingWithSuffixes" or "NoTranslation", got "NoUTF8Escaping"`,
+		},
+		{
+			id:           component.NewIDWithName(metadata.Type, "invalid_wal_overflow_policy"),
+			errorMessage: `wal overflow_policy must be "drop_oldest" or "drop_newest", got "drop_all"`,
+		},
+		{
+			id:           component.NewIDWithName(metadata.Type, "negative_delta_to_cumulative_max_stale"),
+			errorMessage: "delta_to_cumulative max_stale can't be negative",
+		},
+		{
+			id:           component.NewIDWithName(metadata.Type, "staleness_without_criteria"),
+			errorMessage: "staleness requires stale_after_pushes or stale_after",
+		},
+		{
+			id:           component.NewIDWithName(metadata.Type, "duplicate_additional_endpoint"),
+			errorMessage: `additional endpoint name "long-term" is used more than once`,
+		},
 	}
 
 	for _, tt := range tests {
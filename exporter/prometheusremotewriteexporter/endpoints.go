@@ -0,0 +1,84 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package prometheusremotewriteexporter // import "github.com/open-telemetry/opentelemetry-collector-contrib/exporter/prometheusremotewriteexporter"
+
+import (
+	"context"
+
+	"github.com/prometheus/prometheus/prompb"
+	"go.uber.org/zap"
+)
+
+// exportAsync hands requests to the WAL, or to the shards without waiting for
+// them to be sent, so that a slow additional endpoint doesn't hold back the
+// exporter. Without a WAL, the series which don't fit in the shards are
+// dropped.
+func (prwe *prwExporter) exportAsync(ctx context.Context, requests []*prompb.WriteRequest) {
+	logger := prwe.settings.Logger.With(zap.String("endpoint", prwe.endpointName))
+	select {
+	case <-prwe.closeChan:
+		return
+	default:
+	}
+
+	if prwe.walEnabled() {
+		if err := prwe.wal.persistToWAL(ctx, requests); err != nil {
+			prwe.telemetry.recordDroppedRequest(ctx)
+			logger.Warn("Failed to write to the WAL of the additional endpoint", zap.Error(err))
+		}
+		return
+	}
+
+	var series, metadata []*prompb.WriteRequest
+	for _, request := range requests {
+		if len(request.Timeseries) != 0 {
+			series = append(series, request)
+		} else {
+			metadata = append(metadata, request)
+		}
+	}
+	// The export context ends with PushMetrics, the series are sent after.
+	sendCtx := context.Background()
+	if tenant := tenantFromContext(ctx); tenant != "" {
+		sendCtx = contextWithTenant(sendCtx, tenant)
+	}
+	result, err := prwe.queue.enqueue(sendCtx, series, false)
+	if err != nil {
+		prwe.telemetry.recordDroppedRequest(ctx)
+		logger.Warn("Dropped series for the additional endpoint", zap.Error(err))
+	}
+
+	prwe.wg.Add(1)
+	go func() {
+		defer prwe.wg.Done()
+		for _, request := range metadata {
+			if err := prwe.execute(sendCtx, request); err != nil {
+				logger.Warn("Failed to send metadata to the additional endpoint", zap.Error(err))
+			}
+		}
+		if result == nil {
+			return
+		}
+		if err := result.wait(sendCtx); err != nil {
+			logger.Warn("Failed to send series to the additional endpoint", zap.Error(err))
+		}
+	}()
+}
+
+// cloneWriteRequests copies the series of requests which are modified when
+// they are sent, so that each endpoint can send them concurrently.
+func cloneWriteRequests(requests []*prompb.WriteRequest) []*prompb.WriteRequest {
+	clones := make([]*prompb.WriteRequest, len(requests))
+	for i, request := range requests {
+		clone := *request
+		clone.Timeseries = make([]prompb.TimeSeries, len(request.Timeseries))
+		for j, ts := range request.Timeseries {
+			ts.Labels = append([]prompb.Label(nil), ts.Labels...)
+			ts.Samples = append([]prompb.Sample(nil), ts.Samples...)
+			clone.Timeseries[j] = ts
+		}
+		clones[i] = &clone
+	}
+	return clones
+}
@@ -0,0 +1,121 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package prometheusremotewriteexporter
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/golang/snappy"
+	"github.com/prometheus/prometheus/prompb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/config/confighttp"
+	"go.opentelemetry.io/collector/config/configopaque"
+	"go.opentelemetry.io/collector/config/configretry"
+	"go.opentelemetry.io/collector/exporter/exportertest"
+)
+
+func TestAdditionalEndpoints(t *testing.T) {
+	var primarySeries atomic.Int64
+	primary := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		compressed, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		data, err := snappy.Decode(nil, compressed)
+		require.NoError(t, err)
+		var req prompb.WriteRequest
+		require.NoError(t, proto.Unmarshal(data, &req))
+		primarySeries.Add(int64(len(req.Timeseries)))
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer primary.Close()
+
+	// The additional endpoint hangs until released.
+	release := make(chan struct{})
+	var secondaryTenant atomic.Value
+	var secondaryRequests atomic.Int64
+	secondary := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+		secondaryTenant.Store(r.Header.Get("X-Scope-OrgID"))
+		secondaryRequests.Add(1)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer secondary.Close()
+
+	cfg := createDefaultConfig().(*Config)
+	cfg.ClientConfig.Endpoint = primary.URL
+	cfg.AdditionalEndpoints = []RemoteWriteEndpoint{{
+		Name: "long-term",
+		ClientConfig: confighttp.ClientConfig{
+			Endpoint: secondary.URL,
+			Headers:  map[string]configopaque.String{"X-Scope-OrgID": "archive"},
+		},
+	}}
+	require.NoError(t, cfg.Validate())
+
+	prwe, err := newPRWExporter(cfg, exportertest.NewNopCreateSettings())
+	require.NoError(t, err)
+	require.Len(t, prwe.additionalEndpoints, 1)
+	require.NoError(t, prwe.Start(context.Background(), componenttest.NewNopHost()))
+
+	md := getMetricsFromMetricList(validMetrics1[validSum], validMetrics2[validSum])
+	done := make(chan error)
+	go func() {
+		done <- prwe.PushMetrics(context.Background(), md)
+	}()
+	select {
+	case err = <-done:
+		require.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("the additional endpoint held back the exporter")
+	}
+	assert.Positive(t, primarySeries.Load())
+
+	close(release)
+	require.NoError(t, prwe.Shutdown(context.Background()))
+	assert.Positive(t, secondaryRequests.Load())
+	assert.Equal(t, "archive", secondaryTenant.Load())
+}
+
+func TestEndpointConfig(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.RetryOnHTTP429 = true
+	cfg.WAL = &WALConfig{Directory: "primary"}
+
+	retry := configretry.BackOffConfig{Enabled: false}
+	endpointCfg := cfg.endpointConfig(RemoteWriteEndpoint{
+		Name:          "secondary",
+		ClientConfig:  confighttp.ClientConfig{Endpoint: "http://secondary:9090/api/v1/write"},
+		BackOffConfig: &retry,
+	})
+	assert.Equal(t, "http://secondary:9090/api/v1/write", endpointCfg.ClientConfig.Endpoint)
+	assert.Equal(t, cfg.ClientConfig.Timeout, endpointCfg.ClientConfig.Timeout)
+	assert.Equal(t, retry, endpointCfg.BackOffConfig)
+	assert.Equal(t, cfg.RemoteWriteQueue, endpointCfg.RemoteWriteQueue)
+	assert.True(t, endpointCfg.RetryOnHTTP429)
+	assert.Nil(t, endpointCfg.WAL)
+	assert.Nil(t, endpointCfg.AdditionalEndpoints)
+}
+
+func TestCloneWriteRequests(t *testing.T) {
+	requests := []*prompb.WriteRequest{{
+		Timeseries: []prompb.TimeSeries{{
+			Labels:  []prompb.Label{{Name: "__name__", Value: "up"}},
+			Samples: []prompb.Sample{{Value: 1, Timestamp: 2}, {Value: 1, Timestamp: 1}},
+		}},
+	}}
+	clones := cloneWriteRequests(requests)
+	require.Equal(t, requests, clones)
+
+	orderBySampleTimestamp(clones[0].Timeseries)
+	assert.Equal(t, int64(2), requests[0].Timeseries[0].Samples[0].Timestamp)
+	assert.Equal(t, int64(1), clones[0].Timeseries[0].Samples[0].Timestamp)
+}
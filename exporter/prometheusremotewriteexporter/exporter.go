@@ -9,15 +9,18 @@ import (
 	"errors"
 	"fmt"
 	"io"
-	"math"
 	"net/http"
 	"net/url"
+	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/cenkalti/backoff/v4"
 	"github.com/gogo/protobuf/proto"
 	"github.com/golang/snappy"
+	"github.com/prometheus/prometheus/model/relabel"
 	"github.com/prometheus/prometheus/prompb"
 	"go.opentelemetry.io/collector/component"
 	"go.opentelemetry.io/collector/config/confighttp"
@@ -33,16 +36,29 @@ import (
 	"github.com/open-telemetry/opentelemetry-collector-contrib/exporter/prometheusremotewriteexporter/internal/metadata"
 	prometheustranslator "github.com/open-telemetry/opentelemetry-collector-contrib/pkg/translator/prometheus"
 	"github.com/open-telemetry/opentelemetry-collector-contrib/pkg/translator/prometheusremotewrite"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/pkg/translator/prometheusremotewrite/writev2"
 )
 
 type prwTelemetry interface {
 	recordTranslationFailure(ctx context.Context)
 	recordTranslatedTimeSeries(ctx context.Context, numTS int)
+	recordThrottledRequest(ctx context.Context)
+	recordRetriedRequest(ctx context.Context)
+	recordDroppedRequest(ctx context.Context)
+	recordWALDroppedEntries(ctx context.Context, numEntries int, reason string)
+	recordWALReplayedEntries(ctx context.Context, numEntries int)
+	observeWAL(wal *prweWAL)
 }
 
 type prwTelemetryOtel struct {
 	failedTranslations   metric.Int64Counter
 	translatedTimeSeries metric.Int64Counter
+	throttledRequests    metric.Int64Counter
+	retriedRequests      metric.Int64Counter
+	droppedRequests      metric.Int64Counter
+	walDroppedEntries    metric.Int64Counter
+	walReplayedEntries   metric.Int64Counter
+	wal                  atomic.Pointer[prweWAL]
 	otelAttrs            []attribute.KeyValue
 }
 
@@ -54,24 +70,66 @@ func (p *prwTelemetryOtel) recordTranslatedTimeSeries(ctx context.Context, numTS
 	p.translatedTimeSeries.Add(ctx, int64(numTS), metric.WithAttributes(p.otelAttrs...))
 }
 
+func (p *prwTelemetryOtel) recordThrottledRequest(ctx context.Context) {
+	p.throttledRequests.Add(ctx, 1, metric.WithAttributes(p.otelAttrs...))
+}
+
+func (p *prwTelemetryOtel) recordRetriedRequest(ctx context.Context) {
+	p.retriedRequests.Add(ctx, 1, metric.WithAttributes(p.otelAttrs...))
+}
+
+func (p *prwTelemetryOtel) recordDroppedRequest(ctx context.Context) {
+	p.droppedRequests.Add(ctx, 1, metric.WithAttributes(p.otelAttrs...))
+}
+
+func (p *prwTelemetryOtel) recordWALDroppedEntries(ctx context.Context, numEntries int, reason string) {
+	attrs := append([]attribute.KeyValue{attribute.String("reason", reason)}, p.otelAttrs...)
+	p.walDroppedEntries.Add(ctx, int64(numEntries), metric.WithAttributes(attrs...))
+}
+
+func (p *prwTelemetryOtel) recordWALReplayedEntries(ctx context.Context, numEntries int) {
+	p.walReplayedEntries.Add(ctx, int64(numEntries), metric.WithAttributes(p.otelAttrs...))
+}
+
+// observeWAL makes the size and lag gauges report the state of wal.
+func (p *prwTelemetryOtel) observeWAL(wal *prweWAL) {
+	p.wal.Store(wal)
+}
+
 // prwExporter converts OTLP metrics to Prometheus remote write TimeSeries and sends them to a remote endpoint.
 type prwExporter struct {
 	endpointURL       *url.URL
 	client            *http.Client
 	wg                *sync.WaitGroup
 	closeChan         chan struct{}
-	concurrency       int
+	queue             *shardQueue
 	userAgentHeader   string
 	maxBatchSizeBytes int
 	clientSettings    *confighttp.ClientConfig
 	settings          component.TelemetrySettings
-	retrySettings     configretry.BackOffConfig
-	wal               *prweWAL
-	exporterSettings  prometheusremotewrite.Settings
-	telemetry         prwTelemetry
+	id                component.ID
+	endpointName      string
+	// writeRelabelConfigs relabel the series before they are sent.
+	writeRelabelConfigs []*relabel.Config
+	// additionalEndpoints send the series translated by this exporter to
+	// the additional endpoints of its configuration.
+	additionalEndpoints []*prwExporter
+	retrySettings       configretry.BackOffConfig
+	wal                 *prweWAL
+	exporterSettings    prometheusremotewrite.Settings
+	telemetry           prwTelemetry
+	protocolVersion     string
+	retryOnHTTP429      bool
+	// tenants splits the metrics by tenant when tenant routing is enabled,
+	// and tenantHeader is the header set to the tenant of each request.
+	tenants      *tenantRouter
+	tenantHeader string
+	// staleness tracks the series sent, to send staleness markers for the
+	// series which are no longer sent.
+	staleness *stalenessTracker
 }
 
-func newPRWTelemetry(set exporter.CreateSettings) (prwTelemetry, error) {
+func newPRWTelemetry(set exporter.CreateSettings, endpointName string) (prwTelemetry, error) {
 
 	meter := metadata.Meter(set.TelemetrySettings)
 	// TODO: create helper functions similar to the processor helper: BuildCustomMetricName
@@ -86,17 +144,96 @@ func newPRWTelemetry(set exporter.CreateSettings) (prwTelemetry, error) {
 		metric.WithUnit("1"),
 	)
 
-	return &prwTelemetryOtel{
+	throttledRequests, errThrottledRequests := meter.Int64Counter(prefix+"throttled_requests",
+		metric.WithDescription("Number of remote write requests rejected by the endpoint with HTTP 429"),
+		metric.WithUnit("1"),
+	)
+
+	retriedRequests, errRetriedRequests := meter.Int64Counter(prefix+"retried_requests",
+		metric.WithDescription("Number of remote write requests retried after a recoverable error"),
+		metric.WithUnit("1"),
+	)
+
+	droppedRequests, errDroppedRequests := meter.Int64Counter(prefix+"dropped_requests",
+		metric.WithDescription("Number of remote write requests dropped after a permanent error or exhausted retries"),
+		metric.WithUnit("1"),
+	)
+
+	walDroppedEntries, errWALDroppedEntries := meter.Int64Counter(prefix+"wal_dropped_entries",
+		metric.WithDescription("Number of WAL entries dropped because of the WAL size or age limits"),
+		metric.WithUnit("1"),
+	)
+
+	walReplayedEntries, errWALReplayedEntries := meter.Int64Counter(prefix+"wal_replayed_entries",
+		metric.WithDescription("Number of WAL entries read and exported to the remote write endpoint"),
+		metric.WithUnit("1"),
+	)
+
+	p := &prwTelemetryOtel{
 		failedTranslations:   failedTranslations,
 		translatedTimeSeries: translatedTimeSeries,
+		throttledRequests:    throttledRequests,
+		retriedRequests:      retriedRequests,
+		droppedRequests:      droppedRequests,
+		walDroppedEntries:    walDroppedEntries,
+		walReplayedEntries:   walReplayedEntries,
 		otelAttrs: []attribute.KeyValue{
 			attribute.String("exporter", set.ID.String()),
 		},
-	}, errors.Join(errFailedTranslation, errTranslatedMetrics)
+	}
+	if endpointName != "" {
+		p.otelAttrs = append(p.otelAttrs, attribute.String("endpoint", endpointName))
+	}
+
+	_, errWALSize := meter.Int64ObservableGauge(prefix+"wal_size",
+		metric.WithDescription("Size of the WAL on disk"),
+		metric.WithUnit("By"),
+		metric.WithInt64Callback(func(_ context.Context, o metric.Int64Observer) error {
+			if wal := p.wal.Load(); wal != nil {
+				o.Observe(wal.sizeBytes.Load(), metric.WithAttributes(p.otelAttrs...))
+			}
+			return nil
+		}),
+	)
+
+	_, errWALLag := meter.Int64ObservableGauge(prefix+"wal_lag",
+		metric.WithDescription("Number of WAL entries not read yet"),
+		metric.WithUnit("1"),
+		metric.WithInt64Callback(func(_ context.Context, o metric.Int64Observer) error {
+			if wal := p.wal.Load(); wal != nil {
+				o.Observe(wal.lag(), metric.WithAttributes(p.otelAttrs...))
+			}
+			return nil
+		}),
+	)
+
+	return p, errors.Join(errFailedTranslation, errTranslatedMetrics, errThrottledRequests, errRetriedRequests, errDroppedRequests,
+		errWALDroppedEntries, errWALReplayedEntries, errWALSize, errWALLag)
 }
 
 // newPRWExporter initializes a new prwExporter instance and sets fields accordingly.
 func newPRWExporter(cfg *Config, set exporter.CreateSettings) (*prwExporter, error) {
+	prwe, err := newEndpointExporter(cfg, set, "")
+	if err != nil {
+		return nil, err
+	}
+	// The additional endpoints get the staleness markers of the exporter.
+	if cfg.Staleness.Enabled {
+		prwe.staleness = newStalenessTracker(cfg.Staleness)
+	}
+	for _, endpoint := range cfg.AdditionalEndpoints {
+		additional, err := newEndpointExporter(cfg.endpointConfig(endpoint), set, endpoint.Name)
+		if err != nil {
+			return nil, fmt.Errorf("additional endpoint %q: %w", endpoint.Name, err)
+		}
+		prwe.additionalEndpoints = append(prwe.additionalEndpoints, additional)
+	}
+	return prwe, nil
+}
+
+// newEndpointExporter initializes the prwExporter sending to the endpoint of
+// cfg, endpointName is empty for the main endpoint of the exporter.
+func newEndpointExporter(cfg *Config, set exporter.CreateSettings, endpointName string) (*prwExporter, error) {
 	sanitizedLabels, err := validateAndSanitizeExternalLabels(cfg)
 	if err != nil {
 		return nil, err
@@ -107,7 +244,7 @@ func newPRWExporter(cfg *Config, set exporter.CreateSettings) (*prwExporter, err
 		return nil, errors.New("invalid endpoint")
 	}
 
-	prwTelemetry, err := newPRWTelemetry(set)
+	prwTelemetry, err := newPRWTelemetry(set, endpointName)
 	if err != nil {
 		return nil, err
 	}
@@ -115,15 +252,17 @@ func newPRWExporter(cfg *Config, set exporter.CreateSettings) (*prwExporter, err
 	userAgentHeader := fmt.Sprintf("%s/%s", strings.ReplaceAll(strings.ToLower(set.BuildInfo.Description), " ", "-"), set.BuildInfo.Version)
 
 	prwe := &prwExporter{
-		endpointURL:       endpointURL,
-		wg:                new(sync.WaitGroup),
-		closeChan:         make(chan struct{}),
-		userAgentHeader:   userAgentHeader,
-		maxBatchSizeBytes: cfg.MaxBatchSizeBytes,
-		concurrency:       cfg.RemoteWriteQueue.NumConsumers,
-		clientSettings:    &cfg.ClientConfig,
-		settings:          set.TelemetrySettings,
-		retrySettings:     cfg.BackOffConfig,
+		endpointURL:         endpointURL,
+		wg:                  new(sync.WaitGroup),
+		closeChan:           make(chan struct{}),
+		userAgentHeader:     userAgentHeader,
+		maxBatchSizeBytes:   cfg.MaxBatchSizeBytes,
+		clientSettings:      &cfg.ClientConfig,
+		settings:            set.TelemetrySettings,
+		id:                  set.ID,
+		endpointName:        endpointName,
+		writeRelabelConfigs: cfg.WriteRelabelConfigs,
+		retrySettings:       cfg.BackOffConfig,
 		exporterSettings: prometheusremotewrite.Settings{
 			Namespace:           cfg.Namespace,
 			ExternalLabels:      sanitizedLabels,
@@ -131,11 +270,27 @@ func newPRWExporter(cfg *Config, set exporter.CreateSettings) (*prwExporter, err
 			ExportCreatedMetric: cfg.CreatedMetric.Enabled,
 			AddMetricSuffixes:   cfg.AddMetricSuffixes,
 			SendMetadata:        cfg.SendMetadata,
+			TranslationStrategy: cfg.TranslationStrategy,
 		},
-		telemetry: prwTelemetry,
+		telemetry:       prwTelemetry,
+		protocolVersion: cfg.ProtocolVersion,
+		retryOnHTTP429:  cfg.RetryOnHTTP429,
+	}
+	if cfg.Tenant != nil {
+		prwe.tenants = newTenantRouter(cfg.Tenant)
+		prwe.tenantHeader = cfg.Tenant.header()
+	}
+	if cfg.DeltaToCumulative.Enabled {
+		prwe.exporterSettings.DeltaToCumulative = prometheusremotewrite.NewDeltaToCumulativeConverter(cfg.DeltaToCumulative.MaxStale)
+	}
+	if prwe.protocolVersion == protocolVersion2 {
+		// Remote Write 2.0 carries metadata and created timestamps with each series.
+		prwe.exporterSettings.ExportCreatedMetric = true
+		prwe.exporterSettings.SendMetadata = true
 	}
 
-	prwe.wal = newWAL(cfg.WAL, prwe.export)
+	prwe.queue = newShardQueue(cfg.RemoteWriteQueue, cfg.MaxBatchSizeBytes, prwe.protocolVersion == protocolVersion2, prwe.execute, set.Logger)
+	prwe.wal = newWAL(cfg.WAL, prwe.export, prwTelemetry)
 	return prwe, nil
 }
 
@@ -145,7 +300,21 @@ func (prwe *prwExporter) Start(ctx context.Context, host component.Host) (err er
 	if err != nil {
 		return err
 	}
-	return prwe.turnOnWALIfEnabled(contextWithLogger(ctx, prwe.settings.Logger.Named("prw.wal")))
+	// The shards outlive Start, they are stopped by Shutdown.
+	prwe.queue.start(context.Background())
+	if err = prwe.turnOnWALIfEnabled(contextWithLogger(ctx, prwe.settings.Logger.Named("prw.wal")), host); err != nil {
+		return err
+	}
+	if prwe.staleness != nil && prwe.staleness.checkInterval() > 0 {
+		prwe.wg.Add(1)
+		go prwe.sendStalenessMarkers(prwe.staleness.checkInterval())
+	}
+	for _, additional := range prwe.additionalEndpoints {
+		if err = additional.Start(ctx, host); err != nil {
+			return fmt.Errorf("additional endpoint %q: %w", additional.endpointName, err)
+		}
+	}
+	return nil
 }
 
 func (prwe *prwExporter) shutdownWALIfEnabled() error {
@@ -165,6 +334,12 @@ func (prwe *prwExporter) Shutdown(context.Context) error {
 	}
 	err := prwe.shutdownWALIfEnabled()
 	prwe.wg.Wait()
+	if prwe.queue != nil {
+		prwe.queue.stop()
+	}
+	for _, additional := range prwe.additionalEndpoints {
+		err = multierr.Append(err, additional.Shutdown(context.Background()))
+	}
 	return err
 }
 
@@ -179,23 +354,48 @@ func (prwe *prwExporter) PushMetrics(ctx context.Context, md pmetric.Metrics) er
 	case <-prwe.closeChan:
 		return errors.New("shutdown has been called")
 	default:
+		if prwe.tenants == nil {
+			return prwe.pushMetrics(ctx, md)
+		}
 
-		tsMap, err := prometheusremotewrite.FromMetrics(md, prwe.exporterSettings)
+		split, err := prwe.tenants.split(ctx, md)
 		if err != nil {
-			prwe.telemetry.recordTranslationFailure(ctx)
-			prwe.settings.Logger.Debug("failed to translate metrics, exporting remaining metrics", zap.Error(err), zap.Int("translated", len(tsMap)))
+			prwe.settings.Logger.Warn("Dropped the metrics of tenants above max_tenants", zap.Error(err))
+			err = consumererror.NewPermanent(err)
+		}
+		for tenant, tenantMetrics := range split {
+			err = multierr.Append(err, prwe.pushMetrics(contextWithTenant(ctx, tenant), tenantMetrics))
 		}
+		return err
+	}
+}
 
-		prwe.telemetry.recordTranslatedTimeSeries(ctx, len(tsMap))
+// pushMetrics translates and exports md, to the tenant of ctx if any.
+func (prwe *prwExporter) pushMetrics(ctx context.Context, md pmetric.Metrics) error {
+	settings := prwe.exporterSettings
+	settings.Tenant = tenantFromContext(ctx)
+	tsMap, err := prometheusremotewrite.FromMetrics(md, settings)
+	if err != nil {
+		prwe.telemetry.recordTranslationFailure(ctx)
+		prwe.settings.Logger.Debug("failed to translate metrics, exporting remaining metrics", zap.Error(err), zap.Int("translated", len(tsMap)))
+	}
 
-		var m []*prompb.MetricMetadata
-		if prwe.exporterSettings.SendMetadata {
-			m = prometheusremotewrite.OtelMetricsToMetadata(md, prwe.exporterSettings.AddMetricSuffixes)
-		}
+	prwe.telemetry.recordTranslatedTimeSeries(ctx, len(tsMap))
+	tsMap = relabelTimeSeries(tsMap, prwe.writeRelabelConfigs)
+	if prwe.staleness != nil {
+		prwe.staleness.observe(tenantFromContext(ctx), tsMap)
+	}
 
-		// Call export even if a conversion error, since there may be points that were successfully converted.
-		return prwe.handleExport(ctx, tsMap, m)
+	var m []*prompb.MetricMetadata
+	if prwe.exporterSettings.SendMetadata {
+		m = prometheusremotewrite.MetricsToMetadata(md, prometheusremotewrite.Settings{
+			AddMetricSuffixes:   prwe.exporterSettings.AddMetricSuffixes,
+			TranslationStrategy: prwe.exporterSettings.TranslationStrategy,
+		})
 	}
+
+	// Call export even if a conversion error, since there may be points that were successfully converted.
+	return prwe.handleExport(ctx, tsMap, m)
 }
 
 func validateAndSanitizeExternalLabels(cfg *Config) (map[string]string, error) {
@@ -204,7 +404,10 @@ func validateAndSanitizeExternalLabels(cfg *Config) (map[string]string, error) {
 		if key == "" || value == "" {
 			return nil, fmt.Errorf("prometheus remote write: external labels configuration contains an empty key or value")
 		}
-		sanitizedLabels[prometheustranslator.NormalizeLabel(key)] = value
+		if cfg.TranslationStrategy.ShouldEscape() {
+			key = prometheustranslator.NormalizeLabel(key)
+		}
+		sanitizedLabels[key] = value
 	}
 
 	return sanitizedLabels, nil
@@ -217,10 +420,17 @@ func (prwe *prwExporter) handleExport(ctx context.Context, tsMap map[string]*pro
 	}
 
 	// Calls the helper function to convert and batch the TsMap to the desired format
-	requests, err := batchTimeSeries(tsMap, prwe.maxBatchSizeBytes, m)
+	batch := batchTimeSeries
+	if prwe.protocolVersion == protocolVersion2 {
+		batch = batchTimeSeriesV2
+	}
+	requests, err := batch(tsMap, prwe.maxBatchSizeBytes, m)
 	if err != nil {
 		return err
 	}
+	for _, additional := range prwe.additionalEndpoints {
+		additional.exportAsync(ctx, cloneWriteRequests(requests))
+	}
 	if !prwe.walEnabled() {
 		// Perform a direct export otherwise.
 		return prwe.export(ctx, requests)
@@ -228,64 +438,58 @@ func (prwe *prwExporter) handleExport(ctx context.Context, tsMap map[string]*pro
 
 	// Otherwise the WAL is enabled, and just persist the requests to the WAL
 	// and they'll be exported in another goroutine to the RemoteWrite endpoint.
-	if err = prwe.wal.persistToWAL(requests); err != nil {
+	if err = prwe.wal.persistToWAL(ctx, requests); err != nil {
 		return consumererror.NewPermanent(err)
 	}
 	return nil
 }
 
-// export sends a Snappy-compressed WriteRequest containing TimeSeries to a remote write endpoint in order
+// export sends Snappy-compressed WriteRequests to a remote write endpoint. The
+// series are sent by the shards of the queue, which keep the samples of each
+// series in order, and export waits for them to be sent.
 func (prwe *prwExporter) export(ctx context.Context, requests []*prompb.WriteRequest) error {
-	input := make(chan *prompb.WriteRequest, len(requests))
+	var errs error
+	series := make([]*prompb.WriteRequest, 0, len(requests))
 	for _, request := range requests {
-		input <- request
+		if len(request.Timeseries) != 0 {
+			series = append(series, request)
+			continue
+		}
+		// Metadata requests don't depend on the order of samples.
+		if errExecute := prwe.execute(ctx, request); errExecute != nil {
+			errs = multierr.Append(errs, consumererror.NewPermanent(errExecute))
+		}
 	}
-	close(input)
-
-	var wg sync.WaitGroup
-
-	concurrencyLimit := int(math.Min(float64(prwe.concurrency), float64(len(requests))))
-	wg.Add(concurrencyLimit) // used to wait for workers to be finished
-
-	var mu sync.Mutex
-	var errs error
-	// Run concurrencyLimit of workers until there
-	// is no more requests to execute in the input channel.
-	for i := 0; i < concurrencyLimit; i++ {
-		go func() {
-			defer wg.Done()
-			for {
-				select {
-				case <-ctx.Done(): // Check firstly to ensure that the context wasn't cancelled.
-					return
-
-				case request, ok := <-input:
-					if !ok {
-						return
-					}
-					if errExecute := prwe.execute(ctx, request); errExecute != nil {
-						mu.Lock()
-						errs = multierr.Append(errs, consumererror.NewPermanent(errExecute))
-						mu.Unlock()
-					}
-				}
-			}
-		}()
+	if len(series) == 0 {
+		return errs
+	}
+	if err := prwe.queue.append(ctx, series); err != nil {
+		errs = multierr.Append(errs, consumererror.NewPermanent(err))
 	}
-	wg.Wait()
-
 	return errs
 }
 
 func (prwe *prwExporter) execute(ctx context.Context, writeReq *prompb.WriteRequest) error {
 	// Uses proto.Marshal to convert the WriteRequest into bytes array
-	data, errMarshal := proto.Marshal(writeReq)
+	contentType, version := "application/x-protobuf", "0.1.0"
+	var data []byte
+	var errMarshal error
+	if prwe.protocolVersion == protocolVersion2 {
+		contentType, version = writev2.ContentType, "2.0.0"
+		data, errMarshal = prometheusremotewrite.ToWriteRequestV2(writeReq).Marshal()
+	} else {
+		data, errMarshal = proto.Marshal(writeReq)
+	}
 	if errMarshal != nil {
 		return consumererror.NewPermanent(errMarshal)
 	}
 	buf := make([]byte, len(data), cap(data))
 	compressedData := snappy.Encode(buf, data)
 
+	// executeFunc records the Retry-After header of retryable responses in b
+	// to delay the next attempt.
+	b := &retryAfterBackOff{}
+
 	// executeFunc can be used for backoff and non backoff scenarios.
 	executeFunc := func() error {
 		// check there was no timeout in the component level to avoid retries
@@ -306,9 +510,12 @@ func (prwe *prwExporter) execute(ctx context.Context, writeReq *prompb.WriteRequ
 		// Add necessary headers specified by:
 		// https://cortexmetrics.io/docs/apis/#remote-api
 		req.Header.Add("Content-Encoding", "snappy")
-		req.Header.Set("Content-Type", "application/x-protobuf")
-		req.Header.Set("X-Prometheus-Remote-Write-Version", "0.1.0")
+		req.Header.Set("Content-Type", contentType)
+		req.Header.Set("X-Prometheus-Remote-Write-Version", version)
 		req.Header.Set("User-Agent", prwe.userAgentHeader)
+		if tenant := tenantFromContext(ctx); tenant != "" && prwe.tenantHeader != "" {
+			req.Header.Set(prwe.tenantHeader, tenant)
+		}
 
 		resp, err := prwe.client.Do(req)
 		if err != nil {
@@ -318,6 +525,7 @@ func (prwe *prwExporter) execute(ctx context.Context, writeReq *prompb.WriteRequ
 
 		// 2xx status code is considered a success
 		// 5xx errors are recoverable and the exporter should retry
+		// 429 errors are recoverable if retry_on_http_429 is enabled
 		// Reference for different behavior according to status code:
 		// https://github.com/prometheus/prometheus/pull/2552/files#diff-ae8db9d16d8057358e49d694522e7186
 		if resp.StatusCode >= 200 && resp.StatusCode < 300 {
@@ -326,7 +534,11 @@ func (prwe *prwExporter) execute(ctx context.Context, writeReq *prompb.WriteRequ
 
 		body, err := io.ReadAll(io.LimitReader(resp.Body, 256))
 		rerr := fmt.Errorf("remote write returned HTTP status %v; err = %w: %s", resp.Status, err, body)
-		if resp.StatusCode >= 500 && resp.StatusCode < 600 {
+		if resp.StatusCode == http.StatusTooManyRequests {
+			prwe.telemetry.recordThrottledRequest(ctx)
+		}
+		if (resp.StatusCode >= 500 && resp.StatusCode < 600) || (resp.StatusCode == http.StatusTooManyRequests && prwe.retryOnHTTP429) {
+			b.retryAfter = parseRetryAfter(resp.Header.Get("Retry-After"), time.Now())
 			return rerr
 		}
 		return backoff.Permanent(consumererror.NewPermanent(rerr))
@@ -335,7 +547,7 @@ func (prwe *prwExporter) execute(ctx context.Context, writeReq *prompb.WriteRequ
 	var err error
 	if prwe.retrySettings.Enabled {
 		// Use the BackOff instance to retry the func with exponential backoff.
-		err = backoff.Retry(executeFunc, &backoff.ExponentialBackOff{
+		b.BackOff = &backoff.ExponentialBackOff{
 			InitialInterval:     prwe.retrySettings.InitialInterval,
 			RandomizationFactor: prwe.retrySettings.RandomizationFactor,
 			Multiplier:          prwe.retrySettings.Multiplier,
@@ -343,24 +555,74 @@ func (prwe *prwExporter) execute(ctx context.Context, writeReq *prompb.WriteRequ
 			MaxElapsedTime:      prwe.retrySettings.MaxElapsedTime,
 			Stop:                backoff.Stop,
 			Clock:               backoff.SystemClock,
+		}
+		err = backoff.RetryNotify(executeFunc, backoff.WithContext(b, ctx), func(error, time.Duration) {
+			prwe.telemetry.recordRetriedRequest(ctx)
 		})
 	} else {
 		err = executeFunc()
 	}
 
 	if err != nil {
+		prwe.telemetry.recordDroppedRequest(ctx)
 		return consumererror.NewPermanent(err)
 	}
 
 	return err
 }
 
+// retryAfterBackOff waits at least the delay requested by the last
+// Retry-After header before the next attempt.
+type retryAfterBackOff struct {
+	backoff.BackOff
+	retryAfter time.Duration
+}
+
+func (b *retryAfterBackOff) NextBackOff() time.Duration {
+	next := b.BackOff.NextBackOff()
+	if next == backoff.Stop {
+		return next
+	}
+	next = max(next, b.retryAfter)
+	b.retryAfter = 0
+	return next
+}
+
+// parseRetryAfter parses a Retry-After header, either a number of seconds or
+// an HTTP date. It returns 0 if the header is missing or invalid.
+func parseRetryAfter(value string, now time.Time) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0
+		}
+		return time.Duration(seconds) * time.Second
+	}
+	if date, err := http.ParseTime(value); err == nil && date.After(now) {
+		return date.Sub(now)
+	}
+	return 0
+}
+
 func (prwe *prwExporter) walEnabled() bool { return prwe.wal != nil }
 
-func (prwe *prwExporter) turnOnWALIfEnabled(ctx context.Context) error {
+func (prwe *prwExporter) turnOnWALIfEnabled(ctx context.Context, host component.Host) error {
 	if !prwe.walEnabled() {
 		return nil
 	}
+	if storageID := prwe.wal.walConfig.StorageID; storageID != nil {
+		storageName := walStorageName
+		if prwe.endpointName != "" {
+			storageName += "_" + prwe.endpointName
+		}
+		client, err := getStorageClient(ctx, host, *storageID, prwe.id, storageName)
+		if err != nil {
+			return fmt.Errorf("failed to get storage client for the WAL: %w", err)
+		}
+		prwe.wal.useStorage(client)
+	}
 	cancelCtx, cancel := context.WithCancel(ctx)
 	go func() {
 		<-prwe.closeChan
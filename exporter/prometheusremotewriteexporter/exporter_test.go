@@ -5,6 +5,7 @@ package prometheusremotewriteexporter
 
 import (
 	"context"
+	"errors"
 	"io"
 	"net/http"
 	"net/http/httptest"
@@ -28,9 +29,12 @@ import (
 	"go.opentelemetry.io/collector/exporter"
 	"go.opentelemetry.io/collector/exporter/exporterhelper"
 	"go.opentelemetry.io/collector/exporter/exportertest"
+	"go.opentelemetry.io/collector/pdata/pcommon"
 	"go.opentelemetry.io/collector/pdata/pmetric"
 
 	"github.com/open-telemetry/opentelemetry-collector-contrib/internal/coreinternal/testdata"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/pkg/translator/prometheusremotewrite"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/pkg/translator/prometheusremotewrite/writev2"
 )
 
 // Test_NewPRWExporter checks that a new exporter instance with non-nil fields is initialized
@@ -210,6 +214,7 @@ func Test_Start(t *testing.T) {
 				return
 			}
 			assert.NotNil(t, prwe.client)
+			assert.NoError(t, prwe.Shutdown(context.Background()))
 		})
 	}
 }
@@ -336,6 +341,58 @@ func TestNoMetricsNoError(t *testing.T) {
 	assert.NoError(t, runExportPipeline(nil, serverURL))
 }
 
+func Test_exportProtocolVersion2(t *testing.T) {
+	var got writev2.Request
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "2.0.0", r.Header.Get("X-Prometheus-Remote-Write-Version"))
+		assert.Equal(t, writev2.ContentType, r.Header.Get("Content-Type"))
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		dest, err := snappy.Decode(nil, body)
+		require.NoError(t, err)
+		require.NoError(t, got.Unmarshal(dest))
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	cfg := createDefaultConfig().(*Config)
+	cfg.ClientConfig.Endpoint = server.URL
+	cfg.ProtocolVersion = protocolVersion2
+	prwe, err := newPRWExporter(cfg, exportertest.NewNopCreateSettings())
+	require.NoError(t, err)
+	assert.True(t, prwe.exporterSettings.ExportCreatedMetric)
+	assert.True(t, prwe.exporterSettings.SendMetadata)
+	require.NoError(t, prwe.Start(context.Background(), componenttest.NewNopHost()))
+	defer func() {
+		require.NoError(t, prwe.Shutdown(context.Background()))
+	}()
+
+	md := pmetric.NewMetrics()
+	m := md.ResourceMetrics().AppendEmpty().ScopeMetrics().AppendEmpty().Metrics().AppendEmpty()
+	m.SetName("requests")
+	m.SetDescription("Number of requests.")
+	m.SetEmptySum().SetIsMonotonic(true)
+	m.Sum().SetAggregationTemporality(pmetric.AggregationTemporalityCumulative)
+	dp := m.Sum().DataPoints().AppendEmpty()
+	dp.SetIntValue(3)
+	dp.SetStartTimestamp(pcommon.Timestamp(1000 * time.Millisecond))
+	dp.SetTimestamp(pcommon.Timestamp(2000 * time.Millisecond))
+	require.NoError(t, prwe.PushMetrics(context.Background(), md))
+
+	var requests *writev2.TimeSeries
+	for i, ts := range got.Timeseries {
+		labels, err := writev2.DesymbolizeLabels(ts.LabelsRefs, got.Symbols)
+		require.NoError(t, err)
+		if metricName(labels) == "requests_total" {
+			requests = &got.Timeseries[i]
+		}
+	}
+	require.NotNil(t, requests)
+	assert.Equal(t, int64(1000), requests.CreatedTimestamp)
+	assert.Equal(t, writev2.MetricTypeCounter, requests.Metadata.Type)
+	assert.Equal(t, "Number of requests.", got.Symbols[requests.Metadata.HelpRef])
+}
+
 func runExportPipeline(ts *prompb.TimeSeries, endpoint *url.URL) error {
 	// First we will construct a TimeSeries array from the testutils package
 	testmap := make(map[string]*prompb.TimeSeries)
@@ -369,12 +426,18 @@ func runExportPipeline(ts *prompb.TimeSeries, endpoint *url.URL) error {
 		return err
 	}
 
-	return prwe.handleExport(context.Background(), testmap, nil)
+	err = prwe.handleExport(context.Background(), testmap, nil)
+	return errors.Join(err, prwe.Shutdown(context.Background()))
 }
 
 type mockPRWTelemetry struct {
 	failedTranslations   int
 	translatedTimeSeries int
+	throttledRequests    int
+	retriedRequests      int
+	droppedRequests      int
+	walDroppedEntries    int
+	walReplayedEntries   int
 }
 
 func (m *mockPRWTelemetry) recordTranslationFailure(_ context.Context) {
@@ -385,6 +448,28 @@ func (m *mockPRWTelemetry) recordTranslatedTimeSeries(_ context.Context, numTs i
 	m.translatedTimeSeries += numTs
 }
 
+func (m *mockPRWTelemetry) recordThrottledRequest(_ context.Context) {
+	m.throttledRequests++
+}
+
+func (m *mockPRWTelemetry) recordRetriedRequest(_ context.Context) {
+	m.retriedRequests++
+}
+
+func (m *mockPRWTelemetry) recordDroppedRequest(_ context.Context) {
+	m.droppedRequests++
+}
+
+func (m *mockPRWTelemetry) recordWALDroppedEntries(_ context.Context, numEntries int, _ string) {
+	m.walDroppedEntries += numEntries
+}
+
+func (m *mockPRWTelemetry) recordWALReplayedEntries(_ context.Context, numEntries int) {
+	m.walReplayedEntries += numEntries
+}
+
+func (m *mockPRWTelemetry) observeWAL(_ *prweWAL) {}
+
 // Test_PushMetrics checks the number of TimeSeries received by server and the number of metrics dropped is the same as
 // expected
 func Test_PushMetrics(t *testing.T) {
@@ -876,6 +961,15 @@ func Test_validateAndSanitizeExternalLabels(t *testing.T) {
 			assert.NoError(t, err)
 		})
 	}
+
+	t.Run("utf8_labels_kept", func(t *testing.T) {
+		cfg := createDefaultConfig().(*Config)
+		cfg.TranslationStrategy = prometheusremotewrite.NoUTF8EscapingWithSuffixes
+		cfg.ExternalLabels = map[string]string{"cluster.name": "val1"}
+		newLabels, err := validateAndSanitizeExternalLabels(cfg)
+		assert.NoError(t, err)
+		assert.EqualValues(t, map[string]string{"cluster.name": "val1"}, newLabels)
+	})
 }
 
 // Ensures that when we attach the Write-Ahead-Log(WAL) to the exporter,
@@ -1108,6 +1202,7 @@ func TestRetries(t *testing.T) {
 				retrySettings: configretry.BackOffConfig{
 					Enabled: true,
 				},
+				telemetry: &mockPRWTelemetry{},
 			}
 
 			err = exporter.execute(tt.ctx, &prompb.WriteRequest{})
@@ -1117,3 +1212,130 @@ func TestRetries(t *testing.T) {
 		})
 	}
 }
+
+func TestRetryOnHTTP429(t *testing.T) {
+	tts := []struct {
+		name             string
+		retryOnHTTP429   bool
+		expectedAttempts int
+		assertError      assert.ErrorAssertionFunc
+		expected         mockPRWTelemetry
+	}{
+		{
+			name:             "429 is dropped by default",
+			expectedAttempts: 1,
+			assertError:      assert.Error,
+			expected:         mockPRWTelemetry{throttledRequests: 1, droppedRequests: 1},
+		},
+		{
+			name:             "429 is retried after Retry-After",
+			retryOnHTTP429:   true,
+			expectedAttempts: 3,
+			assertError:      assert.NoError,
+			expected:         mockPRWTelemetry{throttledRequests: 2, retriedRequests: 2},
+		},
+	}
+
+	for _, tt := range tts {
+		t.Run(tt.name, func(t *testing.T) {
+			var attempts []time.Time
+			mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				attempts = append(attempts, time.Now())
+				if len(attempts) < 3 {
+					w.Header().Set("Retry-After", "1")
+					http.Error(w, http.StatusText(http.StatusTooManyRequests), http.StatusTooManyRequests)
+					return
+				}
+				w.WriteHeader(http.StatusOK)
+			}))
+			defer mockServer.Close()
+
+			endpointURL, err := url.Parse(mockServer.URL)
+			require.NoError(t, err)
+
+			telemetry := &mockPRWTelemetry{}
+			exporter := &prwExporter{
+				endpointURL: endpointURL,
+				client:      http.DefaultClient,
+				retrySettings: configretry.BackOffConfig{
+					Enabled:         true,
+					InitialInterval: time.Millisecond,
+					MaxInterval:     time.Millisecond,
+					MaxElapsedTime:  time.Minute,
+				},
+				telemetry:      telemetry,
+				retryOnHTTP429: tt.retryOnHTTP429,
+			}
+
+			err = exporter.execute(context.Background(), &prompb.WriteRequest{})
+			tt.assertError(t, err)
+			assert.Len(t, attempts, tt.expectedAttempts)
+			assert.Equal(t, tt.expected, *telemetry)
+			for i := 1; i < len(attempts); i++ {
+				assert.GreaterOrEqual(t, attempts[i].Sub(attempts[i-1]), time.Second)
+			}
+		})
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	assert.Equal(t, time.Duration(0), parseRetryAfter("", now))
+	assert.Equal(t, 5*time.Second, parseRetryAfter("5", now))
+	assert.Equal(t, time.Duration(0), parseRetryAfter("-5", now))
+	assert.Equal(t, 30*time.Second, parseRetryAfter(now.Add(30*time.Second).Format(http.TimeFormat), now))
+	assert.Equal(t, time.Duration(0), parseRetryAfter(now.Add(-time.Minute).Format(http.TimeFormat), now))
+	assert.Equal(t, time.Duration(0), parseRetryAfter("soon", now))
+}
+
+func TestPushMetricsDeltaToCumulative(t *testing.T) {
+	var mu sync.Mutex
+	var samples []float64
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		data, err := snappy.Decode(nil, body)
+		require.NoError(t, err)
+		var req prompb.WriteRequest
+		require.NoError(t, proto.Unmarshal(data, &req))
+		mu.Lock()
+		defer mu.Unlock()
+		for _, ts := range req.Timeseries {
+			for _, sample := range ts.Samples {
+				samples = append(samples, sample.Value)
+			}
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer mockServer.Close()
+
+	cfg := createDefaultConfig().(*Config)
+	cfg.ClientConfig.Endpoint = mockServer.URL
+	cfg.RemoteWriteQueue.Enabled = false
+	cfg.TargetInfo.Enabled = false
+	cfg.DeltaToCumulative.Enabled = true
+	prwe, err := newPRWExporter(cfg, exportertest.NewNopCreateSettings())
+	require.NoError(t, err)
+	require.NoError(t, prwe.Start(context.Background(), componenttest.NewNopHost()))
+	defer func() { require.NoError(t, prwe.Shutdown(context.Background())) }()
+
+	for i, v := range []int64{3, 4} {
+		md := pmetric.NewMetrics()
+		metric := md.ResourceMetrics().AppendEmpty().ScopeMetrics().AppendEmpty().Metrics().AppendEmpty()
+		metric.SetName("requests")
+		sum := metric.SetEmptySum()
+		sum.SetIsMonotonic(true)
+		sum.SetAggregationTemporality(pmetric.AggregationTemporalityDelta)
+		pt := sum.DataPoints().AppendEmpty()
+		pt.SetStartTimestamp(pcommon.Timestamp(int64(i+1) * int64(time.Second)))
+		pt.SetTimestamp(pcommon.Timestamp(int64(i+2) * int64(time.Second)))
+		pt.SetIntValue(v)
+		require.NoError(t, prwe.PushMetrics(context.Background(), md))
+	}
+
+	assert.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return assert.ObjectsAreEqual([]float64{3, 7}, samples)
+	}, 5*time.Second, 10*time.Millisecond)
+}
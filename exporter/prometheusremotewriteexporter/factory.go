@@ -78,6 +78,7 @@ func createDefaultConfig() component.Config {
 		BackOffConfig:     retrySettings,
 		AddMetricSuffixes: true,
 		SendMetadata:      false,
+		ProtocolVersion:   protocolVersion1,
 		ClientConfig: confighttp.ClientConfig{
 			Endpoint: "http://some.url:9411/api/prom/push",
 			// We almost read 0 bytes, so no need to tune ReadBufferSize.
@@ -88,9 +89,14 @@ func createDefaultConfig() component.Config {
 		},
 		// TODO(jbd): Adjust the default queue size.
 		RemoteWriteQueue: RemoteWriteQueue{
-			Enabled:      true,
-			QueueSize:    10000,
-			NumConsumers: 5,
+			Enabled:           true,
+			QueueSize:         10000,
+			NumConsumers:      5,
+			MinShards:         defaultMinShards,
+			MaxShards:         defaultMaxShards,
+			Capacity:          defaultShardCapacity,
+			MaxSamplesPerSend: defaultMaxSamplesPerSend,
+			BatchSendDeadline: defaultBatchSendDeadline,
 		},
 		TargetInfo: &TargetInfo{
 			Enabled: true,
@@ -98,5 +104,13 @@ func createDefaultConfig() component.Config {
 		CreatedMetric: &CreatedMetric{
 			Enabled: false,
 		},
+		DeltaToCumulative: DeltaToCumulative{
+			Enabled:  false,
+			MaxStale: defaultDeltaToCumulativeMaxStale,
+		},
+		Staleness: StalenessConfig{
+			Enabled:    false,
+			StaleAfter: defaultStaleAfter,
+		},
 	}
 }
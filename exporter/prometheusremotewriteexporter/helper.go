@@ -6,10 +6,20 @@ package prometheusremotewriteexporter // import "github.com/open-telemetry/opent
 import (
 	"errors"
 	"sort"
+	"strings"
 
+	"github.com/prometheus/common/model"
 	"github.com/prometheus/prometheus/prompb"
 )
 
+const (
+	createdSuffix = "_created"
+	bucketSuffix  = "_bucket"
+	sumSuffix     = "_sum"
+	countSuffix   = "_count"
+	totalSuffix   = "_total"
+)
+
 // batchTimeSeries splits series into multiple batch write requests.
 func batchTimeSeries(tsMap map[string]*prompb.TimeSeries, maxBatchByteSize int, m []*prompb.MetricMetadata) ([]*prompb.WriteRequest, error) {
 	if len(tsMap) == 0 {
@@ -69,6 +79,111 @@ func batchTimeSeries(tsMap map[string]*prompb.TimeSeries, maxBatchByteSize int,
 	return requests, nil
 }
 
+// batchTimeSeriesV2 splits series into multiple batch write requests for Remote
+// Write 2.0. The series of a metric family, including its `_created` series, are
+// kept in the same request together with the family metadata, since both are
+// attached to each series when the request is converted.
+func batchTimeSeriesV2(tsMap map[string]*prompb.TimeSeries, maxBatchByteSize int, m []*prompb.MetricMetadata) ([]*prompb.WriteRequest, error) {
+	if len(tsMap) == 0 {
+		return nil, errors.New("invalid tsMap: cannot be empty map")
+	}
+
+	families := map[string][]prompb.TimeSeries{}
+	for _, v := range tsMap {
+		key := familyKey(v.Labels)
+		families[key] = append(families[key], *v)
+	}
+	keys := make([]string, 0, len(families))
+	for key := range families {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	metadata := make(map[string]prompb.MetricMetadata, len(m))
+	for _, v := range m {
+		metadata[v.MetricFamilyName] = *v
+	}
+
+	var requests []*prompb.WriteRequest
+	var tsArray []prompb.TimeSeries
+	sizeOfCurrentBatch := 0
+	for _, key := range keys {
+		sizeOfFamily := 0
+		for i := range families[key] {
+			sizeOfFamily += families[key][i].Size()
+		}
+
+		if len(tsArray) != 0 && sizeOfCurrentBatch+sizeOfFamily >= maxBatchByteSize {
+			requests = append(requests, convertTimeseriesToRequestV2(tsArray, metadata))
+			tsArray = nil
+			sizeOfCurrentBatch = 0
+		}
+
+		tsArray = append(tsArray, families[key]...)
+		sizeOfCurrentBatch += sizeOfFamily
+	}
+
+	if len(tsArray) != 0 {
+		requests = append(requests, convertTimeseriesToRequestV2(tsArray, metadata))
+	}
+	return requests, nil
+}
+
+func convertTimeseriesToRequestV2(tsArray []prompb.TimeSeries, metadata map[string]prompb.MetricMetadata) *prompb.WriteRequest {
+	request := convertTimeseriesToRequest(tsArray)
+	seen := map[string]bool{}
+	for _, ts := range tsArray {
+		for _, name := range familyNames(metricName(ts.Labels)) {
+			if md, ok := metadata[name]; ok && !seen[name] {
+				seen[name] = true
+				request.Metadata = append(request.Metadata, md)
+			}
+		}
+	}
+	return request
+}
+
+// familyKey identifies the metric family and label set a series belongs to.
+func familyKey(labels []prompb.Label) string {
+	names := familyNames(metricName(labels))
+	ls := make([]string, 0, len(labels))
+	for _, l := range labels {
+		switch l.Name {
+		case model.MetricNameLabel, model.BucketLabel, model.QuantileLabel:
+		default:
+			ls = append(ls, l.Name+"\xff"+l.Value)
+		}
+	}
+	sort.Strings(ls)
+	return names[len(names)-1] + "\xfe" + strings.Join(ls, "\xfe")
+}
+
+// familyNames returns the names a series named name may be described with, from
+// the most to the least specific.
+func familyNames(name string) []string {
+	names := []string{name}
+	if strings.HasSuffix(name, createdSuffix) {
+		name = strings.TrimSuffix(name, createdSuffix)
+		names = append(names, name)
+	}
+	for _, suffix := range []string{bucketSuffix, sumSuffix, countSuffix, totalSuffix} {
+		if strings.HasSuffix(name, suffix) {
+			names = append(names, strings.TrimSuffix(name, suffix))
+			break
+		}
+	}
+	return names
+}
+
+func metricName(labels []prompb.Label) string {
+	for _, l := range labels {
+		if l.Name == model.MetricNameLabel {
+			return l.Value
+		}
+	}
+	return ""
+}
+
 func convertTimeseriesToRequest(tsArray []prompb.TimeSeries) *prompb.WriteRequest {
 	// the remote_write endpoint only requires the timeseries.
 	// otlp defines it's own way to handle metric metadata
@@ -68,6 +68,48 @@ func Test_batchTimeSeries(t *testing.T) {
 	}
 }
 
+func Test_batchTimeSeriesV2(t *testing.T) {
+	series := func(name string, labels ...string) *prompb.TimeSeries {
+		ls := []prompb.Label{{Name: "__name__", Value: name}}
+		for i := 0; i < len(labels); i += 2 {
+			ls = append(ls, prompb.Label{Name: labels[i], Value: labels[i+1]})
+		}
+		return &prompb.TimeSeries{Labels: ls, Samples: []prompb.Sample{getSample(floatVal1, msTime1)}}
+	}
+	tsMap := getTimeseriesMap([]*prompb.TimeSeries{
+		series("latency_bucket", "le", "1", "job", "a"),
+		series("latency_bucket", "le", "+Inf", "job", "a"),
+		series("latency_sum", "job", "a"),
+		series("latency_count", "job", "a"),
+		series("latency_created", "job", "a"),
+		series("requests_total", "job", "a"),
+		series("requests_total_created", "job", "a"),
+	})
+	m := []*prompb.MetricMetadata{
+		{Type: prompb.MetricMetadata_HISTOGRAM, MetricFamilyName: "latency"},
+		{Type: prompb.MetricMetadata_COUNTER, MetricFamilyName: "requests_total"},
+		{Type: prompb.MetricMetadata_GAUGE, MetricFamilyName: "unused"},
+	}
+
+	// A single byte limit still keeps the series of a family together.
+	requests, err := batchTimeSeriesV2(tsMap, 1, m)
+	assert.NoError(t, err)
+	assert.Len(t, requests, 2)
+	for _, request := range requests {
+		assert.Len(t, request.Metadata, 1)
+		family := request.Metadata[0].MetricFamilyName
+		for _, ts := range request.Timeseries {
+			assert.Contains(t, metricName(ts.Labels), family)
+		}
+	}
+
+	requests, err = batchTimeSeriesV2(tsMap, 3000000, m)
+	assert.NoError(t, err)
+	assert.Len(t, requests, 1)
+	assert.Len(t, requests[0].Timeseries, 7)
+	assert.Len(t, requests[0].Metadata, 2)
+}
+
 // Ensure that before a prompb.WriteRequest is created, that the points per TimeSeries
 // are sorted by Timestamp value, to prevent Prometheus from barfing when it gets poorly
 // sorted values. See issues:
@@ -0,0 +1,89 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package prometheusremotewriteexporter // import "github.com/open-telemetry/opentelemetry-collector-contrib/exporter/prometheusremotewriteexporter"
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/model/relabel"
+	"github.com/prometheus/prometheus/prompb"
+	"gopkg.in/yaml.v3"
+)
+
+// unmarshalRelabelConfigs decodes write_relabel_configs with the yaml
+// unmarshalers of Prometheus, which set the defaults and validate each config.
+func unmarshalRelabelConfigs(in any) ([]*relabel.Config, error) {
+	yamlOut, err := yaml.Marshal(in)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal write_relabel_configs to yaml: %w", err)
+	}
+	var cfgs []*relabel.Config
+	decoder := yaml.NewDecoder(bytes.NewReader(yamlOut))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&cfgs); err != nil {
+		return nil, fmt.Errorf("invalid write_relabel_configs: %w", err)
+	}
+	return cfgs, nil
+}
+
+// relabelTimeSeries applies cfgs to the labels of the series of tsMap, and
+// returns the series left, keyed by their new labels. Series dropped or left
+// without any label are removed, and series relabeled to the same labels are
+// merged, since a request can't hold the same series twice.
+func relabelTimeSeries(tsMap map[string]*prompb.TimeSeries, cfgs []*relabel.Config) map[string]*prompb.TimeSeries {
+	if len(cfgs) == 0 {
+		return tsMap
+	}
+	relabeled := make(map[string]*prompb.TimeSeries, len(tsMap))
+	builder := labels.NewScratchBuilder(0)
+	for _, ts := range tsMap {
+		builder.Reset()
+		for _, l := range ts.Labels {
+			builder.Add(l.Name, l.Value)
+		}
+		builder.Sort()
+		lbls, keep := relabel.Process(builder.Labels(), cfgs...)
+		if !keep || lbls.IsEmpty() {
+			continue
+		}
+		key := lbls.String()
+		if existing, ok := relabeled[key]; ok {
+			mergeTimeSeries(existing, ts)
+			continue
+		}
+		ts.Labels = ts.Labels[:0]
+		lbls.Range(func(l labels.Label) {
+			ts.Labels = append(ts.Labels, prompb.Label{Name: l.Name, Value: l.Value})
+		})
+		relabeled[key] = ts
+	}
+	return relabeled
+}
+
+// mergeTimeSeries adds the samples, histograms and exemplars of from to to,
+// keeping them in time order. Samples and histograms of from at the timestamp
+// of one of to are dropped.
+func mergeTimeSeries(to, from *prompb.TimeSeries) {
+	to.Samples = mergeByTimestamp(to.Samples, from.Samples, func(s prompb.Sample) int64 { return s.Timestamp })
+	to.Histograms = mergeByTimestamp(to.Histograms, from.Histograms, func(h prompb.Histogram) int64 { return h.Timestamp })
+	to.Exemplars = mergeByTimestamp(to.Exemplars, from.Exemplars, func(e prompb.Exemplar) int64 { return e.Timestamp })
+}
+
+func mergeByTimestamp[T any](a, b []T, timestamp func(T) int64) []T {
+	if len(b) == 0 {
+		return a
+	}
+	merged := append(a, b...)
+	sort.SliceStable(merged, func(i, j int) bool { return timestamp(merged[i]) < timestamp(merged[j]) })
+	deduped := merged[:1]
+	for _, v := range merged[1:] {
+		if timestamp(v) != timestamp(deduped[len(deduped)-1]) {
+			deduped = append(deduped, v)
+		}
+	}
+	return deduped
+}
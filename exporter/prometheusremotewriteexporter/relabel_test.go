@@ -0,0 +1,140 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package prometheusremotewriteexporter
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/model/relabel"
+	"github.com/prometheus/prometheus/prompb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/confmap/confmaptest"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/exporter/prometheusremotewriteexporter/internal/metadata"
+)
+
+func TestLoadWriteRelabelConfigs(t *testing.T) {
+	cm, err := confmaptest.LoadConf(filepath.Join("testdata", "config.yaml"))
+	require.NoError(t, err)
+
+	sub, err := cm.Sub(component.NewIDWithName(metadata.Type, "write_relabel_configs").String())
+	require.NoError(t, err)
+	cfg := createDefaultConfig().(*Config)
+	require.NoError(t, component.UnmarshalConfig(sub, cfg))
+	assert.NoError(t, component.ValidateConfig(cfg))
+	assert.Equal(t, "localhost:8888", cfg.ClientConfig.Endpoint)
+	require.Len(t, cfg.WriteRelabelConfigs, 2)
+	assert.Equal(t, relabel.Drop, cfg.WriteRelabelConfigs[0].Action)
+	assert.Equal(t, model.LabelNames{"__name__"}, cfg.WriteRelabelConfigs[0].SourceLabels)
+	assert.Equal(t, relabel.LabelMap, cfg.WriteRelabelConfigs[1].Action)
+	// Defaults of Prometheus are applied.
+	assert.Equal(t, ";", cfg.WriteRelabelConfigs[1].Separator)
+
+	sub, err = cm.Sub(component.NewIDWithName(metadata.Type, "invalid_write_relabel_configs").String())
+	require.NoError(t, err)
+	cfg = createDefaultConfig().(*Config)
+	assert.ErrorContains(t, component.UnmarshalConfig(sub, cfg), `unknown relabel action "unknown"`)
+}
+
+func TestRelabelTimeSeries(t *testing.T) {
+	series := func(lbls ...string) *prompb.TimeSeries {
+		ts := &prompb.TimeSeries{}
+		for i := 0; i < len(lbls); i += 2 {
+			ts.Labels = append(ts.Labels, prompb.Label{Name: lbls[i], Value: lbls[i+1]})
+		}
+		return ts
+	}
+	mustRegexp := relabel.MustNewRegexp
+
+	tests := []struct {
+		name     string
+		cfgs     []*relabel.Config
+		expected []*prompb.TimeSeries
+	}{
+		{
+			name: "keep",
+			cfgs: []*relabel.Config{{SourceLabels: model.LabelNames{"__name__"}, Regex: mustRegexp("http_.*"), Action: relabel.Keep}},
+			expected: []*prompb.TimeSeries{
+				series("__name__", "http_requests_total", "pod_name", "api-0"),
+			},
+		},
+		{
+			name: "drop",
+			cfgs: []*relabel.Config{{SourceLabels: model.LabelNames{"__name__"}, Regex: mustRegexp("http_.*"), Action: relabel.Drop}},
+			expected: []*prompb.TimeSeries{
+				series("__name__", "go_goroutines", "job", "api"),
+			},
+		},
+		{
+			name: "replace",
+			cfgs: []*relabel.Config{{SourceLabels: model.LabelNames{"job"}, Regex: mustRegexp("(.+)"), TargetLabel: "service", Replacement: "svc-$1", Action: relabel.Replace}},
+			expected: []*prompb.TimeSeries{
+				series("__name__", "http_requests_total", "pod_name", "api-0"),
+				series("__name__", "go_goroutines", "job", "api", "service", "svc-api"),
+			},
+		},
+		{
+			name: "labelmap and labeldrop",
+			cfgs: []*relabel.Config{
+				{Regex: mustRegexp("pod_(.+)"), Replacement: "k8s_pod_$1", Action: relabel.LabelMap},
+				{Regex: mustRegexp("pod_.+"), Action: relabel.LabelDrop},
+			},
+			expected: []*prompb.TimeSeries{
+				series("__name__", "http_requests_total", "k8s_pod_name", "api-0"),
+				series("__name__", "go_goroutines", "job", "api"),
+			},
+		},
+		{
+			name: "empty label set",
+			cfgs: []*relabel.Config{{Regex: mustRegexp(".*"), Action: relabel.LabelDrop}},
+		},
+		{
+			name: "hashmod",
+			cfgs: []*relabel.Config{
+				{SourceLabels: model.LabelNames{"__name__"}, Modulus: 1, TargetLabel: "shard", Action: relabel.HashMod},
+			},
+			expected: []*prompb.TimeSeries{
+				series("__name__", "http_requests_total", "pod_name", "api-0", "shard", "0"),
+				series("__name__", "go_goroutines", "job", "api", "shard", "0"),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tsMap := map[string]*prompb.TimeSeries{
+				"http": series("__name__", "http_requests_total", "pod_name", "api-0"),
+				"go":   series("__name__", "go_goroutines", "job", "api"),
+			}
+			var got []*prompb.TimeSeries
+			for _, ts := range relabelTimeSeries(tsMap, tt.cfgs) {
+				got = append(got, ts)
+			}
+			assert.ElementsMatch(t, tt.expected, got)
+		})
+	}
+}
+
+func TestRelabelTimeSeriesMergesCollisions(t *testing.T) {
+	tsMap := map[string]*prompb.TimeSeries{
+		"api-0": {
+			Labels:  []prompb.Label{{Name: "__name__", Value: "up"}, {Name: "pod", Value: "api-0"}},
+			Samples: []prompb.Sample{{Value: 1, Timestamp: 1}, {Value: 1, Timestamp: 3}},
+		},
+		"api-1": {
+			Labels:  []prompb.Label{{Name: "__name__", Value: "up"}, {Name: "pod", Value: "api-1"}},
+			Samples: []prompb.Sample{{Value: 0, Timestamp: 2}},
+		},
+	}
+	got := relabelTimeSeries(tsMap, []*relabel.Config{{Regex: relabel.MustNewRegexp("pod"), Action: relabel.LabelDrop}})
+	require.Len(t, got, 1)
+	for _, ts := range got {
+		assert.Equal(t, []prompb.Label{{Name: "__name__", Value: "up"}}, ts.Labels)
+		assert.Equal(t, []prompb.Sample{{Value: 1, Timestamp: 1}, {Value: 0, Timestamp: 2}, {Value: 1, Timestamp: 3}}, ts.Samples)
+	}
+}
@@ -0,0 +1,625 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package prometheusremotewriteexporter // import "github.com/open-telemetry/opentelemetry-collector-contrib/exporter/prometheusremotewriteexporter"
+
+import (
+	"context"
+	"errors"
+	"hash/fnv"
+	"math"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/prometheus/prometheus/prompb"
+	"go.uber.org/multierr"
+	"go.uber.org/zap"
+)
+
+const (
+	defaultMinShards         = 1
+	defaultMaxShards         = 50
+	defaultShardCapacity     = 10000
+	defaultMaxSamplesPerSend = 2000
+	defaultBatchSendDeadline = 5 * time.Second
+
+	// reshardInterval is how often the number of shards is updated.
+	reshardInterval = 10 * time.Second
+	// reshardTolerance is how far the desired number of shards must be from
+	// the current one to reshard, to avoid flapping.
+	reshardTolerance = 0.3
+	// backlogCatchup is the time within which the shards should send the
+	// samples waiting in the queue, on top of the incoming samples.
+	backlogCatchup = 10 * time.Second
+	// ewmaWeight is the weight of the last interval in the smoothed rates.
+	ewmaWeight = 0.2
+)
+
+var (
+	errShardsStopped = errors.New("remote write shards are not running")
+	errShardsFull    = errors.New("remote write shards are full")
+)
+
+// shardQueue sends series over a number of shards adjusted to the observed
+// send latency and backlog, like the queue manager of Prometheus. A series
+// always goes to the same shard and each shard sends one request at a time, so
+// the samples of a series are sent in order.
+type shardQueue struct {
+	minShards         int
+	maxShards         int
+	capacity          int
+	maxSamplesPerSend int
+	maxBatchSizeBytes int
+	batchSendDeadline time.Duration
+	// byFamily keeps the series of a metric family together, as Remote Write
+	// 2.0 attaches metadata and created timestamps from the family.
+	byFamily bool
+	send     func(context.Context, *prompb.WriteRequest) error
+	logger   *zap.Logger
+
+	// mu guards the current shards. It is never held while waiting for a
+	// shard, so that exports and resharding don't hold each other up.
+	mu     sync.RWMutex
+	shards *shardSet
+	// next is the number of shards started by start.
+	next int
+
+	samplesIn  atomic.Int64
+	samplesOut atomic.Int64
+	pending    atomic.Int64
+	sendNanos  atomic.Int64
+	failures   atomic.Int64
+
+	// rateIn is the smoothed rate of incoming samples per second, and
+	// sendTimePerSample the smoothed time spent sending each sample.
+	rateIn            ewma
+	sendTimePerSample ewma
+
+	done chan struct{}
+	wg   sync.WaitGroup
+}
+
+// shardSet is the shards running between two reshards.
+type shardSet struct {
+	shards []*shard
+	// senders counts the exports queueing series to the shards. It is only
+	// incremented while the set is current, under shardQueue.mu.
+	senders sync.WaitGroup
+	// retired is closed once the set was replaced, so that exports blocked on
+	// a full shard queue their series to the new shards.
+	retired chan struct{}
+	// drained is closed once the shards sent all their series. The shards of
+	// the next set wait for it, to keep the samples of each series in order.
+	drained chan struct{}
+}
+
+// shard sends the series queued to it in batches, one request at a time.
+type shard struct {
+	items chan queuedSeries
+	done  chan struct{}
+}
+
+// queuedSeries is a group of series sent in the same request, or a flush
+// marker when series is empty.
+type queuedSeries struct {
+	ctx      context.Context
+	series   []prompb.TimeSeries
+	metadata []prompb.MetricMetadata
+	result   *exportResult
+}
+
+func (q queuedSeries) samples() int {
+	n := 0
+	for i := range q.series {
+		n += len(q.series[i].Samples) + len(q.series[i].Histograms)
+	}
+	return n
+}
+
+func (q queuedSeries) size() int {
+	n := 0
+	for i := range q.series {
+		n += q.series[i].Size()
+	}
+	return n
+}
+
+// exportResult collects the outcome of the series of one export.
+type exportResult struct {
+	wg   sync.WaitGroup
+	mu   sync.Mutex
+	errs error
+}
+
+func (r *exportResult) finish(err error) {
+	if err != nil {
+		r.mu.Lock()
+		r.errs = multierr.Append(r.errs, err)
+		r.mu.Unlock()
+	}
+	r.wg.Done()
+}
+
+func (r *exportResult) wait(ctx context.Context) error {
+	finished := make(chan struct{})
+	go func() {
+		r.wg.Wait()
+		close(finished)
+	}()
+	select {
+	case <-finished:
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		return r.errs
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func newShardQueue(cfg RemoteWriteQueue, maxBatchSizeBytes int, byFamily bool, send func(context.Context, *prompb.WriteRequest) error, logger *zap.Logger) *shardQueue {
+	q := &shardQueue{
+		minShards:         valueOrDefault(cfg.MinShards, defaultMinShards),
+		maxShards:         valueOrDefault(cfg.MaxShards, defaultMaxShards),
+		capacity:          valueOrDefault(cfg.Capacity, defaultShardCapacity),
+		maxSamplesPerSend: valueOrDefault(cfg.MaxSamplesPerSend, defaultMaxSamplesPerSend),
+		batchSendDeadline: cfg.BatchSendDeadline,
+		maxBatchSizeBytes: maxBatchSizeBytes,
+		byFamily:          byFamily,
+		send:              send,
+		logger:            logger,
+	}
+	if q.batchSendDeadline <= 0 {
+		q.batchSendDeadline = defaultBatchSendDeadline
+	}
+	if q.maxShards < q.minShards {
+		q.maxShards = q.minShards
+	}
+	q.next = clampShards(cfg.NumConsumers, q.minShards, q.maxShards)
+	return q
+}
+
+func valueOrDefault(v, def int) int {
+	if v <= 0 {
+		return def
+	}
+	return v
+}
+
+func clampShards(n, minShards, maxShards int) int {
+	return max(minShards, min(n, maxShards))
+}
+
+// start starts the shards and the loop updating their number. The shards
+// send with ctx.
+func (q *shardQueue) start(ctx context.Context) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.shards != nil {
+		return
+	}
+	q.shards = q.startShards(ctx, q.next, nil)
+	q.done = make(chan struct{})
+	q.wg.Add(1)
+	go q.reshardLoop(ctx)
+}
+
+// stop sends the series already queued and stops the shards.
+func (q *shardQueue) stop() {
+	q.mu.Lock()
+	if q.shards == nil {
+		q.mu.Unlock()
+		return
+	}
+	close(q.done)
+	q.mu.Unlock()
+	q.wg.Wait()
+
+	q.mu.Lock()
+	set := q.shards
+	q.shards = nil
+	q.mu.Unlock()
+	set.stop()
+}
+
+// startShards starts n shards, which start sending once after is closed.
+func (q *shardQueue) startShards(ctx context.Context, n int, after <-chan struct{}) *shardSet {
+	set := &shardSet{
+		shards:  make([]*shard, n),
+		retired: make(chan struct{}),
+		drained: make(chan struct{}),
+	}
+	for i := range set.shards {
+		s := &shard{
+			items: make(chan queuedSeries, q.capacity),
+			done:  make(chan struct{}),
+		}
+		set.shards[i] = s
+		go func() {
+			if after != nil {
+				<-after
+			}
+			q.runShard(ctx, s)
+		}()
+	}
+	return set
+}
+
+// stop sends the series queued to the shards of a set which is no longer
+// current, once the exports still queueing to them are done.
+func (set *shardSet) stop() {
+	close(set.retired)
+	set.senders.Wait()
+	for _, s := range set.shards {
+		close(s.items)
+	}
+	for _, s := range set.shards {
+		<-s.done
+	}
+	close(set.drained)
+}
+
+// acquire returns the current shards, which are not stopped before release
+// is called.
+func (q *shardQueue) acquire() (*shardSet, error) {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+	if q.shards == nil {
+		return nil, errShardsStopped
+	}
+	q.shards.senders.Add(1)
+	return q.shards, nil
+}
+
+func (set *shardSet) release() {
+	set.senders.Done()
+}
+
+// append queues the series of requests to their shards and waits for them
+// to be sent.
+func (q *shardQueue) append(ctx context.Context, requests []*prompb.WriteRequest) error {
+	result, err := q.enqueue(ctx, requests, true)
+	if err != nil {
+		return err
+	}
+	return result.wait(ctx)
+}
+
+// enqueue queues the series of requests to their shards. Unless block is
+// set, the series of full shards are dropped and errShardsFull is returned
+// along with the result of the queued series.
+func (q *shardQueue) enqueue(ctx context.Context, requests []*prompb.WriteRequest, block bool) (*exportResult, error) {
+	result := &exportResult{}
+	set, err := q.acquire()
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if set != nil {
+			set.release()
+		}
+	}()
+	// send queues item to s, or reports false when the set of s was retired
+	// in the meantime.
+	send := func(s *shard, item queuedSeries, retired <-chan struct{}) (bool, error) {
+		if !block {
+			select {
+			case s.items <- item:
+				return true, nil
+			case <-retired:
+				return false, nil
+			default:
+				return false, errShardsFull
+			}
+		}
+		select {
+		case s.items <- item:
+			return true, nil
+		case <-retired:
+			return false, nil
+		case <-ctx.Done():
+			return false, ctx.Err()
+		}
+	}
+	// used maps the shards which got series to the retired channel of their set.
+	used := map[*shard]chan struct{}{}
+	var dropped error
+	for _, req := range requests {
+		for _, group := range q.groups(req.Timeseries) {
+			item := queuedSeries{ctx: ctx, series: group, metadata: req.Metadata, result: result}
+			hash := seriesHash(group[0].Labels, q.byFamily)
+			samples := int64(item.samples())
+			result.wg.Add(1)
+			q.samplesIn.Add(samples)
+			q.pending.Add(samples)
+			for {
+				s := set.shards[hash%uint64(len(set.shards))]
+				var queued bool
+				if queued, err = send(s, item, set.retired); queued {
+					used[s] = set.retired
+					break
+				}
+				if err != nil {
+					break
+				}
+				// Resharded while waiting, queue to the new shards.
+				set.release()
+				if set, err = q.acquire(); err != nil {
+					break
+				}
+			}
+			if err != nil {
+				result.wg.Done()
+				q.pending.Add(-samples)
+				if errors.Is(err, errShardsFull) {
+					dropped, err = err, nil
+					continue
+				}
+				break
+			}
+		}
+		if err != nil {
+			break
+		}
+	}
+	// Flush markers make the shards send the series of this export without
+	// waiting for the batch send deadline. A full shard is sent soon anyway,
+	// as are the shards of a set released after resharding.
+	for s, retired := range used {
+		if err != nil {
+			break
+		}
+		if retired != set.retired {
+			continue
+		}
+		if _, errFlush := send(s, queuedSeries{}, retired); !errors.Is(errFlush, errShardsFull) {
+			err = errFlush
+		}
+	}
+	if err == nil {
+		err = dropped
+	}
+	return result, err
+}
+
+// groups splits series into the groups which must be sent together.
+func (q *shardQueue) groups(tss []prompb.TimeSeries) [][]prompb.TimeSeries {
+	groups := make([][]prompb.TimeSeries, 0, len(tss))
+	if !q.byFamily {
+		for i := range tss {
+			groups = append(groups, tss[i:i+1])
+		}
+		return groups
+	}
+	index := map[string]int{}
+	for _, ts := range tss {
+		key := familyKey(ts.Labels)
+		i, ok := index[key]
+		if !ok {
+			i = len(groups)
+			index[key] = i
+			groups = append(groups, nil)
+		}
+		groups[i] = append(groups[i], ts)
+	}
+	return groups
+}
+
+// seriesHash hashes the labels of a series, or of its metric family.
+func seriesHash(labels []prompb.Label, byFamily bool) uint64 {
+	h := fnv.New64a()
+	if byFamily {
+		_, _ = h.Write([]byte(familyKey(labels)))
+		return h.Sum64()
+	}
+	for _, l := range labels {
+		_, _ = h.Write([]byte(l.Name))
+		_, _ = h.Write([]byte{0xff})
+		_, _ = h.Write([]byte(l.Value))
+		_, _ = h.Write([]byte{0xfe})
+	}
+	return h.Sum64()
+}
+
+// runShard batches the series queued to s until the batch is full, a flush
+// marker arrives or the batch send deadline expires.
+func (q *shardQueue) runShard(ctx context.Context, s *shard) {
+	defer close(s.done)
+	var (
+		batch    []queuedSeries
+		samples  int
+		size     int
+		deadline <-chan time.Time
+	)
+	flush := func() {
+		if len(batch) > 0 {
+			q.sendBatch(ctx, batch)
+		}
+		batch, samples, size, deadline = nil, 0, 0, nil
+	}
+	for {
+		select {
+		case item, ok := <-s.items:
+			if !ok {
+				flush()
+				return
+			}
+			if len(item.series) == 0 {
+				flush()
+				continue
+			}
+			itemSize := item.size()
+			// A request is sent to a single tenant.
+			if len(batch) > 0 && (size+itemSize >= q.maxBatchSizeBytes || tenantFromContext(item.ctx) != tenantFromContext(batch[0].ctx)) {
+				flush()
+			}
+			if len(batch) == 0 {
+				deadline = time.After(q.batchSendDeadline)
+			}
+			batch = append(batch, item)
+			samples += item.samples()
+			size += itemSize
+			if samples >= q.maxSamplesPerSend {
+				flush()
+			}
+		case <-deadline:
+			flush()
+		}
+	}
+}
+
+func (q *shardQueue) sendBatch(ctx context.Context, batch []queuedSeries) {
+	var tsArray []prompb.TimeSeries
+	metadata := map[string]prompb.MetricMetadata{}
+	samples := 0
+	live := make([]queuedSeries, 0, len(batch))
+	for _, item := range batch {
+		n := item.samples()
+		samples += n
+		// The export gave up waiting, it already reported an error.
+		if err := item.ctx.Err(); err != nil {
+			q.pending.Add(int64(-n))
+			item.result.finish(err)
+			continue
+		}
+		live = append(live, item)
+		tsArray = append(tsArray, item.series...)
+		for _, md := range item.metadata {
+			metadata[md.MetricFamilyName] = md
+		}
+	}
+	if len(live) == 0 {
+		return
+	}
+
+	var request *prompb.WriteRequest
+	if q.byFamily {
+		request = convertTimeseriesToRequestV2(tsArray, metadata)
+	} else {
+		request = convertTimeseriesToRequest(tsArray)
+	}
+	if tenant := tenantFromContext(live[0].ctx); tenant != "" {
+		ctx = contextWithTenant(ctx, tenant)
+	}
+	// Stop sending, and retrying, once all the exports of the batch gave up.
+	sendCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go func() {
+		for _, item := range live {
+			select {
+			case <-item.ctx.Done():
+			case <-sendCtx.Done():
+				return
+			}
+		}
+		cancel()
+	}()
+
+	start := time.Now()
+	err := q.send(sendCtx, request)
+	q.sendNanos.Add(int64(time.Since(start)))
+	if err != nil {
+		q.failures.Add(1)
+	}
+
+	// Report the error once per export.
+	sent := 0
+	reported := map[*exportResult]bool{}
+	for _, item := range live {
+		sent += item.samples()
+		if reported[item.result] {
+			item.result.finish(nil)
+			continue
+		}
+		reported[item.result] = true
+		item.result.finish(err)
+	}
+	q.samplesOut.Add(int64(sent))
+	q.pending.Add(int64(-sent))
+}
+
+func (q *shardQueue) reshardLoop(ctx context.Context) {
+	defer q.wg.Done()
+	ticker := time.NewTicker(reshardInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if n, ok := q.desiredShards(reshardInterval); ok {
+				q.reshard(ctx, n)
+			}
+		case <-q.done:
+			return
+		}
+	}
+}
+
+// desiredShards estimates the number of shards needed to send the incoming
+// samples and catch up with the backlog, from the time spent sending each
+// sample. It reports false when the number of shards should not change.
+func (q *shardQueue) desiredShards(interval time.Duration) (int, bool) {
+	samplesIn := q.samplesIn.Swap(0)
+	samplesOut := q.samplesOut.Swap(0)
+	sendTime := time.Duration(q.sendNanos.Swap(0))
+	failures := q.failures.Swap(0)
+
+	q.rateIn.update(float64(samplesIn) / interval.Seconds())
+	if samplesOut > 0 {
+		q.sendTimePerSample.update(sendTime.Seconds() / float64(samplesOut))
+	}
+	// More shards don't help when the endpoint is failing, they would only
+	// increase its load.
+	if failures > 0 || !q.sendTimePerSample.set {
+		return 0, false
+	}
+
+	backlog := float64(q.pending.Load()) / backlogCatchup.Seconds()
+	desired := q.sendTimePerSample.value * (q.rateIn.value + backlog)
+
+	q.mu.RLock()
+	current := 0
+	if q.shards != nil {
+		current = len(q.shards.shards)
+	}
+	q.mu.RUnlock()
+	if current == 0 {
+		return 0, false
+	}
+	low, high := float64(current)*(1-reshardTolerance), float64(current)*(1+reshardTolerance)
+	if desired > low && desired < high {
+		return 0, false
+	}
+	n := clampShards(int(math.Ceil(desired)), q.minShards, q.maxShards)
+	return n, n != current
+}
+
+// reshard replaces the shards with n shards, then sends the series queued to
+// the old ones. The new shards accept series right away but only start
+// sending once the old ones are drained.
+func (q *shardQueue) reshard(ctx context.Context, n int) {
+	q.mu.Lock()
+	old := q.shards
+	if old == nil {
+		q.mu.Unlock()
+		return
+	}
+	q.logger.Info("Resharding remote write queue", zap.Int("from", len(old.shards)), zap.Int("to", n))
+	q.next = n
+	q.shards = q.startShards(ctx, n, old.drained)
+	q.mu.Unlock()
+	old.stop()
+}
+
+// ewma is an exponentially weighted moving average.
+type ewma struct {
+	value float64
+	set   bool
+}
+
+func (e *ewma) update(v float64) {
+	if !e.set {
+		e.value, e.set = v, true
+		return
+	}
+	e.value = ewmaWeight*v + (1-ewmaWeight)*e.value
+}
@@ -0,0 +1,201 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package prometheusremotewriteexporter
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/prometheus/prometheus/prompb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+type recordingSender struct {
+	mu       sync.Mutex
+	requests []*prompb.WriteRequest
+	err      error
+}
+
+func (r *recordingSender) send(_ context.Context, req *prompb.WriteRequest) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.requests = append(r.requests, req)
+	return r.err
+}
+
+func seriesRequest(samples int, names ...string) *prompb.WriteRequest {
+	req := &prompb.WriteRequest{}
+	for _, name := range names {
+		ts := prompb.TimeSeries{Labels: []prompb.Label{{Name: "__name__", Value: name}}}
+		for i := 0; i < samples; i++ {
+			ts.Samples = append(ts.Samples, prompb.Sample{Value: float64(i), Timestamp: int64(i)})
+		}
+		req.Timeseries = append(req.Timeseries, ts)
+	}
+	return req
+}
+
+func TestShardQueueBatches(t *testing.T) {
+	sender := &recordingSender{}
+	q := newShardQueue(RemoteWriteQueue{NumConsumers: 1, MaxSamplesPerSend: 4, BatchSendDeadline: time.Hour}, 3000000, false, sender.send, zap.NewNop())
+	q.start(context.Background())
+	defer q.stop()
+
+	// The flush marker sends the last batch without waiting for the deadline.
+	require.NoError(t, q.append(context.Background(), []*prompb.WriteRequest{seriesRequest(2, "a", "b", "c")}))
+	require.Len(t, sender.requests, 2)
+	assert.Len(t, sender.requests[0].Timeseries, 2)
+	assert.Len(t, sender.requests[1].Timeseries, 1)
+}
+
+func TestShardQueueErrors(t *testing.T) {
+	sender := &recordingSender{err: errors.New("unavailable")}
+	q := newShardQueue(RemoteWriteQueue{NumConsumers: 4}, 3000000, false, sender.send, zap.NewNop())
+	q.start(context.Background())
+
+	err := q.append(context.Background(), []*prompb.WriteRequest{seriesRequest(1, "a", "b", "c", "d")})
+	assert.ErrorContains(t, err, "unavailable")
+
+	q.stop()
+	assert.ErrorIs(t, q.append(context.Background(), []*prompb.WriteRequest{seriesRequest(1, "a")}), errShardsStopped)
+}
+
+func TestShardQueueOrderAcrossReshard(t *testing.T) {
+	sender := &recordingSender{}
+	q := newShardQueue(RemoteWriteQueue{NumConsumers: 1, MaxShards: 8}, 3000000, false, sender.send, zap.NewNop())
+	q.start(context.Background())
+	defer q.stop()
+
+	// Queue the exports of the same series without waiting for them to be
+	// sent, resharding in between.
+	var results []*exportResult
+	for i := 0; i < 20; i++ {
+		if i == 10 {
+			q.reshard(context.Background(), 8)
+		}
+		req := &prompb.WriteRequest{Timeseries: []prompb.TimeSeries{{
+			Labels:  []prompb.Label{{Name: "__name__", Value: "up"}},
+			Samples: []prompb.Sample{{Value: 1, Timestamp: int64(i)}},
+		}}}
+		result, err := q.enqueue(context.Background(), []*prompb.WriteRequest{req}, true)
+		require.NoError(t, err)
+		results = append(results, result)
+	}
+	for _, result := range results {
+		require.NoError(t, result.wait(context.Background()))
+	}
+
+	var last int64 = -1
+	for _, req := range sender.requests {
+		for _, ts := range req.Timeseries {
+			for _, s := range ts.Samples {
+				assert.Greater(t, s.Timestamp, last)
+				last = s.Timestamp
+			}
+		}
+	}
+	assert.Equal(t, int64(19), last)
+}
+
+func TestShardQueueExportsDuringReshard(t *testing.T) {
+	release := make(chan struct{})
+	var sends atomic.Int32
+	send := func(context.Context, *prompb.WriteRequest) error {
+		sends.Add(1)
+		<-release
+		return nil
+	}
+	q := newShardQueue(RemoteWriteQueue{NumConsumers: 1, MaxShards: 4}, 3000000, false, send, zap.NewNop())
+	q.start(context.Background())
+	defer q.stop()
+
+	first, err := q.enqueue(context.Background(), []*prompb.WriteRequest{seriesRequest(1, "a")}, true)
+	require.NoError(t, err)
+	require.Eventually(t, func() bool { return sends.Load() == 1 }, time.Second, time.Millisecond)
+
+	q.mu.RLock()
+	old := q.shards
+	q.mu.RUnlock()
+	resharded := make(chan struct{})
+	go func() {
+		q.reshard(context.Background(), 2)
+		close(resharded)
+	}()
+	require.Eventually(t, func() bool {
+		q.mu.RLock()
+		defer q.mu.RUnlock()
+		return q.shards != old
+	}, time.Second, time.Millisecond)
+
+	// The old shard is still sending, the new ones queue without waiting.
+	second, err := q.enqueue(context.Background(), []*prompb.WriteRequest{seriesRequest(1, "b")}, true)
+	require.NoError(t, err)
+	select {
+	case <-resharded:
+		t.Fatal("resharding finished before the old shards were drained")
+	default:
+	}
+	assert.Equal(t, int32(1), sends.Load())
+
+	close(release)
+	<-resharded
+	require.NoError(t, first.wait(context.Background()))
+	require.NoError(t, second.wait(context.Background()))
+}
+
+func TestShardQueueGroupsFamilies(t *testing.T) {
+	q := newShardQueue(RemoteWriteQueue{}, 3000000, true, nil, zap.NewNop())
+	tss := []prompb.TimeSeries{
+		{Labels: []prompb.Label{{Name: "__name__", Value: "requests_total"}, {Name: "code", Value: "200"}}},
+		{Labels: []prompb.Label{{Name: "__name__", Value: "requests_total"}, {Name: "code", Value: "500"}}},
+		{Labels: []prompb.Label{{Name: "__name__", Value: "requests_created"}, {Name: "code", Value: "200"}}},
+	}
+	groups := q.groups(tss)
+	require.Len(t, groups, 2)
+	assert.Len(t, groups[0], 2)
+	assert.Equal(t, "requests_created", metricName(groups[0][1].Labels))
+	assert.Equal(t, seriesHash(groups[0][0].Labels, true), seriesHash(groups[0][1].Labels, true))
+}
+
+func TestDesiredShards(t *testing.T) {
+	q := newShardQueue(RemoteWriteQueue{NumConsumers: 4, MaxShards: 10}, 3000000, false, nil, zap.NewNop())
+	q.shards = &shardSet{shards: make([]*shard, 4)}
+
+	// Sending takes 10ms per 100 samples, and 1000 samples/s come in: 1 shard
+	// is enough.
+	q.samplesIn.Store(10000)
+	q.samplesOut.Store(10000)
+	q.sendNanos.Store(int64(time.Second))
+	n, ok := q.desiredShards(10 * time.Second)
+	assert.True(t, ok)
+	assert.Equal(t, 1, n)
+
+	// Within the tolerance of the current number of shards.
+	q.samplesIn.Store(40000)
+	q.samplesOut.Store(40000)
+	q.sendNanos.Store(int64(40 * time.Second))
+	q.rateIn = ewma{}
+	q.sendTimePerSample = ewma{}
+	_, ok = q.desiredShards(10 * time.Second)
+	assert.False(t, ok)
+
+	// A backlog needs more shards, up to the maximum.
+	q.pending.Store(1000000)
+	q.samplesOut.Store(40000)
+	q.sendNanos.Store(int64(40 * time.Second))
+	n, ok = q.desiredShards(10 * time.Second)
+	assert.True(t, ok)
+	assert.Equal(t, 10, n)
+
+	// Failing sends don't trigger resharding.
+	q.failures.Store(1)
+	_, ok = q.desiredShards(10 * time.Second)
+	assert.False(t, ok)
+}
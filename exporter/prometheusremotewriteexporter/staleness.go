@@ -0,0 +1,267 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package prometheusremotewriteexporter // import "github.com/open-telemetry/opentelemetry-collector-contrib/exporter/prometheusremotewriteexporter"
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/model/value"
+	"github.com/prometheus/prometheus/prompb"
+	"go.uber.org/zap"
+)
+
+const (
+	defaultStaleAfter = 2 * time.Minute
+
+	// maxStalenessCheckInterval is the maximum interval between the checks
+	// of the series not sent for stale_after.
+	maxStalenessCheckInterval = 10 * time.Second
+)
+
+// StalenessConfig allows to configure the staleness markers sent for the
+// series which stop being reported.
+type StalenessConfig struct {
+	// Enabled if true a staleness marker is sent for the series which are
+	// no longer reported.
+	Enabled bool `mapstructure:"enabled"`
+
+	// StaleAfterPushes is the number of pushes of the resource of a series
+	// without the series after which the series is stale. Disabled if 0. A
+	// push of a resource lasts until a newer timestamp is pushed for it, so
+	// that the metrics of a scrape split across batches count as one push.
+	StaleAfterPushes int `mapstructure:"stale_after_pushes"`
+
+	// StaleAfter is the time without the series after which the series is
+	// stale. Disabled if 0.
+	StaleAfter time.Duration `mapstructure:"stale_after"`
+}
+
+// Validate checks if the staleness configuration is valid.
+func (sc *StalenessConfig) Validate() error {
+	if sc.StaleAfterPushes < 0 || sc.StaleAfter < 0 {
+		return fmt.Errorf("staleness stale_after_pushes and stale_after can't be negative")
+	}
+	if sc.Enabled && sc.StaleAfterPushes == 0 && sc.StaleAfter == 0 {
+		return fmt.Errorf("staleness requires stale_after_pushes or stale_after")
+	}
+	return nil
+}
+
+// stalenessTracker remembers the series sent for each resource, identified by
+// its tenant and its job and instance labels like a Prometheus target, and
+// returns staleness markers for the series which are no longer sent.
+type stalenessTracker struct {
+	staleAfterPushes int
+	staleAfter       time.Duration
+	now              func() time.Time
+
+	mu      sync.Mutex
+	targets map[stalenessTarget]*targetPushes
+}
+
+type stalenessTarget struct {
+	tenant   string
+	job      string
+	instance string
+}
+
+// targetPushes is the series sent for a target, and its current push.
+type targetPushes struct {
+	series map[string]*trackedSeries
+	// timestamp is the latest timestamp pushed for the target, and push counts
+	// the pushes: a push starts with each newer timestamp and may be split
+	// across several calls to observe.
+	timestamp int64
+	push      int
+}
+
+// trackedSeries is a series sent for a target.
+type trackedSeries struct {
+	labels        []prompb.Label
+	histogram     bool
+	lastSeen      time.Time
+	lastTimestamp int64
+	// lastPush is the push of the target the series was last sent in.
+	lastPush     int
+	missedPushes int
+}
+
+func newStalenessTracker(cfg StalenessConfig) *stalenessTracker {
+	return &stalenessTracker{
+		staleAfterPushes: cfg.StaleAfterPushes,
+		staleAfter:       cfg.StaleAfter,
+		now:              time.Now,
+		targets:          map[stalenessTarget]*targetPushes{},
+	}
+}
+
+// checkInterval returns the interval between the checks of the series not
+// sent for stale_after, 0 if disabled.
+func (t *stalenessTracker) checkInterval() time.Duration {
+	return min(t.staleAfter, maxStalenessCheckInterval)
+}
+
+func targetOf(tenant string, labels []prompb.Label) stalenessTarget {
+	target := stalenessTarget{tenant: tenant}
+	for _, l := range labels {
+		switch l.Name {
+		case model.JobLabel:
+			target.job = l.Value
+		case model.InstanceLabel:
+			target.instance = l.Value
+		}
+	}
+	return target
+}
+
+// observe records the series of a push to tenant, keyed by their signature,
+// and adds to tsMap the staleness markers of the series missing from the
+// last stale_after_pushes complete pushes of their target. A push of a target
+// is complete once a newer timestamp is pushed for it.
+func (t *stalenessTracker) observe(tenant string, tsMap map[string]*prompb.TimeSeries) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := t.now()
+	// pushed holds the latest timestamp of each target in tsMap.
+	pushed := map[stalenessTarget]int64{}
+	markers := map[string]bool{}
+	for _, ts := range tsMap {
+		target := targetOf(tenant, ts.Labels)
+		pushed[target] = max(pushed[target], seriesTimestamp(ts))
+	}
+	for target, timestamp := range pushed {
+		pushes, ok := t.targets[target]
+		if !ok {
+			pushes = &targetPushes{series: map[string]*trackedSeries{}, timestamp: timestamp, push: 1}
+			t.targets[target] = pushes
+			continue
+		}
+		if timestamp <= pushes.timestamp {
+			continue
+		}
+		// The previous push is complete, count the series it missed.
+		if t.staleAfterPushes > 0 {
+			for sig, tracked := range pushes.series {
+				if tracked.lastPush == pushes.push {
+					continue
+				}
+				tracked.missedPushes++
+				if tracked.missedPushes >= t.staleAfterPushes {
+					if _, ok := tsMap[sig]; !ok {
+						tsMap[sig] = tracked.marker(now)
+						markers[sig] = true
+					}
+					delete(pushes.series, sig)
+				}
+			}
+		}
+		pushes.timestamp = timestamp
+		pushes.push++
+	}
+
+	for sig, ts := range tsMap {
+		if markers[sig] {
+			continue
+		}
+		pushes := t.targets[targetOf(tenant, ts.Labels)]
+		tracked, ok := pushes.series[sig]
+		if !ok {
+			tracked = &trackedSeries{labels: append([]prompb.Label(nil), ts.Labels...)}
+			pushes.series[sig] = tracked
+		}
+		tracked.histogram = len(ts.Samples) == 0 && len(ts.Histograms) > 0
+		tracked.lastSeen = now
+		tracked.lastPush = pushes.push
+		tracked.missedPushes = 0
+		tracked.lastTimestamp = max(tracked.lastTimestamp, seriesTimestamp(ts))
+	}
+	for target := range pushed {
+		if len(t.targets[target].series) == 0 {
+			delete(t.targets, target)
+		}
+	}
+}
+
+// seriesTimestamp returns the timestamp of the last sample or histogram of ts.
+func seriesTimestamp(ts *prompb.TimeSeries) int64 {
+	var timestamp int64
+	for _, sample := range ts.Samples {
+		timestamp = max(timestamp, sample.Timestamp)
+	}
+	for _, histogram := range ts.Histograms {
+		timestamp = max(timestamp, histogram.Timestamp)
+	}
+	return timestamp
+}
+
+// expire returns the staleness markers of the series not sent for
+// stale_after, by tenant.
+func (t *stalenessTracker) expire() map[string]map[string]*prompb.TimeSeries {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	markers := map[string]map[string]*prompb.TimeSeries{}
+	if t.staleAfter == 0 {
+		return markers
+	}
+	now := t.now()
+	for target, pushes := range t.targets {
+		for sig, tracked := range pushes.series {
+			if now.Sub(tracked.lastSeen) < t.staleAfter {
+				continue
+			}
+			if markers[target.tenant] == nil {
+				markers[target.tenant] = map[string]*prompb.TimeSeries{}
+			}
+			markers[target.tenant][sig] = tracked.marker(now)
+			delete(pushes.series, sig)
+		}
+		if len(pushes.series) == 0 {
+			delete(t.targets, target)
+		}
+	}
+	return markers
+}
+
+// marker returns the staleness marker of the series, after its last sample.
+func (s *trackedSeries) marker(now time.Time) *prompb.TimeSeries {
+	timestamp := max(now.UnixMilli(), s.lastTimestamp+1)
+	ts := &prompb.TimeSeries{Labels: s.labels}
+	if s.histogram {
+		ts.Histograms = []prompb.Histogram{{Sum: math.Float64frombits(value.StaleNaN), Timestamp: timestamp}}
+	} else {
+		ts.Samples = []prompb.Sample{{Value: math.Float64frombits(value.StaleNaN), Timestamp: timestamp}}
+	}
+	return ts
+}
+
+// sendStalenessMarkers sends the staleness markers of the series not sent for
+// stale_after, until the exporter is shut down.
+func (prwe *prwExporter) sendStalenessMarkers(interval time.Duration) {
+	defer prwe.wg.Done()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-prwe.closeChan:
+			return
+		case <-ticker.C:
+			for tenant, markers := range prwe.staleness.expire() {
+				ctx := context.Background()
+				if tenant != "" {
+					ctx = contextWithTenant(ctx, tenant)
+				}
+				if err := prwe.handleExport(ctx, markers, nil); err != nil {
+					prwe.settings.Logger.Warn("Failed to send staleness markers", zap.Error(err), zap.Int("series", len(markers)))
+				}
+			}
+		}
+	}
+}
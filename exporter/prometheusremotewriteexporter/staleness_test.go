@@ -0,0 +1,155 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package prometheusremotewriteexporter
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/golang/snappy"
+	"github.com/prometheus/prometheus/model/value"
+	"github.com/prometheus/prometheus/prompb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/exporter/exportertest"
+)
+
+func stalenessSeries(job, name string, timestamp int64) *prompb.TimeSeries {
+	return &prompb.TimeSeries{
+		Labels:  []prompb.Label{{Name: "__name__", Value: name}, {Name: "job", Value: job}},
+		Samples: []prompb.Sample{{Value: 1, Timestamp: timestamp}},
+	}
+}
+
+func TestStalenessTrackerPushes(t *testing.T) {
+	tracker := newStalenessTracker(StalenessConfig{Enabled: true, StaleAfterPushes: 2})
+
+	tracker.observe("", map[string]*prompb.TimeSeries{
+		"a-up":   stalenessSeries("a", "up", 1000),
+		"a-reqs": stalenessSeries("a", "reqs", 1000),
+		"b-up":   stalenessSeries("b", "up", 1000),
+	})
+
+	// The series of b aren't stale while b doesn't push. A push of a is only
+	// complete once the next one starts, so reqs misses the pushes at 2000
+	// and 3000 when the push at 4000 starts.
+	for _, timestamp := range []int64{2000, 3000, 4000} {
+		tsMap := map[string]*prompb.TimeSeries{"a-up": stalenessSeries("a", "up", timestamp)}
+		tracker.observe("", tsMap)
+		if timestamp < 4000 {
+			assert.Len(t, tsMap, 1)
+			continue
+		}
+		require.Len(t, tsMap, 2)
+		marker := tsMap["a-reqs"]
+		require.NotNil(t, marker)
+		assert.Equal(t, "reqs", marker.Labels[0].Value)
+		require.Len(t, marker.Samples, 1)
+		assert.True(t, value.IsStaleNaN(marker.Samples[0].Value))
+		assert.Greater(t, marker.Samples[0].Timestamp, int64(1000))
+	}
+
+	// The marker is sent once.
+	tsMap := map[string]*prompb.TimeSeries{"a-up": stalenessSeries("a", "up", 5000)}
+	tracker.observe("", tsMap)
+	assert.Len(t, tsMap, 1)
+	assert.Empty(t, tracker.expire())
+}
+
+func TestStalenessTrackerSplitPushes(t *testing.T) {
+	tracker := newStalenessTracker(StalenessConfig{Enabled: true, StaleAfterPushes: 1})
+
+	// Each push of a is split in two batches, its series are never stale.
+	for _, timestamp := range []int64{1000, 2000, 3000, 4000} {
+		for _, name := range []string{"up", "reqs"} {
+			tsMap := map[string]*prompb.TimeSeries{"a-" + name: stalenessSeries("a", name, timestamp)}
+			tracker.observe("", tsMap)
+			assert.Len(t, tsMap, 1)
+		}
+	}
+
+	// Once reqs is no longer sent, it is stale when the push after the one
+	// missing it starts.
+	tracker.observe("", map[string]*prompb.TimeSeries{"a-up": stalenessSeries("a", "up", 5000)})
+	tsMap := map[string]*prompb.TimeSeries{"a-up": stalenessSeries("a", "up", 6000)}
+	tracker.observe("", tsMap)
+	require.Len(t, tsMap, 2)
+	assert.Contains(t, tsMap, "a-reqs")
+}
+
+func TestStalenessTrackerExpire(t *testing.T) {
+	now := time.Now()
+	tracker := newStalenessTracker(StalenessConfig{Enabled: true, StaleAfter: time.Minute})
+	tracker.now = func() time.Time { return now }
+
+	tracker.observe("team-a", map[string]*prompb.TimeSeries{"a-up": stalenessSeries("a", "up", now.UnixMilli())})
+	histogram := &prompb.TimeSeries{
+		Labels:     []prompb.Label{{Name: "__name__", Value: "latency"}, {Name: "job", Value: "b"}},
+		Histograms: []prompb.Histogram{{Sum: 1, Timestamp: now.UnixMilli()}},
+	}
+	tracker.observe("", map[string]*prompb.TimeSeries{"b-latency": histogram})
+
+	now = now.Add(30 * time.Second)
+	tracker.observe("team-a", map[string]*prompb.TimeSeries{"a-up": stalenessSeries("a", "up", now.UnixMilli())})
+	assert.Empty(t, tracker.expire())
+
+	now = now.Add(45 * time.Second)
+	markers := tracker.expire()
+	require.Len(t, markers, 1)
+	require.Contains(t, markers[""], "b-latency")
+	assert.True(t, value.IsStaleNaN(markers[""]["b-latency"].Histograms[0].Sum))
+
+	now = now.Add(time.Minute)
+	markers = tracker.expire()
+	require.Len(t, markers, 1)
+	assert.Contains(t, markers["team-a"], "a-up")
+	assert.Empty(t, tracker.targets)
+}
+
+func TestStalenessMarkersSent(t *testing.T) {
+	var mu sync.Mutex
+	staleSeries := map[string]bool{}
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		data, err := snappy.Decode(nil, body)
+		require.NoError(t, err)
+		var req prompb.WriteRequest
+		require.NoError(t, proto.Unmarshal(data, &req))
+		mu.Lock()
+		defer mu.Unlock()
+		for _, ts := range req.Timeseries {
+			for _, sample := range ts.Samples {
+				if value.IsStaleNaN(sample.Value) {
+					staleSeries[ts.Labels[0].Value] = true
+				}
+			}
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer mockServer.Close()
+
+	cfg := createDefaultConfig().(*Config)
+	cfg.ClientConfig.Endpoint = mockServer.URL
+	cfg.TargetInfo.Enabled = false
+	cfg.Staleness = StalenessConfig{Enabled: true, StaleAfter: 50 * time.Millisecond}
+	prwe, err := newPRWExporter(cfg, exportertest.NewNopCreateSettings())
+	require.NoError(t, err)
+	require.NoError(t, prwe.Start(context.Background(), componenttest.NewNopHost()))
+	defer func() { require.NoError(t, prwe.Shutdown(context.Background())) }()
+
+	require.NoError(t, prwe.PushMetrics(context.Background(), tenantMetrics("")))
+	assert.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return staleSeries["up"]
+	}, 5*time.Second, 10*time.Millisecond)
+}
@@ -0,0 +1,248 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package prometheusremotewriteexporter // import "github.com/open-telemetry/opentelemetry-collector-contrib/exporter/prometheusremotewriteexporter"
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/prometheus/prometheus/prompb"
+	"go.opentelemetry.io/collector/client"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.uber.org/multierr"
+)
+
+const (
+	defaultTenantHeader = "X-Scope-OrgID"
+	defaultTenant       = "anonymous"
+	defaultMaxTenants   = 100
+
+	// tenantIdleTimeout is the time after which a tenant without series is
+	// no longer counted in max_tenants.
+	tenantIdleTimeout = 10 * time.Minute
+
+	// walTenantField is the protobuf field number of the tenant of a WAL
+	// entry, appended to the encoded WriteRequest. It isn't a field of
+	// WriteRequest, so the entry still decodes as a WriteRequest.
+	walTenantField = 1000
+)
+
+// TenantConfig routes the series to the tenant of their resource, set in a
+// header of each request.
+type TenantConfig struct {
+	// ResourceAttribute is the resource attribute holding the tenant.
+	ResourceAttribute string `mapstructure:"resource_attribute"`
+
+	// MetadataKey is the client metadata key holding the tenant, used for
+	// the resources without ResourceAttribute.
+	MetadataKey string `mapstructure:"metadata_key"`
+
+	// Header is the request header set to the tenant.
+	Header string `mapstructure:"header"`
+
+	// Default is the tenant of the series without tenant.
+	Default string `mapstructure:"default"`
+
+	// MaxTenants is the maximum number of tenants the exporter sends series
+	// for at a time, the series of the other tenants are dropped.
+	MaxTenants int `mapstructure:"max_tenants"`
+}
+
+func (tc *TenantConfig) header() string {
+	if tc.Header == "" {
+		return defaultTenantHeader
+	}
+	return tc.Header
+}
+
+// Validate checks if the tenant configuration is valid.
+func (tc *TenantConfig) Validate() error {
+	if tc.ResourceAttribute == "" && tc.MetadataKey == "" {
+		return fmt.Errorf("tenant requires a resource_attribute or a metadata_key")
+	}
+	if tc.MaxTenants < 0 {
+		return fmt.Errorf("tenant max_tenants can't be negative")
+	}
+	return nil
+}
+
+type tenantContextKey struct{}
+
+func contextWithTenant(ctx context.Context, tenant string) context.Context {
+	return context.WithValue(ctx, tenantContextKey{}, tenant)
+}
+
+// tenantFromContext returns the tenant of the series sent with ctx, or an
+// empty string without tenant routing.
+func tenantFromContext(ctx context.Context) string {
+	tenant, _ := ctx.Value(tenantContextKey{}).(string)
+	return tenant
+}
+
+// tenantRouter splits the metrics by tenant, and tracks the tenants to cap
+// their number.
+type tenantRouter struct {
+	resourceAttribute string
+	metadataKey       string
+	defaultTenant     string
+	maxTenants        int
+
+	mu       sync.Mutex
+	lastSeen map[string]time.Time
+	now      func() time.Time
+}
+
+func newTenantRouter(cfg *TenantConfig) *tenantRouter {
+	r := &tenantRouter{
+		resourceAttribute: cfg.ResourceAttribute,
+		metadataKey:       cfg.MetadataKey,
+		defaultTenant:     cfg.Default,
+		maxTenants:        cfg.MaxTenants,
+		lastSeen:          map[string]time.Time{},
+		now:               time.Now,
+	}
+	if r.defaultTenant == "" {
+		r.defaultTenant = defaultTenant
+	}
+	if r.maxTenants == 0 {
+		r.maxTenants = defaultMaxTenants
+	}
+	return r
+}
+
+// split returns the metrics of each tenant. The metrics of the tenants above
+// the maximum number of tenants are dropped and reported in the error.
+func (r *tenantRouter) split(ctx context.Context, md pmetric.Metrics) (map[string]pmetric.Metrics, error) {
+	metadataTenant := r.defaultTenant
+	if r.metadataKey != "" {
+		if values := client.FromContext(ctx).Metadata.Get(r.metadataKey); len(values) > 0 && values[0] != "" {
+			metadataTenant = values[0]
+		}
+	}
+
+	resourceMetrics := md.ResourceMetrics()
+	tenants := make([]string, resourceMetrics.Len())
+	single := true
+	for i := range tenants {
+		tenants[i] = metadataTenant
+		if r.resourceAttribute != "" {
+			if value, ok := resourceMetrics.At(i).Resource().Attributes().Get(r.resourceAttribute); ok && value.AsString() != "" {
+				tenants[i] = value.AsString()
+			}
+		}
+		single = single && tenants[i] == tenants[0]
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	now := r.now()
+	if single && len(tenants) > 0 {
+		if !r.track(tenants[0], now) {
+			return nil, fmt.Errorf("dropped the series of tenant %q, max_tenants reached", tenants[0])
+		}
+		return map[string]pmetric.Metrics{tenants[0]: md}, nil
+	}
+
+	var errs error
+	split := map[string]pmetric.Metrics{}
+	dropped := map[string]bool{}
+	for i, tenant := range tenants {
+		if dropped[tenant] {
+			continue
+		}
+		tenantMetrics, ok := split[tenant]
+		if !ok {
+			if !r.track(tenant, now) {
+				dropped[tenant] = true
+				errs = multierr.Append(errs, fmt.Errorf("dropped the series of tenant %q, max_tenants reached", tenant))
+				continue
+			}
+			tenantMetrics = pmetric.NewMetrics()
+			split[tenant] = tenantMetrics
+		}
+		resourceMetrics.At(i).CopyTo(tenantMetrics.ResourceMetrics().AppendEmpty())
+	}
+	return split, errs
+}
+
+// track records that tenant has series, and returns false if it is a new
+// tenant above the maximum number of tenants. The default tenant is always
+// accepted.
+func (r *tenantRouter) track(tenant string, now time.Time) bool {
+	if tenant == r.defaultTenant {
+		return true
+	}
+	if _, ok := r.lastSeen[tenant]; ok {
+		r.lastSeen[tenant] = now
+		return true
+	}
+	if len(r.lastSeen) >= r.maxTenants {
+		for t, seen := range r.lastSeen {
+			if now.Sub(seen) > tenantIdleTimeout {
+				delete(r.lastSeen, t)
+			}
+		}
+	}
+	if len(r.lastSeen) >= r.maxTenants {
+		return false
+	}
+	r.lastSeen[tenant] = now
+	return true
+}
+
+// appendWALTenant appends the tenant of a WAL entry to the encoded request.
+func appendWALTenant(protoBlob []byte, tenant string) []byte {
+	if tenant == "" {
+		return protoBlob
+	}
+	buf := proto.NewBuffer(protoBlob)
+	_ = buf.EncodeVarint(uint64(walTenantField)<<3 | proto.WireBytes)
+	_ = buf.EncodeStringBytes(tenant)
+	return buf.Bytes()
+}
+
+// walTenant returns the tenant of a request read from the WAL, which the
+// request kept as an unrecognized field, and removes it from the request.
+func walTenant(req *prompb.WriteRequest) string {
+	buf := proto.NewBuffer(req.XXX_unrecognized)
+	req.XXX_unrecognized = nil
+	tag, err := buf.DecodeVarint()
+	if err != nil || tag != uint64(walTenantField)<<3|proto.WireBytes {
+		return ""
+	}
+	tenant, err := buf.DecodeStringBytes()
+	if err != nil {
+		return ""
+	}
+	return tenant
+}
+
+// exportByTenant exports the requests read from the WAL with the tenant they
+// were written with.
+func (prwe *prweWAL) exportByTenant(ctx context.Context, reqL []*prompb.WriteRequest) error {
+	var tenants []string
+	byTenant := map[string][]*prompb.WriteRequest{}
+	for _, req := range reqL {
+		tenant := walTenant(req)
+		if _, ok := byTenant[tenant]; !ok {
+			tenants = append(tenants, tenant)
+		}
+		byTenant[tenant] = append(byTenant[tenant], req)
+	}
+	if len(tenants) == 0 {
+		return prwe.exportSink(ctx, reqL)
+	}
+	var errs error
+	for _, tenant := range tenants {
+		tenantCtx := ctx
+		if tenant != "" {
+			tenantCtx = contextWithTenant(ctx, tenant)
+		}
+		errs = multierr.Append(errs, prwe.exportSink(tenantCtx, byTenant[tenant]))
+	}
+	return errs
+}
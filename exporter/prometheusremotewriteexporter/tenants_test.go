@@ -0,0 +1,158 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package prometheusremotewriteexporter
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/golang/snappy"
+	"github.com/prometheus/prometheus/prompb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/client"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/config/configopaque"
+	"go.opentelemetry.io/collector/exporter/exportertest"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+)
+
+// tenantMetrics returns a gauge for each tenant, in a resource with a tenant
+// attribute unless the tenant is empty.
+func tenantMetrics(tenants ...string) pmetric.Metrics {
+	md := pmetric.NewMetrics()
+	for _, tenant := range tenants {
+		rm := md.ResourceMetrics().AppendEmpty()
+		if tenant != "" {
+			rm.Resource().Attributes().PutStr("tenant", tenant)
+		}
+		metric := rm.ScopeMetrics().AppendEmpty().Metrics().AppendEmpty()
+		metric.SetName("up")
+		pt := metric.SetEmptyGauge().DataPoints().AppendEmpty()
+		pt.SetTimestamp(pcommon.NewTimestampFromTime(time.Now()))
+		pt.SetDoubleValue(1)
+	}
+	return md
+}
+
+func TestTenantRouterSplit(t *testing.T) {
+	router := newTenantRouter(&TenantConfig{ResourceAttribute: "tenant", MetadataKey: "X-Tenant", MaxTenants: 2})
+
+	split, err := router.split(context.Background(), tenantMetrics("a", "b", "a", ""))
+	require.NoError(t, err)
+	require.Len(t, split, 3)
+	assert.Equal(t, 2, split["a"].ResourceMetrics().Len())
+	assert.Equal(t, 1, split["b"].ResourceMetrics().Len())
+	assert.Equal(t, 1, split[defaultTenant].ResourceMetrics().Len())
+
+	// The client metadata is used for the resources without tenant.
+	ctx := client.NewContext(context.Background(), client.Info{
+		Metadata: client.NewMetadata(map[string][]string{"X-Tenant": {"b"}}),
+	})
+	md := tenantMetrics("", "")
+	split, err = router.split(ctx, md)
+	require.NoError(t, err)
+	require.Len(t, split, 1)
+	assert.Equal(t, md, split["b"])
+
+	// New tenants are dropped above max_tenants, until a tenant is idle.
+	split, err = router.split(context.Background(), tenantMetrics("c", "a"))
+	assert.EqualError(t, err, `dropped the series of tenant "c", max_tenants reached`)
+	require.Len(t, split, 1)
+	assert.Contains(t, split, "a")
+
+	now := time.Now()
+	router.now = func() time.Time { return now.Add(tenantIdleTimeout + time.Minute) }
+	_, err = router.split(context.Background(), tenantMetrics("a"))
+	require.NoError(t, err)
+	split, err = router.split(context.Background(), tenantMetrics("c"))
+	require.NoError(t, err)
+	assert.Contains(t, split, "c")
+}
+
+func TestWALTenant(t *testing.T) {
+	req := &prompb.WriteRequest{Timeseries: []prompb.TimeSeries{{
+		Labels:  []prompb.Label{{Name: "__name__", Value: "up"}},
+		Samples: []prompb.Sample{{Value: 1, Timestamp: 1}},
+	}}}
+	protoBlob, err := proto.Marshal(req)
+	require.NoError(t, err)
+
+	for _, tenant := range []string{"", "team-a"} {
+		got := new(prompb.WriteRequest)
+		require.NoError(t, proto.Unmarshal(appendWALTenant(protoBlob, tenant), got))
+		assert.Equal(t, tenant, walTenant(got))
+		assert.Equal(t, req, got)
+	}
+}
+
+func TestTenantConfigValidate(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.Tenant = &TenantConfig{}
+	assert.EqualError(t, cfg.Tenant.Validate(), "tenant requires a resource_attribute or a metadata_key")
+
+	cfg.Tenant.ResourceAttribute = "tenant"
+	cfg.ClientConfig.Headers = map[string]configopaque.String{"x-scope-orgid": "static"}
+	assert.EqualError(t, cfg.Validate(), `tenant header "X-Scope-Orgid" can't be set in headers`)
+}
+
+func TestTenantRouting(t *testing.T) {
+	for _, withWAL := range []bool{false, true} {
+		t.Run(map[bool]string{false: "queue", true: "wal"}[withWAL], func(t *testing.T) {
+			var mu sync.Mutex
+			seriesByTenant := map[string]int{}
+			mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, err := io.ReadAll(r.Body)
+				require.NoError(t, err)
+				data, err := snappy.Decode(nil, body)
+				require.NoError(t, err)
+				var req prompb.WriteRequest
+				require.NoError(t, proto.Unmarshal(data, &req))
+				mu.Lock()
+				defer mu.Unlock()
+				seriesByTenant[r.Header.Get("X-Scope-OrgID")] += len(req.Timeseries)
+				w.WriteHeader(http.StatusNoContent)
+			}))
+			defer mockServer.Close()
+
+			cfg := createDefaultConfig().(*Config)
+			cfg.ClientConfig.Endpoint = mockServer.URL
+			cfg.TargetInfo.Enabled = false
+			cfg.Tenant = &TenantConfig{ResourceAttribute: "tenant"}
+			if withWAL {
+				cfg.WAL = &WALConfig{Directory: t.TempDir(), BufferSize: 1, TruncateFrequency: 10 * time.Millisecond}
+			}
+			require.NoError(t, cfg.Validate())
+
+			prwe, err := newPRWExporter(cfg, exportertest.NewNopCreateSettings())
+			require.NoError(t, err)
+			require.NoError(t, prwe.Start(context.Background(), componenttest.NewNopHost()))
+			require.NoError(t, prwe.PushMetrics(context.Background(), tenantMetrics("a", "b", "a", "")))
+
+			assert.Eventually(t, func() bool {
+				mu.Lock()
+				defer mu.Unlock()
+				return len(seriesByTenant) == 3
+			}, 5*time.Second, 10*time.Millisecond)
+			require.NoError(t, prwe.Shutdown(context.Background()))
+
+			mu.Lock()
+			defer mu.Unlock()
+			tenants := make([]string, 0, len(seriesByTenant))
+			for tenant := range seriesByTenant {
+				tenants = append(tenants, tenant)
+			}
+			sort.Strings(tenants)
+			assert.Equal(t, []string{"a", defaultTenant, "b"}, tenants)
+		})
+	}
+}
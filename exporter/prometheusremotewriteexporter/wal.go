@@ -7,6 +7,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"os"
 	"path/filepath"
 	"sync"
 	"sync/atomic"
@@ -16,33 +17,121 @@ import (
 	"github.com/gogo/protobuf/proto"
 	"github.com/prometheus/prometheus/prompb"
 	"github.com/tidwall/wal"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/extension/experimental/storage"
 	"go.uber.org/multierr"
 	"go.uber.org/zap"
 )
 
 type prweWAL struct {
 	mu        sync.Mutex // mu protects the fields below.
-	wal       *wal.Log
+	wal       walLog
 	walConfig *WALConfig
 	walPath   string
+	// storageClient stores the WAL instead of walConfig.Directory when
+	// walConfig.StorageID is set.
+	storageClient storage.Client
+	// written is signaled by persistToWAL, for the reader of a WAL
+	// which can't be watched on disk.
+	written chan struct{}
 
 	exportSink func(ctx context.Context, reqL []*prompb.WriteRequest) error
+	telemetry  prwTelemetry
 
 	stopOnce  sync.Once
 	stopChan  chan struct{}
 	rWALIndex *atomic.Uint64
 	wWALIndex *atomic.Uint64
+	sizeBytes *atomic.Int64
 }
 
 const (
 	defaultWALBufferSize        = 300
 	defaultWALTruncateFrequency = 1 * time.Minute
+
+	walOverflowDropOldest = "drop_oldest"
+	walOverflowDropNewest = "drop_newest"
+
+	walDropReasonSize = "size"
+	walDropReasonAge  = "age"
 )
 
+// walLog is the log the WAL entries are stored in, either files managed by
+// tidwall/wal or a storage extension.
+type walLog interface {
+	FirstIndex() (uint64, error)
+	LastIndex() (uint64, error)
+	Read(index uint64) ([]byte, error)
+	// writeEntries writes entries at consecutive indices from index.
+	writeEntries(index uint64, entries [][]byte) error
+	TruncateFront(index uint64) error
+	Sync() error
+	Close() error
+	// size is the size of the log in bytes.
+	size() int64
+}
+
+// fileLog is a walLog stored in a directory.
+type fileLog struct {
+	*wal.Log
+	path string
+}
+
+func (l *fileLog) writeEntries(index uint64, entries [][]byte) error {
+	batch := new(wal.Batch)
+	for i, data := range entries {
+		batch.Write(index+uint64(i), data)
+	}
+	return l.WriteBatch(batch)
+}
+
+func (l *fileLog) size() int64 {
+	dirEntries, err := os.ReadDir(l.path)
+	if err != nil {
+		return 0
+	}
+	var size int64
+	for _, entry := range dirEntries {
+		if info, err := entry.Info(); err == nil {
+			size += info.Size()
+		}
+	}
+	return size
+}
+
 type WALConfig struct {
 	Directory         string        `mapstructure:"directory"`
 	BufferSize        int           `mapstructure:"buffer_size"`
 	TruncateFrequency time.Duration `mapstructure:"truncate_frequency"`
+
+	// StorageID is the ID of a storage extension to store the WAL in,
+	// instead of Directory.
+	StorageID *component.ID `mapstructure:"storage"`
+
+	// MaxSizeBytes is the maximum size of the WAL on disk, 0 for no limit.
+	MaxSizeBytes int64 `mapstructure:"max_size_bytes"`
+	// MaxAge is the maximum age of the newest sample of an entry before the
+	// entry is dropped, 0 for no limit.
+	MaxAge time.Duration `mapstructure:"max_age"`
+	// OverflowPolicy is what happens when the WAL exceeds MaxSizeBytes:
+	// "drop_oldest" drops the oldest entries, "drop_newest" rejects the
+	// incoming requests.
+	OverflowPolicy string `mapstructure:"overflow_policy"`
+}
+
+func (wc *WALConfig) Validate() error {
+	if wc.MaxSizeBytes < 0 {
+		return fmt.Errorf("wal max_size_bytes can't be negative")
+	}
+	if wc.MaxAge < 0 {
+		return fmt.Errorf("wal max_age can't be negative")
+	}
+	switch wc.OverflowPolicy {
+	case "", walOverflowDropOldest, walOverflowDropNewest:
+		return nil
+	default:
+		return fmt.Errorf("wal overflow_policy must be %q or %q, got %q", walOverflowDropOldest, walOverflowDropNewest, wc.OverflowPolicy)
+	}
 }
 
 func (wc *WALConfig) bufferSize() int {
@@ -59,23 +148,32 @@ func (wc *WALConfig) truncateFrequency() time.Duration {
 	return defaultWALTruncateFrequency
 }
 
-func newWAL(walConfig *WALConfig, exportSink func(context.Context, []*prompb.WriteRequest) error) *prweWAL {
+func (wc *WALConfig) dropNewest() bool {
+	return wc.OverflowPolicy == walOverflowDropNewest
+}
+
+func newWAL(walConfig *WALConfig, exportSink func(context.Context, []*prompb.WriteRequest) error, telemetry prwTelemetry) *prweWAL {
 	if walConfig == nil {
 		// There are cases for which the WAL can be disabled.
 		// TODO: Perhaps log that the WAL wasn't enabled.
 		return nil
 	}
 
-	return &prweWAL{
+	prwe := &prweWAL{
 		exportSink: exportSink,
+		telemetry:  telemetry,
 		walConfig:  walConfig,
 		stopChan:   make(chan struct{}),
 		rWALIndex:  &atomic.Uint64{},
 		wWALIndex:  &atomic.Uint64{},
+		sizeBytes:  &atomic.Int64{},
+		written:    make(chan struct{}, 1),
 	}
+	telemetry.observeWAL(prwe)
+	return prwe
 }
 
-func (wc *WALConfig) createWAL() (*wal.Log, string, error) {
+func (wc *WALConfig) createWAL() (walLog, string, error) {
 	walPath := filepath.Join(wc.Directory, "prom_remotewrite")
 	log, err := wal.Open(walPath, &wal.Options{
 		SegmentCacheSize: wc.bufferSize(),
@@ -84,46 +182,94 @@ func (wc *WALConfig) createWAL() (*wal.Log, string, error) {
 	if err != nil {
 		return nil, "", fmt.Errorf("prometheusremotewriteexporter: failed to open WAL: %w", err)
 	}
-	return log, walPath, nil
+	return &fileLog{Log: log, path: walPath}, walPath, nil
+}
+
+// useStorage makes the WAL store its entries in client, which is closed when
+// the WAL is stopped.
+func (prwe *prweWAL) useStorage(client storage.Client) {
+	prwe.mu.Lock()
+	defer prwe.mu.Unlock()
+	prwe.storageClient = client
+}
+
+// openLog opens the log of the WAL and returns the path to watch for writes,
+// empty if the log isn't stored in a directory.
+func (prwe *prweWAL) openLog() (walLog, string, error) {
+	if prwe.storageClient == nil {
+		return prwe.walConfig.createWAL()
+	}
+	log, err := newStorageLog(context.Background(), prwe.storageClient)
+	if err != nil {
+		return nil, "", fmt.Errorf("prometheusremotewriteexporter: failed to open WAL from storage: %w", err)
+	}
+	return log, "", nil
 }
 
 var (
 	errAlreadyClosed = errors.New("already closed")
 	errNilWAL        = errors.New("wal is nil")
+	errWALFull       = errors.New("wal is full")
 )
 
 // retrieveWALIndices queries the WriteAheadLog for its current first and last indices.
+// The read index is kept when the WAL is reopened, so that the entries already
+// read are not replayed again.
 func (prwe *prweWAL) retrieveWALIndices() (err error) {
 	prwe.mu.Lock()
 	defer prwe.mu.Unlock()
 
-	err = prwe.closeWAL()
-	if err != nil {
-		return err
-	}
+	// The storage log keeps its indices up to date, only the file log is
+	// reopened to reload them.
+	if prwe.wal == nil || prwe.storageClient == nil {
+		err = prwe.closeWAL()
+		if err != nil {
+			return err
+		}
 
-	log, walPath, err := prwe.walConfig.createWAL()
-	if err != nil {
-		return err
-	}
+		log, walPath, err := prwe.openLog()
+		if err != nil {
+			return err
+		}
 
-	prwe.wal = log
-	prwe.walPath = walPath
+		prwe.wal = log
+		prwe.walPath = walPath
+	}
 
-	rIndex, err := prwe.wal.FirstIndex()
+	firstIndex, err := prwe.wal.FirstIndex()
 	if err != nil {
 		return fmt.Errorf("prometheusremotewriteexporter: failed to retrieve the first WAL index: %w", err)
 	}
-	prwe.rWALIndex.Store(rIndex)
 
 	wIndex, err := prwe.wal.LastIndex()
 	if err != nil {
 		return fmt.Errorf("prometheusremotewriteexporter: failed to retrieve the last WAL index: %w", err)
 	}
 	prwe.wWALIndex.Store(wIndex)
+
+	rIndex := max(firstIndex, 1)
+	if prev := prwe.rWALIndex.Load(); prev > rIndex && prev <= wIndex+1 {
+		rIndex = prev
+	}
+	prwe.rWALIndex.Store(rIndex)
+	prwe.refreshSize()
 	return nil
 }
 
+// refreshSize updates the size of the WAL from its log.
+func (prwe *prweWAL) refreshSize() {
+	prwe.sizeBytes.Store(prwe.wal.size())
+}
+
+// lag is the number of entries in the WAL that were not read yet.
+func (prwe *prweWAL) lag() int64 {
+	w, r := prwe.wWALIndex.Load(), prwe.rWALIndex.Load()
+	if w < r {
+		return 0
+	}
+	return int64(w - r + 1)
+}
+
 func (prwe *prweWAL) stop() error {
 	err := errAlreadyClosed
 	prwe.stopOnce.Do(func() {
@@ -132,6 +278,9 @@ func (prwe *prweWAL) stop() error {
 
 		close(prwe.stopChan)
 		err = prwe.closeWAL()
+		if prwe.storageClient != nil {
+			err = multierr.Append(err, prwe.storageClient.Close(context.Background()))
+		}
 	})
 	return err
 }
@@ -191,7 +340,7 @@ func (prwe *prweWAL) continuallyPopWALThenExport(ctx context.Context, signalStar
 	defer func() {
 		// Keeping it within a closure to ensure that the later
 		// updated value of reqL is always flushed to disk.
-		if errL := prwe.exportSink(ctx, reqL); errL != nil {
+		if errL := prwe.exportByTenant(ctx, reqL); errL != nil {
 			err = multierr.Append(err, errL)
 		}
 	}()
@@ -287,9 +436,10 @@ func (prwe *prweWAL) exportThenFrontTruncateWAL(ctx context.Context, reqL []*pro
 		return nil
 	}
 
-	if errL := prwe.exportSink(ctx, reqL); errL != nil {
+	if errL := prwe.exportByTenant(ctx, reqL); errL != nil {
 		return errL
 	}
+	prwe.telemetry.recordWALReplayedEntries(ctx, len(reqL))
 	if err := prwe.syncAndTruncateFront(); err != nil {
 		return err
 	}
@@ -300,29 +450,141 @@ func (prwe *prweWAL) exportThenFrontTruncateWAL(ctx context.Context, reqL []*pro
 // persistToWAL is the routine that'll be hooked into the exporter's receiving side and it'll
 // write them to the Write-Ahead-Log so that shutdowns won't lose data, and that the routine that
 // reads from the WAL can then process the previously serialized requests.
-func (prwe *prweWAL) persistToWAL(requests []*prompb.WriteRequest) error {
+func (prwe *prweWAL) persistToWAL(ctx context.Context, requests []*prompb.WriteRequest) error {
 	prwe.mu.Lock()
 	defer prwe.mu.Unlock()
 
-	// Write all the requests to the WAL in a batch.
-	batch := new(wal.Batch)
+	blobs := make([][]byte, 0, len(requests))
+	var batchSize int64
 	for _, req := range requests {
 		protoBlob, err := proto.Marshal(req)
 		if err != nil {
 			return err
 		}
-		wIndex := prwe.wWALIndex.Add(1)
-		batch.Write(wIndex, protoBlob)
+		protoBlob = appendWALTenant(protoBlob, tenantFromContext(ctx))
+		blobs = append(blobs, protoBlob)
+		batchSize += int64(len(protoBlob))
 	}
 
-	return prwe.wal.WriteBatch(batch)
+	maxSize := prwe.walConfig.MaxSizeBytes
+	if maxSize > 0 && prwe.walConfig.dropNewest() && prwe.sizeBytes.Load()+batchSize > maxSize {
+		prwe.telemetry.recordWALDroppedEntries(ctx, len(requests), walDropReasonSize)
+		return errWALFull
+	}
+
+	// Write all the requests to the WAL in a batch.
+	wIndex := prwe.wWALIndex.Add(uint64(len(blobs))) - uint64(len(blobs)) + 1
+	if err := prwe.wal.writeEntries(wIndex, blobs); err != nil {
+		return err
+	}
+	prwe.refreshSize()
+	select {
+	case prwe.written <- struct{}{}:
+	default:
+	}
+	return prwe.enforceLimits(ctx)
 }
 
-func (prwe *prweWAL) readPrompbFromWAL(ctx context.Context, index uint64) (wreq *prompb.WriteRequest, err error) {
-	prwe.mu.Lock()
-	defer prwe.mu.Unlock()
+// enforceLimits drops the oldest entries of the WAL older than MaxAge, then
+// those over MaxSizeBytes with the drop_oldest policy. The last entry is never
+// dropped. Entries dropped before being read are skipped by the reader.
+func (prwe *prweWAL) enforceLimits(ctx context.Context) error {
+	firstIndex, err := prwe.wal.FirstIndex()
+	if err != nil {
+		return err
+	}
+	lastIndex, err := prwe.wal.LastIndex()
+	if err != nil {
+		return err
+	}
+
+	index := firstIndex
+	if maxAge := prwe.walConfig.MaxAge; maxAge > 0 {
+		cutoff := time.Now().Add(-maxAge).UnixMilli()
+		expired := index
+		for i := index; i < lastIndex; i++ {
+			req, err := prwe.readEntry(i)
+			if err != nil {
+				return err
+			}
+			newest, ok := newestTimestamp(req)
+			if !ok {
+				// Requests without samples, like metadata, are dropped
+				// along with the expired samples around them.
+				continue
+			}
+			if newest >= cutoff {
+				break
+			}
+			expired = i + 1
+		}
+		if expired > index {
+			prwe.telemetry.recordWALDroppedEntries(ctx, int(expired-index), walDropReasonAge)
+			index = expired
+		}
+	}
+
+	if maxSize := prwe.walConfig.MaxSizeBytes; maxSize > 0 && !prwe.walConfig.dropNewest() {
+		excess := prwe.sizeBytes.Load() - maxSize
+		overflow := index
+		for i := index; excess > 0 && i < lastIndex; i++ {
+			protoBlob, err := prwe.wal.Read(i)
+			if err != nil {
+				return err
+			}
+			excess -= int64(len(protoBlob))
+			overflow = i + 1
+		}
+		if overflow > index {
+			prwe.telemetry.recordWALDroppedEntries(ctx, int(overflow-index), walDropReasonSize)
+			index = overflow
+		}
+	}
+
+	if index == firstIndex {
+		return nil
+	}
+	if err := prwe.wal.TruncateFront(index); err != nil {
+		return err
+	}
+	if prwe.rWALIndex.Load() < index {
+		prwe.rWALIndex.Store(index)
+	}
+	prwe.refreshSize()
+	return nil
+}
+
+func (prwe *prweWAL) readEntry(index uint64) (*prompb.WriteRequest, error) {
+	protoBlob, err := prwe.wal.Read(index)
+	if err != nil {
+		return nil, err
+	}
+	req := new(prompb.WriteRequest)
+	if err := proto.Unmarshal(protoBlob, req); err != nil {
+		return nil, err
+	}
+	return req, nil
+}
+
+// newestTimestamp returns the timestamp of the newest sample of req.
+func newestTimestamp(req *prompb.WriteRequest) (int64, bool) {
+	var newest int64
+	found := false
+	for _, ts := range req.Timeseries {
+		for _, sample := range ts.Samples {
+			newest, found = max(newest, sample.Timestamp), true
+		}
+		for _, histogram := range ts.Histograms {
+			newest, found = max(newest, histogram.Timestamp), true
+		}
+	}
+	return newest, found
+}
 
-	var protoBlob []byte
+// readPrompbFromWAL reads the entry at index, or the first entry if the
+// entries up to index were dropped. It waits for the entry to be written
+// without holding the lock, so that persistToWAL isn't blocked meanwhile.
+func (prwe *prweWAL) readPrompbFromWAL(ctx context.Context, index uint64) (wreq *prompb.WriteRequest, err error) {
 	for i := 0; i < 12; i++ {
 		// Firstly check if we've been terminated, then exit if so.
 		select {
@@ -333,24 +595,9 @@ func (prwe *prweWAL) readPrompbFromWAL(ctx context.Context, index uint64) (wreq
 		default:
 		}
 
-		if index <= 0 {
-			index = 1
-		}
-
-		if prwe.wal == nil {
-			return nil, fmt.Errorf("attempt to read from closed WAL")
-		}
-
-		protoBlob, err = prwe.wal.Read(index)
+		var req *prompb.WriteRequest
+		req, index, err = prwe.readPrompbAt(index)
 		if err == nil { // The read succeeded.
-			req := new(prompb.WriteRequest)
-			if err = proto.Unmarshal(protoBlob, req); err != nil {
-				return nil, err
-			}
-
-			// Now increment the WAL's read index.
-			prwe.rWALIndex.Add(1)
-
 			return req, nil
 		}
 
@@ -358,6 +605,19 @@ func (prwe *prweWAL) readPrompbFromWAL(ctx context.Context, index uint64) (wreq
 			return nil, err
 		}
 
+		if prwe.walPath == "" {
+			// The log isn't stored in a directory that could be watched,
+			// wait for persistToWAL to write to it.
+			select {
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			case <-prwe.stopChan:
+				return nil, fmt.Errorf("attempt to read from WAL after stopped")
+			case <-prwe.written:
+			}
+			continue
+		}
+
 		if index <= 1 {
 			// This could be the very first attempted read, so try again, after a small sleep.
 			time.Sleep(time.Duration(1<<i) * time.Millisecond)
@@ -390,6 +650,10 @@ func (prwe *prweWAL) readPrompbFromWAL(ctx context.Context, index uint64) (wreq
 				wErr = ctx.Err()
 				return
 
+			case <-prwe.stopChan:
+				wErr = fmt.Errorf("attempt to read from WAL after stopped")
+				return
+
 			case event, ok := <-walWatcher.Events:
 				if !ok {
 					return
@@ -422,3 +686,28 @@ func (prwe *prweWAL) readPrompbFromWAL(ctx context.Context, index uint64) (wreq
 	}
 	return nil, err
 }
+
+// readPrompbAt reads the entry at index, skipping the entries dropped by the
+// limits, and moves the read index after it. It returns the index it read.
+func (prwe *prweWAL) readPrompbAt(index uint64) (*prompb.WriteRequest, uint64, error) {
+	prwe.mu.Lock()
+	defer prwe.mu.Unlock()
+
+	if prwe.wal == nil {
+		return nil, index, fmt.Errorf("attempt to read from closed WAL")
+	}
+
+	firstIndex, err := prwe.wal.FirstIndex()
+	if err != nil {
+		return nil, index, err
+	}
+	index = max(index, firstIndex, 1)
+
+	req, err := prwe.readEntry(index)
+	if err != nil {
+		return nil, index, err
+	}
+	// Now move the WAL's read index past the entry.
+	prwe.rWALIndex.Store(index + 1)
+	return req, index, nil
+}
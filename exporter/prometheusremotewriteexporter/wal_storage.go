@@ -0,0 +1,216 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package prometheusremotewriteexporter // import "github.com/open-telemetry/opentelemetry-collector-contrib/exporter/prometheusremotewriteexporter"
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"sync"
+
+	"github.com/tidwall/wal"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/extension/experimental/storage"
+)
+
+const (
+	walStorageName = "wal"
+	// walStorageMetadataKey holds the first and last indices and the size of
+	// the log, so that opening it doesn't read every entry.
+	walStorageMetadataKey = "metadata"
+	walStorageMetadataLen = 24
+)
+
+func getStorageClient(ctx context.Context, host component.Host, storageID component.ID, componentID component.ID, storageName string) (storage.Client, error) {
+	extension, ok := host.GetExtensions()[storageID]
+	if !ok {
+		return nil, fmt.Errorf("storage extension '%s' not found", storageID)
+	}
+
+	storageExtension, ok := extension.(storage.Extension)
+	if !ok {
+		return nil, fmt.Errorf("non-storage extension '%s' found", storageID)
+	}
+
+	return storageExtension.GetClient(ctx, component.KindExporter, componentID, storageName)
+}
+
+// storageLog is a walLog storing its entries in a storage.Client, with the
+// same indexing rules as the file log: entries are written at consecutive
+// indices, and the last entry is never truncated.
+type storageLog struct {
+	mu         sync.Mutex
+	client     storage.Client
+	firstIndex uint64
+	lastIndex  uint64
+	sizeBytes  int64
+	// entrySizes holds the sizes of the entries written or read since the log
+	// was opened, so that truncating them doesn't read them again.
+	entrySizes map[uint64]int64
+	closed     bool
+}
+
+var _ walLog = (*storageLog)(nil)
+
+// newStorageLog loads the indices and size of the log stored in client. The
+// client is owned by the caller, closing the log doesn't close it.
+func newStorageLog(ctx context.Context, client storage.Client) (*storageLog, error) {
+	l := &storageLog{client: client, entrySizes: map[uint64]int64{}}
+	data, err := client.Get(ctx, walStorageMetadataKey)
+	if err != nil {
+		return nil, err
+	}
+	if data == nil {
+		return l, nil
+	}
+	if len(data) != walStorageMetadataLen {
+		return nil, fmt.Errorf("invalid WAL metadata in storage")
+	}
+	l.firstIndex = binary.BigEndian.Uint64(data)
+	l.lastIndex = binary.BigEndian.Uint64(data[8:])
+	l.sizeBytes = int64(binary.BigEndian.Uint64(data[16:]))
+	return l, nil
+}
+
+func walStorageEntryKey(index uint64) string {
+	return fmt.Sprintf("entry_%d", index)
+}
+
+func encodeStorageMetadata(firstIndex, lastIndex uint64, size int64) []byte {
+	data := make([]byte, 0, walStorageMetadataLen)
+	data = binary.BigEndian.AppendUint64(data, firstIndex)
+	data = binary.BigEndian.AppendUint64(data, lastIndex)
+	return binary.BigEndian.AppendUint64(data, uint64(size))
+}
+
+func (l *storageLog) FirstIndex() (uint64, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.closed {
+		return 0, wal.ErrClosed
+	}
+	return l.firstIndex, nil
+}
+
+func (l *storageLog) LastIndex() (uint64, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.closed {
+		return 0, wal.ErrClosed
+	}
+	return l.lastIndex, nil
+}
+
+func (l *storageLog) Read(index uint64) ([]byte, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.closed {
+		return nil, wal.ErrClosed
+	}
+	if index == 0 || l.lastIndex == 0 || index < l.firstIndex || index > l.lastIndex {
+		return nil, wal.ErrNotFound
+	}
+	data, err := l.client.Get(context.Background(), walStorageEntryKey(index))
+	if err != nil {
+		return nil, err
+	}
+	if data == nil {
+		return nil, wal.ErrNotFound
+	}
+	l.entrySizes[index] = int64(len(data))
+	return data, nil
+}
+
+func (l *storageLog) writeEntries(index uint64, entries [][]byte) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.closed {
+		return wal.ErrClosed
+	}
+	if index != l.lastIndex+1 {
+		return wal.ErrOutOfOrder
+	}
+	if len(entries) == 0 {
+		return nil
+	}
+	ops := make([]storage.Operation, 0, len(entries)+1)
+	size := l.sizeBytes
+	for i, data := range entries {
+		ops = append(ops, storage.SetOperation(walStorageEntryKey(index+uint64(i)), data))
+		size += int64(len(data))
+	}
+	firstIndex, lastIndex := l.firstIndex, index+uint64(len(entries))-1
+	if l.lastIndex == 0 {
+		firstIndex = index
+	}
+	ops = append(ops, storage.SetOperation(walStorageMetadataKey, encodeStorageMetadata(firstIndex, lastIndex, size)))
+	if err := l.client.Batch(context.Background(), ops...); err != nil {
+		return err
+	}
+	l.firstIndex, l.lastIndex = firstIndex, lastIndex
+	l.sizeBytes = size
+	for i, data := range entries {
+		l.entrySizes[index+uint64(i)] = int64(len(data))
+	}
+	return nil
+}
+
+func (l *storageLog) TruncateFront(index uint64) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.closed {
+		return wal.ErrClosed
+	}
+	if index == 0 || l.lastIndex == 0 || index < l.firstIndex || index > l.lastIndex {
+		return wal.ErrOutOfRange
+	}
+	if index == l.firstIndex {
+		return nil
+	}
+	ctx := context.Background()
+	size := l.sizeBytes
+	ops := make([]storage.Operation, 0, index-l.firstIndex)
+	for i := l.firstIndex; i < index; i++ {
+		entrySize, ok := l.entrySizes[i]
+		if !ok {
+			// Only entries written before the log was opened and never read.
+			data, err := l.client.Get(ctx, walStorageEntryKey(i))
+			if err != nil {
+				return err
+			}
+			entrySize = int64(len(data))
+		}
+		size -= entrySize
+		ops = append(ops, storage.DeleteOperation(walStorageEntryKey(i)))
+	}
+	// Move the first index before deleting the entries, so that a failure
+	// leaves unreachable entries rather than missing ones.
+	if err := l.client.Set(ctx, walStorageMetadataKey, encodeStorageMetadata(index, l.lastIndex, size)); err != nil {
+		return err
+	}
+	for i := l.firstIndex; i < index; i++ {
+		delete(l.entrySizes, i)
+	}
+	l.firstIndex = index
+	l.sizeBytes = size
+	return l.client.Batch(ctx, ops...)
+}
+
+// Sync is a no-op, the storage extension persists each write.
+func (l *storageLog) Sync() error {
+	return nil
+}
+
+func (l *storageLog) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.closed = true
+	return nil
+}
+
+func (l *storageLog) size() int64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.sizeBytes
+}
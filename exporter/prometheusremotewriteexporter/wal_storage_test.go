@@ -0,0 +1,156 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package prometheusremotewriteexporter
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/prometheus/prometheus/prompb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tidwall/wal"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/config/confighttp"
+	"go.opentelemetry.io/collector/exporter/exportertest"
+	"go.opentelemetry.io/collector/extension/experimental/storage"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/exporter/prometheusremotewriteexporter/internal/metadata"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/storage/storagetest"
+)
+
+func TestStorageLog(t *testing.T) {
+	ctx := context.Background()
+	client := storagetest.NewInMemoryClient(component.KindExporter, component.NewID(metadata.Type), walStorageName)
+
+	log, err := newStorageLog(ctx, client)
+	require.NoError(t, err)
+	first, err := log.FirstIndex()
+	require.NoError(t, err)
+	assert.Zero(t, first)
+	_, err = log.Read(1)
+	assert.ErrorIs(t, err, wal.ErrNotFound)
+
+	assert.ErrorIs(t, log.writeEntries(2, [][]byte{[]byte("a")}), wal.ErrOutOfOrder)
+	require.NoError(t, log.writeEntries(1, [][]byte{[]byte("a"), []byte("bb")}))
+	require.NoError(t, log.writeEntries(3, [][]byte{[]byte("ccc")}))
+	assert.Equal(t, int64(6), log.size())
+
+	// The last entry can't be truncated.
+	assert.ErrorIs(t, log.TruncateFront(4), wal.ErrOutOfRange)
+	require.NoError(t, log.TruncateFront(3))
+	_, err = log.Read(2)
+	assert.ErrorIs(t, err, wal.ErrNotFound)
+	assert.Equal(t, int64(3), log.size())
+	require.NoError(t, log.Close())
+
+	// The indices and entries are loaded back from the storage.
+	log, err = newStorageLog(ctx, client)
+	require.NoError(t, err)
+	first, err = log.FirstIndex()
+	require.NoError(t, err)
+	last, err := log.LastIndex()
+	require.NoError(t, err)
+	assert.Equal(t, uint64(3), first)
+	assert.Equal(t, uint64(3), last)
+	data, err := log.Read(3)
+	require.NoError(t, err)
+	assert.Equal(t, "ccc", string(data))
+	assert.Equal(t, int64(3), log.size())
+}
+
+// countingClient counts the reads of a storage client.
+type countingClient struct {
+	storage.Client
+	gets atomic.Int64
+}
+
+func (c *countingClient) Get(ctx context.Context, key string) ([]byte, error) {
+	c.gets.Add(1)
+	return c.Client.Get(ctx, key)
+}
+
+func TestStorageLogReads(t *testing.T) {
+	ctx := context.Background()
+	client := &countingClient{Client: storagetest.NewInMemoryClient(component.KindExporter, component.NewID(metadata.Type), walStorageName)}
+
+	log, err := newStorageLog(ctx, client)
+	require.NoError(t, err)
+	entries := make([][]byte, 100)
+	for i := range entries {
+		entries[i] = []byte("entry")
+	}
+	require.NoError(t, log.writeEntries(1, entries))
+
+	// Opening the log only reads its metadata.
+	client.gets.Store(0)
+	log, err = newStorageLog(ctx, client)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), client.gets.Load())
+	assert.Equal(t, int64(500), log.size())
+
+	// Entries read since opening are truncated without reading them again.
+	for i := uint64(1); i <= 50; i++ {
+		_, err = log.Read(i)
+		require.NoError(t, err)
+	}
+	client.gets.Store(0)
+	require.NoError(t, log.TruncateFront(51))
+	assert.Zero(t, client.gets.Load())
+	assert.Equal(t, int64(250), log.size())
+}
+
+func TestWAL_storage(t *testing.T) {
+	var mu sync.Mutex
+	var exported []*prompb.WriteRequest
+	exportSink := func(_ context.Context, reqL []*prompb.WriteRequest) error {
+		mu.Lock()
+		defer mu.Unlock()
+		exported = append(exported, reqL...)
+		return nil
+	}
+
+	client := storagetest.NewInMemoryClient(component.KindExporter, component.NewID(metadata.Type), walStorageName)
+	config := &WALConfig{BufferSize: 1}
+	pwal := newWAL(config, exportSink, &mockPRWTelemetry{})
+	pwal.useStorage(client)
+
+	ctx := contextWithLogger(context.Background(), exportertest.NewNopCreateSettings().Logger)
+	require.NoError(t, pwal.run(ctx))
+	require.NoError(t, pwal.persistToWAL(ctx, []*prompb.WriteRequest{sampleRequest("a", 1), sampleRequest("b", 2)}))
+
+	pwal.mu.Lock()
+	log := pwal.wal
+	pwal.mu.Unlock()
+	assert.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(exported) == 2
+	}, 5*time.Second, 10*time.Millisecond)
+	// The log stays open across exports.
+	pwal.mu.Lock()
+	assert.Same(t, log, pwal.wal)
+	pwal.mu.Unlock()
+	require.NoError(t, pwal.stop())
+
+	// Stopping the WAL closes the storage client.
+	_, err := client.Get(ctx, walStorageMetadataKey)
+	assert.Error(t, err)
+}
+
+func TestWAL_storageExtensionNotFound(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.ClientConfig = confighttp.ClientConfig{Endpoint: "http://localhost:9009"}
+	storageID := component.NewIDWithName(component.MustNewType("file_storage"), "prw")
+	cfg.WAL = &WALConfig{StorageID: &storageID}
+
+	prwe, err := newPRWExporter(cfg, exportertest.NewNopCreateSettings())
+	require.NoError(t, err)
+	err = prwe.Start(context.Background(), storagetest.NewStorageHost())
+	assert.ErrorContains(t, err, "storage extension 'file_storage/prw' not found")
+	require.NoError(t, prwe.Shutdown(context.Background()))
+}
@@ -21,13 +21,13 @@ func doNothingExportSink(_ context.Context, reqL []*prompb.WriteRequest) error {
 
 func TestWALCreation_nilConfig(t *testing.T) {
 	config := (*WALConfig)(nil)
-	pwal := newWAL(config, doNothingExportSink)
+	pwal := newWAL(config, doNothingExportSink, &mockPRWTelemetry{})
 	require.Nil(t, pwal)
 }
 
 func TestWALCreation_nonNilConfig(t *testing.T) {
 	config := &WALConfig{Directory: t.TempDir()}
-	pwal := newWAL(config, doNothingExportSink)
+	pwal := newWAL(config, doNothingExportSink, &mockPRWTelemetry{})
 	require.NotNil(t, pwal)
 	assert.NoError(t, pwal.stop())
 }
@@ -78,7 +78,7 @@ func TestWALStopManyTimes(t *testing.T) {
 		TruncateFrequency: 60 * time.Microsecond,
 		BufferSize:        1,
 	}
-	pwal := newWAL(config, doNothingExportSink)
+	pwal := newWAL(config, doNothingExportSink, &mockPRWTelemetry{})
 	require.NotNil(t, pwal)
 
 	// Ensure that invoking .stop() multiple times doesn't cause a panic, but actually
@@ -94,7 +94,7 @@ func TestWAL_persist(t *testing.T) {
 	// Unit tests that requests written to the WAL persist.
 	config := &WALConfig{Directory: t.TempDir()}
 
-	pwal := newWAL(config, doNothingExportSink)
+	pwal := newWAL(config, doNothingExportSink, &mockPRWTelemetry{})
 	require.NotNil(t, pwal)
 
 	// 1. Write out all the entries.
@@ -127,7 +127,7 @@ func TestWAL_persist(t *testing.T) {
 		assert.NoError(t, pwal.stop())
 	})
 
-	require.NoError(t, pwal.persistToWAL(reqL))
+	require.NoError(t, pwal.persistToWAL(ctx, reqL))
 
 	// 2. Read all the entries from the WAL itself, guided by the indices available,
 	// and ensure that they are exactly in order as we'd expect them.
@@ -149,3 +149,108 @@ func TestWAL_persist(t *testing.T) {
 	require.Equal(t, reqLFromWAL[0], reqL[0])
 	require.Equal(t, reqLFromWAL[1], reqL[1])
 }
+
+func sampleRequest(name string, timestamp int64) *prompb.WriteRequest {
+	return &prompb.WriteRequest{
+		Timeseries: []prompb.TimeSeries{{
+			Labels:  []prompb.Label{{Name: "__name__", Value: name}},
+			Samples: []prompb.Sample{{Value: 1, Timestamp: timestamp}},
+		}},
+	}
+}
+
+func TestWAL_limits(t *testing.T) {
+	now := time.Now().UnixMilli()
+	old := time.Now().Add(-time.Hour).UnixMilli()
+
+	tests := []struct {
+		name            string
+		config          WALConfig
+		requests        []*prompb.WriteRequest
+		expectedErr     error
+		expectedFirst   string
+		expectedDropped int
+	}{
+		{
+			name:          "no limits",
+			requests:      []*prompb.WriteRequest{sampleRequest("a", old), sampleRequest("b", now), sampleRequest("c", now)},
+			expectedFirst: "a",
+		},
+		{
+			name:            "max age drops expired entries",
+			config:          WALConfig{MaxAge: time.Minute},
+			requests:        []*prompb.WriteRequest{sampleRequest("a", old), {}, sampleRequest("b", old), sampleRequest("c", now)},
+			expectedFirst:   "c",
+			expectedDropped: 3,
+		},
+		{
+			name:            "max size drops oldest entries",
+			config:          WALConfig{MaxSizeBytes: 1},
+			requests:        []*prompb.WriteRequest{sampleRequest("a", now), sampleRequest("b", now), sampleRequest("c", now)},
+			expectedFirst:   "c",
+			expectedDropped: 2,
+		},
+		{
+			name:            "max size rejects newest entries",
+			config:          WALConfig{MaxSizeBytes: 1, OverflowPolicy: walOverflowDropNewest},
+			requests:        []*prompb.WriteRequest{sampleRequest("a", now), sampleRequest("b", now), sampleRequest("c", now)},
+			expectedErr:     errWALFull,
+			expectedDropped: 3,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := tt.config
+			config.Directory = t.TempDir()
+			telemetry := &mockPRWTelemetry{}
+			pwal := newWAL(&config, doNothingExportSink, telemetry)
+			require.NoError(t, pwal.retrieveWALIndices())
+			t.Cleanup(func() {
+				assert.NoError(t, pwal.stop())
+			})
+
+			ctx := context.Background()
+			err := pwal.persistToWAL(ctx, tt.requests)
+			assert.ErrorIs(t, err, tt.expectedErr)
+			assert.Equal(t, tt.expectedDropped, telemetry.walDroppedEntries)
+			if tt.expectedErr != nil {
+				assert.Zero(t, pwal.lag())
+				return
+			}
+
+			req, err := pwal.readPrompbFromWAL(ctx, pwal.rWALIndex.Load())
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedFirst, req.Timeseries[0].Labels[0].Value)
+			assert.Positive(t, pwal.sizeBytes.Load())
+		})
+	}
+}
+
+func TestWAL_readIndexKeptOnRestart(t *testing.T) {
+	config := &WALConfig{Directory: t.TempDir()}
+	pwal := newWAL(config, doNothingExportSink, &mockPRWTelemetry{})
+	require.NoError(t, pwal.retrieveWALIndices())
+	t.Cleanup(func() {
+		assert.NoError(t, pwal.stop())
+	})
+
+	ctx := context.Background()
+	require.NoError(t, pwal.persistToWAL(ctx, []*prompb.WriteRequest{sampleRequest("a", 1)}))
+	req, err := pwal.readPrompbFromWAL(ctx, pwal.rWALIndex.Load())
+	require.NoError(t, err)
+	assert.Equal(t, "a", req.Timeseries[0].Labels[0].Value)
+
+	// The WAL can't truncate its last entry, so the entry already read is
+	// still there after reopening it, but it isn't read again.
+	require.NoError(t, pwal.syncAndTruncateFront())
+	require.NoError(t, pwal.retrieveWALIndices())
+	assert.Zero(t, pwal.lag())
+
+	require.NoError(t, pwal.persistToWAL(ctx, []*prompb.WriteRequest{sampleRequest("b", 2)}))
+	assert.Equal(t, int64(1), pwal.lag())
+	req, err = pwal.readPrompbFromWAL(ctx, pwal.rWALIndex.Load())
+	require.NoError(t, err)
+	assert.Equal(t, "b", req.Timeseries[0].Labels[0].Value)
+	assert.Zero(t, pwal.lag())
+}
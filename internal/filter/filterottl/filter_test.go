@@ -9,6 +9,8 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/ptrace"
 
 	"github.com/open-telemetry/opentelemetry-collector-contrib/pkg/ottl"
 	"github.com/open-telemetry/opentelemetry-collector-contrib/pkg/ottl/contexts/ottldatapoint"
@@ -270,3 +272,21 @@ func Test_NewBoolExprForResource(t *testing.T) {
 		})
 	}
 }
+
+func Test_NewBoolExprForSpan_UserFunctions(t *testing.T) {
+	functions := []ottl.UserFunction{
+		{
+			Name:      "IsTest",
+			Params:    []string{"value"},
+			Condition: `IsMatch(value, "^test")`,
+		},
+	}
+	spanBoolExpr, err := NewBoolExprForSpan([]string{`IsTest(name)`}, StandardSpanFuncs(), ottl.PropagateError, componenttest.NewNopTelemetrySettings(), ottlspan.WithUserFunctions(functions))
+	assert.NoError(t, err)
+
+	span := ptrace.NewSpan()
+	span.SetName("test span")
+	result, err := spanBoolExpr.Eval(context.Background(), ottlspan.NewTransformContext(span, pcommon.NewInstrumentationScope(), pcommon.NewResource()))
+	assert.NoError(t, err)
+	assert.True(t, result)
+}
@@ -61,19 +61,23 @@ func (tCtx TransformContext) getCache() pcommon.Map {
 
 func NewParser(functions map[string]ottl.Factory[TransformContext], telemetrySettings component.TelemetrySettings, options ...Option) (ottl.Parser[TransformContext], error) {
 	pep := pathExpressionParser{telemetrySettings}
-	p, err := ottl.NewParser[TransformContext](
+	ottlOptions := []ottl.Option[TransformContext]{ottl.WithEnumParser[TransformContext](parseEnum)}
+	for _, opt := range options {
+		ottlOptions = append(ottlOptions, ottl.Option[TransformContext](opt))
+	}
+	return ottl.NewParser[TransformContext](
 		functions,
 		pep.parsePath,
 		telemetrySettings,
-		ottl.WithEnumParser[TransformContext](parseEnum),
+		ottlOptions...,
 	)
-	if err != nil {
-		return ottl.Parser[TransformContext]{}, err
-	}
-	for _, opt := range options {
-		opt(&p)
+}
+
+// WithUserFunctions adds the functions defined in the configuration to the Parser.
+func WithUserFunctions(functions []ottl.UserFunction) Option {
+	return func(p *ottl.Parser[TransformContext]) {
+		ottl.WithUserFunctions[TransformContext](functions)(p)
 	}
-	return p, err
 }
 
 type StatementSequenceOption func(*ottl.StatementSequence[TransformContext])
@@ -13,6 +13,7 @@ import (
 	"go.opentelemetry.io/collector/pdata/pcommon"
 	"go.opentelemetry.io/collector/pdata/plog"
 
+	"github.com/open-telemetry/opentelemetry-collector-contrib/pkg/ottl"
 	"github.com/open-telemetry/opentelemetry-collector-contrib/pkg/ottl/contexts/ottllog"
 	"github.com/open-telemetry/opentelemetry-collector-contrib/pkg/ottl/ottlfuncs"
 	"github.com/open-telemetry/opentelemetry-collector-contrib/pkg/pdatatest/plogtest"
@@ -769,3 +770,63 @@ func newResourceLogs(tCtx ottllog.TransformContext) plog.ResourceLogs {
 	tCtx.GetLogRecord().CopyTo(l)
 	return rl
 }
+
+func Test_e2e_user_functions(t *testing.T) {
+	functions := []ottl.UserFunction{
+		{
+			Name:   "rename_method",
+			Params: []string{"map", "method"},
+			Statements: []string{
+				`set(map["http.request.method"], method) where method != nil`,
+				`delete_key(map, "http.method")`,
+			},
+		},
+		{
+			Name:      "IsHealthCheck",
+			Params:    []string{"path"},
+			Condition: `IsMatch(path, "/health$")`,
+		},
+	}
+
+	tests := []struct {
+		statement string
+		want      func(tCtx ottllog.TransformContext)
+	}{
+		{
+			statement: `rename_method(attributes, attributes["http.method"])`,
+			want: func(tCtx ottllog.TransformContext) {
+				tCtx.GetLogRecord().Attributes().Remove("http.method")
+				tCtx.GetLogRecord().Attributes().PutStr("http.request.method", "get")
+			},
+		},
+		{
+			statement: `rename_method(attributes["foo"], attributes["foo"]["bar"]) where IsHealthCheck(attributes["http.path"])`,
+			want: func(tCtx ottllog.TransformContext) {
+				m, _ := tCtx.GetLogRecord().Attributes().Get("foo")
+				m.Map().PutStr("http.request.method", "pass")
+			},
+		},
+		{
+			statement: `set(attributes["test"], "pass") where not IsHealthCheck(attributes["http.url"])`,
+			want:      func(tCtx ottllog.TransformContext) {},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.statement, func(t *testing.T) {
+			settings := componenttest.NewNopTelemetrySettings()
+			logParser, err := ottllog.NewParser(ottlfuncs.StandardFuncs[ottllog.TransformContext](), settings, ottllog.WithUserFunctions(functions))
+			assert.NoError(t, err)
+			logStatements, err := logParser.ParseStatement(tt.statement)
+			assert.NoError(t, err)
+
+			tCtx := constructLogTransformContext()
+			_, _, _ = logStatements.Execute(context.Background(), tCtx)
+
+			exTCtx := constructLogTransformContext()
+			tt.want(exTCtx)
+
+			assert.NoError(t, plogtest.CompareResourceLogs(newResourceLogs(exTCtx), newResourceLogs(tCtx)))
+		})
+	}
+}
@@ -188,6 +188,9 @@ func (p *Parser[K]) parsePath(ip *basePath[K]) (GetSetter[K], error) {
 }
 
 func (p *Parser[K]) newFunctionCall(ed editor) (Expr[K], error) {
+	if uf, ok := p.userFunctions[ed.Function]; ok {
+		return p.newUserFunctionCall(uf, ed)
+	}
 	f, ok := p.functions[ed.Function]
 	if !ok {
 		return Expr[K]{}, fmt.Errorf("undefined function %q", ed.Function)
@@ -220,7 +223,9 @@ func (p *Parser[K]) newFunctionCall(ed editor) (Expr[K], error) {
 	return Expr[K]{exprFunc: fn}, err
 }
 
-func (p *Parser[K]) buildArgs(ed editor, argsVal reflect.Value) error {
+// checkArgs checks the order, number and names of the arguments of a call,
+// without building them.
+func checkArgs(ed editor, argsVal reflect.Value) error {
 	requiredArgs := 0
 	seenNamed := false
 
@@ -242,6 +247,19 @@ func (p *Parser[K]) buildArgs(ed editor, argsVal reflect.Value) error {
 		return fmt.Errorf("incorrect number of arguments. Expected: %d Received: %d", argsVal.NumField(), len(ed.Arguments))
 	}
 
+	for _, edArg := range ed.Arguments {
+		if edArg.Name != "" && !argsVal.FieldByName(strcase.ToCamel(edArg.Name)).IsValid() {
+			return fmt.Errorf("no such parameter: %s", edArg.Name)
+		}
+	}
+	return nil
+}
+
+func (p *Parser[K]) buildArgs(ed editor, argsVal reflect.Value) error {
+	if err := checkArgs(ed, argsVal); err != nil {
+		return err
+	}
+
 	for i, edArg := range ed.Arguments {
 		var field reflect.Value
 		var fieldType reflect.Type
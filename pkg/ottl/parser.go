@@ -7,6 +7,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"sync"
 
 	"github.com/alecthomas/participle/v2"
 	"go.opentelemetry.io/collector/component"
@@ -58,6 +59,11 @@ type Parser[K any] struct {
 	pathParser        PathExpressionParser[K]
 	enumParser        EnumParser
 	telemetrySettings component.TelemetrySettings
+	userFunctions     map[string]UserFunction
+	// userFunctionChecks holds the result of the type checking of the body of
+	// each user function, by name, shared by the copies of the Parser.
+	userFunctionChecks *sync.Map
+	userFunctionsErr   error
 }
 
 func NewParser[K any](
@@ -80,6 +86,9 @@ func NewParser[K any](
 	for _, opt := range options {
 		opt(&p)
 	}
+	if p.userFunctionsErr != nil {
+		return Parser[K]{}, p.userFunctionsErr
+	}
 	return p, nil
 }
 
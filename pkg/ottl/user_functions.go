@@ -0,0 +1,652 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package ottl // import "github.com/open-telemetry/opentelemetry-collector-contrib/pkg/ottl"
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"slices"
+	"sync"
+)
+
+var (
+	editorNameRegexp    = regexp.MustCompile(`^[a-z][a-zA-Z0-9_]*$`)
+	converterNameRegexp = regexp.MustCompile(`^[A-Z][a-zA-Z0-9_]*$`)
+	paramNameRegexp     = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+
+	// reservedParamNames are the keywords of the grammar matching paramNameRegexp.
+	reservedParamNames = []string{"and", "false", "nil", "not", "or", "true", "where"}
+)
+
+// UserFunction is a function defined in the configuration of a component, which
+// can be called from its OTTL statements and conditions like the functions of
+// the Factories.
+//
+// A function with Statements is an editor, which executes its statements in order.
+// A function with a Condition is a converter, which returns the result of its condition.
+// In the body, the parameters are referenced as paths made of their name, optionally
+// followed by more fields or keys, and they are replaced by the arguments of each
+// call when the call is parsed, so that the body is type checked for these arguments.
+type UserFunction struct {
+	// Name is the name of the function. The name of a function with statements
+	// must start with a lowercase letter, and the name of a function with a
+	// condition with an uppercase letter.
+	Name string `mapstructure:"name"`
+
+	// Params are the names of the parameters of the function, all required.
+	Params []string `mapstructure:"params"`
+
+	// Statements are the statements executed by the function.
+	Statements []string `mapstructure:"statements"`
+
+	// Condition is the condition returned by the function.
+	Condition string `mapstructure:"condition"`
+}
+
+// Validate checks if the definition of the function is valid.
+func (f *UserFunction) Validate() error {
+	switch {
+	case len(f.Statements) > 0 && f.Condition != "":
+		return fmt.Errorf("function %q can't have both statements and a condition", f.Name)
+	case len(f.Statements) > 0:
+		if !editorNameRegexp.MatchString(f.Name) {
+			return fmt.Errorf("function name %q must start with a lowercase letter and contain only letters, digits and underscores", f.Name)
+		}
+	case f.Condition != "":
+		if !converterNameRegexp.MatchString(f.Name) {
+			return fmt.Errorf("function name %q must start with an uppercase letter and contain only letters, digits and underscores", f.Name)
+		}
+	default:
+		return fmt.Errorf("function %q requires statements or a condition", f.Name)
+	}
+
+	seen := make(map[string]bool, len(f.Params))
+	for _, param := range f.Params {
+		if !paramNameRegexp.MatchString(param) || slices.Contains(reservedParamNames, param) {
+			return fmt.Errorf("parameter %q of function %q must contain only lowercase letters, digits and underscores and not be a keyword", param, f.Name)
+		}
+		if seen[param] {
+			return fmt.Errorf("duplicate parameter %q of function %q", param, f.Name)
+		}
+		seen[param] = true
+	}
+
+	_, err := f.calls()
+	return err
+}
+
+// calls parses the body of the function, and returns the names of the
+// functions it calls.
+func (f *UserFunction) calls() ([]string, error) {
+	r := &paramReplacer{}
+	for _, statement := range f.Statements {
+		parsed, err := parseStatement(statement)
+		if err != nil {
+			return nil, fmt.Errorf("unable to parse OTTL statement %q of function %q: %w", statement, f.Name, err)
+		}
+		if err = r.statement(parsed); err != nil {
+			return nil, err
+		}
+	}
+	if f.Condition != "" {
+		parsed, err := parseCondition(f.Condition)
+		if err != nil {
+			return nil, fmt.Errorf("unable to parse OTTL condition %q of function %q: %w", f.Condition, f.Name, err)
+		}
+		if err = r.booleanExpression(parsed); err != nil {
+			return nil, err
+		}
+	}
+	return r.calls, nil
+}
+
+// WithUserFunctions adds functions defined in the configuration to the
+// Parser. The functions can't have the name of a function of the Parser's
+// Factories, and can't call themselves, these errors are returned by
+// NewParser. The body of a function is type checked once, when the first
+// call to the function is parsed, so that a function using paths or
+// functions of another context can be added to the parsers of all contexts.
+func WithUserFunctions[K any](functions []UserFunction) Option[K] {
+	return func(p *Parser[K]) {
+		p.userFunctions = make(map[string]UserFunction, len(functions))
+		p.userFunctionChecks = &sync.Map{}
+		p.userFunctionsErr = nil
+		var errs []error
+		calls := make(map[string][]string, len(functions))
+		for _, f := range functions {
+			if err := f.Validate(); err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			if _, ok := p.functions[f.Name]; ok {
+				errs = append(errs, fmt.Errorf("function %q is already defined", f.Name))
+				continue
+			}
+			if _, ok := p.userFunctions[f.Name]; ok {
+				errs = append(errs, fmt.Errorf("duplicate function %q", f.Name))
+				continue
+			}
+			p.userFunctions[f.Name] = f
+			calls[f.Name], _ = f.calls()
+		}
+		for _, f := range functions {
+			if _, ok := calls[f.Name]; ok && calledFunctions(f.Name, calls)[f.Name] {
+				errs = append(errs, fmt.Errorf("function %q calls itself", f.Name))
+			}
+		}
+		p.userFunctionsErr = errors.Join(errs...)
+	}
+}
+
+// calledFunctions returns the functions called by a function, directly or
+// through other functions.
+func calledFunctions(name string, calls map[string][]string) map[string]bool {
+	visited := map[string]bool{}
+	pending := append([]string(nil), calls[name]...)
+	for len(pending) > 0 {
+		next := pending[len(pending)-1]
+		pending = pending[:len(pending)-1]
+		if visited[next] {
+			continue
+		}
+		visited[next] = true
+		pending = append(pending, calls[next]...)
+	}
+	return visited
+}
+
+// newUserFunctionCall returns the expression of a call to a function defined
+// in the configuration, with its body parsed for the arguments of the call.
+func (p *Parser[K]) newUserFunctionCall(f UserFunction, ed editor) (Expr[K], error) {
+	if err := p.checkUserFunctionOnce(f); err != nil {
+		return Expr[K]{}, err
+	}
+	args, err := bindUserFunctionArgs(f, ed.Arguments)
+	if err != nil {
+		return Expr[K]{}, fmt.Errorf("error while parsing arguments for call to %q: %w", f.Name, err)
+	}
+	r := &paramReplacer{args: args}
+
+	if f.Condition != "" {
+		parsed, err := parseCondition(f.Condition)
+		if err != nil {
+			return Expr[K]{}, err
+		}
+		if err = r.booleanExpression(parsed); err != nil {
+			return Expr[K]{}, fmt.Errorf("error in condition of function %q: %w", f.Name, err)
+		}
+		condition, err := p.newBoolExpr(parsed)
+		if err != nil {
+			return Expr[K]{}, fmt.Errorf("error in condition of function %q: %w", f.Name, err)
+		}
+		return Expr[K]{exprFunc: func(ctx context.Context, tCtx K) (any, error) {
+			return condition.Eval(ctx, tCtx)
+		}}, nil
+	}
+
+	statements := make([]*Statement[K], 0, len(f.Statements))
+	for _, statement := range f.Statements {
+		parsed, err := parseStatement(statement)
+		if err != nil {
+			return Expr[K]{}, err
+		}
+		if err = r.statement(parsed); err != nil {
+			return Expr[K]{}, fmt.Errorf("error in statement %q of function %q: %w", statement, f.Name, err)
+		}
+		function, err := p.newFunctionCall(parsed.Editor)
+		if err != nil {
+			return Expr[K]{}, fmt.Errorf("error in statement %q of function %q: %w", statement, f.Name, err)
+		}
+		condition, err := p.newBoolExpr(parsed.WhereClause)
+		if err != nil {
+			return Expr[K]{}, fmt.Errorf("error in statement %q of function %q: %w", statement, f.Name, err)
+		}
+		statements = append(statements, &Statement[K]{
+			function:  function,
+			condition: condition,
+			origText:  statement,
+		})
+	}
+	return Expr[K]{exprFunc: func(ctx context.Context, tCtx K) (any, error) {
+		for _, statement := range statements {
+			if _, _, err := statement.Execute(ctx, tCtx); err != nil {
+				return nil, fmt.Errorf("failed to execute statement %v of function %v: %w", statement.origText, f.Name, err)
+			}
+		}
+		return nil, nil
+	}}, nil
+}
+
+// checkUserFunctionOnce type checks the body of a function defined in the
+// configuration the first time it is called, and returns the same result for
+// the next calls.
+func (p *Parser[K]) checkUserFunctionOnce(f UserFunction) error {
+	if checked, ok := p.userFunctionChecks.Load(f.Name); ok {
+		err, _ := checked.(error)
+		return err
+	}
+	err := p.checkUserFunction(f)
+	p.userFunctionChecks.Store(f.Name, err)
+	return err
+}
+
+// checkUserFunction type checks the body of a function defined in the
+// configuration.
+func (p *Parser[K]) checkUserFunction(f UserFunction) error {
+	c := &userFunctionChecker[K]{p: p, params: f.Params}
+	for _, statement := range f.Statements {
+		parsed, err := parseStatement(statement)
+		if err != nil {
+			return err
+		}
+		if err = c.statement(parsed); err != nil {
+			return fmt.Errorf("error in statement %q of function %q: %w", statement, f.Name, err)
+		}
+	}
+	if f.Condition != "" {
+		parsed, err := parseCondition(f.Condition)
+		if err != nil {
+			return err
+		}
+		if _, err = c.booleanExpression(parsed); err != nil {
+			return fmt.Errorf("error in condition of function %q: %w", f.Name, err)
+		}
+	}
+	return nil
+}
+
+// bindUserFunctionArgs returns the arguments of a call to a function defined
+// in the configuration, by parameter name.
+func bindUserFunctionArgs(f UserFunction, arguments []argument) (map[string]value, error) {
+	if len(arguments) != len(f.Params) {
+		return nil, fmt.Errorf("incorrect number of arguments. Expected: %d Received: %d", len(f.Params), len(arguments))
+	}
+	args := make(map[string]value, len(arguments))
+	seenNamed := false
+	for i, arg := range arguments {
+		name := arg.Name
+		switch {
+		case name != "":
+			seenNamed = true
+		case seenNamed:
+			return nil, errors.New("unnamed argument used after named argument")
+		default:
+			name = f.Params[i]
+		}
+		if !slices.Contains(f.Params, name) {
+			return nil, fmt.Errorf("no such parameter: %s", name)
+		}
+		if _, ok := args[name]; ok {
+			return nil, fmt.Errorf("duplicate argument for parameter: %s", name)
+		}
+		args[name] = arg.Value
+	}
+	return args, nil
+}
+
+// paramReplacer walks the syntax tree of the body of a function defined in
+// the configuration, replacing its parameters by the arguments of a call and
+// recording the functions it calls.
+type paramReplacer struct {
+	args  map[string]value
+	calls []string
+}
+
+func (r *paramReplacer) statement(s *parsedStatement) error {
+	if err := r.editor(&s.Editor); err != nil {
+		return err
+	}
+	if s.WhereClause != nil {
+		return r.booleanExpression(s.WhereClause)
+	}
+	return nil
+}
+
+func (r *paramReplacer) editor(ed *editor) error {
+	r.calls = append(r.calls, ed.Function)
+	for i := range ed.Arguments {
+		if err := r.value(&ed.Arguments[i].Value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *paramReplacer) converter(c *converter) error {
+	r.calls = append(r.calls, c.Function)
+	for i := range c.Arguments {
+		if err := r.value(&c.Arguments[i].Value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *paramReplacer) booleanExpression(b *booleanExpression) error {
+	if err := r.term(b.Left); err != nil {
+		return err
+	}
+	for _, rhs := range b.Right {
+		if err := r.term(rhs.Term); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *paramReplacer) term(t *term) error {
+	if err := r.booleanValue(t.Left); err != nil {
+		return err
+	}
+	for _, rhs := range t.Right {
+		if err := r.booleanValue(rhs.Value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *paramReplacer) booleanValue(b *booleanValue) error {
+	switch {
+	case b.Comparison != nil:
+		if err := r.value(&b.Comparison.Left); err != nil {
+			return err
+		}
+		return r.value(&b.Comparison.Right)
+	case b.ConstExpr != nil && b.ConstExpr.Converter != nil:
+		return r.converter(b.ConstExpr.Converter)
+	case b.SubExpr != nil:
+		return r.booleanExpression(b.SubExpr)
+	}
+	return nil
+}
+
+func (r *paramReplacer) value(v *value) error {
+	switch {
+	case v.Literal != nil:
+		arg, err := r.literal(v.Literal)
+		if err != nil || arg == nil {
+			return err
+		}
+		*v = *arg
+	case v.MathExpression != nil:
+		return r.mathExpression(v.MathExpression)
+	case v.List != nil:
+		for i := range v.List.Values {
+			if err := r.value(&v.List.Values[i]); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// literal returns the argument replacing a literal referencing a parameter,
+// or nil if the literal doesn't reference a parameter.
+func (r *paramReplacer) literal(l *mathExprLiteral) (*value, error) {
+	switch {
+	case l.Converter != nil:
+		return nil, r.converter(l.Converter)
+	case l.Editor != nil:
+		return nil, r.editor(l.Editor)
+	case l.Path == nil:
+		return nil, nil
+	}
+
+	param := l.Path.Fields[0]
+	arg, ok := r.args[param.Name]
+	if !ok {
+		return nil, nil
+	}
+	if len(param.Keys) == 0 && len(l.Path.Fields) == 1 {
+		return &arg, nil
+	}
+
+	// The parameter is indexed or followed by more fields, the argument
+	// must be a path or a converter.
+	switch {
+	case arg.Literal != nil && arg.Literal.Path != nil:
+		fields := append([]field(nil), arg.Literal.Path.Fields...)
+		last := &fields[len(fields)-1]
+		last.Keys = append(append([]key(nil), last.Keys...), param.Keys...)
+		fields = append(fields, l.Path.Fields[1:]...)
+		return &value{Literal: &mathExprLiteral{Path: &path{Fields: fields}}}, nil
+	case arg.Literal != nil && arg.Literal.Converter != nil && len(l.Path.Fields) == 1:
+		c := *arg.Literal.Converter
+		c.Keys = append(append([]key(nil), c.Keys...), param.Keys...)
+		return &value{Literal: &mathExprLiteral{Converter: &c}}, nil
+	}
+	return nil, fmt.Errorf("parameter %q can only be indexed if its argument is a path or a converter", param.Name)
+}
+
+func (r *paramReplacer) mathExpression(m *mathExpression) error {
+	if err := r.addSubTerm(m.Left); err != nil {
+		return err
+	}
+	for _, rhs := range m.Right {
+		if err := r.addSubTerm(rhs.Term); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *paramReplacer) addSubTerm(t *addSubTerm) error {
+	if err := r.mathValue(t.Left); err != nil {
+		return err
+	}
+	for _, rhs := range t.Right {
+		if err := r.mathValue(rhs.Value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *paramReplacer) mathValue(m *mathValue) error {
+	if m.SubExpression != nil {
+		return r.mathExpression(m.SubExpression)
+	}
+	arg, err := r.literal(m.Literal)
+	if err != nil || arg == nil {
+		return err
+	}
+	switch {
+	case arg.Literal != nil:
+		m.Literal = arg.Literal
+	case arg.MathExpression != nil:
+		m.Literal = nil
+		m.SubExpression = arg.MathExpression
+	default:
+		return fmt.Errorf("the argument of a parameter used in a math expression must be a number, a path, a converter or a math expression")
+	}
+	return nil
+}
+
+// userFunctionChecker walks the syntax tree of the body of a function defined
+// in the configuration. The calls and comparisons which don't reference the
+// parameters are fully parsed. The types of the parameters are only known
+// when the function is called, so for the other calls only the called
+// function and the number and names of the arguments are checked. Each method
+// returns true if the node references a parameter.
+type userFunctionChecker[K any] struct {
+	p      *Parser[K]
+	params []string
+}
+
+func (c *userFunctionChecker[K]) statement(s *parsedStatement) error {
+	if _, err := c.call(s.Editor); err != nil {
+		return err
+	}
+	if s.WhereClause != nil {
+		_, err := c.booleanExpression(s.WhereClause)
+		return err
+	}
+	return nil
+}
+
+func (c *userFunctionChecker[K]) call(ed editor) (bool, error) {
+	usesParams := false
+	for _, arg := range ed.Arguments {
+		uses, err := c.value(arg.Value)
+		if err != nil {
+			return false, err
+		}
+		usesParams = usesParams || uses
+	}
+	if !usesParams {
+		_, err := c.p.newFunctionCall(ed)
+		return false, err
+	}
+
+	if uf, ok := c.p.userFunctions[ed.Function]; ok {
+		if _, err := bindUserFunctionArgs(uf, ed.Arguments); err != nil {
+			return false, fmt.Errorf("error while parsing arguments for call to %q: %w", ed.Function, err)
+		}
+		return true, nil
+	}
+	f, ok := c.p.functions[ed.Function]
+	if !ok {
+		return false, fmt.Errorf("undefined function %q", ed.Function)
+	}
+	defaultArgs := f.CreateDefaultArguments()
+	if defaultArgs == nil || reflect.TypeOf(defaultArgs).Kind() != reflect.Pointer {
+		// Reported by newFunctionCall when the function is called.
+		return true, nil
+	}
+	if err := checkArgs(ed, reflect.ValueOf(defaultArgs).Elem()); err != nil {
+		return false, fmt.Errorf("error while parsing arguments for call to %q: %w", ed.Function, err)
+	}
+	return true, nil
+}
+
+func (c *userFunctionChecker[K]) booleanExpression(b *booleanExpression) (bool, error) {
+	usesParams, err := c.term(b.Left)
+	if err != nil {
+		return false, err
+	}
+	for _, rhs := range b.Right {
+		uses, err := c.term(rhs.Term)
+		if err != nil {
+			return false, err
+		}
+		usesParams = usesParams || uses
+	}
+	return usesParams, nil
+}
+
+func (c *userFunctionChecker[K]) term(t *term) (bool, error) {
+	usesParams, err := c.booleanValue(t.Left)
+	if err != nil {
+		return false, err
+	}
+	for _, rhs := range t.Right {
+		uses, err := c.booleanValue(rhs.Value)
+		if err != nil {
+			return false, err
+		}
+		usesParams = usesParams || uses
+	}
+	return usesParams, nil
+}
+
+func (c *userFunctionChecker[K]) booleanValue(b *booleanValue) (bool, error) {
+	switch {
+	case b.Comparison != nil:
+		left, err := c.value(b.Comparison.Left)
+		if err != nil {
+			return false, err
+		}
+		right, err := c.value(b.Comparison.Right)
+		if err != nil {
+			return false, err
+		}
+		if !left && !right {
+			_, err = c.p.newComparisonEvaluator(b.Comparison)
+		}
+		return left || right, err
+	case b.ConstExpr != nil && b.ConstExpr.Converter != nil:
+		return c.call(editor(*b.ConstExpr.Converter))
+	case b.SubExpr != nil:
+		return c.booleanExpression(b.SubExpr)
+	}
+	return false, nil
+}
+
+func (c *userFunctionChecker[K]) value(v value) (bool, error) {
+	switch {
+	case v.Literal != nil:
+		return c.literal(v.Literal)
+	case v.MathExpression != nil:
+		return c.mathExpression(v.MathExpression)
+	case v.List != nil:
+		usesParams := false
+		for _, item := range v.List.Values {
+			uses, err := c.value(item)
+			if err != nil {
+				return false, err
+			}
+			usesParams = usesParams || uses
+		}
+		return usesParams, nil
+	}
+	return false, nil
+}
+
+func (c *userFunctionChecker[K]) literal(l *mathExprLiteral) (bool, error) {
+	switch {
+	case l.Converter != nil:
+		return c.call(editor(*l.Converter))
+	case l.Path == nil:
+		return false, nil
+	case slices.Contains(c.params, l.Path.Fields[0].Name):
+		return true, nil
+	}
+	np, err := newPath[K](l.Path.Fields)
+	if err != nil {
+		return false, err
+	}
+	_, err = c.p.parsePath(np)
+	return false, err
+}
+
+func (c *userFunctionChecker[K]) mathExpression(m *mathExpression) (bool, error) {
+	usesParams, err := c.addSubTerm(m.Left)
+	if err != nil {
+		return false, err
+	}
+	for _, rhs := range m.Right {
+		uses, err := c.addSubTerm(rhs.Term)
+		if err != nil {
+			return false, err
+		}
+		usesParams = usesParams || uses
+	}
+	return usesParams, nil
+}
+
+func (c *userFunctionChecker[K]) addSubTerm(t *addSubTerm) (bool, error) {
+	usesParams, err := c.mathValue(t.Left)
+	if err != nil {
+		return false, err
+	}
+	for _, rhs := range t.Right {
+		uses, err := c.mathValue(rhs.Value)
+		if err != nil {
+			return false, err
+		}
+		usesParams = usesParams || uses
+	}
+	return usesParams, nil
+}
+
+func (c *userFunctionChecker[K]) mathValue(m *mathValue) (bool, error) {
+	if m.SubExpression != nil {
+		return c.mathExpression(m.SubExpression)
+	}
+	return c.literal(m.Literal)
+}
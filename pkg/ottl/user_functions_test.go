@@ -0,0 +1,369 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package ottl
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
+)
+
+type userFunctionsTestContext = map[string]any
+
+// userFunctionsTestPath parses the paths of a name with an optional key, stored
+// in the transform context as name or name.key.
+func userFunctionsTestPath(p Path[userFunctionsTestContext]) (GetSetter[userFunctionsTestContext], error) {
+	key := p.Name()
+	keys := p.Keys()
+	if p.Next() != nil || len(keys) > 1 {
+		return nil, fmt.Errorf("bad path %v", p)
+	}
+	if len(keys) == 1 {
+		s, _ := keys[0].String(context.Background(), nil)
+		if s == nil {
+			return nil, fmt.Errorf("bad path %v", p)
+		}
+		key += "." + *s
+	}
+	return &StandardGetSetter[userFunctionsTestContext]{
+		Getter: func(_ context.Context, tCtx userFunctionsTestContext) (any, error) {
+			return tCtx[key], nil
+		},
+		Setter: func(_ context.Context, tCtx userFunctionsTestContext, val any) error {
+			tCtx[key] = val
+			return nil
+		},
+	}, nil
+}
+
+type userFunctionsSetArguments struct {
+	Target GetSetter[userFunctionsTestContext]
+	Value  Getter[userFunctionsTestContext]
+}
+
+type userFunctionsConcatArguments struct {
+	Left  Getter[userFunctionsTestContext]
+	Right Getter[userFunctionsTestContext]
+}
+
+func userFunctionsTestParser(t *testing.T, functions []UserFunction) Parser[userFunctionsTestContext] {
+	p, err := newUserFunctionsTestParser(functions)
+	require.NoError(t, err)
+	return p
+}
+
+func newUserFunctionsTestParser(functions []UserFunction) (Parser[userFunctionsTestContext], error) {
+	set := NewFactory("set", &userFunctionsSetArguments{}, func(_ FunctionContext, args Arguments) (ExprFunc[userFunctionsTestContext], error) {
+		a := args.(*userFunctionsSetArguments)
+		return func(ctx context.Context, tCtx userFunctionsTestContext) (any, error) {
+			val, err := a.Value.Get(ctx, tCtx)
+			if err != nil {
+				return nil, err
+			}
+			return nil, a.Target.Set(ctx, tCtx, val)
+		}, nil
+	})
+	concat := NewFactory("Concat", &userFunctionsConcatArguments{}, func(_ FunctionContext, args Arguments) (ExprFunc[userFunctionsTestContext], error) {
+		a := args.(*userFunctionsConcatArguments)
+		return func(ctx context.Context, tCtx userFunctionsTestContext) (any, error) {
+			left, err := a.Left.Get(ctx, tCtx)
+			if err != nil {
+				return nil, err
+			}
+			right, err := a.Right.Get(ctx, tCtx)
+			if err != nil {
+				return nil, err
+			}
+			return fmt.Sprint(left, right), nil
+		}, nil
+	})
+
+	return NewParser(
+		CreateFactoryMap(set, concat),
+		userFunctionsTestPath,
+		componenttest.NewNopTelemetrySettings(),
+		WithUserFunctions[userFunctionsTestContext](functions),
+	)
+}
+
+var testUserFunctions = []UserFunction{
+	{
+		Name:   "copy_and_tag",
+		Params: []string{"target", "value"},
+		Statements: []string{
+			`set(target, value)`,
+			`set(attributes["tag"], Concat("copied ", value)) where target != nil`,
+		},
+	},
+	{
+		Name:       "set_key",
+		Params:     []string{"map", "value"},
+		Statements: []string{`set(map["key"], value)`},
+	},
+	{
+		Name:       "tag_big",
+		Params:     []string{"value"},
+		Statements: []string{`copy_and_tag(attributes["big"], value) where IsAbove(value, 3)`},
+	},
+	{
+		Name:      "IsAbove",
+		Params:    []string{"value", "limit"},
+		Condition: `value * 2 > limit`,
+	},
+}
+
+func Test_UserFunctions_Statements(t *testing.T) {
+	tests := []struct {
+		name      string
+		statement string
+		tCtx      userFunctionsTestContext
+		want      userFunctionsTestContext
+	}{
+		{
+			name:      "statements",
+			statement: `copy_and_tag(attributes["dst"], name)`,
+			tCtx:      userFunctionsTestContext{"name": "a"},
+			want:      userFunctionsTestContext{"name": "a", "attributes.dst": "a", "attributes.tag": "copied a"},
+		},
+		{
+			name:      "where clause of the call",
+			statement: `copy_and_tag(attributes["dst"], name) where name != nil`,
+			tCtx:      userFunctionsTestContext{},
+			want:      userFunctionsTestContext{},
+		},
+		{
+			name:      "named arguments",
+			statement: `copy_and_tag(value = "b", target = status)`,
+			tCtx:      userFunctionsTestContext{},
+			want:      userFunctionsTestContext{"status": "b", "attributes.tag": "copied b"},
+		},
+		{
+			name:      "indexed parameter",
+			statement: `set_key(attributes, 1)`,
+			tCtx:      userFunctionsTestContext{},
+			want:      userFunctionsTestContext{"attributes.key": int64(1)},
+		},
+		{
+			name:      "nested functions",
+			statement: `tag_big(count)`,
+			tCtx:      userFunctionsTestContext{"count": int64(2)},
+			want:      userFunctionsTestContext{"count": int64(2), "attributes.big": int64(2), "attributes.tag": "copied 2"},
+		},
+		{
+			name:      "converter in where clause",
+			statement: `set(attributes["big"], true) where IsAbove(count, 3)`,
+			tCtx:      userFunctionsTestContext{"count": int64(1)},
+			want:      userFunctionsTestContext{"count": int64(1)},
+		},
+	}
+	p := userFunctionsTestParser(t, testUserFunctions)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			statement, err := p.ParseStatement(tt.statement)
+			require.NoError(t, err)
+			_, _, err = statement.Execute(context.Background(), tt.tCtx)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, tt.tCtx)
+		})
+	}
+}
+
+func Test_UserFunctions_Condition(t *testing.T) {
+	p := userFunctionsTestParser(t, testUserFunctions)
+	// The math expression of the argument is evaluated before the one of the body.
+	condition, err := p.ParseCondition(`IsAbove(count + 1, 10)`)
+	require.NoError(t, err)
+
+	result, err := condition.Eval(context.Background(), userFunctionsTestContext{"count": int64(5)})
+	require.NoError(t, err)
+	assert.True(t, result)
+	result, err = condition.Eval(context.Background(), userFunctionsTestContext{"count": int64(4)})
+	require.NoError(t, err)
+	assert.False(t, result)
+}
+
+func Test_UserFunctions_CallErrors(t *testing.T) {
+	tests := []struct {
+		statement string
+		wantErr   string
+	}{
+		{
+			statement: `copy_and_tag(attributes["dst"])`,
+			wantErr:   `error while parsing arguments for call to "copy_and_tag": incorrect number of arguments. Expected: 2 Received: 1`,
+		},
+		{
+			statement: `copy_and_tag(target = attributes["dst"], other = 1)`,
+			wantErr:   `error while parsing arguments for call to "copy_and_tag": no such parameter: other`,
+		},
+		{
+			statement: `copy_and_tag(target = attributes["dst"], name)`,
+			wantErr:   `unnamed argument used after named argument`,
+		},
+		{
+			statement: `copy_and_tag("dst", name)`,
+			wantErr:   `error in statement "set(target, value)" of function "copy_and_tag"`,
+		},
+		{
+			statement: `set_key(Concat("a", "b"), 1)`,
+			wantErr:   `error in statement "set(map[\"key\"], value)" of function "set_key"`,
+		},
+		{
+			statement: `set_key("map", 1)`,
+			wantErr:   `parameter "map" can only be indexed if its argument is a path or a converter`,
+		},
+		{
+			statement: `set(name, true) where IsAbove("a", 1)`,
+			wantErr:   `error in condition of function "IsAbove"`,
+		},
+	}
+	p := userFunctionsTestParser(t, testUserFunctions)
+	for _, tt := range tests {
+		t.Run(tt.statement, func(t *testing.T) {
+			_, err := p.ParseStatement(tt.statement)
+			assert.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+}
+
+func Test_UserFunctions_DefinitionErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		function UserFunction
+		wantErr  string
+	}{
+		{
+			name:     "no body",
+			function: UserFunction{Name: "empty"},
+			wantErr:  `function "empty" requires statements or a condition`,
+		},
+		{
+			name:     "statements and condition",
+			function: UserFunction{Name: "both", Statements: []string{`set(name, 1)`}, Condition: `true`},
+			wantErr:  `function "both" can't have both statements and a condition`,
+		},
+		{
+			name:     "editor name",
+			function: UserFunction{Name: "Editor", Statements: []string{`set(name, 1)`}},
+			wantErr:  `function name "Editor" must start with a lowercase letter`,
+		},
+		{
+			name:     "converter name",
+			function: UserFunction{Name: "converter", Condition: `true`},
+			wantErr:  `function name "converter" must start with an uppercase letter`,
+		},
+		{
+			name:     "parameter name",
+			function: UserFunction{Name: "Keyword", Params: []string{"not"}, Condition: `true`},
+			wantErr:  `parameter "not" of function "Keyword"`,
+		},
+		{
+			name:     "duplicate parameter",
+			function: UserFunction{Name: "Params", Params: []string{"a", "a"}, Condition: `true`},
+			wantErr:  `duplicate parameter "a" of function "Params"`,
+		},
+		{
+			name:     "invalid statement",
+			function: UserFunction{Name: "invalid", Statements: []string{`set(`}},
+			wantErr:  `unable to parse OTTL statement "set(" of function "invalid"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.ErrorContains(t, tt.function.Validate(), tt.wantErr)
+		})
+	}
+}
+
+func Test_WithUserFunctions_Errors(t *testing.T) {
+	_, err := newUserFunctionsTestParser([]UserFunction{
+		{Name: "set", Params: []string{"value"}, Statements: []string{`set(name, value)`}},
+		{Name: "first", Statements: []string{`second()`}},
+		{Name: "second", Statements: []string{`set(name, 1)`, `first() where name == nil`}},
+		{Name: "third", Statements: []string{`first()`}},
+		{Name: "Twice", Condition: `true`},
+		{Name: "Twice", Condition: `false`},
+	})
+	require.Error(t, err)
+	assert.ErrorContains(t, err, `function "set" is already defined`)
+	assert.ErrorContains(t, err, `function "first" calls itself`)
+	assert.ErrorContains(t, err, `function "second" calls itself`)
+	assert.ErrorContains(t, err, `duplicate function "Twice"`)
+	assert.NotContains(t, err.Error(), `function "third" calls itself`)
+}
+
+func Test_UserFunctions_TypeErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		call     string
+		function UserFunction
+		wantErr  string
+	}{
+		{
+			name:     "undefined function",
+			call:     `undefined(name)`,
+			function: UserFunction{Name: "undefined", Params: []string{"value"}, Statements: []string{`delete(value)`}},
+			wantErr:  `error in statement "delete(value)" of function "undefined": undefined function "delete"`,
+		},
+		{
+			name:     "undefined converter",
+			call:     `set(name, 1) where Undefined(name)`,
+			function: UserFunction{Name: "Undefined", Params: []string{"value"}, Condition: `IsString(value)`},
+			wantErr:  `error in condition of function "Undefined": undefined function "IsString"`,
+		},
+		{
+			name:     "number of arguments",
+			call:     `arguments(name)`,
+			function: UserFunction{Name: "arguments", Params: []string{"value"}, Statements: []string{`set(value)`}},
+			wantErr:  `error while parsing arguments for call to "set": incorrect number of arguments. Expected: 2 Received: 1`,
+		},
+		{
+			name:     "argument name",
+			call:     `names(name)`,
+			function: UserFunction{Name: "names", Params: []string{"value"}, Statements: []string{`set(target = name, other = value)`}},
+			wantErr:  `error while parsing arguments for call to "set": no such parameter: other`,
+		},
+		{
+			name:     "call to a user function",
+			call:     `nested(name)`,
+			function: UserFunction{Name: "nested", Params: []string{"value"}, Statements: []string{`set_key(value)`}},
+			wantErr:  `error while parsing arguments for call to "set_key": incorrect number of arguments. Expected: 2 Received: 1`,
+		},
+		{
+			name:     "invalid path",
+			call:     `path(name)`,
+			function: UserFunction{Name: "path", Params: []string{"value"}, Statements: []string{`set(name.first, value)`}},
+			wantErr:  `bad path`,
+		},
+		{
+			name:     "statement without parameters",
+			call:     `constant()`,
+			function: UserFunction{Name: "constant", Statements: []string{`set("name", 1)`}},
+			wantErr:  `error in statement "set(\"name\", 1)" of function "constant"`,
+		},
+		{
+			name:     "comparison without parameters",
+			call:     `set(name, 1) where Comparison(name)`,
+			function: UserFunction{Name: "Comparison", Params: []string{"value"}, Condition: `value != nil and name.first == 1`},
+			wantErr:  `error in condition of function "Comparison": bad path`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// The body is only checked when the function is called, so that
+			// it can use paths and functions of another context.
+			p, err := newUserFunctionsTestParser(append([]UserFunction{tt.function}, testUserFunctions[1]))
+			require.NoError(t, err)
+			_, err = p.ParseStatement(`set(name, 1)`)
+			require.NoError(t, err)
+			_, err = p.ParseStatement(tt.call)
+			assert.ErrorContains(t, err, tt.wantErr)
+			_, err = p.ParseStatement(tt.call)
+			assert.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+}
@@ -113,32 +113,13 @@ func normalizeName(metric pmetric.Metric, namespace string) string {
 		func(r rune) bool { return !unicode.IsLetter(r) && !unicode.IsDigit(r) },
 	)
 
-	// Split unit at the '/' if any
-	unitTokens := strings.SplitN(metric.Unit(), "/", 2)
-
-	// Main unit
-	// Append if not blank, doesn't contain '{}', and is not present in metric name already
-	if len(unitTokens) > 0 {
-		mainUnitOtel := strings.TrimSpace(unitTokens[0])
-		if mainUnitOtel != "" && !strings.ContainsAny(mainUnitOtel, "{}") {
-			mainUnitProm := CleanUpString(unitMapGetOrDefault(mainUnitOtel))
-			if mainUnitProm != "" && !contains(nameTokens, mainUnitProm) {
-				nameTokens = append(nameTokens, mainUnitProm)
-			}
-		}
-
-		// Per unit
-		// Append if not blank, doesn't contain '{}', and is not present in metric name already
-		if len(unitTokens) > 1 && unitTokens[1] != "" {
-			perUnitOtel := strings.TrimSpace(unitTokens[1])
-			if perUnitOtel != "" && !strings.ContainsAny(perUnitOtel, "{}") {
-				perUnitProm := CleanUpString(perUnitMapGetOrDefault(perUnitOtel))
-				if perUnitProm != "" && !contains(nameTokens, perUnitProm) {
-					nameTokens = append(append(nameTokens, "per"), perUnitProm)
-				}
-			}
-		}
-
+	// Append the main and per units if not present in metric name already
+	mainUnitProm, perUnitProm := unitSuffixes(metric.Unit())
+	if mainUnitProm != "" && !contains(nameTokens, mainUnitProm) {
+		nameTokens = append(nameTokens, mainUnitProm)
+	}
+	if perUnitProm != "" && !contains(nameTokens, perUnitProm) {
+		nameTokens = append(append(nameTokens, "per"), perUnitProm)
 	}
 
 	// Append _total for Counters
@@ -171,6 +152,62 @@ func normalizeName(metric pmetric.Metric, namespace string) string {
 	return normalizedName
 }
 
+// BuildMetricName builds the Prometheus name of the specified metric without
+// escaping it, for the backends accepting UTF-8 metric names.
+//
+// Metric name is prefixed with specified namespace and underscore (if any).
+// If addMetricSuffixes is set, the unit and type suffixes are appended with
+// underscores, like with BuildCompliantName.
+func BuildMetricName(metric pmetric.Metric, namespace string, addMetricSuffixes bool) string {
+	metricName := metric.Name()
+	if namespace != "" {
+		metricName = namespace + "_" + metricName
+	}
+	if !addMetricSuffixes {
+		return metricName
+	}
+
+	nameTokens := strings.FieldsFunc(
+		metric.Name(),
+		func(r rune) bool { return !unicode.IsLetter(r) && !unicode.IsDigit(r) },
+	)
+	mainUnitProm, perUnitProm := unitSuffixes(metric.Unit())
+	if mainUnitProm != "" && !contains(nameTokens, mainUnitProm) {
+		metricName += "_" + mainUnitProm
+	}
+	if perUnitProm != "" && !contains(nameTokens, perUnitProm) {
+		metricName += "_per_" + perUnitProm
+	}
+	if metric.Type() == pmetric.MetricTypeSum && metric.Sum().IsMonotonic() && !strings.HasSuffix(metricName, "_total") {
+		metricName += "_total"
+	}
+	if metric.Unit() == "1" && metric.Type() == pmetric.MetricTypeGauge && !strings.HasSuffix(metricName, "_ratio") {
+		metricName += "_ratio"
+	}
+	return metricName
+}
+
+// unitSuffixes returns the Prometheus suffixes of the main unit and of the
+// per unit of the specified OTLP unit, empty for blank units and units
+// containing '{}'.
+func unitSuffixes(unit string) (mainUnitProm string, perUnitProm string) {
+	// Split unit at the '/' if any
+	unitTokens := strings.SplitN(unit, "/", 2)
+
+	mainUnitOtel := strings.TrimSpace(unitTokens[0])
+	if mainUnitOtel != "" && !strings.ContainsAny(mainUnitOtel, "{}") {
+		mainUnitProm = CleanUpString(unitMapGetOrDefault(mainUnitOtel))
+	}
+
+	if len(unitTokens) > 1 {
+		perUnitOtel := strings.TrimSpace(unitTokens[1])
+		if perUnitOtel != "" && !strings.ContainsAny(perUnitOtel, "{}") {
+			perUnitProm = CleanUpString(perUnitMapGetOrDefault(perUnitOtel))
+		}
+	}
+	return mainUnitProm, perUnitProm
+}
+
 // TrimPromSuffixes trims type and unit prometheus suffixes from a metric name.
 // Following the [OpenTelemetry specs] for converting Prometheus Metric points to OTLP.
 //
@@ -243,3 +243,26 @@ func TestBuildCompliantNameWithoutSuffixes(t *testing.T) {
 	require.Equal(t, ":foo::bar", BuildCompliantName(createCounter(":foo::bar", ""), "", addUnitAndTypeSuffixes))
 
 }
+
+func TestBuildMetricName(t *testing.T) {
+
+	addUnitAndTypeSuffixes := true
+	require.Equal(t, "system.io_bytes_total", BuildMetricName(createCounter("system.io", "By"), "", addUnitAndTypeSuffixes))
+	require.Equal(t, "system_network.io_bytes_total", BuildMetricName(createCounter("network.io", "By"), "system", addUnitAndTypeSuffixes))
+	require.Equal(t, "http.requests_total", BuildMetricName(createCounter("http.requests_total", ""), "", addUnitAndTypeSuffixes))
+	require.Equal(t, "http.server.duration_seconds", BuildMetricName(createGauge("http.server.duration", "s"), "", addUnitAndTypeSuffixes))
+	require.Equal(t, "disk.io.rate_bytes_per_second", BuildMetricName(createGauge("disk.io.rate", "By/s"), "", addUnitAndTypeSuffixes))
+	require.Equal(t, "cpu.utilization_ratio", BuildMetricName(createGauge("cpu.utilization", "1"), "", addUnitAndTypeSuffixes))
+	require.Equal(t, "3.14 digits", BuildMetricName(createGauge("3.14 digits", "{digits}"), "", addUnitAndTypeSuffixes))
+	require.Equal(t, "température", BuildMetricName(createGauge("température", ""), "", addUnitAndTypeSuffixes))
+
+}
+
+func TestBuildMetricNameWithoutSuffixes(t *testing.T) {
+
+	addUnitAndTypeSuffixes := false
+	require.Equal(t, "system.io", BuildMetricName(createCounter("system.io", "By"), "", addUnitAndTypeSuffixes))
+	require.Equal(t, "system_network.io", BuildMetricName(createCounter("network.io", "By"), "system", addUnitAndTypeSuffixes))
+	require.Equal(t, "cpu.utilization", BuildMetricName(createGauge("cpu.utilization", "1"), "", addUnitAndTypeSuffixes))
+
+}
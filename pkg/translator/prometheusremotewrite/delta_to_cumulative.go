@@ -0,0 +1,386 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package prometheusremotewrite // import "github.com/open-telemetry/opentelemetry-collector-contrib/pkg/translator/prometheusremotewrite"
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+)
+
+// DeltaToCumulativeConverter accumulates the points of delta sums, histograms
+// and exponential histograms into cumulative series, so that they can be
+// exported to Prometheus.
+//
+// A series is identified by its tenant, resource, scope, metric and point
+// attributes.
+// It starts at the start timestamp of its first point and is reset, starting
+// again from the next point, when:
+//   - it received no point for MaxStale,
+//   - a point has no recorded value, the point is exported as a staleness marker,
+//   - the shape of the points changes: the value type of a sum, the bucket
+//     bounds of a histogram or the zero threshold of an exponential histogram.
+//
+// Points older than the last point of their series, or overlapping it, are dropped.
+type DeltaToCumulativeConverter struct {
+	mu        sync.Mutex
+	maxStale  time.Duration
+	streams   map[string]*deltaStream
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+// NewDeltaToCumulativeConverter creates a converter forgetting the series which
+// received no point for maxStale. The series never expire if maxStale is 0.
+func NewDeltaToCumulativeConverter(maxStale time.Duration) *DeltaToCumulativeConverter {
+	return &DeltaToCumulativeConverter{
+		maxStale: maxStale,
+		streams:  make(map[string]*deltaStream),
+		now:      time.Now,
+	}
+}
+
+// deltaStream is the accumulated state of a series.
+type deltaStream struct {
+	start    pcommon.Timestamp
+	last     pcommon.Timestamp
+	lastSeen time.Time
+	// shaped is true once the series has the shape of its points.
+	shaped bool
+
+	// sums
+	valueType   pmetric.NumberDataPointValueType
+	intValue    int64
+	doubleValue float64
+
+	// histograms and exponential histograms
+	count        uint64
+	sum          float64
+	hasMin       bool
+	min          float64
+	hasMax       bool
+	max          float64
+	bounds       []float64
+	bucketCounts []uint64
+
+	// exponential histograms
+	scale         int32
+	zeroThreshold float64
+	zeroCount     uint64
+	positive      expBuckets
+	negative      expBuckets
+}
+
+// isDeltaMetric returns true if the metric is a delta sum, histogram or
+// exponential histogram.
+func isDeltaMetric(metric pmetric.Metric) bool {
+	//exhaustive:enforce
+	switch metric.Type() {
+	case pmetric.MetricTypeSum:
+		return metric.Sum().AggregationTemporality() == pmetric.AggregationTemporalityDelta
+	case pmetric.MetricTypeHistogram:
+		return metric.Histogram().AggregationTemporality() == pmetric.AggregationTemporalityDelta
+	case pmetric.MetricTypeExponentialHistogram:
+		return metric.ExponentialHistogram().AggregationTemporality() == pmetric.AggregationTemporalityDelta
+	case pmetric.MetricTypeGauge, pmetric.MetricTypeSummary, pmetric.MetricTypeEmpty:
+	}
+	return false
+}
+
+// convert returns a cumulative copy of the delta metric, whose points are the
+// accumulated values of their series. It returns an error if points were dropped.
+func (c *DeltaToCumulativeConverter) convert(tenant string, resource pcommon.Resource, scope pcommon.InstrumentationScope, metric pmetric.Metric) (pmetric.Metric, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.now()
+	c.expire(now)
+
+	cumulative := pmetric.NewMetric()
+	metric.CopyTo(cumulative)
+	prefix := streamKeyPrefix(tenant, resource, scope, metric)
+	dropped := 0
+
+	//exhaustive:enforce
+	switch metric.Type() {
+	case pmetric.MetricTypeSum:
+		cumulative.Sum().SetAggregationTemporality(pmetric.AggregationTemporalityCumulative)
+		cumulative.Sum().DataPoints().RemoveIf(func(pt pmetric.NumberDataPoint) bool {
+			stream, ok := c.stream(prefix, pt.Attributes(), pt.StartTimestamp(), pt.Timestamp(), pt.Flags(), now)
+			if !ok {
+				dropped++
+				return true
+			}
+			if stream != nil {
+				stream.accumulateNumber(pt)
+			}
+			return false
+		})
+	case pmetric.MetricTypeHistogram:
+		cumulative.Histogram().SetAggregationTemporality(pmetric.AggregationTemporalityCumulative)
+		cumulative.Histogram().DataPoints().RemoveIf(func(pt pmetric.HistogramDataPoint) bool {
+			stream, ok := c.stream(prefix, pt.Attributes(), pt.StartTimestamp(), pt.Timestamp(), pt.Flags(), now)
+			if !ok {
+				dropped++
+				return true
+			}
+			if stream != nil {
+				stream.accumulateHistogram(pt)
+			}
+			return false
+		})
+	case pmetric.MetricTypeExponentialHistogram:
+		cumulative.ExponentialHistogram().SetAggregationTemporality(pmetric.AggregationTemporalityCumulative)
+		cumulative.ExponentialHistogram().DataPoints().RemoveIf(func(pt pmetric.ExponentialHistogramDataPoint) bool {
+			stream, ok := c.stream(prefix, pt.Attributes(), pt.StartTimestamp(), pt.Timestamp(), pt.Flags(), now)
+			if !ok {
+				dropped++
+				return true
+			}
+			if stream != nil {
+				stream.accumulateExponentialHistogram(pt)
+			}
+			return false
+		})
+	case pmetric.MetricTypeGauge, pmetric.MetricTypeSummary, pmetric.MetricTypeEmpty:
+		return cumulative, nil
+	}
+
+	if dropped > 0 {
+		return cumulative, fmt.Errorf("%d out of order delta points of metric %q dropped", dropped, metric.Name())
+	}
+	return cumulative, nil
+}
+
+// stream returns the series of a point, creating it if needed. It returns
+// false if the point must be dropped, and a nil series if the point has no
+// recorded value and must be exported as is.
+func (c *DeltaToCumulativeConverter) stream(prefix string, attributes pcommon.Map, start, timestamp pcommon.Timestamp, flags pmetric.DataPointFlags, now time.Time) (*deltaStream, bool) {
+	key := prefix + fmt.Sprint(attributes.AsRaw())
+	if flags.NoRecordedValue() {
+		delete(c.streams, key)
+		return nil, true
+	}
+
+	stream, ok := c.streams[key]
+	if ok && c.isStale(stream, now) {
+		ok = false
+	}
+	if ok && (timestamp <= stream.last || (start != 0 && start < stream.last)) {
+		return nil, false
+	}
+	if !ok {
+		if start == 0 {
+			start = timestamp
+		}
+		stream = &deltaStream{start: start}
+		c.streams[key] = stream
+	}
+	stream.last = timestamp
+	stream.lastSeen = now
+	return stream, true
+}
+
+func (c *DeltaToCumulativeConverter) isStale(stream *deltaStream, now time.Time) bool {
+	return c.maxStale > 0 && now.Sub(stream.lastSeen) > c.maxStale
+}
+
+// expire forgets the stale series, at most once per MaxStale.
+func (c *DeltaToCumulativeConverter) expire(now time.Time) {
+	if c.maxStale <= 0 || now.Sub(c.lastSweep) < c.maxStale {
+		return
+	}
+	c.lastSweep = now
+	for key, stream := range c.streams {
+		if c.isStale(stream, now) {
+			delete(c.streams, key)
+		}
+	}
+}
+
+// streamKeyPrefix identifies the metric of a series, its points are
+// identified by their attributes.
+func streamKeyPrefix(tenant string, resource pcommon.Resource, scope pcommon.InstrumentationScope, metric pmetric.Metric) string {
+	var b strings.Builder
+	b.WriteString(tenant)
+	b.WriteByte(0xff)
+	b.WriteString(fmt.Sprint(resource.Attributes().AsRaw()))
+	for _, s := range []string{scope.Name(), scope.Version(), metric.Type().String(), metric.Name(), metric.Unit()} {
+		b.WriteByte(0xff)
+		b.WriteString(s)
+	}
+	b.WriteByte(0xff)
+	return b.String()
+}
+
+// accumulateNumber adds pt to the sum, and sets pt to the accumulated value.
+func (s *deltaStream) accumulateNumber(pt pmetric.NumberDataPoint) {
+	if !s.shaped || s.valueType != pt.ValueType() {
+		s.reset(pt.StartTimestamp())
+		s.valueType = pt.ValueType()
+	}
+	switch pt.ValueType() {
+	case pmetric.NumberDataPointValueTypeInt:
+		s.intValue += pt.IntValue()
+		pt.SetIntValue(s.intValue)
+	case pmetric.NumberDataPointValueTypeDouble:
+		s.doubleValue += pt.DoubleValue()
+		pt.SetDoubleValue(s.doubleValue)
+	case pmetric.NumberDataPointValueTypeEmpty:
+	}
+	pt.SetStartTimestamp(s.start)
+}
+
+// accumulateHistogram adds pt to the histogram, and sets pt to the accumulated value.
+func (s *deltaStream) accumulateHistogram(pt pmetric.HistogramDataPoint) {
+	if !s.shaped || !slices.Equal(s.bounds, pt.ExplicitBounds().AsRaw()) || len(s.bucketCounts) != pt.BucketCounts().Len() {
+		s.reset(pt.StartTimestamp())
+		s.bounds = pt.ExplicitBounds().AsRaw()
+		s.bucketCounts = make([]uint64, pt.BucketCounts().Len())
+	}
+	s.count += pt.Count()
+	s.sum += pt.Sum()
+	for i := range s.bucketCounts {
+		s.bucketCounts[i] += pt.BucketCounts().At(i)
+	}
+	s.accumulateMinMax(pt.HasMin(), pt.Min(), pt.HasMax(), pt.Max())
+
+	pt.SetStartTimestamp(s.start)
+	pt.SetCount(s.count)
+	pt.SetSum(s.sum)
+	pt.BucketCounts().FromRaw(slices.Clone(s.bucketCounts))
+	s.setMinMax(pt.SetMin, pt.RemoveMin, pt.SetMax, pt.RemoveMax)
+}
+
+// accumulateExponentialHistogram adds pt to the exponential histogram, and sets
+// pt to the accumulated value. The histogram has the lowest scale of its points.
+func (s *deltaStream) accumulateExponentialHistogram(pt pmetric.ExponentialHistogramDataPoint) {
+	if !s.shaped || s.zeroThreshold != pt.ZeroThreshold() {
+		s.reset(pt.StartTimestamp())
+		s.scale = pt.Scale()
+		s.zeroThreshold = pt.ZeroThreshold()
+	}
+	if pt.Scale() < s.scale {
+		s.positive.downscale(s.scale - pt.Scale())
+		s.negative.downscale(s.scale - pt.Scale())
+		s.scale = pt.Scale()
+	}
+	shift := pt.Scale() - s.scale
+	s.positive.merge(pt.Positive(), shift)
+	s.negative.merge(pt.Negative(), shift)
+	s.count += pt.Count()
+	s.sum += pt.Sum()
+	s.zeroCount += pt.ZeroCount()
+	s.accumulateMinMax(pt.HasMin(), pt.Min(), pt.HasMax(), pt.Max())
+
+	pt.SetStartTimestamp(s.start)
+	pt.SetScale(s.scale)
+	pt.SetCount(s.count)
+	pt.SetSum(s.sum)
+	pt.SetZeroCount(s.zeroCount)
+	s.positive.copyTo(pt.Positive())
+	s.negative.copyTo(pt.Negative())
+	s.setMinMax(pt.SetMin, pt.RemoveMin, pt.SetMax, pt.RemoveMax)
+}
+
+func (s *deltaStream) accumulateMinMax(hasMin bool, minValue float64, hasMax bool, maxValue float64) {
+	if hasMin {
+		if s.hasMin {
+			minValue = math.Min(s.min, minValue)
+		}
+		s.min, s.hasMin = minValue, true
+	}
+	if hasMax {
+		if s.hasMax {
+			maxValue = math.Max(s.max, maxValue)
+		}
+		s.max, s.hasMax = maxValue, true
+	}
+}
+
+func (s *deltaStream) setMinMax(setMin func(float64), removeMin func(), setMax func(float64), removeMax func()) {
+	if s.hasMin {
+		setMin(s.min)
+	} else {
+		removeMin()
+	}
+	if s.hasMax {
+		setMax(s.max)
+	} else {
+		removeMax()
+	}
+}
+
+// reset clears the accumulated values when the series gets the shape of its
+// points. A series which already had a shape restarts at start, or at its last
+// point if start is not set.
+func (s *deltaStream) reset(start pcommon.Timestamp) {
+	if !s.shaped {
+		start = s.start
+	} else if start == 0 {
+		start = s.last
+	}
+	*s = deltaStream{start: start, last: s.last, lastSeen: s.lastSeen, shaped: true}
+}
+
+// expBuckets are the buckets of an exponential histogram, the count of the
+// bucket at index i is counts[i-offset].
+type expBuckets struct {
+	offset int32
+	counts []uint64
+}
+
+func (b *expBuckets) isEmpty() bool {
+	return len(b.counts) == 0
+}
+
+// downscale merges the buckets into the buckets of a scale lowered by by.
+func (b *expBuckets) downscale(by int32) {
+	if by <= 0 || b.isEmpty() {
+		return
+	}
+	counts := b.counts
+	first := b.offset
+	b.offset = first >> by
+	b.counts = make([]uint64, ((first+int32(len(counts))-1)>>by)-b.offset+1)
+	for i, count := range counts {
+		b.counts[((first+int32(i))>>by)-b.offset] += count
+	}
+}
+
+// merge adds the buckets of a scale higher by shift.
+func (b *expBuckets) merge(buckets pmetric.ExponentialHistogramDataPointBuckets, shift int32) {
+	for i := 0; i < buckets.BucketCounts().Len(); i++ {
+		if count := buckets.BucketCounts().At(i); count != 0 {
+			b.add((buckets.Offset()+int32(i))>>shift, count)
+		}
+	}
+}
+
+func (b *expBuckets) add(index int32, count uint64) {
+	if b.isEmpty() {
+		b.offset = index
+		b.counts = []uint64{count}
+		return
+	}
+	if index < b.offset {
+		b.counts = append(make([]uint64, b.offset-index), b.counts...)
+		b.offset = index
+	}
+	if last := b.offset + int32(len(b.counts)) - 1; index > last {
+		b.counts = append(b.counts, make([]uint64, index-last)...)
+	}
+	b.counts[index-b.offset] += count
+}
+
+func (b *expBuckets) copyTo(buckets pmetric.ExponentialHistogramDataPointBuckets) {
+	buckets.SetOffset(b.offset)
+	buckets.BucketCounts().FromRaw(slices.Clone(b.counts))
+}
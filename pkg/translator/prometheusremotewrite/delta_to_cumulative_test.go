@@ -0,0 +1,274 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package prometheusremotewrite
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/prometheus/model/value"
+	"github.com/prometheus/prometheus/prompb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+)
+
+func deltaSumMetrics(name string, points ...[3]int64) pmetric.Metrics {
+	md := pmetric.NewMetrics()
+	metric := md.ResourceMetrics().AppendEmpty().ScopeMetrics().AppendEmpty().Metrics().AppendEmpty()
+	metric.SetName(name)
+	sum := metric.SetEmptySum()
+	sum.SetIsMonotonic(true)
+	sum.SetAggregationTemporality(pmetric.AggregationTemporalityDelta)
+	for _, p := range points {
+		pt := sum.DataPoints().AppendEmpty()
+		pt.SetStartTimestamp(pcommon.Timestamp(p[0] * int64(time.Millisecond)))
+		pt.SetTimestamp(pcommon.Timestamp(p[1] * int64(time.Millisecond)))
+		pt.SetIntValue(p[2])
+	}
+	return md
+}
+
+func seriesName(labels []prompb.Label) string {
+	for _, label := range labels {
+		if label.Name == nameStr {
+			return label.Value
+		}
+	}
+	return ""
+}
+
+func sumSamples(t *testing.T, md pmetric.Metrics, settings Settings) ([]float64, error) {
+	tsMap, err := FromMetrics(md, settings)
+	var values []float64
+	for _, ts := range tsMap {
+		if seriesName(ts.Labels) != "requests_total" {
+			continue
+		}
+		for _, sample := range ts.Samples {
+			values = append(values, sample.Value)
+		}
+	}
+	require.LessOrEqual(t, len(tsMap), 2)
+	return values, err
+}
+
+func TestFromMetrics_deltaToCumulativeDisabled(t *testing.T) {
+	tsMap, err := FromMetrics(deltaSumMetrics("requests", [3]int64{0, 1000, 1}), Settings{DisableTargetInfo: true})
+	assert.EqualError(t, err, `invalid temporality and type combination for metric "requests"`)
+	assert.Empty(t, tsMap)
+}
+
+func TestFromMetrics_deltaToCumulativeSum(t *testing.T) {
+	settings := Settings{
+		AddMetricSuffixes:   true,
+		DisableTargetInfo:   true,
+		ExportCreatedMetric: true,
+		DeltaToCumulative:   NewDeltaToCumulativeConverter(time.Minute),
+	}
+
+	values, err := sumSamples(t, deltaSumMetrics("requests", [3]int64{1000, 2000, 3}), settings)
+	require.NoError(t, err)
+	assert.Equal(t, []float64{3}, values)
+	values, err = sumSamples(t, deltaSumMetrics("requests", [3]int64{2000, 3000, 4}, [3]int64{3000, 4000, 5}), settings)
+	require.NoError(t, err)
+	assert.Equal(t, []float64{7, 12}, values)
+
+	// The created metric keeps the start of the first point.
+	tsMap, err := FromMetrics(deltaSumMetrics("requests", [3]int64{4000, 5000, 1}), settings)
+	require.NoError(t, err)
+	var created []float64
+	for _, ts := range tsMap {
+		if seriesName(ts.Labels) == "requests_total_created" {
+			created = append(created, ts.Samples[0].Value)
+		}
+	}
+	assert.Equal(t, []float64{1000}, created)
+
+	// Out of order and overlapping points are dropped.
+	values, err = sumSamples(t, deltaSumMetrics("requests", [3]int64{3000, 4000, 1}, [3]int64{4500, 6000, 1}, [3]int64{5000, 6000, 2}), settings)
+	assert.EqualError(t, err, `2 out of order delta points of metric "requests" dropped`)
+	assert.Equal(t, []float64{15}, values)
+}
+
+func TestFromMetrics_deltaToCumulativeTenants(t *testing.T) {
+	settings := Settings{
+		AddMetricSuffixes: true,
+		DisableTargetInfo: true,
+		DeltaToCumulative: NewDeltaToCumulativeConverter(time.Minute),
+		Tenant:            "a",
+	}
+
+	values, err := sumSamples(t, deltaSumMetrics("requests", [3]int64{1000, 2000, 3}), settings)
+	require.NoError(t, err)
+	assert.Equal(t, []float64{3}, values)
+
+	// The same series of another tenant is accumulated on its own.
+	settings.Tenant = "b"
+	values, err = sumSamples(t, deltaSumMetrics("requests", [3]int64{1000, 2000, 5}), settings)
+	require.NoError(t, err)
+	assert.Equal(t, []float64{5}, values)
+
+	settings.Tenant = "a"
+	values, err = sumSamples(t, deltaSumMetrics("requests", [3]int64{2000, 3000, 1}), settings)
+	require.NoError(t, err)
+	assert.Equal(t, []float64{4}, values)
+}
+
+func TestFromMetrics_deltaToCumulativeNoRecordedValue(t *testing.T) {
+	settings := Settings{
+		AddMetricSuffixes: true,
+		DisableTargetInfo: true,
+		DeltaToCumulative: NewDeltaToCumulativeConverter(time.Minute),
+	}
+	_, err := FromMetrics(deltaSumMetrics("requests", [3]int64{1000, 2000, 3}), settings)
+	require.NoError(t, err)
+
+	md := deltaSumMetrics("requests", [3]int64{2000, 3000, 0})
+	md.ResourceMetrics().At(0).ScopeMetrics().At(0).Metrics().At(0).Sum().DataPoints().At(0).SetFlags(pmetric.DefaultDataPointFlags.WithNoRecordedValue(true))
+	values, err := sumSamples(t, md, settings)
+	require.NoError(t, err)
+	require.Len(t, values, 1)
+	assert.True(t, value.IsStaleNaN(values[0]))
+
+	// The series restarts after the staleness marker.
+	values, err = sumSamples(t, deltaSumMetrics("requests", [3]int64{3000, 4000, 2}), settings)
+	require.NoError(t, err)
+	assert.Equal(t, []float64{2}, values)
+}
+
+func TestDeltaToCumulativeConverter_expiry(t *testing.T) {
+	now := time.Unix(0, 0)
+	converter := NewDeltaToCumulativeConverter(time.Minute)
+	converter.now = func() time.Time { return now }
+	settings := Settings{AddMetricSuffixes: true, DisableTargetInfo: true, DeltaToCumulative: converter}
+
+	_, err := FromMetrics(deltaSumMetrics("requests", [3]int64{1000, 2000, 3}), settings)
+	require.NoError(t, err)
+	now = now.Add(30 * time.Second)
+	values, err := sumSamples(t, deltaSumMetrics("requests", [3]int64{2000, 3000, 1}), settings)
+	require.NoError(t, err)
+	assert.Equal(t, []float64{4}, values)
+
+	// The series is forgotten after MaxStale without points.
+	now = now.Add(2 * time.Minute)
+	values, err = sumSamples(t, deltaSumMetrics("requests", [3]int64{3000, 4000, 1}), settings)
+	require.NoError(t, err)
+	assert.Equal(t, []float64{1}, values)
+
+	_, err = FromMetrics(deltaSumMetrics("other", [3]int64{0, 1000, 1}), settings)
+	require.NoError(t, err)
+	now = now.Add(2 * time.Minute)
+	_, err = FromMetrics(deltaSumMetrics("other", [3]int64{1000, 2000, 1}), settings)
+	require.NoError(t, err)
+	assert.Len(t, converter.streams, 1)
+}
+
+func TestDeltaToCumulativeConverter_histogram(t *testing.T) {
+	converter := NewDeltaToCumulativeConverter(0)
+	metric := pmetric.NewMetric()
+	metric.SetName("latency")
+	histogram := metric.SetEmptyHistogram()
+	histogram.SetAggregationTemporality(pmetric.AggregationTemporalityDelta)
+	pt := histogram.DataPoints().AppendEmpty()
+	pt.SetStartTimestamp(1)
+	pt.SetTimestamp(2)
+	pt.ExplicitBounds().FromRaw([]float64{1, 10})
+	pt.BucketCounts().FromRaw([]uint64{1, 2, 0})
+	pt.SetCount(3)
+	pt.SetSum(12)
+	pt.SetMin(0.5)
+	pt.SetMax(8)
+
+	resource, scope := pcommon.NewResource(), pcommon.NewInstrumentationScope()
+	_, err := converter.convert("", resource, scope, metric)
+	require.NoError(t, err)
+
+	pt.SetStartTimestamp(2)
+	pt.SetTimestamp(3)
+	pt.BucketCounts().FromRaw([]uint64{0, 1, 1})
+	pt.SetCount(2)
+	pt.SetSum(25)
+	pt.SetMin(5)
+	pt.SetMax(20)
+	cumulative, err := converter.convert("", resource, scope, metric)
+	require.NoError(t, err)
+	assert.Equal(t, pmetric.AggregationTemporalityCumulative, cumulative.Histogram().AggregationTemporality())
+	got := cumulative.Histogram().DataPoints().At(0)
+	assert.Equal(t, pcommon.Timestamp(1), got.StartTimestamp())
+	assert.Equal(t, []uint64{1, 3, 1}, got.BucketCounts().AsRaw())
+	assert.Equal(t, uint64(5), got.Count())
+	assert.Equal(t, 37.0, got.Sum())
+	assert.Equal(t, 0.5, got.Min())
+	assert.Equal(t, 20.0, got.Max())
+	// The delta metric is left as is.
+	assert.Equal(t, []uint64{0, 1, 1}, pt.BucketCounts().AsRaw())
+
+	// Changing the bounds resets the series.
+	pt.SetStartTimestamp(3)
+	pt.SetTimestamp(4)
+	pt.ExplicitBounds().FromRaw([]float64{5})
+	pt.BucketCounts().FromRaw([]uint64{1, 1})
+	cumulative, err = converter.convert("", resource, scope, metric)
+	require.NoError(t, err)
+	got = cumulative.Histogram().DataPoints().At(0)
+	assert.Equal(t, pcommon.Timestamp(3), got.StartTimestamp())
+	assert.Equal(t, []uint64{1, 1}, got.BucketCounts().AsRaw())
+	assert.Equal(t, uint64(2), got.Count())
+}
+
+func TestDeltaToCumulativeConverter_exponentialHistogram(t *testing.T) {
+	converter := NewDeltaToCumulativeConverter(0)
+	metric := pmetric.NewMetric()
+	metric.SetName("latency")
+	histogram := metric.SetEmptyExponentialHistogram()
+	histogram.SetAggregationTemporality(pmetric.AggregationTemporalityDelta)
+	pt := histogram.DataPoints().AppendEmpty()
+	pt.SetStartTimestamp(1)
+	pt.SetTimestamp(2)
+	pt.SetScale(1)
+	pt.SetCount(4)
+	pt.SetZeroCount(1)
+	pt.Positive().SetOffset(-1)
+	pt.Positive().BucketCounts().FromRaw([]uint64{1, 1, 1})
+
+	resource, scope := pcommon.NewResource(), pcommon.NewInstrumentationScope()
+	_, err := converter.convert("", resource, scope, metric)
+	require.NoError(t, err)
+
+	// The buckets of scale 1, at indices -1, 0 and 1, are merged into the
+	// buckets of scale 0 at indices -1 and 0.
+	pt.SetTimestamp(3)
+	pt.SetStartTimestamp(2)
+	pt.SetScale(0)
+	pt.SetCount(2)
+	pt.SetZeroCount(0)
+	pt.Positive().SetOffset(1)
+	pt.Positive().BucketCounts().FromRaw([]uint64{2})
+	pt.Negative().BucketCounts().FromRaw([]uint64{0})
+	cumulative, err := converter.convert("", resource, scope, metric)
+	require.NoError(t, err)
+	got := cumulative.ExponentialHistogram().DataPoints().At(0)
+	assert.Equal(t, int32(0), got.Scale())
+	assert.Equal(t, uint64(6), got.Count())
+	assert.Equal(t, uint64(1), got.ZeroCount())
+	assert.Equal(t, int32(-1), got.Positive().Offset())
+	assert.Equal(t, []uint64{1, 2, 2}, got.Positive().BucketCounts().AsRaw())
+	assert.Zero(t, got.Negative().BucketCounts().Len())
+
+	// Higher scales are merged into the lower scale of the series.
+	pt.SetTimestamp(4)
+	pt.SetStartTimestamp(3)
+	pt.SetScale(2)
+	pt.SetCount(1)
+	pt.Positive().SetOffset(-5)
+	pt.Positive().BucketCounts().FromRaw([]uint64{1})
+	cumulative, err = converter.convert("", resource, scope, metric)
+	require.NoError(t, err)
+	got = cumulative.ExponentialHistogram().DataPoints().At(0)
+	assert.Equal(t, int32(0), got.Scale())
+	assert.Equal(t, int32(-2), got.Positive().Offset())
+	assert.Equal(t, []uint64{1, 1, 2, 2}, got.Positive().BucketCounts().AsRaw())
+}
@@ -21,8 +21,6 @@ import (
 	"go.opentelemetry.io/collector/pdata/pcommon"
 	"go.opentelemetry.io/collector/pdata/pmetric"
 	conventions "go.opentelemetry.io/collector/semconv/v1.6.1"
-
-	prometheustranslator "github.com/open-telemetry/opentelemetry-collector-contrib/pkg/translator/prometheus"
 )
 
 const (
@@ -149,8 +147,9 @@ func timeSeriesSignature(datatype string, labels []prompb.Label) string {
 
 // createAttributes creates a slice of Prometheus Labels with OTLP attributes and pairs of string values.
 // Unpaired string values are ignored. String pairs overwrite OTLP labels if collisions happen, and overwrites are
-// logged. Resulting label names are sanitized.
-func createAttributes(resource pcommon.Resource, attributes pcommon.Map, externalLabels map[string]string, extras ...string) []prompb.Label {
+// logged. Resulting label names are sanitized unless the translation strategy keeps UTF-8 names.
+func createAttributes(resource pcommon.Resource, attributes pcommon.Map, settings Settings, extras ...string) []prompb.Label {
+	externalLabels := settings.ExternalLabels
 	serviceName, haveServiceName := resource.Attributes().Get(conventions.AttributeServiceName)
 	instance, haveInstanceID := resource.Attributes().Get(conventions.AttributeServiceInstanceID)
 
@@ -178,7 +177,7 @@ func createAttributes(resource pcommon.Resource, attributes pcommon.Map, externa
 	sort.Stable(ByLabelName(labels))
 
 	for _, label := range labels {
-		var finalKey = prometheustranslator.NormalizeLabel(label.Name)
+		var finalKey = settings.normalizeLabel(label.Name)
 		if existingValue, alreadyExists := l[finalKey]; alreadyExists {
 			l[finalKey] = existingValue + ";" + label.Value
 		} else {
@@ -218,7 +217,7 @@ func createAttributes(resource pcommon.Resource, attributes pcommon.Map, externa
 		// internal labels should be maintained
 		name := extras[i]
 		if !(len(name) > 4 && name[:2] == "__" && name[len(name)-2:] == "__") {
-			name = prometheustranslator.NormalizeLabel(name)
+			name = settings.normalizeLabel(name)
 		}
 		l[name] = extras[i+1]
 	}
@@ -252,7 +251,7 @@ func isValidAggregationTemporality(metric pmetric.Metric) bool {
 // ignore extra buckets if len(ExplicitBounds) > len(BucketCounts)
 func addSingleHistogramDataPoint(pt pmetric.HistogramDataPoint, resource pcommon.Resource, metric pmetric.Metric, settings Settings, tsMap map[string]*prompb.TimeSeries, baseName string) {
 	timestamp := convertTimeStamp(pt.Timestamp())
-	baseLabels := createAttributes(resource, pt.Attributes(), settings.ExternalLabels)
+	baseLabels := createAttributes(resource, pt.Attributes(), settings)
 
 	createLabels := func(nameSuffix string, extras ...string) []prompb.Label {
 		extraLabelCount := len(extras) / 2
@@ -451,7 +450,7 @@ func maxTimestamp(a, b pcommon.Timestamp) pcommon.Timestamp {
 func addSingleSummaryDataPoint(pt pmetric.SummaryDataPoint, resource pcommon.Resource, metric pmetric.Metric, settings Settings,
 	tsMap map[string]*prompb.TimeSeries, baseName string) {
 	timestamp := convertTimeStamp(pt.Timestamp())
-	baseLabels := createAttributes(resource, pt.Attributes(), settings.ExternalLabels)
+	baseLabels := createAttributes(resource, pt.Attributes(), settings)
 
 	createLabels := func(name string, extras ...string) []prompb.Label {
 		extraLabelCount := len(extras) / 2
@@ -563,7 +562,7 @@ func addResourceTargetInfo(resource pcommon.Resource, settings Settings, timesta
 	if len(settings.Namespace) > 0 {
 		name = settings.Namespace + "_" + name
 	}
-	labels := createAttributes(resource, attributes, settings.ExternalLabels, model.MetricNameLabel, name)
+	labels := createAttributes(resource, attributes, settings, model.MetricNameLabel, name)
 	sample := &prompb.Sample{
 		Value: float64(1),
 		// convert ns to ms
@@ -11,8 +11,6 @@ import (
 	"go.opentelemetry.io/collector/pdata/pcommon"
 	"go.opentelemetry.io/collector/pdata/pmetric"
 	"go.uber.org/multierr"
-
-	prometheustranslator "github.com/open-telemetry/opentelemetry-collector-contrib/pkg/translator/prometheus"
 )
 
 /* type Settings struct {
@@ -45,12 +43,18 @@ func FromMetrics(md pmetric.Metrics, settings Settings) (tsMap map[string]*promp
 				metric := metricSlice.At(k)
 				mostRecentTimestamp = maxTimestamp(mostRecentTimestamp, mostRecentTimestampInMetric(metric))
 
+				if settings.DeltaToCumulative != nil && isDeltaMetric(metric) {
+					var err error
+					metric, err = settings.DeltaToCumulative.convert(settings.Tenant, resource, scopeMetrics.Scope(), metric)
+					errs = multierr.Append(errs, err)
+				}
+
 				if !isValidAggregationTemporality(metric) {
 					errs = multierr.Append(errs, fmt.Errorf("invalid temporality and type combination for metric %q", metric.Name()))
 					continue
 				}
 
-				promName := prometheustranslator.BuildCompliantName(metric, settings.Namespace, settings.AddMetricSuffixes)
+				promName := settings.buildMetricName(metric)
 
 				// handle individual metric based on type
 				//exhaustive:enforce
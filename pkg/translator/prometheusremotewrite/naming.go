@@ -0,0 +1,66 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package prometheusremotewrite // import "github.com/open-telemetry/opentelemetry-collector-contrib/pkg/translator/prometheusremotewrite"
+
+import (
+	"go.opentelemetry.io/collector/pdata/pmetric"
+
+	prometheustranslator "github.com/open-telemetry/opentelemetry-collector-contrib/pkg/translator/prometheus"
+)
+
+// TranslationStrategy defines how the OTLP metric and attribute names are
+// translated into Prometheus metric and label names. The values match the
+// translation strategies of the Prometheus OTLP receiver.
+type TranslationStrategy string
+
+const (
+	// UnderscoreEscapingWithSuffixes replaces the characters not allowed in
+	// the legacy Prometheus names by underscores, and appends the unit and
+	// type suffixes if AddMetricSuffixes is set. It is the default.
+	UnderscoreEscapingWithSuffixes TranslationStrategy = "UnderscoreEscapingWithSuffixes"
+
+	// NoUTF8EscapingWithSuffixes keeps the names as is, and appends the unit
+	// and type suffixes if AddMetricSuffixes is set. The backend must accept
+	// UTF-8 names.
+	NoUTF8EscapingWithSuffixes TranslationStrategy = "NoUTF8EscapingWithSuffixes"
+
+	// NoTranslation keeps the names as is, without suffixes. The backend must
+	// accept UTF-8 names.
+	NoTranslation TranslationStrategy = "NoTranslation"
+)
+
+// ShouldEscape returns true if the names are escaped with the strategy.
+func (s TranslationStrategy) ShouldEscape() bool {
+	return s == "" || s == UnderscoreEscapingWithSuffixes
+}
+
+// IsValid returns true if the strategy is known, or empty.
+func (s TranslationStrategy) IsValid() bool {
+	switch s {
+	case "", UnderscoreEscapingWithSuffixes, NoUTF8EscapingWithSuffixes, NoTranslation:
+		return true
+	}
+	return false
+}
+
+// buildMetricName returns the Prometheus metric name of metric, prefixed with
+// the namespace.
+func (settings Settings) buildMetricName(metric pmetric.Metric) string {
+	switch settings.TranslationStrategy {
+	case NoTranslation:
+		return prometheustranslator.BuildMetricName(metric, settings.Namespace, false)
+	case NoUTF8EscapingWithSuffixes:
+		return prometheustranslator.BuildMetricName(metric, settings.Namespace, settings.AddMetricSuffixes)
+	default:
+		return prometheustranslator.BuildCompliantName(metric, settings.Namespace, settings.AddMetricSuffixes)
+	}
+}
+
+// normalizeLabel returns the Prometheus label name of an attribute.
+func (settings Settings) normalizeLabel(name string) string {
+	if !settings.TranslationStrategy.ShouldEscape() {
+		return name
+	}
+	return prometheustranslator.NormalizeLabel(name)
+}
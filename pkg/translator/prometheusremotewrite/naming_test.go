@@ -0,0 +1,67 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package prometheusremotewrite
+
+import (
+	"testing"
+
+	"github.com/prometheus/prometheus/prompb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+)
+
+func TestFromMetrics_translationStrategy(t *testing.T) {
+	md := pmetric.NewMetrics()
+	metric := md.ResourceMetrics().AppendEmpty().ScopeMetrics().AppendEmpty().Metrics().AppendEmpty()
+	metric.SetName("http.server.requests")
+	metric.SetUnit("By")
+	sum := metric.SetEmptySum()
+	sum.SetIsMonotonic(true)
+	sum.SetAggregationTemporality(pmetric.AggregationTemporalityCumulative)
+	pt := sum.DataPoints().AppendEmpty()
+	pt.SetIntValue(1)
+	pt.Attributes().PutStr("http.method", "GET")
+
+	tests := []struct {
+		strategy TranslationStrategy
+		name     string
+		label    string
+	}{
+		{"", "http_server_requests_bytes_total", "http_method"},
+		{UnderscoreEscapingWithSuffixes, "http_server_requests_bytes_total", "http_method"},
+		{NoUTF8EscapingWithSuffixes, "http.server.requests_bytes_total", "http.method"},
+		{NoTranslation, "http.server.requests", "http.method"},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.strategy), func(t *testing.T) {
+			settings := Settings{
+				AddMetricSuffixes:   true,
+				DisableTargetInfo:   true,
+				ExternalLabels:      map[string]string{"cluster.name": "a"},
+				TranslationStrategy: tt.strategy,
+			}
+			tsMap, err := FromMetrics(md, settings)
+			require.NoError(t, err)
+			require.Len(t, tsMap, 1)
+			for _, ts := range tsMap {
+				assert.ElementsMatch(t, []prompb.Label{
+					{Name: nameStr, Value: tt.name},
+					{Name: tt.label, Value: "GET"},
+					{Name: "cluster.name", Value: "a"},
+				}, ts.Labels)
+			}
+
+			metadata := MetricsToMetadata(md, settings)
+			require.Len(t, metadata, 1)
+			assert.Equal(t, tt.name, metadata[0].MetricFamilyName)
+		})
+	}
+}
+
+func TestTranslationStrategy_IsValid(t *testing.T) {
+	assert.True(t, TranslationStrategy("").IsValid())
+	assert.True(t, NoUTF8EscapingWithSuffixes.IsValid())
+	assert.False(t, TranslationStrategy("NoUTF8Escaping").IsValid())
+}
@@ -27,7 +27,7 @@ func addSingleGaugeNumberDataPoint(
 	labels := createAttributes(
 		resource,
 		pt.Attributes(),
-		settings.ExternalLabels,
+		settings,
 		model.MetricNameLabel,
 		name,
 	)
@@ -61,7 +61,7 @@ func addSingleSumNumberDataPoint(
 	labels := createAttributes(
 		resource,
 		pt.Attributes(),
-		settings.ExternalLabels,
+		settings,
 		model.MetricNameLabel, name,
 	)
 	sample := &prompb.Sample{
@@ -6,8 +6,6 @@ package prometheusremotewrite // import "github.com/open-telemetry/opentelemetry
 import (
 	"github.com/prometheus/prometheus/prompb"
 	"go.opentelemetry.io/collector/pdata/pmetric"
-
-	prometheustranslator "github.com/open-telemetry/opentelemetry-collector-contrib/pkg/translator/prometheus"
 )
 
 func otelMetricTypeToPromMetricType(otelMetric pmetric.Metric) prompb.MetricMetadata_MetricType {
@@ -31,6 +29,12 @@ func otelMetricTypeToPromMetricType(otelMetric pmetric.Metric) prompb.MetricMeta
 }
 
 func OtelMetricsToMetadata(md pmetric.Metrics, addMetricSuffixes bool) []*prompb.MetricMetadata {
+	return MetricsToMetadata(md, Settings{AddMetricSuffixes: addMetricSuffixes})
+}
+
+// MetricsToMetadata returns the metadata of the metrics, with the metric family
+// names built from the namespace, suffixes and translation strategy of settings.
+func MetricsToMetadata(md pmetric.Metrics, settings Settings) []*prompb.MetricMetadata {
 	resourceMetricsSlice := md.ResourceMetrics()
 
 	metadataLength := 0
@@ -52,7 +56,7 @@ func OtelMetricsToMetadata(md pmetric.Metrics, addMetricSuffixes bool) []*prompb
 				metric := scopeMetrics.Metrics().At(k)
 				entry := prompb.MetricMetadata{
 					Type:             otelMetricTypeToPromMetricType(metric),
-					MetricFamilyName: prometheustranslator.BuildCompliantName(metric, "", addMetricSuffixes),
+					MetricFamilyName: settings.buildMetricName(metric),
 					Help:             metric.Description(),
 				}
 				metadata = append(metadata, &entry)
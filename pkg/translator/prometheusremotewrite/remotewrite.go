@@ -3,12 +3,21 @@ package prometheusremotewrite
 import "go.uber.org/zap"
 
 type Settings struct {
-	Namespace         string
-	ExternalLabels    map[string]string
-	DisableTargetInfo bool
-	TimeThreshold     int64
-	Logger            zap.Logger
+	Namespace           string
+	ExternalLabels      map[string]string
+	DisableTargetInfo   bool
+	TimeThreshold       int64
+	Logger              zap.Logger
 	ExportCreatedMetric bool
 	AddMetricSuffixes   bool
 	SendMetadata        bool
+	// DeltaToCumulative converts the delta metrics to cumulative ones if set,
+	// they are dropped otherwise.
+	DeltaToCumulative *DeltaToCumulativeConverter
+	// Tenant keeps the series accumulated by DeltaToCumulative apart from the
+	// identical series of other tenants.
+	Tenant string
+	// TranslationStrategy defines how the metric and attribute names are
+	// translated, UnderscoreEscapingWithSuffixes if empty.
+	TranslationStrategy TranslationStrategy
 }
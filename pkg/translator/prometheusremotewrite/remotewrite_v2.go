@@ -0,0 +1,257 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package prometheusremotewrite // import "github.com/open-telemetry/opentelemetry-collector-contrib/pkg/translator/prometheusremotewrite"
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/prometheus/prometheus/prompb"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/pkg/translator/prometheusremotewrite/writev2"
+)
+
+const totalSuffix = "_total"
+
+// ToWriteRequestV2 converts a Remote Write 1.0 request into a Remote Write 2.0
+// one. The metadata of the request is attached to the series of the matching
+// metric family, and `_created` series are folded into the created timestamp
+// of the counter, histogram or summary series they belong to.
+func ToWriteRequestV2(req *prompb.WriteRequest) *writev2.Request {
+	metadata := make(map[string]prompb.MetricMetadata, len(req.Metadata))
+	for _, m := range req.Metadata {
+		metadata[m.MetricFamilyName] = m
+	}
+
+	created := map[string]int64{}
+	var series []*prompb.TimeSeries
+	for i := range req.Timeseries {
+		ts := &req.Timeseries[i]
+		name := metricName(ts.Labels)
+		if strings.HasSuffix(name, createdSuffix) && len(ts.Samples) > 0 {
+			created[createdSignature(name, ts.Labels)] = int64(ts.Samples[len(ts.Samples)-1].Value)
+		}
+		series = append(series, ts)
+	}
+
+	symbols := writev2.NewSymbolTable()
+	folded := map[string]bool{}
+	out := make([]writev2.TimeSeries, 0, len(series))
+	for _, ts := range series {
+		name := metricName(ts.Labels)
+		v2 := writev2.TimeSeries{
+			LabelsRefs: symbols.SymbolizeLabels(ts.Labels, nil),
+		}
+		for _, candidate := range createdCandidates(name) {
+			sig := createdSignature(candidate, ts.Labels)
+			if ct, ok := created[sig]; ok {
+				v2.CreatedTimestamp = ct
+				folded[sig] = true
+				break
+			}
+		}
+		for _, s := range ts.Samples {
+			v2.Samples = append(v2.Samples, writev2.Sample{Value: s.Value, Timestamp: s.Timestamp})
+		}
+		v2.Histograms = ts.Histograms
+		for _, e := range ts.Exemplars {
+			v2.Exemplars = append(v2.Exemplars, writev2.Exemplar{
+				LabelsRefs: symbols.SymbolizeLabels(e.Labels, nil),
+				Value:      e.Value,
+				Timestamp:  e.Timestamp,
+			})
+		}
+		for _, family := range familyCandidates(name) {
+			if m, ok := metadata[family]; ok {
+				v2.Metadata = writev2.Metadata{
+					Type:    writev2.FromMetadataType(m.Type),
+					HelpRef: symbols.Symbolize(m.Help),
+					UnitRef: symbols.Symbolize(m.Unit),
+				}
+				break
+			}
+		}
+		out = append(out, v2)
+	}
+
+	// Drop the `_created` series which were attached to their parent series.
+	timeseries := out[:0]
+	for i, ts := range series {
+		name := metricName(ts.Labels)
+		if strings.HasSuffix(name, createdSuffix) && folded[createdSignature(name, ts.Labels)] {
+			continue
+		}
+		timeseries = append(timeseries, out[i])
+	}
+
+	return &writev2.Request{
+		Symbols:    symbols.Symbols(),
+		Timeseries: timeseries,
+	}
+}
+
+// FromWriteRequestV2 converts a Remote Write 2.0 request into a Remote Write
+// 1.0 one. The inline metadata of the series becomes the metadata of their
+// metric family, and created timestamps are sent as `_created` series.
+func FromWriteRequestV2(req *writev2.Request) (*prompb.WriteRequest, error) {
+	out := &prompb.WriteRequest{
+		Timeseries: make([]prompb.TimeSeries, 0, len(req.Timeseries)),
+	}
+	seenMetadata := map[string]bool{}
+	seenCreated := map[string]bool{}
+	var createdSeries []prompb.TimeSeries
+	for _, ts := range req.Timeseries {
+		labels, err := writev2.DesymbolizeLabels(ts.LabelsRefs, req.Symbols)
+		if err != nil {
+			return nil, err
+		}
+		v1 := prompb.TimeSeries{
+			Labels:     labels,
+			Histograms: ts.Histograms,
+		}
+		for _, s := range ts.Samples {
+			v1.Samples = append(v1.Samples, prompb.Sample{Value: s.Value, Timestamp: s.Timestamp})
+		}
+		for _, e := range ts.Exemplars {
+			exemplarLabels, err := writev2.DesymbolizeLabels(e.LabelsRefs, req.Symbols)
+			if err != nil {
+				return nil, err
+			}
+			v1.Exemplars = append(v1.Exemplars, prompb.Exemplar{Labels: exemplarLabels, Value: e.Value, Timestamp: e.Timestamp})
+		}
+		out.Timeseries = append(out.Timeseries, v1)
+
+		family := familyName(metricName(labels), ts.Metadata.Type)
+		if ts.Metadata.Type != writev2.MetricTypeUnspecified && !seenMetadata[family] {
+			help, err := writev2.Symbol(ts.Metadata.HelpRef, req.Symbols)
+			if err != nil {
+				return nil, err
+			}
+			unit, err := writev2.Symbol(ts.Metadata.UnitRef, req.Symbols)
+			if err != nil {
+				return nil, err
+			}
+			seenMetadata[family] = true
+			out.Metadata = append(out.Metadata, prompb.MetricMetadata{
+				Type:             ts.Metadata.Type.ToMetadataType(),
+				MetricFamilyName: family,
+				Help:             help,
+				Unit:             unit,
+			})
+		}
+
+		if ts.CreatedTimestamp == 0 {
+			continue
+		}
+		createdName := family + createdSuffix
+		sig := createdSignature(createdName, labels)
+		if seenCreated[sig] {
+			continue
+		}
+		seenCreated[sig] = true
+		createdSeries = append(createdSeries, prompb.TimeSeries{
+			Labels: createdLabels(createdName, labels),
+			Samples: []prompb.Sample{{
+				Value:     float64(ts.CreatedTimestamp),
+				Timestamp: lastTimestamp(v1),
+			}},
+		})
+	}
+	out.Timeseries = append(out.Timeseries, createdSeries...)
+	return out, nil
+}
+
+// familyName returns the name of the metric family a series belongs to.
+func familyName(name string, metricType writev2.MetricType) string {
+	switch metricType {
+	case writev2.MetricTypeHistogram, writev2.MetricTypeGaugeHistogram, writev2.MetricTypeSummary:
+		for _, suffix := range []string{bucketStr, sumStr, countStr} {
+			if strings.HasSuffix(name, suffix) {
+				return strings.TrimSuffix(name, suffix)
+			}
+		}
+	}
+	return name
+}
+
+// familyCandidates returns the metric family names a series named name may
+// have been described with.
+func familyCandidates(name string) []string {
+	candidates := []string{name}
+	for _, suffix := range []string{bucketStr, sumStr, countStr, totalSuffix} {
+		if strings.HasSuffix(name, suffix) {
+			candidates = append(candidates, strings.TrimSuffix(name, suffix))
+		}
+	}
+	return candidates
+}
+
+// createdCandidates returns the names of the `_created` series which may hold
+// the created timestamp of a series named name.
+func createdCandidates(name string) []string {
+	if strings.HasSuffix(name, createdSuffix) {
+		return nil
+	}
+	families := familyCandidates(name)
+	candidates := make([]string, 0, len(families))
+	for _, family := range families {
+		candidates = append(candidates, family+createdSuffix)
+	}
+	return candidates
+}
+
+// createdSignature identifies a `_created` series by its name and the labels
+// shared with its parent series.
+func createdSignature(createdName string, labels []prompb.Label) string {
+	ls := createdLabels(createdName, labels)
+	sort.Sort(ByLabelName(ls))
+	var b strings.Builder
+	for _, l := range ls {
+		b.WriteString(l.Name)
+		b.WriteByte('\xff')
+		b.WriteString(l.Value)
+		b.WriteByte('\xfe')
+	}
+	return b.String()
+}
+
+// createdLabels returns the labels of a `_created` series derived from the
+// labels of its parent series.
+func createdLabels(createdName string, labels []prompb.Label) []prompb.Label {
+	out := make([]prompb.Label, 0, len(labels))
+	for _, l := range labels {
+		switch l.Name {
+		case nameStr:
+			out = append(out, prompb.Label{Name: nameStr, Value: createdName})
+		case leStr, quantileStr:
+		default:
+			out = append(out, l)
+		}
+	}
+	return out
+}
+
+func metricName(labels []prompb.Label) string {
+	for _, l := range labels {
+		if l.Name == nameStr {
+			return l.Value
+		}
+	}
+	return ""
+}
+
+func lastTimestamp(ts prompb.TimeSeries) int64 {
+	var last int64
+	for _, s := range ts.Samples {
+		if s.Timestamp > last {
+			last = s.Timestamp
+		}
+	}
+	for _, h := range ts.Histograms {
+		if h.Timestamp > last {
+			last = h.Timestamp
+		}
+	}
+	return last
+}
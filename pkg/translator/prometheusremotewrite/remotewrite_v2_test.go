@@ -0,0 +1,135 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package prometheusremotewrite
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/prometheus/prometheus/prompb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/pkg/translator/prometheusremotewrite/writev2"
+)
+
+func TestToWriteRequestV2(t *testing.T) {
+	req := &prompb.WriteRequest{
+		Timeseries: []prompb.TimeSeries{
+			{
+				Labels:  []prompb.Label{{Name: nameStr, Value: "http_requests_total"}, {Name: "method", Value: "GET"}},
+				Samples: []prompb.Sample{{Value: 10, Timestamp: 2000}},
+			},
+			{
+				Labels:  []prompb.Label{{Name: nameStr, Value: "http_requests_total_created"}, {Name: "method", Value: "GET"}},
+				Samples: []prompb.Sample{{Value: 1000, Timestamp: 2000}},
+			},
+			{
+				Labels:  []prompb.Label{{Name: nameStr, Value: "latency_bucket"}, {Name: leStr, Value: "1"}},
+				Samples: []prompb.Sample{{Value: 2, Timestamp: 2000}},
+			},
+			{
+				Labels:  []prompb.Label{{Name: nameStr, Value: "latency_count"}},
+				Samples: []prompb.Sample{{Value: 2, Timestamp: 2000}},
+			},
+			{
+				Labels:  []prompb.Label{{Name: nameStr, Value: "latency_created"}},
+				Samples: []prompb.Sample{{Value: 1500, Timestamp: 2000}},
+			},
+			{
+				Labels:  []prompb.Label{{Name: nameStr, Value: "orphan_created"}},
+				Samples: []prompb.Sample{{Value: 7, Timestamp: 2000}},
+			},
+		},
+		Metadata: []prompb.MetricMetadata{
+			{Type: prompb.MetricMetadata_COUNTER, MetricFamilyName: "http_requests_total", Help: "Requests."},
+			{Type: prompb.MetricMetadata_HISTOGRAM, MetricFamilyName: "latency", Unit: "seconds"},
+		},
+	}
+
+	got := ToWriteRequestV2(req)
+	require.Equal(t, "", got.Symbols[0])
+	require.Len(t, got.Timeseries, 4)
+
+	byName := map[string]writev2.TimeSeries{}
+	for _, ts := range got.Timeseries {
+		labels, err := writev2.DesymbolizeLabels(ts.LabelsRefs, got.Symbols)
+		require.NoError(t, err)
+		byName[metricName(labels)] = ts
+	}
+
+	counter := byName["http_requests_total"]
+	assert.Equal(t, int64(1000), counter.CreatedTimestamp)
+	assert.Equal(t, writev2.MetricTypeCounter, counter.Metadata.Type)
+	assert.Equal(t, "Requests.", got.Symbols[counter.Metadata.HelpRef])
+	assert.Equal(t, []writev2.Sample{{Value: 10, Timestamp: 2000}}, counter.Samples)
+
+	bucket := byName["latency_bucket"]
+	assert.Equal(t, int64(1500), bucket.CreatedTimestamp)
+	assert.Equal(t, writev2.MetricTypeHistogram, bucket.Metadata.Type)
+	assert.Equal(t, "seconds", got.Symbols[bucket.Metadata.UnitRef])
+	assert.Equal(t, int64(1500), byName["latency_count"].CreatedTimestamp)
+
+	orphan, ok := byName["orphan_created"]
+	require.True(t, ok)
+	assert.Equal(t, writev2.MetricTypeUnspecified, orphan.Metadata.Type)
+}
+
+func TestFromWriteRequestV2(t *testing.T) {
+	symbols := writev2.NewSymbolTable()
+	req := &writev2.Request{
+		Timeseries: []writev2.TimeSeries{
+			{
+				LabelsRefs:       symbols.SymbolizeLabels([]prompb.Label{{Name: nameStr, Value: "latency_bucket"}, {Name: leStr, Value: "1"}, {Name: "job", Value: "api"}}, nil),
+				Samples:          []writev2.Sample{{Value: 2, Timestamp: 2000}},
+				Metadata:         writev2.Metadata{Type: writev2.MetricTypeHistogram, HelpRef: symbols.Symbolize("Latency.")},
+				CreatedTimestamp: 1500,
+			},
+			{
+				LabelsRefs:       symbols.SymbolizeLabels([]prompb.Label{{Name: nameStr, Value: "latency_bucket"}, {Name: leStr, Value: "+Inf"}, {Name: "job", Value: "api"}}, nil),
+				Samples:          []writev2.Sample{{Value: 3, Timestamp: 2000}},
+				Metadata:         writev2.Metadata{Type: writev2.MetricTypeHistogram, HelpRef: symbols.Symbolize("Latency.")},
+				CreatedTimestamp: 1500,
+			},
+			{
+				LabelsRefs: symbols.SymbolizeLabels([]prompb.Label{{Name: nameStr, Value: "up"}}, nil),
+				Samples:    []writev2.Sample{{Value: 1, Timestamp: 2000}},
+				Exemplars: []writev2.Exemplar{{
+					LabelsRefs: symbols.SymbolizeLabels([]prompb.Label{{Name: traceIDKey, Value: "0102"}}, nil),
+					Value:      1,
+					Timestamp:  1900,
+				}},
+			},
+		},
+	}
+	req.Symbols = symbols.Symbols()
+
+	got, err := FromWriteRequestV2(req)
+	require.NoError(t, err)
+	require.Len(t, got.Timeseries, 4)
+	assert.Equal(t, []prompb.MetricMetadata{{Type: prompb.MetricMetadata_HISTOGRAM, MetricFamilyName: "latency", Help: "Latency."}}, got.Metadata)
+
+	created := got.Timeseries[3]
+	assert.Equal(t, []prompb.Label{{Name: nameStr, Value: "latency_created"}, {Name: "job", Value: "api"}}, created.Labels)
+	assert.Equal(t, []prompb.Sample{{Value: 1500, Timestamp: 2000}}, created.Samples)
+	assert.Equal(t, []prompb.Exemplar{{Labels: []prompb.Label{{Name: traceIDKey, Value: "0102"}}, Value: 1, Timestamp: 1900}}, got.Timeseries[2].Exemplars)
+
+	// Converting back folds the `_created` series again.
+	back := ToWriteRequestV2(got)
+	require.Len(t, back.Timeseries, 3)
+	var createdTimestamps []int64
+	for _, ts := range back.Timeseries {
+		createdTimestamps = append(createdTimestamps, ts.CreatedTimestamp)
+	}
+	sort.Slice(createdTimestamps, func(i, j int) bool { return createdTimestamps[i] < createdTimestamps[j] })
+	assert.Equal(t, []int64{0, 1500, 1500}, createdTimestamps)
+}
+
+func TestFromWriteRequestV2InvalidSymbols(t *testing.T) {
+	_, err := FromWriteRequestV2(&writev2.Request{
+		Symbols:    []string{""},
+		Timeseries: []writev2.TimeSeries{{LabelsRefs: []uint32{1, 2}}},
+	})
+	assert.Error(t, err)
+}
@@ -0,0 +1,283 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package writev2 // import "github.com/open-telemetry/opentelemetry-collector-contrib/pkg/translator/prometheusremotewrite/writev2"
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/prometheus/prometheus/prompb"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// Field numbers of io.prometheus.write.v2 messages.
+const (
+	requestSymbolsField    = 4
+	requestTimeseriesField = 5
+
+	seriesLabelsRefsField       = 1
+	seriesSamplesField          = 2
+	seriesHistogramsField       = 3
+	seriesExemplarsField        = 4
+	seriesMetadataField         = 5
+	seriesCreatedTimestampField = 6
+
+	sampleValueField     = 1
+	sampleTimestampField = 2
+
+	exemplarLabelsRefsField = 1
+	exemplarValueField      = 2
+	exemplarTimestampField  = 3
+
+	metadataTypeField    = 1
+	metadataHelpRefField = 3
+	metadataUnitRefField = 4
+)
+
+var errInvalidMessage = errors.New("invalid remote write 2.0 message")
+
+// Marshal encodes the request in the protobuf wire format.
+func (r *Request) Marshal() ([]byte, error) {
+	var b []byte
+	for _, s := range r.Symbols {
+		b = protowire.AppendTag(b, requestSymbolsField, protowire.BytesType)
+		b = protowire.AppendString(b, s)
+	}
+	for i := range r.Timeseries {
+		ts, err := r.Timeseries[i].marshal()
+		if err != nil {
+			return nil, err
+		}
+		b = protowire.AppendTag(b, requestTimeseriesField, protowire.BytesType)
+		b = protowire.AppendBytes(b, ts)
+	}
+	return b, nil
+}
+
+func (ts *TimeSeries) marshal() ([]byte, error) {
+	var b []byte
+	b = appendPackedRefs(b, seriesLabelsRefsField, ts.LabelsRefs)
+	for _, s := range ts.Samples {
+		b = protowire.AppendTag(b, seriesSamplesField, protowire.BytesType)
+		b = protowire.AppendBytes(b, s.marshal())
+	}
+	for i := range ts.Histograms {
+		h, err := ts.Histograms[i].Marshal()
+		if err != nil {
+			return nil, err
+		}
+		b = protowire.AppendTag(b, seriesHistogramsField, protowire.BytesType)
+		b = protowire.AppendBytes(b, h)
+	}
+	for _, e := range ts.Exemplars {
+		b = protowire.AppendTag(b, seriesExemplarsField, protowire.BytesType)
+		b = protowire.AppendBytes(b, e.marshal())
+	}
+	b = protowire.AppendTag(b, seriesMetadataField, protowire.BytesType)
+	b = protowire.AppendBytes(b, ts.Metadata.marshal())
+	if ts.CreatedTimestamp != 0 {
+		b = protowire.AppendTag(b, seriesCreatedTimestampField, protowire.VarintType)
+		b = protowire.AppendVarint(b, uint64(ts.CreatedTimestamp))
+	}
+	return b, nil
+}
+
+func (s Sample) marshal() []byte {
+	var b []byte
+	if s.Value != 0 {
+		b = protowire.AppendTag(b, sampleValueField, protowire.Fixed64Type)
+		b = protowire.AppendFixed64(b, math.Float64bits(s.Value))
+	}
+	if s.Timestamp != 0 {
+		b = protowire.AppendTag(b, sampleTimestampField, protowire.VarintType)
+		b = protowire.AppendVarint(b, uint64(s.Timestamp))
+	}
+	return b
+}
+
+func (e Exemplar) marshal() []byte {
+	var b []byte
+	b = appendPackedRefs(b, exemplarLabelsRefsField, e.LabelsRefs)
+	if e.Value != 0 {
+		b = protowire.AppendTag(b, exemplarValueField, protowire.Fixed64Type)
+		b = protowire.AppendFixed64(b, math.Float64bits(e.Value))
+	}
+	if e.Timestamp != 0 {
+		b = protowire.AppendTag(b, exemplarTimestampField, protowire.VarintType)
+		b = protowire.AppendVarint(b, uint64(e.Timestamp))
+	}
+	return b
+}
+
+func (m Metadata) marshal() []byte {
+	var b []byte
+	if m.Type != MetricTypeUnspecified {
+		b = protowire.AppendTag(b, metadataTypeField, protowire.VarintType)
+		b = protowire.AppendVarint(b, uint64(m.Type))
+	}
+	if m.HelpRef != 0 {
+		b = protowire.AppendTag(b, metadataHelpRefField, protowire.VarintType)
+		b = protowire.AppendVarint(b, uint64(m.HelpRef))
+	}
+	if m.UnitRef != 0 {
+		b = protowire.AppendTag(b, metadataUnitRefField, protowire.VarintType)
+		b = protowire.AppendVarint(b, uint64(m.UnitRef))
+	}
+	return b
+}
+
+func appendPackedRefs(b []byte, field protowire.Number, refs []uint32) []byte {
+	if len(refs) == 0 {
+		return b
+	}
+	var packed []byte
+	for _, ref := range refs {
+		packed = protowire.AppendVarint(packed, uint64(ref))
+	}
+	b = protowire.AppendTag(b, field, protowire.BytesType)
+	return protowire.AppendBytes(b, packed)
+}
+
+// Unmarshal decodes a request from the protobuf wire format. Unknown fields
+// are skipped.
+func (r *Request) Unmarshal(b []byte) error {
+	*r = Request{}
+	return walkFields(b, func(num protowire.Number, typ protowire.Type, v []byte, _ uint64) error {
+		switch {
+		case num == requestSymbolsField && typ == protowire.BytesType:
+			r.Symbols = append(r.Symbols, string(v))
+		case num == requestTimeseriesField && typ == protowire.BytesType:
+			var ts TimeSeries
+			if err := ts.unmarshal(v); err != nil {
+				return err
+			}
+			r.Timeseries = append(r.Timeseries, ts)
+		}
+		return nil
+	})
+}
+
+func (ts *TimeSeries) unmarshal(b []byte) error {
+	return walkFields(b, func(num protowire.Number, typ protowire.Type, v []byte, n uint64) error {
+		var err error
+		switch num {
+		case seriesLabelsRefsField:
+			ts.LabelsRefs, err = appendRefs(ts.LabelsRefs, typ, v, n)
+		case seriesSamplesField:
+			var s Sample
+			if err = s.unmarshal(v); err == nil {
+				ts.Samples = append(ts.Samples, s)
+			}
+		case seriesHistogramsField:
+			ts.Histograms = append(ts.Histograms, prompb.Histogram{})
+			err = ts.Histograms[len(ts.Histograms)-1].Unmarshal(v)
+		case seriesExemplarsField:
+			var e Exemplar
+			if err = e.unmarshal(v); err == nil {
+				ts.Exemplars = append(ts.Exemplars, e)
+			}
+		case seriesMetadataField:
+			err = ts.Metadata.unmarshal(v)
+		case seriesCreatedTimestampField:
+			ts.CreatedTimestamp = int64(n)
+		}
+		return err
+	})
+}
+
+func (s *Sample) unmarshal(b []byte) error {
+	return walkFields(b, func(num protowire.Number, _ protowire.Type, _ []byte, n uint64) error {
+		switch num {
+		case sampleValueField:
+			s.Value = math.Float64frombits(n)
+		case sampleTimestampField:
+			s.Timestamp = int64(n)
+		}
+		return nil
+	})
+}
+
+func (e *Exemplar) unmarshal(b []byte) error {
+	return walkFields(b, func(num protowire.Number, typ protowire.Type, v []byte, n uint64) error {
+		var err error
+		switch num {
+		case exemplarLabelsRefsField:
+			e.LabelsRefs, err = appendRefs(e.LabelsRefs, typ, v, n)
+		case exemplarValueField:
+			e.Value = math.Float64frombits(n)
+		case exemplarTimestampField:
+			e.Timestamp = int64(n)
+		}
+		return err
+	})
+}
+
+func (m *Metadata) unmarshal(b []byte) error {
+	return walkFields(b, func(num protowire.Number, _ protowire.Type, _ []byte, n uint64) error {
+		switch num {
+		case metadataTypeField:
+			m.Type = MetricType(n)
+		case metadataHelpRefField:
+			m.HelpRef = uint32(n)
+		case metadataUnitRefField:
+			m.UnitRef = uint32(n)
+		}
+		return nil
+	})
+}
+
+// appendRefs decodes references which may be sent packed or one per field.
+func appendRefs(refs []uint32, typ protowire.Type, v []byte, n uint64) ([]uint32, error) {
+	if typ == protowire.VarintType {
+		return append(refs, uint32(n)), nil
+	}
+	for len(v) > 0 {
+		ref, l := protowire.ConsumeVarint(v)
+		if l < 0 {
+			return nil, fmt.Errorf("%w: %w", errInvalidMessage, protowire.ParseError(l))
+		}
+		refs = append(refs, uint32(ref))
+		v = v[l:]
+	}
+	return refs, nil
+}
+
+// walkFields calls fn for each field of a message. Length delimited fields
+// are passed as v, varint and fixed fields as n.
+func walkFields(b []byte, fn func(num protowire.Number, typ protowire.Type, v []byte, n uint64) error) error {
+	for len(b) > 0 {
+		num, typ, l := protowire.ConsumeTag(b)
+		if l < 0 {
+			return fmt.Errorf("%w: %w", errInvalidMessage, protowire.ParseError(l))
+		}
+		b = b[l:]
+		var (
+			v []byte
+			n uint64
+		)
+		switch typ {
+		case protowire.VarintType:
+			n, l = protowire.ConsumeVarint(b)
+		case protowire.Fixed64Type:
+			n, l = protowire.ConsumeFixed64(b)
+		case protowire.Fixed32Type:
+			var n32 uint32
+			n32, l = protowire.ConsumeFixed32(b)
+			n = uint64(n32)
+		case protowire.BytesType:
+			v, l = protowire.ConsumeBytes(b)
+		default:
+			l = protowire.ConsumeFieldValue(num, typ, b)
+		}
+		if l < 0 {
+			return fmt.Errorf("%w: %w", errInvalidMessage, protowire.ParseError(l))
+		}
+		b = b[l:]
+		if err := fn(num, typ, v, n); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,108 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package writev2
+
+import (
+	"testing"
+
+	"github.com/prometheus/prometheus/prompb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+func TestRequestRoundTrip(t *testing.T) {
+	symbols := NewSymbolTable()
+	req := &Request{
+		Timeseries: []TimeSeries{
+			{
+				LabelsRefs: symbols.SymbolizeLabels([]prompb.Label{{Name: "__name__", Value: "http_requests_total"}, {Name: "job", Value: "api"}}, nil),
+				Samples:    []Sample{{Value: 3, Timestamp: 1000}, {Value: 0, Timestamp: 2000}},
+				Exemplars: []Exemplar{{
+					LabelsRefs: symbols.SymbolizeLabels([]prompb.Label{{Name: "trace_id", Value: "abc"}}, nil),
+					Value:      1.5,
+					Timestamp:  1500,
+				}},
+				Metadata: Metadata{
+					Type:    MetricTypeCounter,
+					HelpRef: symbols.Symbolize("Number of requests."),
+				},
+				CreatedTimestamp: 500,
+			},
+			{
+				LabelsRefs: symbols.SymbolizeLabels([]prompb.Label{{Name: "__name__", Value: "latency"}}, nil),
+				Histograms: []prompb.Histogram{{
+					Count:          &prompb.Histogram_CountInt{CountInt: 3},
+					ZeroCount:      &prompb.Histogram_ZeroCountInt{ZeroCountInt: 1},
+					Sum:            4.5,
+					Schema:         2,
+					PositiveSpans:  []prompb.BucketSpan{{Offset: 1, Length: 2}},
+					PositiveDeltas: []int64{1, 0},
+					Timestamp:      3000,
+				}},
+				Metadata: Metadata{Type: MetricTypeHistogram, UnitRef: symbols.Symbolize("seconds")},
+			},
+		},
+	}
+	req.Symbols = symbols.Symbols()
+
+	b, err := req.Marshal()
+	require.NoError(t, err)
+
+	var got Request
+	require.NoError(t, got.Unmarshal(b))
+	assert.Equal(t, req.Symbols, got.Symbols)
+	require.Len(t, got.Timeseries, 2)
+	assert.Equal(t, req.Timeseries[0], got.Timeseries[0])
+
+	gotHistogram := got.Timeseries[1]
+	assert.Equal(t, req.Timeseries[1].LabelsRefs, gotHistogram.LabelsRefs)
+	assert.Equal(t, req.Timeseries[1].Metadata, gotHistogram.Metadata)
+	require.Len(t, gotHistogram.Histograms, 1)
+	assert.Equal(t, uint64(3), gotHistogram.Histograms[0].GetCountInt())
+	assert.Equal(t, req.Timeseries[1].Histograms[0].PositiveSpans, gotHistogram.Histograms[0].PositiveSpans)
+	assert.Equal(t, req.Timeseries[1].Histograms[0].PositiveDeltas, gotHistogram.Histograms[0].PositiveDeltas)
+}
+
+func TestRequestUnmarshalUnpackedRefs(t *testing.T) {
+	var series []byte
+	for _, ref := range []uint32{1, 2} {
+		series = protowire.AppendTag(series, seriesLabelsRefsField, protowire.VarintType)
+		series = protowire.AppendVarint(series, uint64(ref))
+	}
+	// Unknown fields are skipped.
+	series = protowire.AppendTag(series, 99, protowire.Fixed32Type)
+	series = protowire.AppendFixed32(series, 7)
+
+	var b []byte
+	for _, s := range []string{"", "__name__", "up"} {
+		b = protowire.AppendTag(b, requestSymbolsField, protowire.BytesType)
+		b = protowire.AppendString(b, s)
+	}
+	b = protowire.AppendTag(b, requestTimeseriesField, protowire.BytesType)
+	b = protowire.AppendBytes(b, series)
+
+	var got Request
+	require.NoError(t, got.Unmarshal(b))
+	require.Len(t, got.Timeseries, 1)
+	labels, err := DesymbolizeLabels(got.Timeseries[0].LabelsRefs, got.Symbols)
+	require.NoError(t, err)
+	assert.Equal(t, []prompb.Label{{Name: "__name__", Value: "up"}}, labels)
+}
+
+func TestRequestUnmarshalInvalid(t *testing.T) {
+	var got Request
+	assert.ErrorIs(t, got.Unmarshal([]byte{0x2a, 0x05, 0x01}), errInvalidMessage)
+}
+
+func TestDesymbolizeLabels(t *testing.T) {
+	symbols := []string{"", "a", "b"}
+	_, err := DesymbolizeLabels([]uint32{1}, symbols)
+	assert.Error(t, err)
+	_, err = DesymbolizeLabels([]uint32{1, 3}, symbols)
+	assert.Error(t, err)
+	labels, err := DesymbolizeLabels([]uint32{1, 2, 2, 0}, symbols)
+	require.NoError(t, err)
+	assert.Equal(t, []prompb.Label{{Name: "a", Value: "b"}, {Name: "b", Value: ""}}, labels)
+}
@@ -0,0 +1,156 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+// Package writev2 implements the Prometheus Remote Write 2.0 message format
+// (io.prometheus.write.v2.Request). It mirrors the types of Prometheus'
+// prompb/io/prometheus/write/v2 package, which is not available in the
+// Prometheus version used by this module.
+package writev2 // import "github.com/open-telemetry/opentelemetry-collector-contrib/pkg/translator/prometheusremotewrite/writev2"
+
+import (
+	"fmt"
+
+	"github.com/prometheus/prometheus/prompb"
+)
+
+const (
+	// ContentType is the content type of Remote Write 2.0 requests.
+	ContentType = "application/x-protobuf;proto=io.prometheus.write.v2.Request"
+	// ProtoMessage is the fully qualified name of the Remote Write 2.0 message.
+	ProtoMessage = "io.prometheus.write.v2.Request"
+	// ProtoMessageV1 is the fully qualified name of the Remote Write 1.0 message.
+	ProtoMessageV1 = "prometheus.WriteRequest"
+)
+
+// Request is a Remote Write 2.0 request. All strings referenced by the series
+// are interned in Symbols, whose first entry is always the empty string.
+type Request struct {
+	Symbols    []string
+	Timeseries []TimeSeries
+}
+
+// TimeSeries is a series of samples or native histograms sharing the same
+// labels, referenced as pairs of name and value indexes into Request.Symbols.
+type TimeSeries struct {
+	LabelsRefs []uint32
+	Samples    []Sample
+	// Histograms are wire compatible with the Remote Write 1.0 histograms.
+	Histograms []prompb.Histogram
+	Exemplars  []Exemplar
+	Metadata   Metadata
+	// CreatedTimestamp is the time in milliseconds at which the counter,
+	// histogram or summary of the series started, or 0 when unknown.
+	CreatedTimestamp int64
+}
+
+// Sample is a single value at a timestamp in milliseconds.
+type Sample struct {
+	Value     float64
+	Timestamp int64
+}
+
+// Exemplar is an exemplar whose labels reference Request.Symbols.
+type Exemplar struct {
+	LabelsRefs []uint32
+	Value      float64
+	Timestamp  int64
+}
+
+// MetricType is the type of the metric a series belongs to.
+type MetricType int32
+
+const (
+	MetricTypeUnspecified    MetricType = 0
+	MetricTypeCounter        MetricType = 1
+	MetricTypeGauge          MetricType = 2
+	MetricTypeHistogram      MetricType = 3
+	MetricTypeGaugeHistogram MetricType = 4
+	MetricTypeSummary        MetricType = 5
+	MetricTypeInfo           MetricType = 6
+	MetricTypeStateset       MetricType = 7
+)
+
+// FromMetadataType converts a Remote Write 1.0 metric type.
+func FromMetadataType(t prompb.MetricMetadata_MetricType) MetricType {
+	// The enum values of both versions match.
+	return MetricType(t)
+}
+
+// ToMetadataType converts the metric type to its Remote Write 1.0 form.
+func (t MetricType) ToMetadataType() prompb.MetricMetadata_MetricType {
+	return prompb.MetricMetadata_MetricType(t)
+}
+
+// Metadata describes the metric a series belongs to. Help and unit are
+// references into Request.Symbols.
+type Metadata struct {
+	Type    MetricType
+	HelpRef uint32
+	UnitRef uint32
+}
+
+// SymbolsTable interns strings for a Request.
+type SymbolsTable struct {
+	symbols    []string
+	symbolsMap map[string]uint32
+}
+
+// NewSymbolTable returns a SymbolsTable holding the mandatory empty string.
+func NewSymbolTable() *SymbolsTable {
+	return &SymbolsTable{
+		symbols:    []string{""},
+		symbolsMap: map[string]uint32{"": 0},
+	}
+}
+
+// Symbolize returns the reference of str, adding it to the table if needed.
+func (t *SymbolsTable) Symbolize(str string) uint32 {
+	if ref, ok := t.symbolsMap[str]; ok {
+		return ref
+	}
+	ref := uint32(len(t.symbols))
+	t.symbols = append(t.symbols, str)
+	t.symbolsMap[str] = ref
+	return ref
+}
+
+// SymbolizeLabels appends the name and value references of labels to buf.
+func (t *SymbolsTable) SymbolizeLabels(labels []prompb.Label, buf []uint32) []uint32 {
+	for _, l := range labels {
+		buf = append(buf, t.Symbolize(l.Name), t.Symbolize(l.Value))
+	}
+	return buf
+}
+
+// Symbols returns the interned strings in reference order.
+func (t *SymbolsTable) Symbols() []string {
+	return t.symbols
+}
+
+// DesymbolizeLabels resolves label references against symbols.
+func DesymbolizeLabels(refs []uint32, symbols []string) ([]prompb.Label, error) {
+	if len(refs)%2 != 0 {
+		return nil, fmt.Errorf("invalid labels references: odd number of references %d", len(refs))
+	}
+	labels := make([]prompb.Label, 0, len(refs)/2)
+	for i := 0; i < len(refs); i += 2 {
+		name, err := Symbol(refs[i], symbols)
+		if err != nil {
+			return nil, err
+		}
+		value, err := Symbol(refs[i+1], symbols)
+		if err != nil {
+			return nil, err
+		}
+		labels = append(labels, prompb.Label{Name: name, Value: value})
+	}
+	return labels, nil
+}
+
+// Symbol resolves a single reference against symbols.
+func Symbol(ref uint32, symbols []string) (string, error) {
+	if int(ref) >= len(symbols) {
+		return "", fmt.Errorf("symbol reference %d out of range, %d symbols", ref, len(symbols))
+	}
+	return symbols[ref], nil
+}
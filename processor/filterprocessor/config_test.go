@@ -907,6 +907,27 @@ func TestLoadingConfigOTTL(t *testing.T) {
 		{
 			id: component.NewIDWithName(metadata.Type, "bad_syntax_log"),
 		},
+		{
+			id: component.NewIDWithName(metadata.Type, "with_functions"),
+			expected: &Config{
+				ErrorMode: ottl.PropagateError,
+				Traces: TraceFilters{
+					SpanConditions: []string{
+						`IsHealthCheck(attributes["http.route"])`,
+					},
+				},
+				Functions: []ottl.UserFunction{
+					{
+						Name:      "IsHealthCheck",
+						Params:    []string{"path"},
+						Condition: `path == "/health" or path == "/ready"`,
+					},
+				},
+			},
+		},
+		{
+			id: component.NewIDWithName(metadata.Type, "unknown_function_in_function"),
+		},
 	}
 
 	for _, tt := range tests {
@@ -38,7 +38,7 @@ func newFilterLogsProcessor(set processor.CreateSettings, cfg *Config) (*filterL
 	flp.telemetry = fpt
 
 	if cfg.Logs.LogConditions != nil {
-		skipExpr, errBoolExpr := filterottl.NewBoolExprForLog(cfg.Logs.LogConditions, filterottl.StandardLogFuncs(), cfg.ErrorMode, set.TelemetrySettings)
+		skipExpr, errBoolExpr := filterottl.NewBoolExprForLog(cfg.Logs.LogConditions, filterottl.StandardLogFuncs(), cfg.ErrorMode, set.TelemetrySettings, ottllog.WithUserFunctions(cfg.Functions))
 		if errBoolExpr != nil {
 			return nil, errBoolExpr
 		}
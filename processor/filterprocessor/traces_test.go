@@ -191,6 +191,7 @@ func TestFilterTraceProcessorWithOTTL(t *testing.T) {
 	tests := []struct {
 		name             string
 		conditions       TraceFilters
+		functions        []ottl.UserFunction
 		filterEverything bool
 		want             func(td ptrace.Traces)
 		errorMode        ottl.ErrorMode
@@ -260,10 +261,65 @@ func TestFilterTraceProcessorWithOTTL(t *testing.T) {
 			want:      func(td ptrace.Traces) {},
 			errorMode: ottl.IgnoreError,
 		},
+		{
+			name: "with user functions",
+			conditions: TraceFilters{
+				SpanEventConditions: []string{
+					`HasName(name, "spanEventA")`,
+				},
+			},
+			functions: []ottl.UserFunction{
+				{
+					Name:      "HasName",
+					Params:    []string{"value", "expected"},
+					Condition: `value == expected`,
+				},
+			},
+			want: func(td ptrace.Traces) {
+				td.ResourceSpans().At(0).ScopeSpans().At(0).Spans().At(1).Events().RemoveIf(func(event ptrace.SpanEvent) bool {
+					return event.Name() == "spanEventA"
+				})
+				td.ResourceSpans().At(0).ScopeSpans().At(1).Spans().At(1).Events().RemoveIf(func(event ptrace.SpanEvent) bool {
+					return event.Name() == "spanEventA"
+				})
+			},
+			errorMode: ottl.IgnoreError,
+		},
+		{
+			name: "with user functions of one context",
+			conditions: TraceFilters{
+				SpanConditions: []string{
+					`IsInternal()`,
+				},
+				SpanEventConditions: []string{
+					`name == "spanEventA"`,
+				},
+			},
+			functions: []ottl.UserFunction{
+				{
+					Name:      "IsInternal",
+					Condition: `kind == SPAN_KIND_INTERNAL`,
+				},
+			},
+			want: func(td ptrace.Traces) {
+				for i := 0; i < td.ResourceSpans().At(0).ScopeSpans().Len(); i++ {
+					spans := td.ResourceSpans().At(0).ScopeSpans().At(i).Spans()
+					spans.RemoveIf(func(span ptrace.Span) bool {
+						return span.Name() == "operationA"
+					})
+					spans.At(0).Events().RemoveIf(func(event ptrace.SpanEvent) bool {
+						return event.Name() == "spanEventA"
+					})
+				}
+			},
+			errorMode: ottl.PropagateError,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			processor, err := newFilterSpansProcessor(processortest.NewNopCreateSettings(), &Config{Traces: tt.conditions, ErrorMode: tt.errorMode})
+			cfg := &Config{Traces: tt.conditions, Functions: tt.functions, ErrorMode: tt.errorMode}
+			assert.NoError(t, cfg.Validate())
+			processor, err := newFilterSpansProcessor(processortest.NewNopCreateSettings(), cfg)
 			assert.NoError(t, err)
 
 			got, err := processor.processTraces(context.Background(), constructTraces())
@@ -57,6 +57,11 @@ type Config struct {
 	// Table contains the routing table for this processor.
 	// Required.
 	Table []RoutingTableItem `mapstructure:"table"`
+
+	// Functions are the functions defined in the configuration, which can be
+	// called from the OTTL statements of the routing table.
+	// Optional.
+	Functions []ottl.UserFunction `mapstructure:"functions"`
 }
 
 // Validate checks if the processor configuration is valid.
@@ -168,5 +173,6 @@ func rewriteRoutingEntriesToOTTL(cfg *Config) *Config {
 	return &Config{
 		DefaultExporters: cfg.DefaultExporters,
 		Table:            table,
+		Functions:        cfg.Functions,
 	}
 }
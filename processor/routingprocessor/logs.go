@@ -38,7 +38,7 @@ type logProcessor struct {
 func newLogProcessor(settings component.TelemetrySettings, config component.Config) (*logProcessor, error) {
 	cfg := rewriteRoutingEntriesToOTTL(config.(*Config))
 
-	logParser, err := ottllog.NewParser(common.Functions[ottllog.TransformContext](), settings)
+	logParser, err := ottllog.NewParser(common.Functions[ottllog.TransformContext](), settings, ottllog.WithUserFunctions(cfg.Functions))
 	if err != nil {
 		return nil, err
 	}
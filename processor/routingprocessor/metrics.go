@@ -38,7 +38,7 @@ type metricsProcessor struct {
 func newMetricProcessor(settings component.TelemetrySettings, config component.Config) (*metricsProcessor, error) {
 	cfg := rewriteRoutingEntriesToOTTL(config.(*Config))
 
-	dataPointParser, err := ottldatapoint.NewParser(common.Functions[ottldatapoint.TransformContext](), settings)
+	dataPointParser, err := ottldatapoint.NewParser(common.Functions[ottldatapoint.TransformContext](), settings, ottldatapoint.WithUserFunctions(cfg.Functions))
 	if err != nil {
 		return nil, err
 	}
@@ -38,7 +38,7 @@ type tracesProcessor struct {
 func newTracesProcessor(settings component.TelemetrySettings, config component.Config) (*tracesProcessor, error) {
 	cfg := rewriteRoutingEntriesToOTTL(config.(*Config))
 
-	spanParser, err := ottlspan.NewParser(common.Functions[ottlspan.TransformContext](), settings)
+	spanParser, err := ottlspan.NewParser(common.Functions[ottlspan.TransformContext](), settings, ottlspan.WithUserFunctions(cfg.Functions))
 	if err != nil {
 		return nil, err
 	}
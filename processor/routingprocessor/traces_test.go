@@ -17,6 +17,8 @@ import (
 	"go.opentelemetry.io/collector/exporter/otlpexporter"
 	"go.opentelemetry.io/collector/pdata/ptrace"
 	"google.golang.org/grpc/metadata"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/pkg/ottl"
 )
 
 func TestTraces_RegisterExportersForValidRoute(t *testing.T) {
@@ -499,6 +501,52 @@ func TestTracesAttributeWithOTTLDoesNotCauseCrash(t *testing.T) {
 
 }
 
+func TestTracesRoutingWithUserFunctions(t *testing.T) {
+	defaultExp := &mockTracesExporter{}
+	firstExp := &mockTracesExporter{}
+
+	host := newMockHost(map[component.DataType]map[component.ID]component.Component{
+		component.DataTypeTraces: {
+			component.MustNewID("otlp"):              defaultExp,
+			component.MustNewIDWithName("otlp", "1"): firstExp,
+		},
+	})
+
+	exp, err := newTracesProcessor(noopTelemetrySettings, &Config{
+		DefaultExporters: []string{"otlp"},
+		Table: []RoutingTableItem{
+			{
+				Statement: `route() where IsBetween(resource.attributes["value"], 0, 4)`,
+				Exporters: []string{"otlp/1"},
+			},
+		},
+		Functions: []ottl.UserFunction{
+			{
+				Name:      "IsBetween",
+				Params:    []string{"value", "low", "high"},
+				Condition: `value > low and value < high`,
+			},
+		},
+	})
+	require.NoError(t, err)
+	require.NoError(t, exp.Start(context.Background(), host))
+
+	tr := ptrace.NewTraces()
+	rl := tr.ResourceSpans().AppendEmpty()
+	rl.Resource().Attributes().PutInt("value", 2)
+	rl.ScopeSpans().AppendEmpty().Spans().AppendEmpty().SetName("span")
+	rl = tr.ResourceSpans().AppendEmpty()
+	rl.Resource().Attributes().PutInt("value", 10)
+	rl.ScopeSpans().AppendEmpty().Spans().AppendEmpty().SetName("span1")
+
+	require.NoError(t, exp.ConsumeTraces(context.Background(), tr))
+
+	require.Len(t, defaultExp.AllTraces(), 1)
+	require.Len(t, firstExp.AllTraces(), 1)
+	assert.Equal(t, "span", firstExp.AllTraces()[0].ResourceSpans().At(0).ScopeSpans().At(0).Spans().At(0).Name())
+	assert.Equal(t, "span1", defaultExp.AllTraces()[0].ResourceSpans().At(0).ScopeSpans().At(0).Spans().At(0).Name())
+}
+
 func TestTraceProcessorCapabilities(t *testing.T) {
 	// prepare
 	config := &Config{
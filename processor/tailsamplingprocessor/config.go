@@ -218,6 +218,9 @@ type OTTLConditionCfg struct {
 	ErrorMode           ottl.ErrorMode `mapstructure:"error_mode"`
 	SpanConditions      []string       `mapstructure:"span"`
 	SpanEventConditions []string       `mapstructure:"spanevent"`
+	// Functions are the functions defined in the configuration, which can be
+	// called from the conditions.
+	Functions []ottl.UserFunction `mapstructure:"functions"`
 }
 
 // Config holds the configuration for tail-based sampling.
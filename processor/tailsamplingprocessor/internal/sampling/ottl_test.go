@@ -8,6 +8,7 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"go.opentelemetry.io/collector/component/componenttest"
 	"go.opentelemetry.io/collector/pdata/pcommon"
 	"go.opentelemetry.io/collector/pdata/ptrace"
@@ -87,7 +88,7 @@ func TestEvaluate_OTTL(t *testing.T) {
 
 	for _, c := range cases {
 		t.Run(c.Desc, func(t *testing.T) {
-			filter, err := NewOTTLConditionFilter(componenttest.NewNopTelemetrySettings(), c.SpanConditions, c.SpanEventConditions, ottl.IgnoreError)
+			filter, err := NewOTTLConditionFilter(componenttest.NewNopTelemetrySettings(), c.SpanConditions, c.SpanEventConditions, nil, ottl.IgnoreError)
 			assert.Equal(t, err != nil, c.WantErr)
 
 			if err == nil {
@@ -127,3 +128,25 @@ func newTraceWithSpansAttributes(spans []spanWithAttributes) *TraceData {
 		ReceivedBatches: traces,
 	}
 }
+
+func TestEvaluate_OTTLUserFunctions(t *testing.T) {
+	traceID := pcommon.TraceID([16]byte{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16})
+	functions := []ottl.UserFunction{
+		{
+			Name:      "HasValue",
+			Params:    []string{"attrs", "value"},
+			Condition: `attrs["attr_k_1"] == value`,
+		},
+	}
+
+	filter, err := NewOTTLConditionFilter(componenttest.NewNopTelemetrySettings(), []string{`HasValue(attributes, "attr_v_1")`}, nil, functions, ottl.IgnoreError)
+	require.NoError(t, err)
+
+	decision, err := filter.Evaluate(context.Background(), traceID, newTraceWithSpansAttributes([]spanWithAttributes{{SpanAttributes: map[string]string{"attr_k_1": "attr_v_1"}}}))
+	assert.NoError(t, err)
+	assert.Equal(t, Sampled, decision)
+
+	decision, err = filter.Evaluate(context.Background(), traceID, newTraceWithSpansAttributes([]spanWithAttributes{{SpanAttributes: map[string]string{"attr_k_1": "attr_v_2"}}}))
+	assert.NoError(t, err)
+	assert.Equal(t, NotSampled, decision)
+}
@@ -92,6 +92,46 @@ func TestLoadConfig(t *testing.T) {
 				LogStatements:    []common.ContextStatements{},
 			},
 		},
+		{
+			id: component.NewIDWithName(metadata.Type, "with_functions"),
+			expected: &Config{
+				ErrorMode: ottl.PropagateError,
+				TraceStatements: []common.ContextStatements{
+					{
+						Context: "span",
+						Statements: []string{
+							`normalize_http(attributes) where not IsHealthCheck(attributes["http.path"])`,
+						},
+					},
+					{
+						Context: "resource",
+						Statements: []string{
+							`normalize_http(attributes)`,
+						},
+					},
+				},
+				MetricStatements: []common.ContextStatements{},
+				LogStatements:    []common.ContextStatements{},
+				Functions: []ottl.UserFunction{
+					{
+						Name:   "normalize_http",
+						Params: []string{"attrs"},
+						Statements: []string{
+							`set(attrs["http.request.method"], attrs["http.method"]) where attrs["http.method"] != nil`,
+							`delete_key(attrs, "http.method")`,
+						},
+					},
+					{
+						Name:      "IsHealthCheck",
+						Params:    []string{"path"},
+						Condition: `IsMatch(path, "^/health$")`,
+					},
+				},
+			},
+		},
+		{
+			id: component.NewIDWithName(metadata.Type, "unknown_function_in_function"),
+		},
 		{
 			id: component.NewIDWithName(metadata.Type, "bad_syntax_trace"),
 		},
@@ -12,8 +12,6 @@ import (
 
 	"github.com/open-telemetry/opentelemetry-collector-contrib/pkg/ottl"
 	"github.com/open-telemetry/opentelemetry-collector-contrib/pkg/ottl/contexts/ottllog"
-	"github.com/open-telemetry/opentelemetry-collector-contrib/pkg/ottl/contexts/ottlresource"
-	"github.com/open-telemetry/opentelemetry-collector-contrib/pkg/ottl/contexts/ottlscope"
 )
 
 var _ consumer.Logs = &logStatements{}
@@ -48,18 +46,15 @@ func (l logStatements) ConsumeLogs(ctx context.Context, ld plog.Logs) error {
 
 type LogParserCollection struct {
 	parserCollection
-	logParser ottl.Parser[ottllog.TransformContext]
+	logParser    ottl.Parser[ottllog.TransformContext]
+	logFunctions map[string]ottl.Factory[ottllog.TransformContext]
 }
 
 type LogParserCollectionOption func(*LogParserCollection) error
 
 func WithLogParser(functions map[string]ottl.Factory[ottllog.TransformContext]) LogParserCollectionOption {
 	return func(lp *LogParserCollection) error {
-		logParser, err := ottllog.NewParser(functions, lp.settings)
-		if err != nil {
-			return err
-		}
-		lp.logParser = logParser
+		lp.logFunctions = functions
 		return nil
 	}
 }
@@ -71,20 +66,18 @@ func WithLogErrorMode(errorMode ottl.ErrorMode) LogParserCollectionOption {
 	}
 }
 
-func NewLogParserCollection(settings component.TelemetrySettings, options ...LogParserCollectionOption) (*LogParserCollection, error) {
-	rp, err := ottlresource.NewParser(ResourceFunctions(), settings)
-	if err != nil {
-		return nil, err
-	}
-	sp, err := ottlscope.NewParser(ScopeFunctions(), settings)
-	if err != nil {
-		return nil, err
+// WithLogUserFunctions adds the functions defined in the configuration to the parsers.
+func WithLogUserFunctions(functions []ottl.UserFunction) LogParserCollectionOption {
+	return func(lp *LogParserCollection) error {
+		lp.userFunctions = functions
+		return nil
 	}
+}
+
+func NewLogParserCollection(settings component.TelemetrySettings, options ...LogParserCollectionOption) (*LogParserCollection, error) {
 	lpc := &LogParserCollection{
 		parserCollection: parserCollection{
-			settings:       settings,
-			resourceParser: rp,
-			scopeParser:    sp,
+			settings: settings,
 		},
 	}
 
@@ -95,6 +88,17 @@ func NewLogParserCollection(settings component.TelemetrySettings, options ...Log
 		}
 	}
 
+	err := lpc.newParsers()
+	if err != nil {
+		return nil, err
+	}
+	if lpc.logFunctions != nil {
+		lpc.logParser, err = ottllog.NewParser(lpc.logFunctions, settings, ottllog.WithUserFunctions(lpc.userFunctions))
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	return lpc, nil
 }
 
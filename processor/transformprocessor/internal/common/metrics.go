@@ -14,8 +14,6 @@ import (
 	"github.com/open-telemetry/opentelemetry-collector-contrib/pkg/ottl"
 	"github.com/open-telemetry/opentelemetry-collector-contrib/pkg/ottl/contexts/ottldatapoint"
 	"github.com/open-telemetry/opentelemetry-collector-contrib/pkg/ottl/contexts/ottlmetric"
-	"github.com/open-telemetry/opentelemetry-collector-contrib/pkg/ottl/contexts/ottlresource"
-	"github.com/open-telemetry/opentelemetry-collector-contrib/pkg/ottl/contexts/ottlscope"
 )
 
 var _ consumer.Metrics = &metricStatements{}
@@ -137,30 +135,24 @@ func (d dataPointStatements) handleSummaryDataPoints(ctx context.Context, dps pm
 
 type MetricParserCollection struct {
 	parserCollection
-	metricParser    ottl.Parser[ottlmetric.TransformContext]
-	dataPointParser ottl.Parser[ottldatapoint.TransformContext]
+	metricParser       ottl.Parser[ottlmetric.TransformContext]
+	dataPointParser    ottl.Parser[ottldatapoint.TransformContext]
+	metricFunctions    map[string]ottl.Factory[ottlmetric.TransformContext]
+	dataPointFunctions map[string]ottl.Factory[ottldatapoint.TransformContext]
 }
 
 type MetricParserCollectionOption func(*MetricParserCollection) error
 
 func WithMetricParser(functions map[string]ottl.Factory[ottlmetric.TransformContext]) MetricParserCollectionOption {
 	return func(mp *MetricParserCollection) error {
-		metricParser, err := ottlmetric.NewParser(functions, mp.settings)
-		if err != nil {
-			return err
-		}
-		mp.metricParser = metricParser
+		mp.metricFunctions = functions
 		return nil
 	}
 }
 
 func WithDataPointParser(functions map[string]ottl.Factory[ottldatapoint.TransformContext]) MetricParserCollectionOption {
 	return func(mp *MetricParserCollection) error {
-		dataPointParser, err := ottldatapoint.NewParser(functions, mp.settings)
-		if err != nil {
-			return err
-		}
-		mp.dataPointParser = dataPointParser
+		mp.dataPointFunctions = functions
 		return nil
 	}
 }
@@ -172,20 +164,18 @@ func WithMetricErrorMode(errorMode ottl.ErrorMode) MetricParserCollectionOption
 	}
 }
 
-func NewMetricParserCollection(settings component.TelemetrySettings, options ...MetricParserCollectionOption) (*MetricParserCollection, error) {
-	rp, err := ottlresource.NewParser(ResourceFunctions(), settings)
-	if err != nil {
-		return nil, err
-	}
-	sp, err := ottlscope.NewParser(ScopeFunctions(), settings)
-	if err != nil {
-		return nil, err
+// WithMetricUserFunctions adds the functions defined in the configuration to the parsers.
+func WithMetricUserFunctions(functions []ottl.UserFunction) MetricParserCollectionOption {
+	return func(mp *MetricParserCollection) error {
+		mp.userFunctions = functions
+		return nil
 	}
+}
+
+func NewMetricParserCollection(settings component.TelemetrySettings, options ...MetricParserCollectionOption) (*MetricParserCollection, error) {
 	mpc := &MetricParserCollection{
 		parserCollection: parserCollection{
-			settings:       settings,
-			resourceParser: rp,
-			scopeParser:    sp,
+			settings: settings,
 		},
 	}
 
@@ -196,6 +186,23 @@ func NewMetricParserCollection(settings component.TelemetrySettings, options ...
 		}
 	}
 
+	err := mpc.newParsers()
+	if err != nil {
+		return nil, err
+	}
+	if mpc.metricFunctions != nil {
+		mpc.metricParser, err = ottlmetric.NewParser(mpc.metricFunctions, settings, ottlmetric.WithUserFunctions(mpc.userFunctions))
+		if err != nil {
+			return nil, err
+		}
+	}
+	if mpc.dataPointFunctions != nil {
+		mpc.dataPointParser, err = ottldatapoint.NewParser(mpc.dataPointFunctions, settings, ottldatapoint.WithUserFunctions(mpc.userFunctions))
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	return mpc, nil
 }
 
@@ -134,6 +134,19 @@ type parserCollection struct {
 	resourceParser ottl.Parser[ottlresource.TransformContext]
 	scopeParser    ottl.Parser[ottlscope.TransformContext]
 	errorMode      ottl.ErrorMode
+	userFunctions  []ottl.UserFunction
+}
+
+// newParsers creates the resource and scope parsers, with the functions
+// defined in the configuration.
+func (pc *parserCollection) newParsers() error {
+	var err error
+	pc.resourceParser, err = ottlresource.NewParser(ResourceFunctions(), pc.settings, ottlresource.WithUserFunctions(pc.userFunctions))
+	if err != nil {
+		return err
+	}
+	pc.scopeParser, err = ottlscope.NewParser(ScopeFunctions(), pc.settings, ottlscope.WithUserFunctions(pc.userFunctions))
+	return err
 }
 
 type baseContext interface {
@@ -11,8 +11,6 @@ import (
 	"go.opentelemetry.io/collector/pdata/ptrace"
 
 	"github.com/open-telemetry/opentelemetry-collector-contrib/pkg/ottl"
-	"github.com/open-telemetry/opentelemetry-collector-contrib/pkg/ottl/contexts/ottlresource"
-	"github.com/open-telemetry/opentelemetry-collector-contrib/pkg/ottl/contexts/ottlscope"
 	"github.com/open-telemetry/opentelemetry-collector-contrib/pkg/ottl/contexts/ottlspan"
 	"github.com/open-telemetry/opentelemetry-collector-contrib/pkg/ottl/contexts/ottlspanevent"
 )
@@ -83,30 +81,24 @@ func (s spanEventStatements) ConsumeTraces(ctx context.Context, td ptrace.Traces
 
 type TraceParserCollection struct {
 	parserCollection
-	spanParser      ottl.Parser[ottlspan.TransformContext]
-	spanEventParser ottl.Parser[ottlspanevent.TransformContext]
+	spanParser         ottl.Parser[ottlspan.TransformContext]
+	spanEventParser    ottl.Parser[ottlspanevent.TransformContext]
+	spanFunctions      map[string]ottl.Factory[ottlspan.TransformContext]
+	spanEventFunctions map[string]ottl.Factory[ottlspanevent.TransformContext]
 }
 
 type TraceParserCollectionOption func(*TraceParserCollection) error
 
 func WithSpanParser(functions map[string]ottl.Factory[ottlspan.TransformContext]) TraceParserCollectionOption {
 	return func(tp *TraceParserCollection) error {
-		spanParser, err := ottlspan.NewParser(functions, tp.settings)
-		if err != nil {
-			return err
-		}
-		tp.spanParser = spanParser
+		tp.spanFunctions = functions
 		return nil
 	}
 }
 
 func WithSpanEventParser(functions map[string]ottl.Factory[ottlspanevent.TransformContext]) TraceParserCollectionOption {
 	return func(tp *TraceParserCollection) error {
-		spanEventParser, err := ottlspanevent.NewParser(functions, tp.settings)
-		if err != nil {
-			return err
-		}
-		tp.spanEventParser = spanEventParser
+		tp.spanEventFunctions = functions
 		return nil
 	}
 }
@@ -118,20 +110,18 @@ func WithTraceErrorMode(errorMode ottl.ErrorMode) TraceParserCollectionOption {
 	}
 }
 
-func NewTraceParserCollection(settings component.TelemetrySettings, options ...TraceParserCollectionOption) (*TraceParserCollection, error) {
-	rp, err := ottlresource.NewParser(ResourceFunctions(), settings)
-	if err != nil {
-		return nil, err
-	}
-	sp, err := ottlscope.NewParser(ScopeFunctions(), settings)
-	if err != nil {
-		return nil, err
+// WithTraceUserFunctions adds the functions defined in the configuration to the parsers.
+func WithTraceUserFunctions(functions []ottl.UserFunction) TraceParserCollectionOption {
+	return func(tp *TraceParserCollection) error {
+		tp.userFunctions = functions
+		return nil
 	}
+}
+
+func NewTraceParserCollection(settings component.TelemetrySettings, options ...TraceParserCollectionOption) (*TraceParserCollection, error) {
 	tpc := &TraceParserCollection{
 		parserCollection: parserCollection{
-			settings:       settings,
-			resourceParser: rp,
-			scopeParser:    sp,
+			settings: settings,
 		},
 	}
 
@@ -142,6 +132,25 @@ func NewTraceParserCollection(settings component.TelemetrySettings, options ...T
 		}
 	}
 
+	// The parsers are created once the user functions are known, so that
+	// the errors in their definitions are returned.
+	err := tpc.newParsers()
+	if err != nil {
+		return nil, err
+	}
+	if tpc.spanFunctions != nil {
+		tpc.spanParser, err = ottlspan.NewParser(tpc.spanFunctions, settings, ottlspan.WithUserFunctions(tpc.userFunctions))
+		if err != nil {
+			return nil, err
+		}
+	}
+	if tpc.spanEventFunctions != nil {
+		tpc.spanEventParser, err = ottlspanevent.NewParser(tpc.spanEventFunctions, settings, ottlspanevent.WithUserFunctions(tpc.userFunctions))
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	return tpc, nil
 }
 
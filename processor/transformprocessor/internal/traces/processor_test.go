@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"go.opentelemetry.io/collector/component/componenttest"
 	"go.opentelemetry.io/collector/pdata/pcommon"
 	"go.opentelemetry.io/collector/pdata/ptrace"
@@ -50,7 +51,7 @@ func Test_ProcessTraces_ResourceContext(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.statement, func(t *testing.T) {
 			td := constructTraces()
-			processor, err := NewProcessor([]common.ContextStatements{{Context: "resource", Statements: []string{tt.statement}}}, ottl.IgnoreError, componenttest.NewNopTelemetrySettings())
+			processor, err := NewProcessor([]common.ContextStatements{{Context: "resource", Statements: []string{tt.statement}}}, ottl.IgnoreError, nil, componenttest.NewNopTelemetrySettings())
 			assert.NoError(t, err)
 
 			_, err = processor.ProcessTraces(context.Background(), td)
@@ -85,7 +86,7 @@ func Test_ProcessTraces_ScopeContext(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.statement, func(t *testing.T) {
 			td := constructTraces()
-			processor, err := NewProcessor([]common.ContextStatements{{Context: "scope", Statements: []string{tt.statement}}}, ottl.IgnoreError, componenttest.NewNopTelemetrySettings())
+			processor, err := NewProcessor([]common.ContextStatements{{Context: "scope", Statements: []string{tt.statement}}}, ottl.IgnoreError, nil, componenttest.NewNopTelemetrySettings())
 			assert.NoError(t, err)
 
 			_, err = processor.ProcessTraces(context.Background(), td)
@@ -379,7 +380,7 @@ func Test_ProcessTraces_TraceContext(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.statement, func(t *testing.T) {
 			td := constructTraces()
-			processor, err := NewProcessor([]common.ContextStatements{{Context: "span", Statements: []string{tt.statement}}}, ottl.IgnoreError, componenttest.NewNopTelemetrySettings())
+			processor, err := NewProcessor([]common.ContextStatements{{Context: "span", Statements: []string{tt.statement}}}, ottl.IgnoreError, nil, componenttest.NewNopTelemetrySettings())
 			assert.NoError(t, err)
 
 			_, err = processor.ProcessTraces(context.Background(), td)
@@ -393,6 +394,78 @@ func Test_ProcessTraces_TraceContext(t *testing.T) {
 	}
 }
 
+func Test_ProcessTraces_UserFunctions(t *testing.T) {
+	functions := []ottl.UserFunction{
+		{
+			Name:   "rename_method",
+			Params: []string{"attrs"},
+			Statements: []string{
+				`set(attrs["http.request.method"], attrs["http.method"])`,
+				`delete_key(attrs, "http.method")`,
+			},
+		},
+		{
+			Name:      "IsOperation",
+			Params:    []string{"span_name", "suffix"},
+			Condition: `span_name == Concat(["operation", suffix], "")`,
+		},
+	}
+	contextStatements := []common.ContextStatements{
+		{Context: "span", Statements: []string{`rename_method(attributes) where IsOperation(name, "A")`}},
+		{Context: "resource", Statements: []string{`rename_method(attributes)`}},
+	}
+
+	td := constructTraces()
+	processor, err := NewProcessor(contextStatements, ottl.PropagateError, functions, componenttest.NewNopTelemetrySettings())
+	require.NoError(t, err)
+	_, err = processor.ProcessTraces(context.Background(), td)
+	require.NoError(t, err)
+
+	exTd := constructTraces()
+	attrs := exTd.ResourceSpans().At(0).ScopeSpans().At(0).Spans().At(0).Attributes()
+	attrs.PutStr("http.request.method", "get")
+	attrs.Remove("http.method")
+	assert.Equal(t, exTd, td)
+
+	_, err = NewProcessor([]common.ContextStatements{{Context: "span", Statements: []string{`rename_method("name")`}}}, ottl.PropagateError, functions, componenttest.NewNopTelemetrySettings())
+	assert.ErrorContains(t, err, `parameter "attrs" can only be indexed if its argument is a path or a converter`)
+}
+
+func Test_ProcessTraces_ContextUserFunctions(t *testing.T) {
+	// mark_error uses paths of the span context only, and the resource and
+	// scope parsers are created with it.
+	functions := []ottl.UserFunction{
+		{
+			Name:   "mark_error",
+			Params: []string{"msg"},
+			Statements: []string{
+				`set(status.code, 2)`,
+				`set(status.message, msg)`,
+			},
+		},
+	}
+	contextStatements := []common.ContextStatements{
+		{Context: "resource", Statements: []string{`set(attributes["host.name"], "localhost")`}},
+		{Context: "span", Statements: []string{`mark_error("failed") where name == "operationA"`}},
+	}
+
+	td := constructTraces()
+	processor, err := NewProcessor(contextStatements, ottl.PropagateError, functions, componenttest.NewNopTelemetrySettings())
+	require.NoError(t, err)
+	_, err = processor.ProcessTraces(context.Background(), td)
+	require.NoError(t, err)
+
+	exTd := constructTraces()
+	exTd.ResourceSpans().At(0).Resource().Attributes().PutStr("host.name", "localhost")
+	status := exTd.ResourceSpans().At(0).ScopeSpans().At(0).Spans().At(0).Status()
+	status.SetCode(ptrace.StatusCodeError)
+	status.SetMessage("failed")
+	assert.Equal(t, exTd, td)
+
+	_, err = NewProcessor([]common.ContextStatements{{Context: "resource", Statements: []string{`mark_error("failed")`}}}, ottl.PropagateError, functions, componenttest.NewNopTelemetrySettings())
+	assert.ErrorContains(t, err, `error in statement "set(status.code, 2)" of function "mark_error"`)
+}
+
 func Test_ProcessTraces_SpanEventContext(t *testing.T) {
 	tests := []struct {
 		statement string
@@ -409,7 +482,7 @@ func Test_ProcessTraces_SpanEventContext(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.statement, func(t *testing.T) {
 			td := constructTraces()
-			processor, err := NewProcessor([]common.ContextStatements{{Context: "spanevent", Statements: []string{tt.statement}}}, ottl.IgnoreError, componenttest.NewNopTelemetrySettings())
+			processor, err := NewProcessor([]common.ContextStatements{{Context: "spanevent", Statements: []string{tt.statement}}}, ottl.IgnoreError, nil, componenttest.NewNopTelemetrySettings())
 			assert.NoError(t, err)
 
 			_, err = processor.ProcessTraces(context.Background(), td)
@@ -526,7 +599,7 @@ func Test_ProcessTraces_MixContext(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			td := constructTraces()
-			processor, err := NewProcessor(tt.contextStatments, ottl.IgnoreError, componenttest.NewNopTelemetrySettings())
+			processor, err := NewProcessor(tt.contextStatments, ottl.IgnoreError, nil, componenttest.NewNopTelemetrySettings())
 			assert.NoError(t, err)
 
 			_, err = processor.ProcessTraces(context.Background(), td)
@@ -562,7 +635,7 @@ func Test_ProcessTraces_Error(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(string(tt.context), func(t *testing.T) {
 			td := constructTraces()
-			processor, err := NewProcessor([]common.ContextStatements{{Context: tt.context, Statements: []string{`set(attributes["test"], ParseJSON(1))`}}}, ottl.PropagateError, componenttest.NewNopTelemetrySettings())
+			processor, err := NewProcessor([]common.ContextStatements{{Context: tt.context, Statements: []string{`set(attributes["test"], ParseJSON(1))`}}}, ottl.PropagateError, nil, componenttest.NewNopTelemetrySettings())
 			assert.NoError(t, err)
 
 			_, err = processor.ProcessTraces(context.Background(), td)
@@ -607,7 +680,7 @@ func BenchmarkTwoSpans(b *testing.B) {
 
 	for _, tt := range tests {
 		b.Run(tt.name, func(b *testing.B) {
-			processor, err := NewProcessor([]common.ContextStatements{{Context: "span", Statements: tt.statements}}, ottl.IgnoreError, componenttest.NewNopTelemetrySettings())
+			processor, err := NewProcessor([]common.ContextStatements{{Context: "span", Statements: tt.statements}}, ottl.IgnoreError, nil, componenttest.NewNopTelemetrySettings())
 			assert.NoError(b, err)
 			b.ResetTimer()
 			for n := 0; n < b.N; n++ {
@@ -649,7 +722,7 @@ func BenchmarkHundredSpans(b *testing.B) {
 	}
 	for _, tt := range tests {
 		b.Run(tt.name, func(b *testing.B) {
-			processor, err := NewProcessor([]common.ContextStatements{{Context: "span", Statements: tt.statements}}, ottl.IgnoreError, componenttest.NewNopTelemetrySettings())
+			processor, err := NewProcessor([]common.ContextStatements{{Context: "span", Statements: tt.statements}}, ottl.IgnoreError, nil, componenttest.NewNopTelemetrySettings())
 			assert.NoError(b, err)
 			b.ResetTimer()
 			for n := 0; n < b.N; n++ {
@@ -22,7 +22,15 @@ var useCreatedMetricGate = featuregate.GlobalRegistry().MustRegister(
 	"receiver.prometheusreceiver.UseCreatedMetric",
 	featuregate.StageAlpha,
 	featuregate.WithRegisterDescription("When enabled, the Prometheus receiver will"+
-		" retrieve the start time for Summary, Histogram and Sum metrics from _created metric"),
+		" retrieve the start time for Summary, Histogram and Sum metrics from _created metric"+
+		" and the created timestamp of the protobuf format"),
+)
+
+var enableNativeHistogramsGate = featuregate.GlobalRegistry().MustRegister(
+	"receiver.prometheusreceiver.EnableNativeHistograms",
+	featuregate.StageAlpha,
+	featuregate.WithRegisterDescription("When enabled, the Prometheus receiver will convert"+
+		" Prometheus native histograms to exponential histograms"),
 )
 
 // NewFactory creates a new Prometheus receiver factory.
@@ -11,6 +11,7 @@ import (
 	"strings"
 
 	"github.com/prometheus/prometheus/model/exemplar"
+	"github.com/prometheus/prometheus/model/histogram"
 	"github.com/prometheus/prometheus/model/labels"
 	"github.com/prometheus/prometheus/model/value"
 	"github.com/prometheus/prometheus/scrape"
@@ -51,6 +52,8 @@ type metricGroup struct {
 	value        float64
 	complexValue []*dataPoint
 	exemplars    pmetric.ExemplarSlice
+	// hValue is the native histogram of exponential histograms.
+	hValue *histogram.FloatHistogram
 }
 
 func newMetricFamily(metricName string, mc scrape.MetricMetadataStore, logger *zap.Logger) *metricFamily {
@@ -156,6 +159,66 @@ func (mg *metricGroup) toDistributionPoint(dest pmetric.HistogramDataPointSlice)
 	mg.setExemplars(point.Exemplars())
 }
 
+func (mg *metricGroup) toExponentialHistogramDataPoints(dest pmetric.ExponentialHistogramDataPointSlice) {
+	if mg.hValue == nil {
+		return
+	}
+	fh := mg.hValue
+
+	point := dest.AppendEmpty()
+	if value.IsStaleNaN(fh.Sum) {
+		point.SetFlags(pmetric.DefaultDataPointFlags.WithNoRecordedValue(true))
+	} else {
+		point.SetScale(fh.Schema)
+		point.SetCount(uint64(fh.Count))
+		point.SetSum(fh.Sum)
+		point.SetZeroThreshold(fh.ZeroThreshold)
+		point.SetZeroCount(uint64(fh.ZeroCount))
+		convertNativeBuckets(fh.PositiveSpans, fh.PositiveBuckets, point.Positive())
+		convertNativeBuckets(fh.NegativeSpans, fh.NegativeBuckets, point.Negative())
+	}
+
+	// The timestamp MUST be in retrieved from milliseconds and converted to nanoseconds.
+	tsNanos := timestampFromMs(mg.ts)
+	if mg.created != 0 {
+		point.SetStartTimestamp(timestampFromFloat64(mg.created))
+	} else {
+		// metrics_adjuster adjusts the startTimestamp to the initial scrape timestamp
+		point.SetStartTimestamp(tsNanos)
+	}
+	point.SetTimestamp(tsNanos)
+	populateAttributes(pmetric.MetricTypeExponentialHistogram, mg.ls, point.Attributes())
+	mg.setExemplars(point.Exemplars())
+}
+
+// convertNativeBuckets sets the buckets of an exponential histogram from the
+// spans and the absolute counts of the buckets of a native histogram. The
+// native bucket at index i has the upper bound base^i, while the exponential
+// histogram bucket at index i has the lower bound base^i, so the indexes are
+// shifted by one.
+func convertNativeBuckets(spans []histogram.Span, counts []float64, dest pmetric.ExponentialHistogramDataPointBuckets) {
+	if len(spans) == 0 {
+		return
+	}
+	dest.SetOffset(spans[0].Offset - 1)
+	bucketCounts := dest.BucketCounts()
+	bucketCounts.EnsureCapacity(len(counts))
+	i := 0
+	for j, span := range spans {
+		if j > 0 {
+			// The offset of the next spans is the number of empty buckets
+			// since the previous span.
+			for k := int32(0); k < span.Offset; k++ {
+				bucketCounts.Append(0)
+			}
+		}
+		for k := uint32(0); k < span.Length && i < len(counts); k++ {
+			bucketCounts.Append(uint64(counts[i]))
+			i++
+		}
+	}
+}
+
 func (mg *metricGroup) setExemplars(exemplars pmetric.ExemplarSlice) {
 	if mg == nil {
 		return
@@ -311,6 +374,29 @@ func (mf *metricFamily) addSeries(seriesRef uint64, metricName string, ls labels
 	return nil
 }
 
+func (mf *metricFamily) addExponentialHistogramSeries(seriesRef uint64, metricName string, ls labels.Labels, t int64, fh *histogram.FloatHistogram) error {
+	mg := mf.loadMetricGroupOrCreate(seriesRef, ls, t)
+	if mg.ts != t {
+		return fmt.Errorf("inconsistent timestamps on metric points for metric %v", metricName)
+	}
+	if mg.mtype != pmetric.MetricTypeExponentialHistogram {
+		return fmt.Errorf("metric type mismatch for exponential histogram metric %v type %s", metricName, mg.mtype.String())
+	}
+	mg.hValue = fh
+	return nil
+}
+
+// addCreationTimestamp sets the created time of the series, used as the start
+// timestamp of its data point like the value of a _created series.
+func (mf *metricFamily) addCreationTimestamp(seriesRef uint64, metricName string, ls labels.Labels, t, ctMs int64) error {
+	mg := mf.loadMetricGroupOrCreate(seriesRef, ls, t)
+	if mg.ts != t {
+		return fmt.Errorf("inconsistent timestamps on metric points for metric %v", metricName)
+	}
+	mg.created = float64(ctMs) / 1e3
+	return nil
+}
+
 func (mf *metricFamily) appendMetric(metrics pmetric.MetricSlice, trimSuffixes bool) {
 	metric := pmetric.NewMetric()
 	// Trims type and unit suffixes from metric name
@@ -326,9 +412,9 @@ func (mf *metricFamily) appendMetric(metrics pmetric.MetricSlice, trimSuffixes b
 
 	switch mf.mtype {
 	case pmetric.MetricTypeHistogram:
-		histogram := metric.SetEmptyHistogram()
-		histogram.SetAggregationTemporality(pmetric.AggregationTemporalityCumulative)
-		hdpL := histogram.DataPoints()
+		hist := metric.SetEmptyHistogram()
+		hist.SetAggregationTemporality(pmetric.AggregationTemporalityCumulative)
+		hdpL := hist.DataPoints()
 		for _, mg := range mf.groupOrders {
 			mg.toDistributionPoint(hdpL)
 		}
@@ -342,6 +428,15 @@ func (mf *metricFamily) appendMetric(metrics pmetric.MetricSlice, trimSuffixes b
 		}
 		pointCount = sdpL.Len()
 
+	case pmetric.MetricTypeExponentialHistogram:
+		expHistogram := metric.SetEmptyExponentialHistogram()
+		expHistogram.SetAggregationTemporality(pmetric.AggregationTemporalityCumulative)
+		hdpL := expHistogram.DataPoints()
+		for _, mg := range mf.groupOrders {
+			mg.toExponentialHistogramDataPoints(hdpL)
+		}
+		pointCount = hdpL.Len()
+
 	case pmetric.MetricTypeSum:
 		sum := metric.SetEmptySum()
 		sum.SetAggregationTemporality(pmetric.AggregationTemporalityCumulative)
@@ -352,7 +447,7 @@ func (mf *metricFamily) appendMetric(metrics pmetric.MetricSlice, trimSuffixes b
 		}
 		pointCount = sdpL.Len()
 
-	case pmetric.MetricTypeEmpty, pmetric.MetricTypeGauge, pmetric.MetricTypeExponentialHistogram:
+	case pmetric.MetricTypeEmpty, pmetric.MetricTypeGauge:
 		fallthrough
 	default: // Everything else should be set to a Gauge.
 		gauge := metric.SetEmptyGauge()
@@ -102,11 +102,16 @@ func (tsm *timeseriesMap) get(metric pmetric.Metric, kv pcommon.Map) (*timeserie
 		name:       name,
 		attributes: getAttributesSignature(kv),
 	}
-	if metric.Type() == pmetric.MetricTypeHistogram {
+	switch metric.Type() {
+	case pmetric.MetricTypeHistogram:
 		// There are 2 types of Histograms whose aggregation temporality needs distinguishing:
 		// * CumulativeHistogram
 		// * GaugeHistogram
 		key.aggTemporality = metric.Histogram().AggregationTemporality()
+	case pmetric.MetricTypeExponentialHistogram:
+		// Same as Histograms.
+		key.aggTemporality = metric.ExponentialHistogram().AggregationTemporality()
+	case pmetric.MetricTypeEmpty, pmetric.MetricTypeGauge, pmetric.MetricTypeSum, pmetric.MetricTypeSummary:
 	}
 
 	tsm.mark = true
@@ -279,13 +284,16 @@ func (a *initialPointAdjuster) AdjustMetrics(metrics pmetric.Metrics) error {
 				case pmetric.MetricTypeHistogram:
 					a.adjustMetricHistogram(tsm, metric)
 
+				case pmetric.MetricTypeExponentialHistogram:
+					a.adjustMetricExponentialHistogram(tsm, metric)
+
 				case pmetric.MetricTypeSummary:
 					a.adjustMetricSummary(tsm, metric)
 
 				case pmetric.MetricTypeSum:
 					a.adjustMetricSum(tsm, metric)
 
-				case pmetric.MetricTypeEmpty, pmetric.MetricTypeExponentialHistogram:
+				case pmetric.MetricTypeEmpty:
 					fallthrough
 
 				default:
@@ -309,7 +317,55 @@ func (a *initialPointAdjuster) adjustMetricHistogram(tsm *timeseriesMap, current
 	for i := 0; i < currentPoints.Len(); i++ {
 		currentDist := currentPoints.At(i)
 
-		// start timestamp was set from _created
+		// start timestamp was set from _created or the created timestamp
+		if a.useCreatedMetric &&
+			!currentDist.Flags().NoRecordedValue() &&
+			currentDist.StartTimestamp() < currentDist.Timestamp() {
+			continue
+		}
+
+		tsi, found := tsm.get(current, currentDist.Attributes())
+		if !found {
+			// initialize everything.
+			tsi.histogram.startTime = currentDist.StartTimestamp()
+			tsi.histogram.previousCount = currentDist.Count()
+			tsi.histogram.previousSum = currentDist.Sum()
+			continue
+		}
+
+		if currentDist.Flags().NoRecordedValue() {
+			// TODO: Investigate why this does not reset.
+			currentDist.SetStartTimestamp(tsi.histogram.startTime)
+			continue
+		}
+
+		if currentDist.Count() < tsi.histogram.previousCount || currentDist.Sum() < tsi.histogram.previousSum {
+			// reset re-initialize everything.
+			tsi.histogram.startTime = currentDist.StartTimestamp()
+			tsi.histogram.previousCount = currentDist.Count()
+			tsi.histogram.previousSum = currentDist.Sum()
+			continue
+		}
+
+		// Update only previous values.
+		tsi.histogram.previousCount = currentDist.Count()
+		tsi.histogram.previousSum = currentDist.Sum()
+		currentDist.SetStartTimestamp(tsi.histogram.startTime)
+	}
+}
+
+func (a *initialPointAdjuster) adjustMetricExponentialHistogram(tsm *timeseriesMap, current pmetric.Metric) {
+	histogram := current.ExponentialHistogram()
+	if histogram.AggregationTemporality() != pmetric.AggregationTemporalityCumulative {
+		// Only dealing with CumulativeDistributions.
+		return
+	}
+
+	currentPoints := histogram.DataPoints()
+	for i := 0; i < currentPoints.Len(); i++ {
+		currentDist := currentPoints.At(i)
+
+		// start timestamp was set from _created or the created timestamp
 		if a.useCreatedMetric &&
 			!currentDist.Flags().NoRecordedValue() &&
 			currentDist.StartTimestamp() < currentDist.Timestamp() {
@@ -351,7 +407,7 @@ func (a *initialPointAdjuster) adjustMetricSum(tsm *timeseriesMap, current pmetr
 	for i := 0; i < currentPoints.Len(); i++ {
 		currentSum := currentPoints.At(i)
 
-		// start timestamp was set from _created
+		// start timestamp was set from _created or the created timestamp
 		if a.useCreatedMetric &&
 			!currentSum.Flags().NoRecordedValue() &&
 			currentSum.StartTimestamp() < currentSum.Timestamp() {
@@ -391,7 +447,7 @@ func (a *initialPointAdjuster) adjustMetricSummary(tsm *timeseriesMap, current p
 	for i := 0; i < currentPoints.Len(); i++ {
 		currentSummary := currentPoints.At(i)
 
-		// start timestamp was set from _created
+		// start timestamp was set from _created or the created timestamp
 		if a.useCreatedMetric &&
 			!currentSummary.Flags().NoRecordedValue() &&
 			currentSummary.StartTimestamp() < currentSummary.Timestamp() {
@@ -212,6 +212,33 @@ func TestHistogram(t *testing.T) {
 	runScript(t, NewInitialPointAdjuster(zap.NewNop(), time.Minute, true), "job", "0", script)
 }
 
+func TestExponentialHistogram(t *testing.T) {
+	script := []*metricsAdjusterTest{
+		{
+			description: "Exponential Histogram: round 1 - initial instance, start time is established",
+			metrics:     metrics(exponentialHistogramMetric(histogram1, exponentialHistogramPoint(k1v1k2v2, t1, t1, 3, -1, []uint64{4, 2, 3, 7}))),
+			adjusted:    metrics(exponentialHistogramMetric(histogram1, exponentialHistogramPoint(k1v1k2v2, t1, t1, 3, -1, []uint64{4, 2, 3, 7}))),
+		}, {
+			description: "Exponential Histogram: round 2 - instance adjusted based on round 1",
+			metrics:     metrics(exponentialHistogramMetric(histogram1, exponentialHistogramPoint(k1v1k2v2, t2, t2, 3, -1, []uint64{6, 3, 4, 8}))),
+			adjusted:    metrics(exponentialHistogramMetric(histogram1, exponentialHistogramPoint(k1v1k2v2, t1, t2, 3, -1, []uint64{6, 3, 4, 8}))),
+		}, {
+			description: "Exponential Histogram: round 3 - instance reset (value less than previous value), start time is reset",
+			metrics:     metrics(exponentialHistogramMetric(histogram1, exponentialHistogramPoint(k1v1k2v2, t3, t3, 3, -1, []uint64{5, 3, 2, 7}))),
+			adjusted:    metrics(exponentialHistogramMetric(histogram1, exponentialHistogramPoint(k1v1k2v2, t3, t3, 3, -1, []uint64{5, 3, 2, 7}))),
+		}, {
+			description: "Exponential Histogram: round 4 - instance adjusted based on round 3",
+			metrics:     metrics(exponentialHistogramMetric(histogram1, exponentialHistogramPoint(k1v1k2v2, t4, t4, 3, -1, []uint64{7, 4, 2, 12}))),
+			adjusted:    metrics(exponentialHistogramMetric(histogram1, exponentialHistogramPoint(k1v1k2v2, t3, t4, 3, -1, []uint64{7, 4, 2, 12}))),
+		}, {
+			description: "Exponential Histogram: round 5 - instance adjusted based on round 3",
+			metrics:     metrics(exponentialHistogramMetric(histogram1, exponentialHistogramPointNoValue(k1v1k2v2, tUnknown, t5))),
+			adjusted:    metrics(exponentialHistogramMetric(histogram1, exponentialHistogramPointNoValue(k1v1k2v2, t3, t5))),
+		},
+	}
+	runScript(t, NewInitialPointAdjuster(zap.NewNop(), time.Minute, true), "job", "0", script)
+}
+
 func TestHistogramFlagNoRecordedValue(t *testing.T) {
 	script := []*metricsAdjusterTest{
 		{
@@ -78,6 +78,53 @@ func histogramMetric(name string, points ...pmetric.HistogramDataPoint) pmetric.
 	return metric
 }
 
+func exponentialHistogramPoint(attributes []*kv, startTimestamp, timestamp pcommon.Timestamp, zeroCount uint64, offset int32, counts []uint64) pmetric.ExponentialHistogramDataPoint {
+	hdp := pmetric.NewExponentialHistogramDataPoint()
+	hdp.SetStartTimestamp(startTimestamp)
+	hdp.SetTimestamp(timestamp)
+	hdp.SetZeroCount(zeroCount)
+	hdp.Positive().SetOffset(offset)
+	hdp.Positive().BucketCounts().FromRaw(counts)
+
+	count := zeroCount
+	var sum float64
+	for i, bcount := range counts {
+		count += bcount
+		sum += float64(bcount) * float64(i)
+	}
+	hdp.SetCount(count)
+	hdp.SetSum(sum)
+
+	attrs := hdp.Attributes()
+	for _, kv := range attributes {
+		attrs.PutStr(kv.Key, kv.Value)
+	}
+
+	return hdp
+}
+
+func exponentialHistogramPointNoValue(attributes []*kv, startTimestamp, timestamp pcommon.Timestamp) pmetric.ExponentialHistogramDataPoint {
+	hdp := exponentialHistogramPoint(attributes, startTimestamp, timestamp, 0, 0, nil)
+	hdp.SetFlags(pmetric.DefaultDataPointFlags.WithNoRecordedValue(true))
+
+	return hdp
+}
+
+func exponentialHistogramMetric(name string, points ...pmetric.ExponentialHistogramDataPoint) pmetric.Metric {
+	metric := pmetric.NewMetric()
+	metric.SetName(name)
+	histogram := metric.SetEmptyExponentialHistogram()
+	histogram.SetAggregationTemporality(pmetric.AggregationTemporalityCumulative)
+
+	destPointL := histogram.DataPoints()
+	for _, point := range points {
+		destPoint := destPointL.AppendEmpty()
+		point.CopyTo(destPoint)
+	}
+
+	return metric
+}
+
 func doublePointRaw(attributes []*kv, startTimestamp, timestamp pcommon.Timestamp) pmetric.NumberDataPoint {
 	ndp := pmetric.NewNumberDataPoint()
 	ndp.SetStartTimestamp(startTimestamp)
@@ -20,13 +20,16 @@ var (
 type startTimeMetricAdjuster struct {
 	startTimeMetricRegex *regexp.Regexp
 	logger               *zap.Logger
+	useCreatedMetric     bool
 }
 
 // NewStartTimeMetricAdjuster returns a new MetricsAdjuster that adjust metrics' start times based on a start time metric.
-func NewStartTimeMetricAdjuster(logger *zap.Logger, startTimeMetricRegex *regexp.Regexp) MetricsAdjuster {
+// If useCreatedMetric is true, the start times set from created timestamps are kept.
+func NewStartTimeMetricAdjuster(logger *zap.Logger, startTimeMetricRegex *regexp.Regexp, useCreatedMetric bool) MetricsAdjuster {
 	return &startTimeMetricAdjuster{
 		startTimeMetricRegex: startTimeMetricRegex,
 		logger:               logger,
+		useCreatedMetric:     useCreatedMetric,
 	}
 }
 
@@ -51,6 +54,12 @@ func (stma *startTimeMetricAdjuster) AdjustMetrics(metrics pmetric.Metrics) erro
 					dataPoints := metric.Sum().DataPoints()
 					for l := 0; l < dataPoints.Len(); l++ {
 						dp := dataPoints.At(l)
+						// start timestamp was set from the created timestamp
+						if stma.useCreatedMetric &&
+							!dp.Flags().NoRecordedValue() &&
+							dp.StartTimestamp() < dp.Timestamp() {
+							continue
+						}
 						dp.SetStartTimestamp(startTimeTs)
 					}
 
@@ -58,6 +67,12 @@ func (stma *startTimeMetricAdjuster) AdjustMetrics(metrics pmetric.Metrics) erro
 					dataPoints := metric.Summary().DataPoints()
 					for l := 0; l < dataPoints.Len(); l++ {
 						dp := dataPoints.At(l)
+						// start timestamp was set from the created timestamp
+						if stma.useCreatedMetric &&
+							!dp.Flags().NoRecordedValue() &&
+							dp.StartTimestamp() < dp.Timestamp() {
+							continue
+						}
 						dp.SetStartTimestamp(startTimeTs)
 					}
 
@@ -65,10 +80,29 @@ func (stma *startTimeMetricAdjuster) AdjustMetrics(metrics pmetric.Metrics) erro
 					dataPoints := metric.Histogram().DataPoints()
 					for l := 0; l < dataPoints.Len(); l++ {
 						dp := dataPoints.At(l)
+						// start timestamp was set from the created timestamp
+						if stma.useCreatedMetric &&
+							!dp.Flags().NoRecordedValue() &&
+							dp.StartTimestamp() < dp.Timestamp() {
+							continue
+						}
+						dp.SetStartTimestamp(startTimeTs)
+					}
+
+				case pmetric.MetricTypeExponentialHistogram:
+					dataPoints := metric.ExponentialHistogram().DataPoints()
+					for l := 0; l < dataPoints.Len(); l++ {
+						dp := dataPoints.At(l)
+						// start timestamp was set from the created timestamp
+						if stma.useCreatedMetric &&
+							!dp.Flags().NoRecordedValue() &&
+							dp.StartTimestamp() < dp.Timestamp() {
+							continue
+						}
 						dp.SetStartTimestamp(startTimeTs)
 					}
 
-				case pmetric.MetricTypeEmpty, pmetric.MetricTypeExponentialHistogram:
+				case pmetric.MetricTypeEmpty:
 					fallthrough
 
 				default:
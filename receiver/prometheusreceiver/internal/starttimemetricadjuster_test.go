@@ -111,7 +111,7 @@ func TestStartTimeMetricMatch(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			stma := NewStartTimeMetricAdjuster(zap.NewNop(), tt.startTimeMetricRegex)
+			stma := NewStartTimeMetricAdjuster(zap.NewNop(), tt.startTimeMetricRegex, false)
 			if tt.expectedErr != nil {
 				assert.ErrorIs(t, stma.AdjustMetrics(tt.inputs), tt.expectedErr)
 				return
@@ -147,3 +147,21 @@ func TestStartTimeMetricMatch(t *testing.T) {
 		})
 	}
 }
+
+func TestStartTimeMetricKeepsCreatedTime(t *testing.T) {
+	const createdTime = pcommon.Timestamp(123 * 1e9)
+	const currentTime = pcommon.Timestamp(126 * 1e9)
+	const matchBuilderStartTime = 124
+
+	inputs := metrics(
+		sumMetric("test_created_sum_metric", doublePoint(nil, createdTime, currentTime, 16)),
+		sumMetric("test_sum_metric", doublePoint(nil, currentTime, currentTime, 16)),
+		gaugeMetric("process_start_time_seconds", doublePoint(nil, currentTime, currentTime, matchBuilderStartTime)),
+	)
+	stma := NewStartTimeMetricAdjuster(zap.NewNop(), nil, true)
+	assert.NoError(t, stma.AdjustMetrics(inputs))
+
+	metrics := inputs.ResourceMetrics().At(0).ScopeMetrics().At(0).Metrics()
+	assert.Equal(t, createdTime, metrics.At(0).Sum().DataPoints().At(0).StartTimestamp())
+	assert.Equal(t, timestampFromFloat64(matchBuilderStartTime), metrics.At(1).Sum().DataPoints().At(0).StartTimestamp())
+}
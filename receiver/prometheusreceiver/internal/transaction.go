@@ -7,6 +7,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"math"
 	"sort"
 
 	"github.com/prometheus/common/model"
@@ -35,19 +36,20 @@ const (
 )
 
 type transaction struct {
-	isNew           bool
-	trimSuffixes    bool
-	ctx             context.Context
-	families        map[scopeID]map[string]*metricFamily
-	mc              scrape.MetricMetadataStore
-	sink            consumer.Metrics
-	externalLabels  labels.Labels
-	nodeResource    pcommon.Resource
-	scopeAttributes map[scopeID]pcommon.Map
-	logger          *zap.Logger
-	buildInfo       component.BuildInfo
-	metricAdjuster  MetricsAdjuster
-	obsrecv         *receiverhelper.ObsReport
+	isNew                  bool
+	trimSuffixes           bool
+	enableNativeHistograms bool
+	ctx                    context.Context
+	families               map[scopeID]map[string]*metricFamily
+	mc                     scrape.MetricMetadataStore
+	sink                   consumer.Metrics
+	externalLabels         labels.Labels
+	nodeResource           pcommon.Resource
+	scopeAttributes        map[scopeID]pcommon.Map
+	logger                 *zap.Logger
+	buildInfo              component.BuildInfo
+	metricAdjuster         MetricsAdjuster
+	obsrecv                *receiverhelper.ObsReport
 	// Used as buffer to calculate series ref hash.
 	bufBytes []byte
 }
@@ -66,20 +68,22 @@ func newTransaction(
 	externalLabels labels.Labels,
 	settings receiver.CreateSettings,
 	obsrecv *receiverhelper.ObsReport,
-	trimSuffixes bool) *transaction {
+	trimSuffixes bool,
+	enableNativeHistograms bool) *transaction {
 	return &transaction{
-		ctx:             ctx,
-		families:        make(map[scopeID]map[string]*metricFamily),
-		isNew:           true,
-		trimSuffixes:    trimSuffixes,
-		sink:            sink,
-		metricAdjuster:  metricAdjuster,
-		externalLabels:  externalLabels,
-		logger:          settings.Logger,
-		buildInfo:       settings.BuildInfo,
-		obsrecv:         obsrecv,
-		bufBytes:        make([]byte, 0, 1024),
-		scopeAttributes: make(map[scopeID]pcommon.Map),
+		ctx:                    ctx,
+		families:               make(map[scopeID]map[string]*metricFamily),
+		isNew:                  true,
+		trimSuffixes:           trimSuffixes,
+		enableNativeHistograms: enableNativeHistograms,
+		sink:                   sink,
+		metricAdjuster:         metricAdjuster,
+		externalLabels:         externalLabels,
+		logger:                 settings.Logger,
+		buildInfo:              settings.BuildInfo,
+		obsrecv:                obsrecv,
+		bufBytes:               make([]byte, 0, 1024),
+		scopeAttributes:        make(map[scopeID]pcommon.Map),
 	}
 }
 
@@ -143,7 +147,28 @@ func (t *transaction) Append(_ storage.SeriesRef, ls labels.Labels, atMs int64,
 		return 0, nil
 	}
 
-	curMF := t.getOrCreateMetricFamily(getScopeID(ls), metricName)
+	curMF, existing := t.getOrCreateMetricFamily(getScopeID(ls), metricName)
+
+	if t.enableNativeHistograms && value.IsStaleNaN(val) && metricName == curMF.name &&
+		(curMF.mtype == pmetric.MetricTypeExponentialHistogram || !existing && curMF.mtype == pmetric.MetricTypeHistogram) {
+		// A native histogram is marked stale with a float staleness marker
+		// named after the histogram, which a classic histogram never has.
+		curMF.mtype = pmetric.MetricTypeExponentialHistogram
+		stale := &histogram.FloatHistogram{Sum: math.Float64frombits(value.StaleNaN)}
+		if err := curMF.addExponentialHistogramSeries(t.getSeriesRef(ls, curMF.mtype), metricName, ls, atMs, stale); err != nil {
+			t.logger.Warn("failed to add datapoint", zap.Error(err), zap.String("metric_name", metricName), zap.Any("labels", ls))
+		}
+		return 0, nil
+	}
+
+	if t.enableNativeHistograms && curMF.mtype == pmetric.MetricTypeExponentialHistogram {
+		// A histogram with both a native and a classic version is appended
+		// as a native histogram first. Getting a float sample of the family
+		// means that scrape_classic_histograms is set, so the classic
+		// histogram is kept.
+		curMF.mtype = pmetric.MetricTypeHistogram
+	}
+
 	err := curMF.addSeries(t.getSeriesRef(ls, curMF.mtype), metricName, ls, atMs, val)
 	if err != nil {
 		t.logger.Warn("failed to add datapoint", zap.Error(err), zap.String("metric_name", metricName), zap.Any("labels", ls))
@@ -152,7 +177,9 @@ func (t *transaction) Append(_ storage.SeriesRef, ls labels.Labels, atMs int64,
 	return 0, nil // never return errors, as that fails the whole scrape
 }
 
-func (t *transaction) getOrCreateMetricFamily(scope scopeID, mn string) *metricFamily {
+// getOrCreateMetricFamily returns the family of the metric, and whether it
+// already existed.
+func (t *transaction) getOrCreateMetricFamily(scope scopeID, mn string) (*metricFamily, bool) {
 	_, ok := t.families[scope]
 	if !ok {
 		t.families[scope] = make(map[string]*metricFamily)
@@ -164,13 +191,13 @@ func (t *transaction) getOrCreateMetricFamily(scope scopeID, mn string) *metricF
 			fn = normalizeMetricName(mn)
 		}
 		if mf, ok := t.families[scope][fn]; ok && mf.includesMetric(mn) {
-			curMf = mf
-		} else {
-			curMf = newMetricFamily(mn, t.mc, t.logger)
-			t.families[scope][curMf.name] = curMf
+			return mf, true
 		}
+		curMf = newMetricFamily(mn, t.mc, t.logger)
+		t.families[scope][curMf.name] = curMf
+		return curMf, false
 	}
-	return curMf
+	return curMf, true
 }
 
 func (t *transaction) AppendExemplar(_ storage.SeriesRef, l labels.Labels, e exemplar.Exemplar) (storage.SeriesRef, error) {
@@ -197,19 +224,133 @@ func (t *transaction) AppendExemplar(_ storage.SeriesRef, l labels.Labels, e exe
 		return 0, errMetricNameNotFound
 	}
 
-	mf := t.getOrCreateMetricFamily(getScopeID(l), mn)
+	mf, _ := t.getOrCreateMetricFamily(getScopeID(l), mn)
 	mf.addExemplar(t.getSeriesRef(l, mf.mtype), e)
 
 	return 0, nil
 }
 
-func (t *transaction) AppendHistogram(_ storage.SeriesRef, _ labels.Labels, _ int64, _ *histogram.Histogram, _ *histogram.FloatHistogram) (storage.SeriesRef, error) {
-	//TODO: implement this func
-	return 0, nil
+// AppendHistogram adds a native histogram as an exponential histogram data
+// point. Like Append, it always returns 0 to disable label caching.
+func (t *transaction) AppendHistogram(_ storage.SeriesRef, ls labels.Labels, atMs int64, h *histogram.Histogram, fh *histogram.FloatHistogram) (storage.SeriesRef, error) {
+	if !t.enableNativeHistograms {
+		return 0, nil
+	}
+
+	select {
+	case <-t.ctx.Done():
+		return 0, errTransactionAborted
+	default:
+	}
+
+	if len(t.externalLabels) != 0 {
+		ls = append(ls, t.externalLabels...)
+		sort.Sort(ls)
+	}
+
+	if t.isNew {
+		if err := t.initTransaction(ls); err != nil {
+			return 0, err
+		}
+	}
+
+	if dupLabel, hasDup := ls.HasDuplicateLabelNames(); hasDup {
+		return 0, fmt.Errorf("invalid sample: non-unique label names: %q", dupLabel)
+	}
+
+	metricName := ls.Get(model.MetricNameLabel)
+	if metricName == "" {
+		return 0, errMetricNameNotFound
+	}
+
+	if h != nil {
+		fh = h.ToFloat(nil)
+	}
+	if fh == nil {
+		return 0, nil
+	}
+	if fh.CounterResetHint == histogram.GaugeType {
+		// dropping support for gauge histograms, like the classic ones
+		t.logger.Debug("dropping unsupported gauge histogram datapoint", zap.String("metric_name", metricName), zap.Any("labels", ls))
+		return 0, nil
+	}
+
+	// The `up`, `target_info` and `otel_scope_info` metrics are never native
+	// histograms, so they aren't checked here as in Append.
+	curMF, existing := t.getOrCreateMetricFamily(getScopeID(ls), metricName)
+	if !existing {
+		curMF.mtype = pmetric.MetricTypeExponentialHistogram
+	} else if curMF.mtype != pmetric.MetricTypeExponentialHistogram {
+		// The family was already appended as a classic histogram.
+		return 0, nil
+	}
+
+	err := curMF.addExponentialHistogramSeries(t.getSeriesRef(ls, curMF.mtype), metricName, ls, atMs, fh)
+	if err != nil {
+		t.logger.Warn("failed to add histogram datapoint", zap.Error(err), zap.String("metric_name", metricName), zap.Any("labels", ls))
+	}
+
+	return 0, nil // never return errors, as that fails the whole scrape
 }
 
-func (t *transaction) AppendCTZeroSample(_ storage.SeriesRef, _ labels.Labels, _, _ int64) (storage.SeriesRef, error) {
-	//TODO: implement this func
+// AppendCTZeroSample records the created timestamp of a series, which is
+// used as the start timestamp of its data point. Prometheus calls it for the
+// created_timestamp field of the protobuf format, before appending the sample.
+func (t *transaction) AppendCTZeroSample(_ storage.SeriesRef, ls labels.Labels, atMs, ctMs int64) (storage.SeriesRef, error) {
+	select {
+	case <-t.ctx.Done():
+		return 0, errTransactionAborted
+	default:
+	}
+
+	if ctMs >= atMs {
+		return 0, storage.ErrOutOfOrderCT
+	}
+
+	if len(t.externalLabels) != 0 {
+		ls = append(ls, t.externalLabels...)
+		sort.Sort(ls)
+	}
+
+	if t.isNew {
+		if err := t.initTransaction(ls); err != nil {
+			return 0, err
+		}
+	}
+
+	if dupLabel, hasDup := ls.HasDuplicateLabelNames(); hasDup {
+		return 0, fmt.Errorf("invalid sample: non-unique label names: %q", dupLabel)
+	}
+
+	metricName := ls.Get(model.MetricNameLabel)
+	if metricName == "" {
+		return 0, errMetricNameNotFound
+	}
+
+	curMF, existing := t.getOrCreateMetricFamily(getScopeID(ls), metricName)
+	if t.enableNativeHistograms {
+		// The created timestamp comes before the sample, so the family gets
+		// the type the sample gives it in Append or AppendHistogram.
+		switch {
+		case metricName == curMF.name && !existing && curMF.mtype == pmetric.MetricTypeHistogram:
+			curMF.mtype = pmetric.MetricTypeExponentialHistogram
+		case metricName != curMF.name && curMF.mtype == pmetric.MetricTypeExponentialHistogram:
+			curMF.mtype = pmetric.MetricTypeHistogram
+		}
+	}
+
+	switch curMF.mtype {
+	case pmetric.MetricTypeSum, pmetric.MetricTypeHistogram, pmetric.MetricTypeExponentialHistogram, pmetric.MetricTypeSummary:
+	default:
+		// only cumulative metrics have a start timestamp
+		return 0, nil
+	}
+
+	err := curMF.addCreationTimestamp(t.getSeriesRef(ls, curMF.mtype), metricName, ls, atMs, ctMs)
+	if err != nil {
+		t.logger.Warn("failed to add created timestamp", zap.Error(err), zap.String("metric_name", metricName), zap.Any("labels", ls))
+	}
+
 	return 0, nil
 }
 
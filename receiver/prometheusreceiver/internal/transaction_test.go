@@ -6,14 +6,18 @@ package internal
 import (
 	"context"
 	"errors"
+	"math"
 	"testing"
 	"time"
 
 	"github.com/prometheus/common/model"
 	"github.com/prometheus/prometheus/model/exemplar"
+	"github.com/prometheus/prometheus/model/histogram"
 	"github.com/prometheus/prometheus/model/labels"
 	"github.com/prometheus/prometheus/model/metadata"
+	"github.com/prometheus/prometheus/model/value"
 	"github.com/prometheus/prometheus/scrape"
+	"github.com/prometheus/prometheus/storage"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"go.opentelemetry.io/collector/component"
@@ -53,24 +57,24 @@ var (
 )
 
 func TestTransactionCommitWithoutAdding(t *testing.T) {
-	tr := newTransaction(scrapeCtx, &startTimeAdjuster{startTime: startTimestamp}, consumertest.NewNop(), nil, receivertest.NewNopCreateSettings(), nopObsRecv(t), false)
+	tr := newTransaction(scrapeCtx, &startTimeAdjuster{startTime: startTimestamp}, consumertest.NewNop(), nil, receivertest.NewNopCreateSettings(), nopObsRecv(t), false, false)
 	assert.NoError(t, tr.Commit())
 }
 
 func TestTransactionRollbackDoesNothing(t *testing.T) {
-	tr := newTransaction(scrapeCtx, &startTimeAdjuster{startTime: startTimestamp}, consumertest.NewNop(), nil, receivertest.NewNopCreateSettings(), nopObsRecv(t), false)
+	tr := newTransaction(scrapeCtx, &startTimeAdjuster{startTime: startTimestamp}, consumertest.NewNop(), nil, receivertest.NewNopCreateSettings(), nopObsRecv(t), false, false)
 	assert.NoError(t, tr.Rollback())
 }
 
 func TestTransactionUpdateMetadataDoesNothing(t *testing.T) {
-	tr := newTransaction(scrapeCtx, &startTimeAdjuster{startTime: startTimestamp}, consumertest.NewNop(), nil, receivertest.NewNopCreateSettings(), nopObsRecv(t), false)
+	tr := newTransaction(scrapeCtx, &startTimeAdjuster{startTime: startTimestamp}, consumertest.NewNop(), nil, receivertest.NewNopCreateSettings(), nopObsRecv(t), false, false)
 	_, err := tr.UpdateMetadata(0, labels.New(), metadata.Metadata{})
 	assert.NoError(t, err)
 }
 
 func TestTransactionAppendNoTarget(t *testing.T) {
 	badLabels := labels.FromStrings(model.MetricNameLabel, "counter_test")
-	tr := newTransaction(scrapeCtx, &startTimeAdjuster{startTime: startTimestamp}, consumertest.NewNop(), nil, receivertest.NewNopCreateSettings(), nopObsRecv(t), false)
+	tr := newTransaction(scrapeCtx, &startTimeAdjuster{startTime: startTimestamp}, consumertest.NewNop(), nil, receivertest.NewNopCreateSettings(), nopObsRecv(t), false, false)
 	_, err := tr.Append(0, badLabels, time.Now().Unix()*1000, 1.0)
 	assert.Error(t, err)
 }
@@ -80,7 +84,7 @@ func TestTransactionAppendNoMetricName(t *testing.T) {
 		model.InstanceLabel: "localhost:8080",
 		model.JobLabel:      "test2",
 	})
-	tr := newTransaction(scrapeCtx, &startTimeAdjuster{startTime: startTimestamp}, consumertest.NewNop(), nil, receivertest.NewNopCreateSettings(), nopObsRecv(t), false)
+	tr := newTransaction(scrapeCtx, &startTimeAdjuster{startTime: startTimestamp}, consumertest.NewNop(), nil, receivertest.NewNopCreateSettings(), nopObsRecv(t), false, false)
 	_, err := tr.Append(0, jobNotFoundLb, time.Now().Unix()*1000, 1.0)
 	assert.ErrorIs(t, err, errMetricNameNotFound)
 
@@ -88,7 +92,7 @@ func TestTransactionAppendNoMetricName(t *testing.T) {
 }
 
 func TestTransactionAppendEmptyMetricName(t *testing.T) {
-	tr := newTransaction(scrapeCtx, &startTimeAdjuster{startTime: startTimestamp}, consumertest.NewNop(), nil, receivertest.NewNopCreateSettings(), nopObsRecv(t), false)
+	tr := newTransaction(scrapeCtx, &startTimeAdjuster{startTime: startTimestamp}, consumertest.NewNop(), nil, receivertest.NewNopCreateSettings(), nopObsRecv(t), false, false)
 	_, err := tr.Append(0, labels.FromMap(map[string]string{
 		model.InstanceLabel:   "localhost:8080",
 		model.JobLabel:        "test2",
@@ -99,7 +103,7 @@ func TestTransactionAppendEmptyMetricName(t *testing.T) {
 
 func TestTransactionAppendResource(t *testing.T) {
 	sink := new(consumertest.MetricsSink)
-	tr := newTransaction(scrapeCtx, &startTimeAdjuster{startTime: startTimestamp}, sink, nil, receivertest.NewNopCreateSettings(), nopObsRecv(t), false)
+	tr := newTransaction(scrapeCtx, &startTimeAdjuster{startTime: startTimestamp}, sink, nil, receivertest.NewNopCreateSettings(), nopObsRecv(t), false, false)
 	_, err := tr.Append(0, labels.FromMap(map[string]string{
 		model.InstanceLabel:   "localhost:8080",
 		model.JobLabel:        "test",
@@ -122,7 +126,7 @@ func TestTransactionAppendResource(t *testing.T) {
 
 func TestReceiverVersionAndNameAreAttached(t *testing.T) {
 	sink := new(consumertest.MetricsSink)
-	tr := newTransaction(scrapeCtx, &startTimeAdjuster{startTime: startTimestamp}, sink, nil, receivertest.NewNopCreateSettings(), nopObsRecv(t), false)
+	tr := newTransaction(scrapeCtx, &startTimeAdjuster{startTime: startTimestamp}, sink, nil, receivertest.NewNopCreateSettings(), nopObsRecv(t), false, false)
 	_, err := tr.Append(0, labels.FromMap(map[string]string{
 		model.InstanceLabel:   "localhost:8080",
 		model.JobLabel:        "test",
@@ -150,7 +154,7 @@ func TestTransactionCommitErrorWhenAdjusterError(t *testing.T) {
 	})
 	sink := new(consumertest.MetricsSink)
 	adjusterErr := errors.New("adjuster error")
-	tr := newTransaction(scrapeCtx, &errorAdjuster{err: adjusterErr}, sink, nil, receivertest.NewNopCreateSettings(), nopObsRecv(t), false)
+	tr := newTransaction(scrapeCtx, &errorAdjuster{err: adjusterErr}, sink, nil, receivertest.NewNopCreateSettings(), nopObsRecv(t), false, false)
 	_, err := tr.Append(0, goodLabels, time.Now().Unix()*1000, 1.0)
 	assert.NoError(t, err)
 	assert.ErrorIs(t, tr.Commit(), adjusterErr)
@@ -159,7 +163,7 @@ func TestTransactionCommitErrorWhenAdjusterError(t *testing.T) {
 // Ensure that we reject duplicate label keys. See https://github.com/open-telemetry/wg-prometheus/issues/44.
 func TestTransactionAppendDuplicateLabels(t *testing.T) {
 	sink := new(consumertest.MetricsSink)
-	tr := newTransaction(scrapeCtx, &startTimeAdjuster{startTime: startTimestamp}, sink, nil, receivertest.NewNopCreateSettings(), nopObsRecv(t), false)
+	tr := newTransaction(scrapeCtx, &startTimeAdjuster{startTime: startTimestamp}, sink, nil, receivertest.NewNopCreateSettings(), nopObsRecv(t), false, false)
 
 	dupLabels := labels.FromStrings(
 		model.InstanceLabel, "0.0.0.0:8855",
@@ -188,6 +192,7 @@ func TestTransactionAppendHistogramNoLe(t *testing.T) {
 		receiverSettings,
 		nopObsRecv(t),
 		false,
+		false,
 	)
 
 	goodLabels := labels.FromStrings(
@@ -218,6 +223,7 @@ func TestTransactionAppendSummaryNoQuantile(t *testing.T) {
 		receiverSettings,
 		nopObsRecv(t),
 		false,
+		false,
 	)
 
 	goodLabels := labels.FromStrings(
@@ -248,6 +254,7 @@ func TestTransactionAppendValidAndInvalid(t *testing.T) {
 		receiverSettings,
 		nopObsRecv(t),
 		false,
+		false,
 	)
 
 	// a valid counter
@@ -282,7 +289,7 @@ func TestTransactionAppendValidAndInvalid(t *testing.T) {
 
 func TestAppendExemplarWithNoMetricName(t *testing.T) {
 	sink := new(consumertest.MetricsSink)
-	tr := newTransaction(scrapeCtx, &startTimeAdjuster{startTime: startTimestamp}, sink, nil, receivertest.NewNopCreateSettings(), nopObsRecv(t), false)
+	tr := newTransaction(scrapeCtx, &startTimeAdjuster{startTime: startTimestamp}, sink, nil, receivertest.NewNopCreateSettings(), nopObsRecv(t), false, false)
 
 	labels := labels.FromStrings(
 		model.InstanceLabel, "0.0.0.0:8855",
@@ -295,7 +302,7 @@ func TestAppendExemplarWithNoMetricName(t *testing.T) {
 
 func TestAppendExemplarWithEmptyMetricName(t *testing.T) {
 	sink := new(consumertest.MetricsSink)
-	tr := newTransaction(scrapeCtx, &startTimeAdjuster{startTime: startTimestamp}, sink, nil, receivertest.NewNopCreateSettings(), nopObsRecv(t), false)
+	tr := newTransaction(scrapeCtx, &startTimeAdjuster{startTime: startTimestamp}, sink, nil, receivertest.NewNopCreateSettings(), nopObsRecv(t), false, false)
 
 	labels := labels.FromStrings(
 		model.InstanceLabel, "0.0.0.0:8855",
@@ -308,7 +315,7 @@ func TestAppendExemplarWithEmptyMetricName(t *testing.T) {
 
 func TestAppendExemplarWithDuplicateLabels(t *testing.T) {
 	sink := new(consumertest.MetricsSink)
-	tr := newTransaction(scrapeCtx, &startTimeAdjuster{startTime: startTimestamp}, sink, nil, receivertest.NewNopCreateSettings(), nopObsRecv(t), false)
+	tr := newTransaction(scrapeCtx, &startTimeAdjuster{startTime: startTimestamp}, sink, nil, receivertest.NewNopCreateSettings(), nopObsRecv(t), false, false)
 
 	labels := labels.FromStrings(
 		model.InstanceLabel, "0.0.0.0:8855",
@@ -324,7 +331,7 @@ func TestAppendExemplarWithDuplicateLabels(t *testing.T) {
 
 func TestAppendExemplarWithoutAddingMetric(t *testing.T) {
 	sink := new(consumertest.MetricsSink)
-	tr := newTransaction(scrapeCtx, &startTimeAdjuster{startTime: startTimestamp}, sink, nil, receivertest.NewNopCreateSettings(), nopObsRecv(t), false)
+	tr := newTransaction(scrapeCtx, &startTimeAdjuster{startTime: startTimestamp}, sink, nil, receivertest.NewNopCreateSettings(), nopObsRecv(t), false, false)
 
 	labels := labels.FromStrings(
 		model.InstanceLabel, "0.0.0.0:8855",
@@ -338,7 +345,7 @@ func TestAppendExemplarWithoutAddingMetric(t *testing.T) {
 
 func TestAppendExemplarWithNoLabels(t *testing.T) {
 	sink := new(consumertest.MetricsSink)
-	tr := newTransaction(scrapeCtx, &startTimeAdjuster{startTime: startTimestamp}, sink, nil, receivertest.NewNopCreateSettings(), nopObsRecv(t), false)
+	tr := newTransaction(scrapeCtx, &startTimeAdjuster{startTime: startTimestamp}, sink, nil, receivertest.NewNopCreateSettings(), nopObsRecv(t), false, false)
 
 	_, err := tr.AppendExemplar(0, nil, exemplar.Exemplar{Value: 0})
 	assert.Equal(t, errNoJobInstance, err)
@@ -346,7 +353,7 @@ func TestAppendExemplarWithNoLabels(t *testing.T) {
 
 func TestAppendExemplarWithEmptyLabelArray(t *testing.T) {
 	sink := new(consumertest.MetricsSink)
-	tr := newTransaction(scrapeCtx, &startTimeAdjuster{startTime: startTimestamp}, sink, nil, receivertest.NewNopCreateSettings(), nopObsRecv(t), false)
+	tr := newTransaction(scrapeCtx, &startTimeAdjuster{startTime: startTimestamp}, sink, nil, receivertest.NewNopCreateSettings(), nopObsRecv(t), false, false)
 
 	_, err := tr.AppendExemplar(0, []labels.Label{}, exemplar.Exemplar{Value: 0})
 	assert.Equal(t, errNoJobInstance, err)
@@ -1461,6 +1468,176 @@ func TestMetricBuilderSummary(t *testing.T) {
 
 }
 
+func TestTransactionAppendNativeHistogram(t *testing.T) {
+	h := &histogram.Histogram{
+		Schema:        1,
+		Count:         8,
+		Sum:           18.4,
+		ZeroThreshold: 0.001,
+		ZeroCount:     2,
+		// absolute counts 1, 2, then an empty bucket, then 1, 1
+		PositiveSpans:   []histogram.Span{{Offset: 0, Length: 2}, {Offset: 1, Length: 2}},
+		PositiveBuckets: []int64{1, 1, -1, 0},
+		NegativeSpans:   []histogram.Span{{Offset: 0, Length: 1}},
+		NegativeBuckets: []int64{1},
+	}
+	lbls := labels.FromStrings(model.MetricNameLabel, "hist_test", model.JobLabel, "job", model.InstanceLabel, "instance", "foo", "bar")
+
+	t.Run("disabled", func(t *testing.T) {
+		sink := new(consumertest.MetricsSink)
+		tr := newTransaction(scrapeCtx, &startTimeAdjuster{startTime: startTimestamp}, sink, nil, receivertest.NewNopCreateSettings(), nopObsRecv(t), false, false)
+		_, err := tr.AppendHistogram(0, lbls, ts, h, nil)
+		require.NoError(t, err)
+		require.NoError(t, tr.Commit())
+		assert.Empty(t, sink.AllMetrics())
+	})
+
+	t.Run("integer", func(t *testing.T) {
+		sink := new(consumertest.MetricsSink)
+		tr := newTransaction(scrapeCtx, &startTimeAdjuster{startTime: startTimestamp}, sink, nil, receivertest.NewNopCreateSettings(), nopObsRecv(t), false, true)
+		_, err := tr.AppendHistogram(0, lbls, ts, h, nil)
+		require.NoError(t, err)
+		require.NoError(t, tr.Commit())
+
+		require.Len(t, sink.AllMetrics(), 1)
+		metric := sink.AllMetrics()[0].ResourceMetrics().At(0).ScopeMetrics().At(0).Metrics().At(0)
+		assert.Equal(t, "hist_test", metric.Name())
+		require.Equal(t, pmetric.MetricTypeExponentialHistogram, metric.Type())
+		assert.Equal(t, pmetric.AggregationTemporalityCumulative, metric.ExponentialHistogram().AggregationTemporality())
+		require.Equal(t, 1, metric.ExponentialHistogram().DataPoints().Len())
+		pt := metric.ExponentialHistogram().DataPoints().At(0)
+		assert.Equal(t, startTimestamp, pt.StartTimestamp())
+		assert.Equal(t, tsNanos, pt.Timestamp())
+		assert.Equal(t, int32(1), pt.Scale())
+		assert.Equal(t, uint64(8), pt.Count())
+		assert.Equal(t, 18.4, pt.Sum())
+		assert.Equal(t, 0.001, pt.ZeroThreshold())
+		assert.Equal(t, uint64(2), pt.ZeroCount())
+		assert.Equal(t, int32(-1), pt.Positive().Offset())
+		assert.Equal(t, []uint64{1, 2, 0, 1, 1}, pt.Positive().BucketCounts().AsRaw())
+		assert.Equal(t, int32(-1), pt.Negative().Offset())
+		assert.Equal(t, []uint64{1}, pt.Negative().BucketCounts().AsRaw())
+		assert.Equal(t, map[string]any{"foo": "bar"}, pt.Attributes().AsRaw())
+	})
+
+	t.Run("float", func(t *testing.T) {
+		sink := new(consumertest.MetricsSink)
+		tr := newTransaction(scrapeCtx, &startTimeAdjuster{startTime: startTimestamp}, sink, nil, receivertest.NewNopCreateSettings(), nopObsRecv(t), false, true)
+		_, err := tr.AppendHistogram(0, lbls, ts, nil, h.ToFloat(nil))
+		require.NoError(t, err)
+		require.NoError(t, tr.Commit())
+
+		require.Len(t, sink.AllMetrics(), 1)
+		pt := sink.AllMetrics()[0].ResourceMetrics().At(0).ScopeMetrics().At(0).Metrics().At(0).ExponentialHistogram().DataPoints().At(0)
+		assert.Equal(t, uint64(8), pt.Count())
+		assert.Equal(t, []uint64{1, 2, 0, 1, 1}, pt.Positive().BucketCounts().AsRaw())
+	})
+
+	t.Run("classic kept", func(t *testing.T) {
+		sink := new(consumertest.MetricsSink)
+		tr := newTransaction(scrapeCtx, &startTimeAdjuster{startTime: startTimestamp}, sink, nil, receivertest.NewNopCreateSettings(), nopObsRecv(t), false, true)
+		_, err := tr.AppendHistogram(0, lbls, ts, h, nil)
+		require.NoError(t, err)
+		for _, pt := range []*testDataPoint{
+			createDataPoint("hist_test_bucket", 8, nil, "foo", "bar", "le", "+Inf"),
+			createDataPoint("hist_test_count", 8, nil, "foo", "bar"),
+			createDataPoint("hist_test_sum", 18.4, nil, "foo", "bar"),
+		} {
+			_, err = tr.Append(0, pt.lb, ts, pt.v)
+			require.NoError(t, err)
+		}
+		require.NoError(t, tr.Commit())
+
+		require.Len(t, sink.AllMetrics(), 1)
+		metrics := sink.AllMetrics()[0].ResourceMetrics().At(0).ScopeMetrics().At(0).Metrics()
+		require.Equal(t, 1, metrics.Len())
+		require.Equal(t, pmetric.MetricTypeHistogram, metrics.At(0).Type())
+		assert.Equal(t, uint64(8), metrics.At(0).Histogram().DataPoints().At(0).Count())
+	})
+
+	t.Run("stale", func(t *testing.T) {
+		sink := new(consumertest.MetricsSink)
+		tr := newTransaction(scrapeCtx, &startTimeAdjuster{startTime: startTimestamp}, sink, nil, receivertest.NewNopCreateSettings(), nopObsRecv(t), false, true)
+		_, err := tr.Append(0, lbls, ts, math.Float64frombits(value.StaleNaN))
+		require.NoError(t, err)
+		require.NoError(t, tr.Commit())
+
+		require.Len(t, sink.AllMetrics(), 1)
+		metric := sink.AllMetrics()[0].ResourceMetrics().At(0).ScopeMetrics().At(0).Metrics().At(0)
+		require.Equal(t, pmetric.MetricTypeExponentialHistogram, metric.Type())
+		pt := metric.ExponentialHistogram().DataPoints().At(0)
+		assert.True(t, pt.Flags().NoRecordedValue())
+		assert.Zero(t, pt.Count())
+	})
+}
+
+func TestTransactionAppendCTZeroSample(t *testing.T) {
+	ctMs := ts - 60000
+	ctNanos := float64(ctMs * 1e6)
+	h := &histogram.Histogram{Count: 1, Sum: 2, PositiveSpans: []histogram.Span{{Offset: 0, Length: 1}}, PositiveBuckets: []int64{1}}
+
+	sink := new(consumertest.MetricsSink)
+	// The initial point adjuster keeps the start timestamps set from created timestamps.
+	tr := newTransaction(scrapeCtx, NewInitialPointAdjuster(zap.NewNop(), time.Minute, true), sink, nil, receivertest.NewNopCreateSettings(), nopObsRecv(t), false, true)
+	for _, pt := range []*testDataPoint{
+		createDataPoint("counter_test", 10, nil, "foo", "bar"),
+		createDataPoint("gauge_test", 1, nil, "foo", "bar"),
+		createDataPoint("hist_test2_count", 1, nil, "foo", "bar"),
+		createDataPoint("hist_test2_sum", 2, nil, "foo", "bar"),
+		createDataPoint("hist_test2_bucket", 1, nil, "foo", "bar", "le", "+Inf"),
+		createDataPoint("summary_test_count", 1, nil, "foo", "bar"),
+		createDataPoint("summary_test_sum", 2, nil, "foo", "bar"),
+	} {
+		_, err := tr.AppendCTZeroSample(0, pt.lb, ts, ctMs)
+		require.NoError(t, err)
+		_, err = tr.Append(0, pt.lb, ts, pt.v)
+		require.NoError(t, err)
+	}
+	native := createDataPoint("hist_test", 0, nil, "foo", "bar")
+	_, err := tr.AppendCTZeroSample(0, native.lb, ts, ctMs)
+	require.NoError(t, err)
+	_, err = tr.AppendHistogram(0, native.lb, ts, h, nil)
+	require.NoError(t, err)
+
+	// Created timestamps after the sample are ignored.
+	late := createDataPoint("counter_test2", 1, nil)
+	_, err = tr.AppendCTZeroSample(0, late.lb, ts, ts)
+	assert.ErrorIs(t, err, storage.ErrOutOfOrderCT)
+	_, err = tr.Append(0, late.lb, ts, late.v)
+	require.NoError(t, err)
+	require.NoError(t, tr.Commit())
+
+	require.Len(t, sink.AllMetrics(), 1)
+	metrics := sink.AllMetrics()[0].ResourceMetrics().At(0).ScopeMetrics().At(0).Metrics()
+	require.Equal(t, 6, metrics.Len())
+	for i := 0; i < metrics.Len(); i++ {
+		metric := metrics.At(i)
+		var startTs, pointTs pcommon.Timestamp
+		switch metric.Type() {
+		case pmetric.MetricTypeGauge:
+			assert.Zero(t, metric.Gauge().DataPoints().At(0).StartTimestamp())
+			continue
+		case pmetric.MetricTypeSum:
+			startTs, pointTs = metric.Sum().DataPoints().At(0).StartTimestamp(), metric.Sum().DataPoints().At(0).Timestamp()
+		case pmetric.MetricTypeHistogram:
+			startTs, pointTs = metric.Histogram().DataPoints().At(0).StartTimestamp(), metric.Histogram().DataPoints().At(0).Timestamp()
+		case pmetric.MetricTypeExponentialHistogram:
+			startTs, pointTs = metric.ExponentialHistogram().DataPoints().At(0).StartTimestamp(), metric.ExponentialHistogram().DataPoints().At(0).Timestamp()
+		case pmetric.MetricTypeSummary:
+			startTs, pointTs = metric.Summary().DataPoints().At(0).StartTimestamp(), metric.Summary().DataPoints().At(0).Timestamp()
+		default:
+			t.Fatalf("unexpected metric type %s", metric.Type())
+		}
+		assert.Equal(t, tsNanos, pointTs, metric.Name())
+		if metric.Name() == "counter_test2" {
+			assert.Equal(t, tsNanos, startTs, metric.Name())
+			continue
+		}
+		// Because of float64 conversion, we check only that we are within 1µs error.
+		assert.InDelta(t, ctNanos, float64(startTs), 1000, metric.Name())
+	}
+}
+
 type buildTestData struct {
 	name   string
 	inputs []*testScrapedPage
@@ -1473,7 +1650,7 @@ func (tt buildTestData) run(t *testing.T) {
 	st := ts
 	for i, page := range tt.inputs {
 		sink := new(consumertest.MetricsSink)
-		tr := newTransaction(scrapeCtx, &startTimeAdjuster{startTime: startTimestamp}, sink, nil, receivertest.NewNopCreateSettings(), nopObsRecv(t), false)
+		tr := newTransaction(scrapeCtx, &startTimeAdjuster{startTime: startTimestamp}, sink, nil, receivertest.NewNopCreateSettings(), nopObsRecv(t), false, false)
 		for _, pt := range page.pts {
 			// set ts for testing
 			pt.t = st
@@ -1534,7 +1711,12 @@ func (s *startTimeAdjuster) AdjustMetrics(metrics pmetric.Metrics) error {
 					for l := 0; l < dps.Len(); l++ {
 						dps.At(l).SetStartTimestamp(s.startTime)
 					}
-				case pmetric.MetricTypeEmpty, pmetric.MetricTypeGauge, pmetric.MetricTypeExponentialHistogram:
+				case pmetric.MetricTypeExponentialHistogram:
+					dps := metric.ExponentialHistogram().DataPoints()
+					for l := 0; l < dps.Len(); l++ {
+						dps.At(l).SetStartTimestamp(s.startTime)
+					}
+				case pmetric.MetricTypeEmpty, pmetric.MetricTypeGauge:
 				}
 			}
 		}
@@ -276,14 +276,16 @@ func (r *pReceiver) initPrometheusComponents(ctx context.Context, logger log.Log
 		useCreatedMetricGate.IsEnabled(),
 		r.cfg.PrometheusConfig.GlobalConfig.ExternalLabels,
 		r.cfg.TrimMetricSuffixes,
+		enableNativeHistogramsGate.IsEnabled(),
 	)
 	if err != nil {
 		return err
 	}
 
 	scrapeManager, err := scrape.NewManager(&scrape.Options{
-		PassMetadataInContext: true,
-		ExtraMetrics:          r.cfg.ReportExtraScrapeMetrics,
+		PassMetadataInContext:               true,
+		ExtraMetrics:                        r.cfg.ReportExtraScrapeMetrics,
+		EnableCreatedTimestampZeroIngestion: useCreatedMetricGate.IsEnabled(),
 		HTTPClientOptions: []commonconfig.HTTPClientOption{
 			commonconfig.WithUserAgent(r.settings.BuildInfo.Command + "/" + r.settings.BuildInfo.Version),
 		},
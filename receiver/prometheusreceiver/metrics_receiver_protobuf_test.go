@@ -6,9 +6,15 @@ package prometheusreceiver
 import (
 	"math"
 	"testing"
+	"time"
 
+	"github.com/gogo/protobuf/types"
 	"github.com/prometheus/prometheus/config"
 	dto "github.com/prometheus/prometheus/prompb/io/prometheus/client"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/featuregate"
+	"go.opentelemetry.io/collector/pdata/pcommon"
 	"go.opentelemetry.io/collector/pdata/pmetric"
 )
 
@@ -157,3 +163,123 @@ func TestScrapeViaProtobuf(t *testing.T) {
 		c.PrometheusConfig.GlobalConfig.ScrapeProtocols = []config.ScrapeProtocol{config.PrometheusProto}
 	})
 }
+
+func TestNativeHistogramScrapeViaProtobuf(t *testing.T) {
+	require.NoError(t, featuregate.GlobalRegistry().Set(enableNativeHistogramsGate.ID(), true))
+	defer func() {
+		require.NoError(t, featuregate.GlobalRegistry().Set(enableNativeHistogramsGate.ID(), false))
+	}()
+
+	mf := &dto.MetricFamily{
+		Name: "test_native_histogram",
+		Type: dto.MetricType_HISTOGRAM,
+		Metric: []dto.Metric{
+			{
+				Histogram: &dto.Histogram{
+					SampleCount:   1213,
+					SampleSum:     456,
+					Schema:        -1,
+					ZeroThreshold: 0.001,
+					ZeroCount:     2,
+					PositiveSpan: []dto.BucketSpan{
+						{Offset: 0, Length: 1},
+						{Offset: 2, Length: 2},
+					},
+					PositiveDelta: []int64{100, 100, -50},
+					NegativeSpan: []dto.BucketSpan{
+						{Offset: -1, Length: 1},
+					},
+					NegativeDelta: []int64{761},
+				},
+			},
+		},
+	}
+	buffer := prometheusMetricFamilyToProtoBuf(t, nil, mf)
+
+	targets := []*testData{
+		{
+			name: "target1",
+			pages: []mockPrometheusResponse{
+				{code: 200, useProtoBuf: true, buf: buffer.Bytes()},
+			},
+			validateFunc: func(t *testing.T, td *testData, result []pmetric.ResourceMetrics) {
+				verifyNumValidScrapeResults(t, td, result)
+				var found bool
+				for _, metric := range getMetrics(result[0]) {
+					if metric.Name() != "test_native_histogram" {
+						continue
+					}
+					found = true
+					require.Equal(t, pmetric.MetricTypeExponentialHistogram, metric.Type())
+					require.Equal(t, 1, metric.ExponentialHistogram().DataPoints().Len())
+					pt := metric.ExponentialHistogram().DataPoints().At(0)
+					assert.Equal(t, int32(-1), pt.Scale())
+					assert.Equal(t, uint64(1213), pt.Count())
+					assert.Equal(t, 456.0, pt.Sum())
+					assert.Equal(t, 0.001, pt.ZeroThreshold())
+					assert.Equal(t, uint64(2), pt.ZeroCount())
+					assert.Equal(t, int32(-1), pt.Positive().Offset())
+					assert.Equal(t, []uint64{100, 0, 0, 200, 150}, pt.Positive().BucketCounts().AsRaw())
+					assert.Equal(t, int32(-2), pt.Negative().Offset())
+					assert.Equal(t, []uint64{761}, pt.Negative().BucketCounts().AsRaw())
+				}
+				assert.True(t, found, "test_native_histogram is missing")
+			},
+		},
+	}
+
+	testComponent(t, targets, func(c *Config) {
+		c.PrometheusConfig.GlobalConfig.ScrapeProtocols = []config.ScrapeProtocol{config.PrometheusProto}
+	})
+}
+
+func TestCreatedTimestampScrapeViaProtobuf(t *testing.T) {
+	require.NoError(t, featuregate.GlobalRegistry().Set(useCreatedMetricGate.ID(), true))
+	defer func() {
+		require.NoError(t, featuregate.GlobalRegistry().Set(useCreatedMetricGate.ID(), false))
+	}()
+
+	created := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	mf := &dto.MetricFamily{
+		Name: "test_counter",
+		Type: dto.MetricType_COUNTER,
+		Metric: []dto.Metric{
+			{
+				Counter: &dto.Counter{
+					Value:            1234,
+					CreatedTimestamp: &types.Timestamp{Seconds: created.Unix()},
+				},
+			},
+		},
+	}
+	buffer := prometheusMetricFamilyToProtoBuf(t, nil, mf)
+
+	targets := []*testData{
+		{
+			name: "target1",
+			pages: []mockPrometheusResponse{
+				{code: 200, useProtoBuf: true, buf: buffer.Bytes()},
+			},
+			validateFunc: func(t *testing.T, td *testData, result []pmetric.ResourceMetrics) {
+				verifyNumValidScrapeResults(t, td, result)
+				var found bool
+				for _, metric := range getMetrics(result[0]) {
+					if metric.Name() != "test_counter" {
+						continue
+					}
+					found = true
+					require.Equal(t, pmetric.MetricTypeSum, metric.Type())
+					require.Equal(t, 1, metric.Sum().DataPoints().Len())
+					pt := metric.Sum().DataPoints().At(0)
+					assert.Equal(t, 1234.0, pt.DoubleValue())
+					assert.Equal(t, pcommon.NewTimestampFromTime(created), pt.StartTimestamp())
+				}
+				assert.True(t, found, "test_counter is missing")
+			},
+		},
+	}
+
+	testComponent(t, targets, func(c *Config) {
+		c.PrometheusConfig.GlobalConfig.ScrapeProtocols = []config.ScrapeProtocol{config.PrometheusProto}
+	})
+}
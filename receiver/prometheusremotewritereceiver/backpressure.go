@@ -0,0 +1,126 @@
+// Copyright  The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package prometheusremotewritereceiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/prometheusremotewritereceiver"
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/receiver/prometheusremotewritereceiver/translator/prometheusremotewrite"
+	"go.opentelemetry.io/collector/consumer/consumererror"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+)
+
+// consumerRetryAfter is how long senders are asked to wait before retrying a
+// request the pipeline failed to consume, for example because the memory
+// limiter refused it.
+const consumerRetryAfter = 5 * time.Second
+
+// writeConsumeError replies to a request the pipeline failed to consume, of
+// which accepted were converted. Permanent errors are reported as 400 so that
+// senders drop the request, other errors as 503 so that they retry it and
+// back off.
+func writeConsumeError(w http.ResponseWriter, v2 bool, accepted writtenCounts, err error) {
+	if v2 {
+		var written writtenCounts
+		var partial consumererror.Metrics
+		if errors.As(err, &partial) {
+			written = accepted.sub(failedCounts(partial.Data()))
+		}
+		writeV2ResponseHeaders(w, written)
+	}
+	if consumererror.IsPermanent(err) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	w.Header().Set("Retry-After", retryAfterSeconds(consumerRetryAfter))
+	http.Error(w, err.Error(), http.StatusServiceUnavailable)
+}
+
+// writtenCounts counts the samples, native histograms and exemplars of a
+// request.
+type writtenCounts struct {
+	samples    int
+	histograms int
+	exemplars  int
+}
+
+func (c writtenCounts) sub(o writtenCounts) writtenCounts {
+	return writtenCounts{
+		samples:    max(c.samples-o.samples, 0),
+		histograms: max(c.histograms-o.histograms, 0),
+		exemplars:  max(c.exemplars-o.exemplars, 0),
+	}
+}
+
+// acceptedCounts returns the samples, native histograms and exemplars of a
+// request converted into data points, those dropped by the conversion, for
+// example for being too old, being not written.
+func acceptedCounts(accepted *prometheusremotewrite.AcceptedSeries) writtenCounts {
+	return writtenCounts{
+		samples:    accepted.Samples,
+		histograms: accepted.Histograms,
+		exemplars:  accepted.Exemplars,
+	}
+}
+
+// failedCounts counts the samples, native histograms and exemplars the data
+// points of md were converted from. A classic histogram or summary point comes
+// from one sample per bucket or quantile, plus its sum and count.
+func failedCounts(md pmetric.Metrics) writtenCounts {
+	var c writtenCounts
+	rms := md.ResourceMetrics()
+	for i := 0; i < rms.Len(); i++ {
+		sms := rms.At(i).ScopeMetrics()
+		for j := 0; j < sms.Len(); j++ {
+			ms := sms.At(j).Metrics()
+			for k := 0; k < ms.Len(); k++ {
+				m := ms.At(k)
+				switch m.Type() {
+				case pmetric.MetricTypeGauge:
+					c.addNumberPoints(m.Gauge().DataPoints())
+				case pmetric.MetricTypeSum:
+					c.addNumberPoints(m.Sum().DataPoints())
+				case pmetric.MetricTypeHistogram:
+					dps := m.Histogram().DataPoints()
+					for l := 0; l < dps.Len(); l++ {
+						c.samples += dps.At(l).BucketCounts().Len() + 2
+						c.exemplars += dps.At(l).Exemplars().Len()
+					}
+				case pmetric.MetricTypeSummary:
+					dps := m.Summary().DataPoints()
+					for l := 0; l < dps.Len(); l++ {
+						c.samples += dps.At(l).QuantileValues().Len() + 2
+					}
+				case pmetric.MetricTypeExponentialHistogram:
+					dps := m.ExponentialHistogram().DataPoints()
+					for l := 0; l < dps.Len(); l++ {
+						c.histograms++
+						c.exemplars += dps.At(l).Exemplars().Len()
+					}
+				}
+			}
+		}
+	}
+	return c
+}
+
+func (c *writtenCounts) addNumberPoints(dps pmetric.NumberDataPointSlice) {
+	for i := 0; i < dps.Len(); i++ {
+		c.samples++
+		c.exemplars += dps.At(i).Exemplars().Len()
+	}
+}
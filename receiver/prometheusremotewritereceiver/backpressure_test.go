@@ -0,0 +1,130 @@
+// Copyright  The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package prometheusremotewritereceiver
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/pkg/translator/prometheusremotewrite/writev2"
+	"github.com/prometheus/prometheus/prompb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/consumer/consumererror"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/receiver/receivertest"
+)
+
+func serveWith(t *testing.T, next consumer.Metrics, r *http.Request) *httptest.ResponseRecorder {
+	rec, err := NewReceiver(receivertest.NewNopCreateSettings(), createDefaultConfig().(*Config), next)
+	require.NoError(t, err)
+	w := httptest.NewRecorder()
+	rec.ServeHTTP(w, r)
+	return w
+}
+
+func TestServeHTTPConsumerErrors(t *testing.T) {
+	body, err := writeRequestWith(1, "up").Marshal()
+	require.NoError(t, err)
+
+	w := serveWith(t, consumertest.NewErr(consumererror.NewPermanent(errors.New("invalid"))), newWriteRequest("", body))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Empty(t, w.Header().Get("Retry-After"))
+
+	w = serveWith(t, consumertest.NewErr(errors.New("data refused due to high memory usage")), newWriteRequest("", body))
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.Equal(t, "5", w.Header().Get("Retry-After"))
+}
+
+func TestServeHTTPPartialWrite(t *testing.T) {
+	ms := time.Now().UnixMilli()
+	symbols := writev2.NewSymbolTable()
+	req := &writev2.Request{}
+	for _, name := range []string{"up", "down"} {
+		req.Timeseries = append(req.Timeseries, writev2.TimeSeries{
+			LabelsRefs: symbols.SymbolizeLabels([]prompb.Label{{Name: "__name__", Value: name}}, nil),
+			Samples:    []writev2.Sample{{Value: 1, Timestamp: ms}},
+			Metadata:   writev2.Metadata{Type: writev2.MetricTypeGauge},
+		})
+	}
+	req.Symbols = symbols.Symbols()
+	body, err := req.Marshal()
+	require.NoError(t, err)
+
+	next, err := consumer.NewMetrics(func(_ context.Context, md pmetric.Metrics) error {
+		failed := pmetric.NewMetrics()
+		m := failed.ResourceMetrics().AppendEmpty().ScopeMetrics().AppendEmpty().Metrics().AppendEmpty()
+		md.ResourceMetrics().At(0).ScopeMetrics().At(0).Metrics().At(0).CopyTo(m)
+		return consumererror.NewMetrics(errors.New("queue is full"), failed)
+	})
+	require.NoError(t, err)
+
+	w := serveWith(t, next, newWriteRequest(writev2.ContentType, body))
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.Equal(t, "1", w.Header().Get(samplesWrittenHeader))
+	assert.Equal(t, "0", w.Header().Get(histogramsWrittenHeader))
+}
+
+func TestServeHTTPWrittenHeaders(t *testing.T) {
+	now := time.Now()
+	symbols := writev2.NewSymbolTable()
+	req := &writev2.Request{Timeseries: []writev2.TimeSeries{
+		{
+			LabelsRefs: symbols.SymbolizeLabels([]prompb.Label{{Name: "__name__", Value: "up"}}, nil),
+			Samples:    []writev2.Sample{{Value: 1, Timestamp: now.UnixMilli()}},
+			Exemplars:  []writev2.Exemplar{{Value: 1, Timestamp: now.UnixMilli()}},
+			Metadata:   writev2.Metadata{Type: writev2.MetricTypeGauge},
+		},
+		{
+			// Too far in the future, dropped with its exemplar.
+			LabelsRefs: symbols.SymbolizeLabels([]prompb.Label{{Name: "__name__", Value: "down"}}, nil),
+			Samples:    []writev2.Sample{{Value: 1, Timestamp: now.Add(time.Hour).UnixMilli()}},
+			Exemplars:  []writev2.Exemplar{{Value: 1, Timestamp: now.Add(time.Hour).UnixMilli()}},
+			Metadata:   writev2.Metadata{Type: writev2.MetricTypeGauge},
+		},
+	}}
+	req.Symbols = symbols.Symbols()
+	body, err := req.Marshal()
+	require.NoError(t, err)
+
+	cfg := createDefaultConfig().(*Config)
+	cfg.FutureTimeThreshold = time.Minute
+	rec, err := NewReceiver(receivertest.NewNopCreateSettings(), cfg, consumertest.NewNop())
+	require.NoError(t, err)
+	w := httptest.NewRecorder()
+	rec.ServeHTTP(w, newWriteRequest(writev2.ContentType, body))
+
+	assert.Equal(t, http.StatusAccepted, w.Code)
+	assert.Equal(t, "1", w.Header().Get(samplesWrittenHeader))
+	assert.Equal(t, "0", w.Header().Get(histogramsWrittenHeader))
+	assert.Equal(t, "1", w.Header().Get(exemplarsWrittenHeader))
+}
+
+func TestFailedCounts(t *testing.T) {
+	md := pmetric.NewMetrics()
+	ms := md.ResourceMetrics().AppendEmpty().ScopeMetrics().AppendEmpty().Metrics()
+	ms.AppendEmpty().SetEmptySum().DataPoints().AppendEmpty().Exemplars().AppendEmpty()
+	ms.AppendEmpty().SetEmptyHistogram().DataPoints().AppendEmpty().BucketCounts().FromRaw([]uint64{1, 2, 3})
+	ms.AppendEmpty().SetEmptySummary().DataPoints().AppendEmpty().QuantileValues().AppendEmpty()
+	ms.AppendEmpty().SetEmptyExponentialHistogram().DataPoints().AppendEmpty()
+
+	assert.Equal(t, writtenCounts{samples: 1 + 5 + 3, histograms: 1, exemplars: 1}, failedCounts(md))
+}
@@ -15,17 +15,97 @@
 package prometheusremotewritereceiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/prometheusremotewritereceiver"
 
 import (
+	"fmt"
+	"strings"
+	"time"
+
 	"go.opentelemetry.io/collector/component"
 	"go.opentelemetry.io/collector/config/confighttp"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/receiver/prometheusremotewritereceiver/translator/prometheusremotewrite"
 )
 
 // Config - remote write
 type Config struct {
 	confighttp.ServerConfig `mapstructure:",squash"`
 	TimeThreshold           int64 `mapstructure:"time_threshold"`
+	// FutureTimeThreshold drops samples further ahead of the current time, 0 disables the check.
+	FutureTimeThreshold time.Duration `mapstructure:"future_time_threshold"`
+	// OutOfOrder is what to do with samples older than the last sample of their series: pass, drop or flag.
+	OutOfOrder prometheusremotewrite.OutOfOrderPolicy `mapstructure:"out_of_order"`
+	// Tenant identifies the tenant of each request and limits what it can send.
+	Tenant TenantConfig `mapstructure:"tenant"`
+	// RemoteRead serves the recently received series over the remote-read protocol.
+	RemoteRead RemoteReadConfig `mapstructure:"remote_read"`
+}
+
+// RemoteReadConfig configures the remote-read endpoint.
+type RemoteReadConfig struct {
+	// Enabled turns the remote-read endpoint on.
+	Enabled bool `mapstructure:"enabled"`
+	// Path is the URL path of the remote-read endpoint. Requests to other paths
+	// are remote-write requests.
+	Path string `mapstructure:"path"`
+	// MaxSeries is the number of series kept in memory. The series updated least
+	// recently are evicted first.
+	MaxSeries int `mapstructure:"max_series"`
+	// SamplesPerSeries is the number of samples, and of native histograms, kept
+	// for each series.
+	SamplesPerSeries int `mapstructure:"samples_per_series"`
+	// MaxRequestSize is the maximum size in bytes of a request, both compressed
+	// and uncompressed.
+	MaxRequestSize int64 `mapstructure:"max_request_size"`
+	// MaxQueries is the maximum number of queries in a request.
+	MaxQueries int `mapstructure:"max_queries"`
+}
+
+// TenantConfig configures multi-tenancy.
+type TenantConfig struct {
+	// Header holds the tenant of a request, for example X-Scope-OrgID. When it is
+	// empty, all requests belong to the same tenant.
+	Header string `mapstructure:"header"`
+	// Limits apply to each tenant separately. Zero values disable a limit.
+	Limits TenantLimits `mapstructure:"limits"`
+}
+
+// TenantLimits are the limits applied to each tenant.
+type TenantLimits struct {
+	// MaxSeries is the maximum number of series with samples in the last 10 minutes.
+	MaxSeries int `mapstructure:"max_series"`
+	// MaxSamplesPerSecond is the rate of samples and native histograms accepted.
+	MaxSamplesPerSecond float64 `mapstructure:"max_samples_per_second"`
+	// MaxSamplesBurst is the largest number of samples accepted at once. It
+	// defaults to MaxSamplesPerSecond. Larger requests are rejected for good,
+	// so it must not be lower than the number of samples senders batch into a
+	// request (max_samples_per_send in Prometheus, 2000 by default).
+	MaxSamplesBurst int `mapstructure:"max_samples_burst"`
+	// MaxRequestSize is the maximum size in bytes of a compressed request.
+	MaxRequestSize int64 `mapstructure:"max_request_size"`
 }
 
 func (c *Config) Validate() error {
+	if c.FutureTimeThreshold < 0 {
+		return fmt.Errorf("future_time_threshold can't be negative")
+	}
+	limits := c.Tenant.Limits
+	if limits.MaxSeries < 0 || limits.MaxSamplesPerSecond < 0 || limits.MaxSamplesBurst < 0 || limits.MaxRequestSize < 0 {
+		return fmt.Errorf("tenant limits can't be negative")
+	}
+	if c.RemoteRead.Enabled {
+		if !strings.HasPrefix(c.RemoteRead.Path, "/") {
+			return fmt.Errorf("remote_read path must start with /, got %q", c.RemoteRead.Path)
+		}
+		rr := c.RemoteRead
+		if rr.MaxSeries <= 0 || rr.SamplesPerSeries <= 0 || rr.MaxRequestSize <= 0 || rr.MaxQueries <= 0 {
+			return fmt.Errorf("remote_read max_series, samples_per_series, max_request_size and max_queries must be positive")
+		}
+	}
+	switch c.OutOfOrder {
+	case "", prometheusremotewrite.OutOfOrderPass, prometheusremotewrite.OutOfOrderDrop, prometheusremotewrite.OutOfOrderFlag:
+	default:
+		return fmt.Errorf("out_of_order must be one of %q, %q or %q, got %q",
+			prometheusremotewrite.OutOfOrderPass, prometheusremotewrite.OutOfOrderDrop, prometheusremotewrite.OutOfOrderFlag, c.OutOfOrder)
+	}
 	return nil
 }
 
@@ -17,6 +17,7 @@ package prometheusremotewritereceiver
 import (
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -25,6 +26,8 @@ import (
 	"go.opentelemetry.io/collector/config/confighttp"
 	"go.opentelemetry.io/collector/config/configtls"
 	"go.opentelemetry.io/collector/confmap/confmaptest"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/receiver/prometheusremotewritereceiver/translator/prometheusremotewrite"
 )
 
 func TestLoadConfig(t *testing.T) {
@@ -49,7 +52,25 @@ func TestLoadConfig(t *testing.T) {
 					Auth:               (*configauth.Authentication)(nil),
 					MaxRequestBodySize: 0,
 					IncludeMetadata:    false},
-				TimeThreshold: 24,
+				TimeThreshold:       24,
+				FutureTimeThreshold: 10 * time.Minute,
+				OutOfOrder:          prometheusremotewrite.OutOfOrderDrop,
+				Tenant: TenantConfig{
+					Header: "X-Scope-OrgID",
+					Limits: TenantLimits{
+						MaxSeries:           100000,
+						MaxSamplesPerSecond: 50000,
+						MaxRequestSize:      4194304,
+					},
+				},
+				RemoteRead: RemoteReadConfig{
+					Enabled:          true,
+					Path:             "/api/v1/read",
+					MaxSeries:        5000,
+					SamplesPerSeries: 240,
+					MaxRequestSize:   1048576,
+					MaxQueries:       16,
+				},
 			},
 		},
 	}
@@ -66,3 +87,28 @@ func TestLoadConfig(t *testing.T) {
 		})
 	}
 }
+
+func TestValidateConfig(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	assert.NoError(t, cfg.Validate())
+
+	cfg.OutOfOrder = "reorder"
+	assert.EqualError(t, cfg.Validate(), `out_of_order must be one of "pass", "drop" or "flag", got "reorder"`)
+
+	cfg.OutOfOrder = prometheusremotewrite.OutOfOrderFlag
+	cfg.FutureTimeThreshold = -time.Second
+	assert.EqualError(t, cfg.Validate(), "future_time_threshold can't be negative")
+
+	cfg.FutureTimeThreshold = 0
+	cfg.Tenant.Limits.MaxSeries = -1
+	assert.EqualError(t, cfg.Validate(), "tenant limits can't be negative")
+
+	cfg.Tenant.Limits.MaxSeries = 0
+	cfg.RemoteRead.Enabled = true
+	assert.NoError(t, cfg.Validate())
+	cfg.RemoteRead.Path = "api/v1/read"
+	assert.EqualError(t, cfg.Validate(), `remote_read path must start with /, got "api/v1/read"`)
+	cfg.RemoteRead.Path = defaultRemoteReadPath
+	cfg.RemoteRead.MaxQueries = 0
+	assert.EqualError(t, cfg.Validate(), "remote_read max_series, samples_per_series, max_request_size and max_queries must be positive")
+}
@@ -0,0 +1,137 @@
+// Copyright  The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package prometheusremotewritereceiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/prometheusremotewritereceiver"
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+
+	"github.com/golang/snappy"
+	prwtranslator "github.com/open-telemetry/opentelemetry-collector-contrib/pkg/translator/prometheusremotewrite"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/pkg/translator/prometheusremotewrite/writev2"
+	"github.com/prometheus/prometheus/prompb"
+)
+
+const (
+	protobufContentType = "application/x-protobuf"
+
+	samplesWrittenHeader    = "X-Prometheus-Remote-Write-Samples-Written"
+	histogramsWrittenHeader = "X-Prometheus-Remote-Write-Histograms-Written"
+	exemplarsWrittenHeader  = "X-Prometheus-Remote-Write-Exemplars-Written"
+)
+
+var (
+	errUnsupportedContentType = errors.New("unsupported content type")
+	errMissingTenant          = errors.New("missing tenant header")
+)
+
+// decodeWriteRequest decodes a Remote Write 1.0 or 2.0 request according to
+// its content type. Remote Write 2.0 requests are converted into their 1.0
+// form and also returned as sent.
+func decodeWriteRequest(r *http.Request) (*prompb.WriteRequest, *writev2.Request, error) {
+	contentType := r.Header.Get("Content-Type")
+	if contentType == "" {
+		// Senders predating Remote Write 2.0 may omit the content type.
+		req, err := decodeWriteRequestV1(r.Body)
+		return req, nil, err
+	}
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %w", errUnsupportedContentType, err)
+	}
+	if mediaType != protobufContentType {
+		return nil, nil, fmt.Errorf("%w: %q", errUnsupportedContentType, contentType)
+	}
+	switch params["proto"] {
+	case "", writev2.ProtoMessageV1:
+		req, err := decodeWriteRequestV1(r.Body)
+		return req, nil, err
+	case writev2.ProtoMessage:
+		b, err := readSnappy(r.Body)
+		if err != nil {
+			return nil, nil, err
+		}
+		var v2 writev2.Request
+		if err = v2.Unmarshal(b); err != nil {
+			return nil, nil, err
+		}
+		req, err := prwtranslator.FromWriteRequestV2(&v2)
+		if err != nil {
+			return nil, nil, err
+		}
+		return req, &v2, nil
+	default:
+		return nil, nil, fmt.Errorf("%w: %q", errUnsupportedContentType, contentType)
+	}
+}
+
+// decodeWriteRequestV1 is the equivalent of Prometheus' remote.DecodeWriteRequest,
+// whose package registers feature gates clashing with the translator's.
+func decodeWriteRequestV1(r io.Reader) (*prompb.WriteRequest, error) {
+	b, err := readSnappy(r)
+	if err != nil {
+		return nil, err
+	}
+	var req prompb.WriteRequest
+	if err := req.Unmarshal(b); err != nil {
+		return nil, err
+	}
+	return &req, nil
+}
+
+func readSnappy(r io.Reader) ([]byte, error) {
+	compressed, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	return snappy.Decode(nil, compressed)
+}
+
+func statusCodeFor(err error) int {
+	var limitErr *limitError
+	switch {
+	case errors.Is(err, errUnsupportedContentType):
+		return http.StatusUnsupportedMediaType
+	case errors.Is(err, errMissingTenant):
+		return http.StatusUnauthorized
+	case errors.As(err, &limitErr) && limitErr.retryAfter == 0:
+		return http.StatusRequestEntityTooLarge
+	case errors.As(err, &limitErr):
+		return http.StatusTooManyRequests
+	}
+	return http.StatusBadRequest
+}
+
+// writeError replies with the status code of err, telling senders when to
+// retry requests rejected by a limit.
+func writeError(w http.ResponseWriter, err error) {
+	var limitErr *limitError
+	if errors.As(err, &limitErr) && limitErr.retryAfter > 0 {
+		w.Header().Set("Retry-After", retryAfterSeconds(limitErr.retryAfter))
+	}
+	http.Error(w, err.Error(), statusCodeFor(err))
+}
+
+// writeV2ResponseHeaders reports how much of a Remote Write 2.0 request was
+// written, as required by the protocol.
+func writeV2ResponseHeaders(w http.ResponseWriter, written writtenCounts) {
+	w.Header().Set(samplesWrittenHeader, strconv.Itoa(written.samples))
+	w.Header().Set(histogramsWrittenHeader, strconv.Itoa(written.histograms))
+	w.Header().Set(exemplarsWrittenHeader, strconv.Itoa(written.exemplars))
+}
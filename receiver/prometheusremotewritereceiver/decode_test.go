@@ -0,0 +1,79 @@
+// Copyright  The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package prometheusremotewritereceiver
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang/snappy"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/pkg/translator/prometheusremotewrite/writev2"
+	"github.com/prometheus/prometheus/prompb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newWriteRequest(contentType string, body []byte) *http.Request {
+	r := httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(snappy.Encode(nil, body)))
+	if contentType != "" {
+		r.Header.Set("Content-Type", contentType)
+	}
+	return r
+}
+
+func TestDecodeWriteRequest(t *testing.T) {
+	v1 := &prompb.WriteRequest{Timeseries: []prompb.TimeSeries{{
+		Labels:  []prompb.Label{{Name: "__name__", Value: "up"}},
+		Samples: []prompb.Sample{{Value: 1, Timestamp: 1000}},
+	}}}
+	v1Body, err := v1.Marshal()
+	require.NoError(t, err)
+
+	symbols := writev2.NewSymbolTable()
+	v2 := &writev2.Request{Timeseries: []writev2.TimeSeries{{
+		LabelsRefs:       symbols.SymbolizeLabels([]prompb.Label{{Name: "__name__", Value: "requests_total"}}, nil),
+		Samples:          []writev2.Sample{{Value: 3, Timestamp: 2000}},
+		Metadata:         writev2.Metadata{Type: writev2.MetricTypeCounter},
+		CreatedTimestamp: 1000,
+	}}}
+	v2.Symbols = symbols.Symbols()
+	v2Body, err := v2.Marshal()
+	require.NoError(t, err)
+
+	for _, contentType := range []string{"", "application/x-protobuf", "application/x-protobuf;proto=prometheus.WriteRequest"} {
+		req, gotV2, err := decodeWriteRequest(newWriteRequest(contentType, v1Body))
+		require.NoError(t, err, contentType)
+		assert.Nil(t, gotV2)
+		assert.Equal(t, v1.Timeseries, req.Timeseries)
+	}
+
+	req, gotV2, err := decodeWriteRequest(newWriteRequest(writev2.ContentType, v2Body))
+	require.NoError(t, err)
+	require.NotNil(t, gotV2)
+	require.Len(t, req.Timeseries, 2)
+	assert.Equal(t, "requests_total_created", req.Timeseries[1].Labels[0].Value)
+	assert.Equal(t, []prompb.MetricMetadata{{Type: prompb.MetricMetadata_COUNTER, MetricFamilyName: "requests_total"}}, req.Metadata)
+
+}
+
+func TestDecodeWriteRequestUnsupportedContentType(t *testing.T) {
+	for _, contentType := range []string{"application/json", "application/x-protobuf;proto=io.prometheus.write.v3.Request", ";;"} {
+		_, _, err := decodeWriteRequest(newWriteRequest(contentType, nil))
+		require.Error(t, err, contentType)
+		assert.Equal(t, http.StatusUnsupportedMediaType, statusCodeFor(err), contentType)
+	}
+}
@@ -21,6 +21,8 @@ import (
 	"go.opentelemetry.io/collector/config/confighttp"
 	"go.opentelemetry.io/collector/consumer"
 	"go.opentelemetry.io/collector/receiver"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/receiver/prometheusremotewritereceiver/translator/prometheusremotewrite"
 )
 
 const (
@@ -28,6 +30,12 @@ const (
 	defaultBindEndpoint  = "0.0.0.0:19291"
 	stability            = component.StabilityLevelDevelopment
 	defaultTimeThreshold = 24
+
+	defaultRemoteReadPath      = "/api/v1/read"
+	defaultRemoteReadMaxSeries = 10000
+	defaultRemoteReadSamples   = 240
+	defaultRemoteReadMaxSize   = 1 << 20
+	defaultRemoteReadQueries   = 16
 )
 
 var componentType = component.MustNewType(typeStr)
@@ -46,6 +54,14 @@ func createDefaultConfig() component.Config {
 			Endpoint: defaultBindEndpoint,
 		},
 		TimeThreshold: defaultTimeThreshold,
+		OutOfOrder:    prometheusremotewrite.OutOfOrderPass,
+		RemoteRead: RemoteReadConfig{
+			Path:             defaultRemoteReadPath,
+			MaxSeries:        defaultRemoteReadMaxSeries,
+			SamplesPerSeries: defaultRemoteReadSamples,
+			MaxRequestSize:   defaultRemoteReadMaxSize,
+			MaxQueries:       defaultRemoteReadQueries,
+		},
 	}
 }
 
@@ -0,0 +1,172 @@
+// Copyright  The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package prometheusremotewritereceiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/prometheusremotewritereceiver"
+
+import (
+	"container/list"
+	"sort"
+	"sync"
+
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/prompb"
+)
+
+// memStore keeps the last samples and native histograms of the most recently
+// updated series, so that they can be served over remote read.
+type memStore struct {
+	maxSeries int
+	capacity  int
+
+	mu     sync.Mutex
+	series map[string]*list.Element
+	// lru holds *memSeries, the most recently updated first.
+	lru *list.List
+}
+
+type memSeries struct {
+	key        string
+	tenant     string
+	labels     labels.Labels
+	samples    ring[prompb.Sample]
+	histograms ring[prompb.Histogram]
+}
+
+func newMemStore(cfg RemoteReadConfig) *memStore {
+	return &memStore{
+		maxSeries: cfg.MaxSeries,
+		capacity:  cfg.SamplesPerSeries,
+		series:    map[string]*list.Element{},
+		lru:       list.New(),
+	}
+}
+
+// append stores the samples and native histograms of tss for tenant,
+// evicting the series updated least recently when there are too many.
+func (s *memStore) append(tenant string, tss []prompb.TimeSeries) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var buf []byte
+	for _, ts := range tss {
+		if len(ts.Samples) == 0 && len(ts.Histograms) == 0 {
+			continue
+		}
+		ls := toLabels(ts.Labels)
+		buf = ls.Bytes(buf)
+		key := tenant + "\xff" + string(buf)
+		elem, ok := s.series[key]
+		if ok {
+			s.lru.MoveToFront(elem)
+		} else {
+			elem = s.lru.PushFront(&memSeries{key: key, tenant: tenant, labels: ls})
+			s.series[key] = elem
+		}
+		series := elem.Value.(*memSeries)
+		for _, sample := range ts.Samples {
+			series.samples.push(sample, s.capacity)
+		}
+		for _, h := range ts.Histograms {
+			series.histograms.push(h, s.capacity)
+		}
+	}
+	for s.lru.Len() > s.maxSeries {
+		oldest := s.lru.Back()
+		s.lru.Remove(oldest)
+		delete(s.series, oldest.Value.(*memSeries).key)
+	}
+}
+
+// query returns the series of tenant matching all matchers, with their
+// samples and native histograms between start and end inclusive, sorted by
+// labels and by time.
+func (s *memStore) query(tenant string, matchers []*labels.Matcher, start, end int64) []*prompb.TimeSeries {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var matched []*memSeries
+	for elem := s.lru.Front(); elem != nil; elem = elem.Next() {
+		series := elem.Value.(*memSeries)
+		if series.tenant == tenant && matchesAll(series.labels, matchers) {
+			matched = append(matched, series)
+		}
+	}
+	sort.Slice(matched, func(i, j int) bool {
+		return labels.Compare(matched[i].labels, matched[j].labels) < 0
+	})
+
+	var out []*prompb.TimeSeries
+	for _, series := range matched {
+		ts := &prompb.TimeSeries{}
+		series.samples.each(func(sample prompb.Sample) {
+			if sample.Timestamp >= start && sample.Timestamp <= end {
+				ts.Samples = append(ts.Samples, sample)
+			}
+		})
+		series.histograms.each(func(h prompb.Histogram) {
+			if h.Timestamp >= start && h.Timestamp <= end {
+				ts.Histograms = append(ts.Histograms, h)
+			}
+		})
+		if len(ts.Samples) == 0 && len(ts.Histograms) == 0 {
+			continue
+		}
+		// Samples may have been received out of order.
+		sort.SliceStable(ts.Samples, func(i, j int) bool { return ts.Samples[i].Timestamp < ts.Samples[j].Timestamp })
+		sort.SliceStable(ts.Histograms, func(i, j int) bool { return ts.Histograms[i].Timestamp < ts.Histograms[j].Timestamp })
+		series.labels.Range(func(l labels.Label) {
+			ts.Labels = append(ts.Labels, prompb.Label{Name: l.Name, Value: l.Value})
+		})
+		out = append(out, ts)
+	}
+	return out
+}
+
+func matchesAll(ls labels.Labels, matchers []*labels.Matcher) bool {
+	for _, m := range matchers {
+		if !m.Matches(ls.Get(m.Name)) {
+			return false
+		}
+	}
+	return true
+}
+
+func toLabels(ls []prompb.Label) labels.Labels {
+	b := labels.NewScratchBuilder(len(ls))
+	for _, l := range ls {
+		b.Add(l.Name, l.Value)
+	}
+	b.Sort()
+	return b.Labels()
+}
+
+// ring is a fixed-capacity buffer overwriting its oldest items.
+type ring[T any] struct {
+	items []T
+	next  int
+}
+
+func (r *ring[T]) push(v T, capacity int) {
+	if len(r.items) < capacity {
+		r.items = append(r.items, v)
+		return
+	}
+	r.items[r.next] = v
+	r.next = (r.next + 1) % capacity
+}
+
+// each calls f for each item, the oldest first.
+func (r *ring[T]) each(f func(T)) {
+	for i := range r.items {
+		f(r.items[(r.next+i)%len(r.items)])
+	}
+}
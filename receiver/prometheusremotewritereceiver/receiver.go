@@ -15,15 +15,18 @@
 package prometheusremotewritereceiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/prometheusremotewritereceiver"
 
 import (
+	"bytes"
 	"context"
 	"errors"
+	"io"
 	"net"
 	"net/http"
 	"sync"
+	"time"
 
 	// "github.com/open-telemetry/opentelemetry-collector-contrib/pkg/translator/prometheusremotewrite"
 	"github.com/open-telemetry/opentelemetry-collector-contrib/receiver/prometheusremotewritereceiver/translator/prometheusremotewrite"
-	"github.com/prometheus/prometheus/storage/remote"
+	"go.opentelemetry.io/collector/client"
 	"go.opentelemetry.io/collector/component"
 	"go.opentelemetry.io/collector/consumer"
 	"go.opentelemetry.io/collector/receiver"
@@ -33,6 +36,19 @@ import (
 
 const (
 	receiverFormat = "protobuf"
+
+	// metadataCacheSize bounds the number of metric families whose metadata is
+	// kept, over all tenants.
+	metadataCacheSize = 100000
+	// metadataTTL is how long metadata is kept after it was last sent.
+	// Prometheus sends it every minute by default.
+	metadataTTL = 10 * time.Minute
+	// targetInfoCacheSize bounds the number of targets whose target_info is
+	// kept, over all tenants.
+	targetInfoCacheSize = 100000
+	// targetInfoTTL is how long the target_info of a target is kept after its
+	// last sample.
+	targetInfoTTL = 10 * time.Minute
 )
 
 var errNilNextConsumer = errors.New("nil next consumer")
@@ -49,12 +65,18 @@ type PrometheusRemoteWriteReceiver struct {
 	startOnce  sync.Once
 	stopOnce   sync.Once
 	shutdownWG sync.WaitGroup
+	done       chan struct{}
 
 	server        *http.Server
 	config        *Config
 	timeThreshold *int64
 	logger        *zap.Logger
 	obsrecv       *receiverhelper.ObsReport
+	metadata      *prometheusremotewrite.MetadataCache
+	targetInfo    *prometheusremotewrite.TargetInfoCache
+	series        *prometheusremotewrite.SeriesTracker
+	limiters      *tenantLimiters
+	store         *memStore
 }
 
 // NewReceiver - remote write
@@ -70,6 +92,14 @@ func NewReceiver(settings receiver.CreateSettings, config *Config, consumer cons
 		logger:        settings.Logger,
 		obsrecv:       obsrecv,
 		timeThreshold: &config.TimeThreshold,
+		metadata:      prometheusremotewrite.NewMetadataCache(metadataCacheSize, metadataTTL),
+		targetInfo:    prometheusremotewrite.NewTargetInfoCache(targetInfoCacheSize, targetInfoTTL),
+		series:        prometheusremotewrite.NewSeriesTracker(),
+		limiters:      newTenantLimiters(config.Tenant.Limits),
+		done:          make(chan struct{}),
+	}
+	if config.RemoteRead.Enabled {
+		zr.store = newMemStore(config.RemoteRead)
 	}
 	return zr, err
 }
@@ -91,6 +121,13 @@ func (rec *PrometheusRemoteWriteReceiver) Start(_ context.Context, host componen
 		if err != nil {
 			return
 		}
+		if rec.limiters.enabled() {
+			rec.shutdownWG.Add(1)
+			go func() {
+				defer rec.shutdownWG.Done()
+				rec.limiters.pruneEvery(tenantPruneInterval, rec.done)
+			}()
+		}
 		rec.shutdownWG.Add(1)
 		go func() {
 			defer rec.shutdownWG.Done()
@@ -103,22 +140,72 @@ func (rec *PrometheusRemoteWriteReceiver) Start(_ context.Context, host componen
 }
 
 func (rec *PrometheusRemoteWriteReceiver) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if rec.store != nil && r.URL.Path == rec.config.RemoteRead.Path {
+		rec.serveRead(w, r)
+		return
+	}
 
 	ctx := rec.obsrecv.StartMetricsOp(r.Context())
-	req, err := remote.DecodeWriteRequest(r.Body)
+	tenant, err := rec.readTenant(r)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		rec.obsrecv.EndMetricsOp(ctx, receiverFormat, 0, err)
+		writeError(w, err)
+		return
+	}
+	req, v2, err := decodeWriteRequest(r)
+	if err != nil {
+		rec.obsrecv.EndMetricsOp(ctx, receiverFormat, 0, err)
+		writeError(w, err)
 		return
 	}
+	if err = rec.limiters.admit(tenant, req, time.Now()); err != nil {
+		// Refused samples are reported like those the pipeline refuses.
+		rec.obsrecv.EndMetricsOp(ctx, receiverFormat, countSamples(req), err)
+		writeError(w, err)
+		return
+	}
+	if rec.config.Tenant.Header != "" && !rec.config.IncludeMetadata {
+		// Let the rest of the pipeline tell tenants apart, as IncludeMetadata would.
+		info := client.FromContext(ctx)
+		info.Metadata = client.NewMetadata(map[string][]string{rec.config.Tenant.Header: {tenant}})
+		ctx = client.NewContext(ctx, info)
+	}
+
+	// Prometheus sends metadata in its own requests, so keep it for the
+	// samples that follow.
+	rec.metadata.Update(tenant, req.Metadata)
+
+	// Series which have not sent anything within the time threshold would
+	// have their samples dropped anyway.
+	rec.series.Prune(time.Now().Add(-time.Duration(*rec.timeThreshold) * time.Hour))
 
+	// The accepted samples are kept for remote read, and counted for the
+	// response headers of Remote Write 2.0.
+	accepted := prometheusremotewrite.NewAcceptedCounts()
+	if rec.store != nil {
+		accepted = prometheusremotewrite.NewAcceptedSeries()
+	}
 	pms, err := prometheusremotewrite.FromTimeSeries(req.Timeseries, prometheusremotewrite.Settings{
-		TimeThreshold: *rec.timeThreshold,
-		Logger:        *rec.logger,
+		TimeThreshold:   *rec.timeThreshold,
+		Logger:          *rec.logger,
+		Tenant:          tenant,
+		Metadata:        rec.metadata,
+		TargetInfo:      rec.targetInfo,
+		FutureThreshold: rec.config.FutureTimeThreshold,
+		OutOfOrder:      rec.config.OutOfOrder,
+		Series:          rec.series,
+		Accepted:        accepted,
 	})
 	if err != nil {
+		rec.obsrecv.EndMetricsOp(ctx, receiverFormat, 0, err)
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
+	if rec.config.Tenant.Header != "" {
+		for i := 0; i < pms.ResourceMetrics().Len(); i++ {
+			pms.ResourceMetrics().At(i).Resource().Attributes().PutStr(tenantAttribute, tenant)
+		}
+	}
 
 	metricCount := pms.ResourceMetrics().Len()
 	dataPointCount := pms.DataPointCount()
@@ -126,14 +213,64 @@ func (rec *PrometheusRemoteWriteReceiver) ServeHTTP(w http.ResponseWriter, r *ht
 		err = rec.nextConsumer.ConsumeMetrics(ctx, pms)
 	}
 	rec.obsrecv.EndMetricsOp(ctx, receiverFormat, dataPointCount, err)
+	if err != nil {
+		rec.logger.Debug("Failed to pass metrics to next consumer", zap.Error(err))
+		writeConsumeError(w, v2 != nil, acceptedCounts(accepted), err)
+		return
+	}
+	if rec.store != nil {
+		rec.store.append(tenant, accepted.Timeseries)
+	}
+	if v2 != nil {
+		writeV2ResponseHeaders(w, acceptedCounts(accepted))
+	}
 	w.WriteHeader(http.StatusAccepted)
 }
 
+// readTenant returns the tenant of a request and checks the request size
+// against its limit, buffering the body when the size is not known upfront.
+func (rec *PrometheusRemoteWriteReceiver) readTenant(r *http.Request) (string, error) {
+	tenant, err := rec.tenantOf(r)
+	if err != nil {
+		return "", err
+	}
+	maxSize := rec.config.Tenant.Limits.MaxRequestSize
+	if maxSize <= 0 {
+		return tenant, nil
+	}
+	if r.ContentLength >= 0 {
+		return tenant, rec.limiters.checkSize(tenant, r.ContentLength)
+	}
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxSize+1))
+	if err != nil {
+		return "", err
+	}
+	if err = rec.limiters.checkSize(tenant, int64(len(body))); err != nil {
+		return "", err
+	}
+	r.Body = io.NopCloser(bytes.NewReader(body))
+	return tenant, nil
+}
+
+// tenantOf returns the tenant of a request, which is empty when no tenant
+// header is configured.
+func (rec *PrometheusRemoteWriteReceiver) tenantOf(r *http.Request) (string, error) {
+	if rec.config.Tenant.Header == "" {
+		return "", nil
+	}
+	tenant := r.Header.Get(rec.config.Tenant.Header)
+	if tenant == "" {
+		return "", errMissingTenant
+	}
+	return tenant, nil
+}
+
 // Shutdown - remote write
 func (rec *PrometheusRemoteWriteReceiver) Shutdown(context.Context) error {
 	var err = errNilNextConsumer
 	rec.stopOnce.Do(func() {
 		err = rec.server.Close()
+		close(rec.done)
 		rec.shutdownWG.Wait()
 	})
 	return err
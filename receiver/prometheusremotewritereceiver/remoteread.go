@@ -0,0 +1,338 @@
+// Copyright  The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package prometheusremotewritereceiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/prometheusremotewritereceiver"
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"net/http"
+
+	"github.com/golang/snappy"
+	"github.com/prometheus/prometheus/model/histogram"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/prompb"
+	"github.com/prometheus/prometheus/tsdb/chunkenc"
+	"go.uber.org/zap"
+)
+
+const (
+	streamedContentType = "application/x-streamed-protobuf; proto=prometheus.ChunkedReadResponse"
+	// maxSamplesPerChunk is the number of samples Prometheus puts in a chunk.
+	maxSamplesPerChunk = 120
+)
+
+var castagnoliTable = crc32.MakeTable(crc32.Castagnoli)
+
+// serveRead answers a remote-read request from the series kept in memory.
+func (rec *PrometheusRemoteWriteReceiver) serveRead(w http.ResponseWriter, r *http.Request) {
+	tenant, err := rec.tenantOf(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	body, err := rec.readReadRequest(w, r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	var req prompb.ReadRequest
+	if err = req.Unmarshal(body); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if maxQueries := rec.config.RemoteRead.MaxQueries; len(req.Queries) > maxQueries {
+		writeError(w, &limitError{msg: fmt.Sprintf("remote-read request has more than %d queries", maxQueries)})
+		return
+	}
+
+	results := make([][]*prompb.TimeSeries, len(req.Queries))
+	for i, q := range req.Queries {
+		matchers, err := toMatchers(q.Matchers)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		results[i] = rec.store.query(tenant, matchers, q.StartTimestampMs, q.EndTimestampMs)
+	}
+
+	if negotiateReadResponse(req.AcceptedResponseTypes) == prompb.ReadRequest_STREAMED_XOR_CHUNKS {
+		w.Header().Set("Content-Type", streamedContentType)
+		if err = writeChunkedResults(w, results); err != nil {
+			// The status code was already sent, the sender sees a truncated stream.
+			rec.logger.Debug("Failed to stream remote-read response", zap.Error(err))
+		}
+		return
+	}
+
+	resp := &prompb.ReadResponse{Results: make([]*prompb.QueryResult, len(results))}
+	for i, tss := range results {
+		resp.Results[i] = &prompb.QueryResult{Timeseries: tss}
+	}
+	data, err := resp.Marshal()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", protobufContentType)
+	w.Header().Set("Content-Encoding", "snappy")
+	_, _ = w.Write(snappy.Encode(nil, data))
+}
+
+// readReadRequest returns the uncompressed body of a remote-read request,
+// rejecting requests larger than the configured size before or after
+// decompression.
+func (rec *PrometheusRemoteWriteReceiver) readReadRequest(w http.ResponseWriter, r *http.Request) ([]byte, error) {
+	maxSize := rec.config.RemoteRead.MaxRequestSize
+	tooLarge := &limitError{msg: fmt.Sprintf("remote-read request is larger than %d bytes", maxSize)}
+	compressed, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxSize))
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		return nil, tooLarge
+	} else if err != nil {
+		return nil, err
+	}
+	size, err := snappy.DecodedLen(compressed)
+	if err != nil {
+		return nil, err
+	}
+	if int64(size) > maxSize {
+		return nil, tooLarge
+	}
+	return snappy.Decode(nil, compressed)
+}
+
+// negotiateReadResponse returns the first response type accepted by the
+// sender which is supported, in the order of preference of the sender.
+func negotiateReadResponse(accepted []prompb.ReadRequest_ResponseType) prompb.ReadRequest_ResponseType {
+	for _, t := range accepted {
+		switch t {
+		case prompb.ReadRequest_SAMPLES, prompb.ReadRequest_STREAMED_XOR_CHUNKS:
+			return t
+		}
+	}
+	return prompb.ReadRequest_SAMPLES
+}
+
+func toMatchers(matchers []*prompb.LabelMatcher) ([]*labels.Matcher, error) {
+	out := make([]*labels.Matcher, 0, len(matchers))
+	for _, m := range matchers {
+		var typ labels.MatchType
+		switch m.Type {
+		case prompb.LabelMatcher_EQ:
+			typ = labels.MatchEqual
+		case prompb.LabelMatcher_NEQ:
+			typ = labels.MatchNotEqual
+		case prompb.LabelMatcher_RE:
+			typ = labels.MatchRegexp
+		case prompb.LabelMatcher_NRE:
+			typ = labels.MatchNotRegexp
+		default:
+			return nil, fmt.Errorf("invalid matcher type %v", m.Type)
+		}
+		matcher, err := labels.NewMatcher(typ, m.Name, m.Value)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, matcher)
+	}
+	return out, nil
+}
+
+// writeChunkedResults streams each series of results in its own
+// ChunkedReadResponse frame.
+func writeChunkedResults(w http.ResponseWriter, results [][]*prompb.TimeSeries) error {
+	flusher, _ := w.(http.Flusher)
+	for i, tss := range results {
+		for _, ts := range tss {
+			chunks, err := encodeChunks(ts)
+			if err != nil {
+				return err
+			}
+			frame, err := (&prompb.ChunkedReadResponse{
+				ChunkedSeries: []*prompb.ChunkedSeries{{Labels: ts.Labels, Chunks: chunks}},
+				QueryIndex:    int64(i),
+			}).Marshal()
+			if err != nil {
+				return err
+			}
+			if err = writeFrame(w, frame); err != nil {
+				return err
+			}
+			if flusher != nil {
+				flusher.Flush()
+			}
+		}
+	}
+	return nil
+}
+
+// writeFrame writes a frame of a streamed response: the uvarint size of data,
+// its big-endian CRC32 Castagnoli checksum, then data.
+func writeFrame(w io.Writer, data []byte) error {
+	header := make([]byte, binary.MaxVarintLen64+4)
+	n := binary.PutUvarint(header, uint64(len(data)))
+	binary.BigEndian.PutUint32(header[n:], crc32.Checksum(data, castagnoliTable))
+	if _, err := w.Write(header[:n+4]); err != nil {
+		return err
+	}
+	_, err := w.Write(data)
+	return err
+}
+
+// encodeChunks encodes the samples and native histograms of ts, which are
+// sorted by time, into Prometheus chunks.
+func encodeChunks(ts *prompb.TimeSeries) ([]prompb.Chunk, error) {
+	var e chunkEncoder
+	samples, histograms := ts.Samples, ts.Histograms
+	for len(samples) > 0 || len(histograms) > 0 {
+		if len(histograms) == 0 || (len(samples) > 0 && samples[0].Timestamp <= histograms[0].Timestamp) {
+			e.appendSample(samples[0])
+			samples = samples[1:]
+			continue
+		}
+		if err := e.appendHistogram(histograms[0]); err != nil {
+			return nil, err
+		}
+		histograms = histograms[1:]
+	}
+	e.flush()
+	return e.chunks, nil
+}
+
+type chunkEncoder struct {
+	chunks     []prompb.Chunk
+	chunk      chunkenc.Chunk
+	app        chunkenc.Appender
+	minT, maxT int64
+}
+
+// prepare cuts a new chunk when the current one is full or has another
+// encoding.
+func (e *chunkEncoder) prepare(enc chunkenc.Encoding, t int64) error {
+	if e.chunk != nil && e.chunk.Encoding() == enc && e.chunk.NumSamples() < maxSamplesPerChunk {
+		return nil
+	}
+	e.flush()
+	chunk, err := chunkenc.NewEmptyChunk(enc)
+	if err != nil {
+		return err
+	}
+	app, err := chunk.Appender()
+	if err != nil {
+		return err
+	}
+	e.chunk, e.app, e.minT = chunk, app, t
+	return nil
+}
+
+func (e *chunkEncoder) flush() {
+	if e.chunk == nil || e.chunk.NumSamples() == 0 {
+		return
+	}
+	e.chunks = append(e.chunks, prompb.Chunk{
+		MinTimeMs: e.minT,
+		MaxTimeMs: e.maxT,
+		Type:      prompb.Chunk_Encoding(e.chunk.Encoding()),
+		Data:      e.chunk.Bytes(),
+	})
+	e.chunk = nil
+}
+
+func (e *chunkEncoder) appendSample(s prompb.Sample) {
+	// Cutting an XOR chunk can't fail.
+	_ = e.prepare(chunkenc.EncXOR, s.Timestamp)
+	e.app.Append(s.Timestamp, s.Value)
+	e.maxT = s.Timestamp
+}
+
+func (e *chunkEncoder) appendHistogram(h prompb.Histogram) error {
+	var (
+		chunk    chunkenc.Chunk
+		recoded  bool
+		app      chunkenc.Appender
+		err      error
+		t        = h.Timestamp
+		encoding = chunkenc.EncHistogram
+	)
+	if h.IsFloatHistogram() {
+		encoding = chunkenc.EncFloatHistogram
+	}
+	if err = e.prepare(encoding, t); err != nil {
+		return err
+	}
+	if h.IsFloatHistogram() {
+		chunk, recoded, app, err = e.app.AppendFloatHistogram(nil, t, toFloatHistogram(h), false)
+	} else {
+		chunk, recoded, app, err = e.app.AppendHistogram(nil, t, toHistogram(h), false)
+	}
+	if err != nil {
+		return err
+	}
+	if chunk != nil {
+		// The histogram didn't fit the current chunk: either the chunk was
+		// recoded to a new layout, or a new chunk was started.
+		if !recoded {
+			e.flush()
+			e.minT = t
+		}
+		e.chunk = chunk
+	}
+	e.app = app
+	e.maxT = t
+	return nil
+}
+
+// toHistogram converts h, copying its buckets as appending to a chunk may
+// modify them.
+func toHistogram(h prompb.Histogram) *histogram.Histogram {
+	return &histogram.Histogram{
+		CounterResetHint: histogram.CounterResetHint(h.ResetHint),
+		Schema:           h.Schema,
+		ZeroThreshold:    h.ZeroThreshold,
+		ZeroCount:        h.GetZeroCountInt(),
+		Count:            h.GetCountInt(),
+		Sum:              h.Sum,
+		PositiveSpans:    toSpans(h.PositiveSpans),
+		PositiveBuckets:  append([]int64(nil), h.PositiveDeltas...),
+		NegativeSpans:    toSpans(h.NegativeSpans),
+		NegativeBuckets:  append([]int64(nil), h.NegativeDeltas...),
+	}
+}
+
+func toFloatHistogram(h prompb.Histogram) *histogram.FloatHistogram {
+	return &histogram.FloatHistogram{
+		CounterResetHint: histogram.CounterResetHint(h.ResetHint),
+		Schema:           h.Schema,
+		ZeroThreshold:    h.ZeroThreshold,
+		ZeroCount:        h.GetZeroCountFloat(),
+		Count:            h.GetCountFloat(),
+		Sum:              h.Sum,
+		PositiveSpans:    toSpans(h.PositiveSpans),
+		PositiveBuckets:  append([]float64(nil), h.PositiveCounts...),
+		NegativeSpans:    toSpans(h.NegativeSpans),
+		NegativeBuckets:  append([]float64(nil), h.NegativeCounts...),
+	}
+}
+
+func toSpans(spans []prompb.BucketSpan) []histogram.Span {
+	out := make([]histogram.Span, len(spans))
+	for i, s := range spans {
+		out[i] = histogram.Span{Offset: s.Offset, Length: s.Length}
+	}
+	return out
+}
@@ -0,0 +1,288 @@
+// Copyright  The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package prometheusremotewritereceiver
+
+import (
+	"bytes"
+	"encoding/binary"
+	"hash/crc32"
+	"io"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/snappy"
+	"github.com/prometheus/prometheus/model/value"
+	"github.com/prometheus/prometheus/prompb"
+	"github.com/prometheus/prometheus/tsdb/chunkenc"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.opentelemetry.io/collector/receiver/receivertest"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/receiver/prometheusremotewritereceiver/translator/prometheusremotewrite"
+)
+
+func newReadRequest(t *testing.T, req *prompb.ReadRequest) *http.Request {
+	body, err := req.Marshal()
+	require.NoError(t, err)
+	r := newWriteRequest("", body)
+	r.URL.Path = defaultRemoteReadPath
+	return r
+}
+
+func TestRemoteRead(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.RemoteRead.Enabled = true
+	rec, err := NewReceiver(receivertest.NewNopCreateSettings(), cfg, consumertest.NewNop())
+	require.NoError(t, err)
+
+	start := time.Now().UnixMilli()
+	write := &prompb.WriteRequest{}
+	for _, job := range []string{"api", "db"} {
+		ts := prompb.TimeSeries{Labels: []prompb.Label{{Name: "__name__", Value: "up"}, {Name: "job", Value: job}}}
+		for i := int64(0); i < 130; i++ {
+			ts.Samples = append(ts.Samples, prompb.Sample{Value: float64(i), Timestamp: start + i})
+		}
+		write.Timeseries = append(write.Timeseries, ts)
+	}
+	body, err := write.Marshal()
+	require.NoError(t, err)
+	w := httptest.NewRecorder()
+	rec.ServeHTTP(w, newWriteRequest("", body))
+	require.Equal(t, http.StatusAccepted, w.Code)
+
+	query := &prompb.Query{
+		StartTimestampMs: start + 10,
+		EndTimestampMs:   start + 200,
+		Matchers: []*prompb.LabelMatcher{
+			{Type: prompb.LabelMatcher_EQ, Name: "__name__", Value: "up"},
+			{Type: prompb.LabelMatcher_RE, Name: "job", Value: "a.*"},
+		},
+	}
+
+	t.Run("samples", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		rec.ServeHTTP(w, newReadRequest(t, &prompb.ReadRequest{Queries: []*prompb.Query{query}}))
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "snappy", w.Header().Get("Content-Encoding"))
+
+		data, err := snappy.Decode(nil, w.Body.Bytes())
+		require.NoError(t, err)
+		var resp prompb.ReadResponse
+		require.NoError(t, resp.Unmarshal(data))
+		require.Len(t, resp.Results, 1)
+		require.Len(t, resp.Results[0].Timeseries, 1)
+		ts := resp.Results[0].Timeseries[0]
+		assert.Equal(t, []prompb.Label{{Name: "__name__", Value: "up"}, {Name: "job", Value: "api"}}, ts.Labels)
+		require.Len(t, ts.Samples, 120)
+		assert.Equal(t, start+10, ts.Samples[0].Timestamp)
+	})
+
+	t.Run("streamed", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		rec.ServeHTTP(w, newReadRequest(t, &prompb.ReadRequest{
+			Queries:               []*prompb.Query{query},
+			AcceptedResponseTypes: []prompb.ReadRequest_ResponseType{prompb.ReadRequest_STREAMED_XOR_CHUNKS},
+		}))
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, streamedContentType, w.Header().Get("Content-Type"))
+
+		frames := readFrames(t, w.Body)
+		require.Len(t, frames, 1)
+		series := frames[0].ChunkedSeries[0]
+		assert.Equal(t, "api", labelsValue(series.Labels, "job"))
+		require.Len(t, series.Chunks, 1)
+		chunk, err := chunkenc.FromData(chunkenc.EncXOR, series.Chunks[0].Data)
+		require.NoError(t, err)
+		assert.Equal(t, 120, chunk.NumSamples())
+		assert.Equal(t, start+10, series.Chunks[0].MinTimeMs)
+		assert.Equal(t, start+129, series.Chunks[0].MaxTimeMs)
+	})
+}
+
+func TestRemoteReadOnlyAccepted(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.RemoteRead.Enabled = true
+	cfg.FutureTimeThreshold = time.Minute
+	cfg.OutOfOrder = prometheusremotewrite.OutOfOrderFlag
+	rec, err := NewReceiver(receivertest.NewNopCreateSettings(), cfg, consumertest.NewNop())
+	require.NoError(t, err)
+
+	now := time.Now().UnixMilli()
+	write := &prompb.WriteRequest{Timeseries: []prompb.TimeSeries{{
+		Labels: []prompb.Label{{Name: "__name__", Value: "up"}},
+		Samples: []prompb.Sample{
+			{Value: 1, Timestamp: now},
+			{Value: 2, Timestamp: now - 1000},
+			{Value: math.Float64frombits(value.StaleNaN), Timestamp: now + 1},
+			{Value: 3, Timestamp: now + time.Hour.Milliseconds()},
+		},
+	}}}
+	body, err := write.Marshal()
+	require.NoError(t, err)
+	w := httptest.NewRecorder()
+	rec.ServeHTTP(w, newWriteRequest("", body))
+	require.Equal(t, http.StatusAccepted, w.Code)
+
+	got := rec.store.query("", nil, 0, now+2*time.Hour.Milliseconds())
+	require.Len(t, got, 1)
+	assert.Equal(t, []prompb.Sample{{Value: 1, Timestamp: now}}, got[0].Samples)
+}
+
+func TestRemoteReadTenant(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.RemoteRead.Enabled = true
+	cfg.Tenant.Header = "X-Scope-OrgID"
+	rec, err := NewReceiver(receivertest.NewNopCreateSettings(), cfg, consumertest.NewNop())
+	require.NoError(t, err)
+	rec.store.append("team-a", writeRequestWith(1, "up").Timeseries)
+
+	query := &prompb.ReadRequest{Queries: []*prompb.Query{{EndTimestampMs: time.Now().Add(time.Minute).UnixMilli()}}}
+	w := httptest.NewRecorder()
+	rec.ServeHTTP(w, newReadRequest(t, query))
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+	for tenant, want := range map[string]int{"team-a": 1, "team-b": 0} {
+		r := newReadRequest(t, query)
+		r.Header.Set("X-Scope-OrgID", tenant)
+		w = httptest.NewRecorder()
+		rec.ServeHTTP(w, r)
+		require.Equal(t, http.StatusOK, w.Code)
+		data, err := snappy.Decode(nil, w.Body.Bytes())
+		require.NoError(t, err)
+		var resp prompb.ReadResponse
+		require.NoError(t, resp.Unmarshal(data))
+		assert.Len(t, resp.Results[0].Timeseries, want, tenant)
+	}
+}
+
+func TestRemoteReadLimits(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.RemoteRead.Enabled = true
+	cfg.RemoteRead.MaxRequestSize = 1024
+	cfg.RemoteRead.MaxQueries = 2
+	rec, err := NewReceiver(receivertest.NewNopCreateSettings(), cfg, consumertest.NewNop())
+	require.NoError(t, err)
+
+	matcher := []*prompb.LabelMatcher{{Type: prompb.LabelMatcher_EQ, Name: "__name__", Value: "up"}}
+	tests := []struct {
+		name string
+		req  *http.Request
+		code int
+	}{
+		{
+			name: "accepted",
+			req:  newReadRequest(t, &prompb.ReadRequest{Queries: []*prompb.Query{{Matchers: matcher}, {Matchers: matcher}}}),
+			code: http.StatusOK,
+		},
+		{
+			name: "too many queries",
+			req:  newReadRequest(t, &prompb.ReadRequest{Queries: []*prompb.Query{{Matchers: matcher}, {Matchers: matcher}, {Matchers: matcher}}}),
+			code: http.StatusRequestEntityTooLarge,
+		},
+		{
+			name: "compressed body too large",
+			req: func() *http.Request {
+				r := httptest.NewRequest(http.MethodPost, defaultRemoteReadPath, bytes.NewReader(make([]byte, 2048)))
+				r.ContentLength = -1
+				return r
+			}(),
+			code: http.StatusRequestEntityTooLarge,
+		},
+		{
+			// Compresses to a few bytes, but decodes beyond the limit.
+			name: "uncompressed body too large",
+			req: newReadRequest(t, &prompb.ReadRequest{Queries: []*prompb.Query{{
+				Matchers: []*prompb.LabelMatcher{{Type: prompb.LabelMatcher_EQ, Name: "job", Value: strings.Repeat("a", 4096)}},
+			}}}),
+			code: http.StatusRequestEntityTooLarge,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			rec.ServeHTTP(w, tt.req)
+			assert.Equal(t, tt.code, w.Code, w.Body.String())
+		})
+	}
+}
+
+func TestMemStoreBounds(t *testing.T) {
+	store := newMemStore(RemoteReadConfig{MaxSeries: 2, SamplesPerSeries: 3})
+	store.append("", writeRequestWith(5, "a", "b").Timeseries)
+	store.append("", writeRequestWith(1, "c").Timeseries)
+
+	got := store.query("", nil, 0, time.Now().Add(time.Minute).UnixMilli())
+	require.Len(t, got, 2)
+	assert.Equal(t, "b", got[0].Labels[0].Value)
+	assert.Len(t, got[0].Samples, 3)
+	assert.Equal(t, "c", got[1].Labels[0].Value)
+}
+
+func TestEncodeChunksHistograms(t *testing.T) {
+	ts := &prompb.TimeSeries{
+		Samples: []prompb.Sample{{Value: 1, Timestamp: 1}},
+		Histograms: []prompb.Histogram{
+			{Count: &prompb.Histogram_CountInt{CountInt: 1}, Sum: 1, Timestamp: 2, PositiveSpans: []prompb.BucketSpan{{Length: 1}}, PositiveDeltas: []int64{1}},
+			{Count: &prompb.Histogram_CountInt{CountInt: 3}, Sum: 4, Timestamp: 3, PositiveSpans: []prompb.BucketSpan{{Length: 2}}, PositiveDeltas: []int64{1, 1}},
+			{Count: &prompb.Histogram_CountFloat{CountFloat: 1.5}, Sum: 2, Timestamp: 4},
+		},
+	}
+	chunks, err := encodeChunks(ts)
+	require.NoError(t, err)
+	require.Len(t, chunks, 3)
+	assert.Equal(t, prompb.Chunk_XOR, chunks[0].Type)
+	assert.Equal(t, prompb.Chunk_HISTOGRAM, chunks[1].Type)
+	assert.Equal(t, prompb.Chunk_FLOAT_HISTOGRAM, chunks[2].Type)
+
+	// The layout change recoded the histogram chunk rather than cutting a new one.
+	assert.Equal(t, int64(2), chunks[1].MinTimeMs)
+	assert.Equal(t, int64(3), chunks[1].MaxTimeMs)
+	chunk, err := chunkenc.FromData(chunkenc.EncHistogram, chunks[1].Data)
+	require.NoError(t, err)
+	it := chunk.Iterator(nil)
+	var counts []uint64
+	for it.Next() == chunkenc.ValHistogram {
+		_, h := it.AtHistogram(nil)
+		counts = append(counts, h.Count)
+	}
+	assert.Equal(t, []uint64{1, 3}, counts)
+}
+
+func readFrames(t *testing.T, r io.Reader) []prompb.ChunkedReadResponse {
+	br := bytes.NewBuffer(nil)
+	_, err := io.Copy(br, r)
+	require.NoError(t, err)
+	var frames []prompb.ChunkedReadResponse
+	for br.Len() > 0 {
+		size, err := binary.ReadUvarint(br)
+		require.NoError(t, err)
+		checksum := binary.BigEndian.Uint32(br.Next(4))
+		data := br.Next(int(size))
+		require.Equal(t, crc32.Checksum(data, castagnoliTable), checksum)
+		var frame prompb.ChunkedReadResponse
+		require.NoError(t, frame.Unmarshal(data))
+		frames = append(frames, frame)
+	}
+	return frames
+}
+
+func labelsValue(ls []prompb.Label, name string) string {
+	return toLabels(ls).Get(name)
+}
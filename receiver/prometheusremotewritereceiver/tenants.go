@@ -0,0 +1,242 @@
+// Copyright  The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package prometheusremotewritereceiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/prometheusremotewritereceiver"
+
+import (
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/prometheus/prometheus/prompb"
+	"golang.org/x/time/rate"
+)
+
+const (
+	// tenantAttribute is the resource attribute holding the tenant of a request.
+	tenantAttribute = "tenant.id"
+	// activeSeriesWindow is how long a series counts towards the series limit
+	// of its tenant after its last sample.
+	activeSeriesWindow = 10 * time.Minute
+	// tenantPruneInterval is how often inactive series and idle tenants are
+	// forgotten. Series count towards the limit until they are pruned.
+	tenantPruneInterval = time.Minute
+)
+
+// limitError is returned when a request exceeds a limit of its tenant. Without
+// a retryAfter delay, the request can never be accepted and must not be
+// retried.
+type limitError struct {
+	msg        string
+	retryAfter time.Duration
+}
+
+func (e *limitError) Error() string {
+	return e.msg
+}
+
+// tenantLimiters holds the state of the limits of each tenant.
+type tenantLimiters struct {
+	limits TenantLimits
+
+	mu      sync.Mutex
+	tenants map[string]*tenantLimiter
+}
+
+type tenantLimiter struct {
+	samples *rate.Limiter
+
+	mu sync.Mutex
+	// lastSeen is the time of the last request of the tenant.
+	lastSeen time.Time
+	// series maps the hash of each active series to the time it was last seen.
+	series map[uint64]time.Time
+	// evicted is set once the tenant was forgotten for being idle.
+	evicted bool
+}
+
+func newTenantLimiters(limits TenantLimits) *tenantLimiters {
+	return &tenantLimiters{
+		limits:  limits,
+		tenants: map[string]*tenantLimiter{},
+	}
+}
+
+// enabled reports whether any limit needs state to be kept per tenant.
+func (l *tenantLimiters) enabled() bool {
+	return l.limits.MaxSamplesPerSecond > 0 || l.limits.MaxSeries > 0
+}
+
+// lock returns the locked state of tenant, creating it when needed.
+func (l *tenantLimiters) lock(tenant string) *tenantLimiter {
+	for {
+		l.mu.Lock()
+		t, ok := l.tenants[tenant]
+		if !ok {
+			t = &tenantLimiter{series: map[uint64]time.Time{}}
+			if l.limits.MaxSamplesPerSecond > 0 {
+				burst := l.limits.MaxSamplesBurst
+				if burst == 0 {
+					burst = int(math.Ceil(l.limits.MaxSamplesPerSecond))
+				}
+				t.samples = rate.NewLimiter(rate.Limit(l.limits.MaxSamplesPerSecond), burst)
+			}
+			l.tenants[tenant] = t
+		}
+		l.mu.Unlock()
+
+		t.mu.Lock()
+		if !t.evicted {
+			return t
+		}
+		// Evicted since it was looked up, the next lookup creates it again.
+		t.mu.Unlock()
+	}
+}
+
+// checkSize returns a limitError when a request body of size bytes exceeds
+// the request size limit.
+func (l *tenantLimiters) checkSize(tenant string, size int64) error {
+	if l.limits.MaxRequestSize > 0 && size > l.limits.MaxRequestSize {
+		return &limitError{
+			msg: fmt.Sprintf("request of tenant %q is larger than %d bytes", tenant, l.limits.MaxRequestSize),
+		}
+	}
+	return nil
+}
+
+// admit checks the samples and series of a decoded request against the limits
+// of its tenant, and accounts for them when the request is accepted.
+func (l *tenantLimiters) admit(tenant string, req *prompb.WriteRequest, now time.Time) error {
+	if !l.enabled() {
+		return nil
+	}
+	t := l.lock(tenant)
+	defer t.mu.Unlock()
+	t.lastSeen = now
+
+	var reservation *rate.Reservation
+	if t.samples != nil {
+		reservation = t.samples.ReserveN(now, countSamples(req))
+		if !reservation.OK() {
+			return &limitError{
+				msg: fmt.Sprintf("request of tenant %q has more samples than the burst of %d", tenant, t.samples.Burst()),
+			}
+		}
+		if delay := reservation.DelayFrom(now); delay > 0 {
+			reservation.CancelAt(now)
+			return &limitError{
+				msg:        fmt.Sprintf("tenant %q exceeded %v samples per second", tenant, l.limits.MaxSamplesPerSecond),
+				retryAfter: delay,
+			}
+		}
+	}
+
+	if l.limits.MaxSeries > 0 {
+		hashes := make([]uint64, 0, len(req.Timeseries))
+		newSeries := 0
+		for _, ts := range req.Timeseries {
+			hash := seriesHash(ts.Labels)
+			if _, ok := t.series[hash]; !ok {
+				newSeries++
+			}
+			hashes = append(hashes, hash)
+		}
+		if newSeries > 0 && len(t.series)+newSeries > l.limits.MaxSeries {
+			if reservation != nil {
+				reservation.CancelAt(now)
+			}
+			return &limitError{
+				msg:        fmt.Sprintf("tenant %q exceeded %d active series", tenant, l.limits.MaxSeries),
+				retryAfter: time.Minute,
+			}
+		}
+		for _, hash := range hashes {
+			t.series[hash] = now
+		}
+	}
+	return nil
+}
+
+// pruneEvery prunes the tenants every interval until done is closed.
+func (l *tenantLimiters) pruneEvery(interval time.Duration, done <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-done:
+			return
+		case now := <-ticker.C:
+			l.prune(now)
+		}
+	}
+}
+
+// prune forgets the series which are no longer active, then the tenants which
+// are idle: without active series and with all their sample budget back, so
+// that forgetting them makes no difference to their limits.
+func (l *tenantLimiters) prune(now time.Time) {
+	l.mu.Lock()
+	tenants := make([]*tenantLimiter, 0, len(l.tenants))
+	for _, t := range l.tenants {
+		tenants = append(tenants, t)
+	}
+	l.mu.Unlock()
+
+	// Each tenant is locked on its own so that requests of other tenants
+	// are not held up.
+	for _, t := range tenants {
+		t.mu.Lock()
+		for hash, lastSeen := range t.series {
+			if now.Sub(lastSeen) > activeSeriesWindow {
+				delete(t.series, hash)
+			}
+		}
+		t.mu.Unlock()
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for tenant, t := range l.tenants {
+		// A tenant with a request in progress is not idle.
+		if !t.mu.TryLock() {
+			continue
+		}
+		if len(t.series) == 0 && now.Sub(t.lastSeen) > activeSeriesWindow &&
+			(t.samples == nil || t.samples.TokensAt(now) >= float64(t.samples.Burst())) {
+			t.evicted = true
+			delete(l.tenants, tenant)
+		}
+		t.mu.Unlock()
+	}
+}
+
+func countSamples(req *prompb.WriteRequest) int {
+	n := 0
+	for _, ts := range req.Timeseries {
+		n += len(ts.Samples) + len(ts.Histograms)
+	}
+	return n
+}
+
+func seriesHash(ls []prompb.Label) uint64 {
+	return toLabels(ls).Hash()
+}
+
+// retryAfterSeconds formats a delay for the Retry-After header, rounding up
+// to whole seconds.
+func retryAfterSeconds(d time.Duration) string {
+	return fmt.Sprintf("%d", int64(math.Ceil(d.Seconds())))
+}
@@ -0,0 +1,151 @@
+// Copyright  The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package prometheusremotewritereceiver
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/prometheus/prometheus/prompb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.opentelemetry.io/collector/receiver/receivertest"
+)
+
+func writeRequestWith(n int, names ...string) *prompb.WriteRequest {
+	req := &prompb.WriteRequest{}
+	for _, name := range names {
+		ts := prompb.TimeSeries{Labels: []prompb.Label{{Name: "__name__", Value: name}}}
+		for i := 0; i < n; i++ {
+			ts.Samples = append(ts.Samples, prompb.Sample{Value: 1, Timestamp: time.Now().UnixMilli() + int64(i)})
+		}
+		req.Timeseries = append(req.Timeseries, ts)
+	}
+	return req
+}
+
+func TestTenantLimitersSamples(t *testing.T) {
+	limiters := newTenantLimiters(TenantLimits{MaxSamplesPerSecond: 10})
+	now := time.Now()
+
+	require.NoError(t, limiters.admit("a", writeRequestWith(5, "up", "down"), now))
+	err := limiters.admit("a", writeRequestWith(5, "up", "down"), now)
+	var limitErr *limitError
+	require.ErrorAs(t, err, &limitErr)
+	assert.Equal(t, time.Second, limitErr.retryAfter)
+
+	// Tenants are limited separately, and the rejected request was not counted.
+	require.NoError(t, limiters.admit("b", writeRequestWith(10, "up"), now))
+	require.NoError(t, limiters.admit("a", writeRequestWith(10, "up"), now.Add(time.Second)))
+
+	// Requests larger than the burst are never accepted.
+	err = limiters.admit("c", writeRequestWith(11, "up"), now)
+	require.ErrorAs(t, err, &limitErr)
+	assert.Zero(t, limitErr.retryAfter)
+}
+
+func TestTenantLimitersSeries(t *testing.T) {
+	limiters := newTenantLimiters(TenantLimits{MaxSeries: 2})
+	now := time.Now()
+
+	require.NoError(t, limiters.admit("a", writeRequestWith(1, "up", "down"), now))
+	require.NoError(t, limiters.admit("a", writeRequestWith(1, "up"), now))
+	assert.Error(t, limiters.admit("a", writeRequestWith(1, "sideways"), now))
+
+	// Series stop counting once they are inactive and pruned.
+	later := now.Add(activeSeriesWindow + time.Second)
+	assert.Error(t, limiters.admit("a", writeRequestWith(1, "sideways"), later))
+	limiters.prune(later)
+	require.NoError(t, limiters.admit("a", writeRequestWith(1, "sideways"), later))
+}
+
+func TestTenantLimitersPruneTenants(t *testing.T) {
+	limiters := newTenantLimiters(TenantLimits{MaxSeries: 2, MaxSamplesPerSecond: 0.5, MaxSamplesBurst: 1000})
+	now := time.Now()
+
+	require.NoError(t, limiters.admit("a", writeRequestWith(1000, "up"), now))
+	require.NoError(t, limiters.admit("b", writeRequestWith(1, "up"), now))
+	evicted := limiters.tenants["b"]
+
+	// a is idle but has not got its sample budget back yet.
+	later := now.Add(activeSeriesWindow + time.Second)
+	limiters.prune(later)
+	assert.Len(t, limiters.tenants, 1)
+	assert.Contains(t, limiters.tenants, "a")
+	assert.True(t, evicted.evicted)
+
+	limiters.prune(now.Add(time.Hour))
+	assert.Empty(t, limiters.tenants)
+
+	require.NoError(t, limiters.admit("b", writeRequestWith(1, "up"), now.Add(time.Hour)))
+	assert.NotSame(t, evicted, limiters.tenants["b"])
+}
+
+func TestServeHTTPTenant(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.Tenant = TenantConfig{
+		Header: "X-Scope-OrgID",
+		Limits: TenantLimits{MaxRequestSize: 1 << 20, MaxSeries: 1},
+	}
+	sink := new(consumertest.MetricsSink)
+	rec, err := NewReceiver(receivertest.NewNopCreateSettings(), cfg, sink)
+	require.NoError(t, err)
+
+	body, err := writeRequestWith(1, "up").Marshal()
+	require.NoError(t, err)
+
+	w := httptest.NewRecorder()
+	rec.ServeHTTP(w, newWriteRequest("", body))
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+	r := newWriteRequest("", body)
+	r.Header.Set("X-Scope-OrgID", "team-a")
+	w = httptest.NewRecorder()
+	rec.ServeHTTP(w, r)
+	assert.Equal(t, http.StatusAccepted, w.Code)
+	require.Len(t, sink.AllMetrics(), 1)
+	tenant, ok := sink.AllMetrics()[0].ResourceMetrics().At(0).Resource().Attributes().Get(tenantAttribute)
+	require.True(t, ok)
+	assert.Equal(t, "team-a", tenant.Str())
+
+	body, err = writeRequestWith(1, "down").Marshal()
+	require.NoError(t, err)
+	r = newWriteRequest("", body)
+	r.Header.Set("X-Scope-OrgID", "team-a")
+	w = httptest.NewRecorder()
+	rec.ServeHTTP(w, r)
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Equal(t, strconv.Itoa(int(time.Minute.Seconds())), w.Header().Get("Retry-After"))
+}
+
+func TestServeHTTPRequestSize(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.Tenant.Limits.MaxRequestSize = 10
+	rec, err := NewReceiver(receivertest.NewNopCreateSettings(), cfg, consumertest.NewNop())
+	require.NoError(t, err)
+
+	body, err := writeRequestWith(5, "up").Marshal()
+	require.NoError(t, err)
+	r := newWriteRequest("", body)
+	r.ContentLength = -1
+	w := httptest.NewRecorder()
+	rec.ServeHTTP(w, r)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+	assert.Empty(t, w.Header().Get("Retry-After"))
+}
@@ -0,0 +1,93 @@
+// Copyright  The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package prometheusremotewrite // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/prometheusremotewritereceiver/translator/prometheusremotewrite"
+
+import (
+	"github.com/prometheus/prometheus/model/value"
+	"github.com/prometheus/prometheus/prompb"
+)
+
+// AcceptedSeries collects the samples and native histograms FromTimeSeries
+// converted into data points, for example to keep them for remote read.
+// Staleness markers and out-of-order samples are left out, so that the
+// samples of each series stay in time order.
+type AcceptedSeries struct {
+	// Timeseries holds the accepted series in the order they were first seen,
+	// nil if created by NewAcceptedCounts.
+	Timeseries []prompb.TimeSeries
+	index      map[string]int
+
+	// Samples, Histograms and Exemplars count what was converted into data
+	// points, including the staleness markers and out-of-order samples.
+	Samples    int
+	Histograms int
+	Exemplars  int
+}
+
+// NewAcceptedSeries creates an empty AcceptedSeries.
+func NewAcceptedSeries() *AcceptedSeries {
+	return &AcceptedSeries{index: map[string]int{}}
+}
+
+// NewAcceptedCounts creates an empty AcceptedSeries which only counts the
+// converted samples, native histograms and exemplars.
+func NewAcceptedCounts() *AcceptedSeries {
+	return &AcceptedSeries{}
+}
+
+func (a *AcceptedSeries) series(labels []prompb.Label) *prompb.TimeSeries {
+	sig := labelsSignature("", labels)
+	i, ok := a.index[sig]
+	if !ok {
+		i = len(a.Timeseries)
+		a.index[sig] = i
+		a.Timeseries = append(a.Timeseries, prompb.TimeSeries{Labels: labels})
+	}
+	return &a.Timeseries[i]
+}
+
+// acceptSample records a sample of the series with labels which was kept.
+func (settings Settings) acceptSample(labels []prompb.Label, s prompb.Sample, check sampleCheck) {
+	if settings.Accepted == nil {
+		return
+	}
+	settings.Accepted.Samples++
+	if settings.Accepted.index == nil || check.outOfOrder || value.IsStaleNaN(s.Value) {
+		return
+	}
+	ts := settings.Accepted.series(labels)
+	ts.Samples = append(ts.Samples, s)
+}
+
+// acceptHistogram records a native histogram of the series with labels which
+// was converted.
+func (settings Settings) acceptHistogram(labels []prompb.Label, h prompb.Histogram, check sampleCheck) {
+	if settings.Accepted == nil {
+		return
+	}
+	settings.Accepted.Histograms++
+	if settings.Accepted.index == nil || check.outOfOrder || value.IsStaleNaN(h.Sum) {
+		return
+	}
+	ts := settings.Accepted.series(labels)
+	ts.Histograms = append(ts.Histograms, h)
+}
+
+// acceptExemplars records exemplars added to the data points.
+func (settings Settings) acceptExemplars(n int) {
+	if settings.Accepted != nil {
+		settings.Accepted.Exemplars += n
+	}
+}
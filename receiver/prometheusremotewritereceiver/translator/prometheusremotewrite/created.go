@@ -0,0 +1,111 @@
+// Copyright  The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package prometheusremotewrite // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/prometheusremotewritereceiver/translator/prometheusremotewrite"
+
+import (
+	"strings"
+
+	"github.com/prometheus/prometheus/prompb"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+)
+
+const createdSuffix = "_created"
+
+// createdTimestamps indexes the `_created` series of a request, which hold the
+// time in seconds or milliseconds at which a counter, histogram or summary
+// started. Remote Write 2.0 created timestamps are converted into such series.
+type createdTimestamps struct {
+	timestamps map[string]pcommon.Timestamp
+	used       map[string]bool
+}
+
+func findCreatedTimestamps(tss []prompb.TimeSeries) createdTimestamps {
+	c := createdTimestamps{
+		timestamps: map[string]pcommon.Timestamp{},
+		used:       map[string]bool{},
+	}
+	for _, ts := range tss {
+		name, err := finalName(ts.Labels)
+		if err != nil || !strings.HasSuffix(name, createdSuffix) || len(ts.Samples) == 0 {
+			continue
+		}
+		value := ts.Samples[len(ts.Samples)-1].Value
+		if value <= 0 {
+			continue
+		}
+		c.timestamps[labelsSignature(name, familyLabels(ts.Labels))] = createdToTimestamp(value)
+	}
+	return c
+}
+
+// lookup returns the start timestamp of the series named name, or 0 when the
+// request carries no matching `_created` series.
+func (c createdTimestamps) lookup(name string, labels []prompb.Label) pcommon.Timestamp {
+	if len(c.timestamps) == 0 || strings.HasSuffix(name, createdSuffix) {
+		return 0
+	}
+	ls := familyLabels(labels)
+	for _, candidate := range createdCandidates(name) {
+		sig := labelsSignature(candidate, ls)
+		if ts, ok := c.timestamps[sig]; ok {
+			c.used[sig] = true
+			return ts
+		}
+	}
+	return 0
+}
+
+// consumed reports whether the `_created` series was used as the start
+// timestamp of another series.
+func (c createdTimestamps) consumed(name string, labels []prompb.Label) bool {
+	return c.used[labelsSignature(name, familyLabels(labels))]
+}
+
+// createdCandidates returns the names of the `_created` series which may hold
+// the start timestamp of a series named name.
+func createdCandidates(name string) []string {
+	candidates := []string{name + createdSuffix}
+	for _, suffix := range []string{totalStr, bucketStr, sumStr, countStr} {
+		if strings.HasSuffix(name, suffix) {
+			candidates = append(candidates, strings.TrimSuffix(name, suffix)+createdSuffix)
+		}
+	}
+	return candidates
+}
+
+// familyLabels returns the labels shared by all series of a metric family.
+func familyLabels(labels []prompb.Label) []prompb.Label {
+	out := make([]prompb.Label, 0, len(labels))
+	for _, l := range labels {
+		switch l.Name {
+		case nameStr, leStr, quantileStr:
+		default:
+			out = append(out, l)
+		}
+	}
+	return out
+}
+
+// createdToTimestamp converts the value of a `_created` series. Prometheus
+// client libraries expose it in seconds while Remote Write 2.0 created
+// timestamps are in milliseconds; values too large to be seconds are treated
+// as milliseconds.
+func createdToTimestamp(value float64) pcommon.Timestamp {
+	const maxSeconds = 1e11 // year 5138
+	if value < maxSeconds {
+		return pcommon.Timestamp(value * 1e9)
+	}
+	return timestampFromMillis(int64(value))
+}
@@ -0,0 +1,61 @@
+// Copyright  The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package prometheusremotewrite
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/prometheus/prompb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+)
+
+func TestFromTimeSeries_CreatedTimestamps(t *testing.T) {
+	start := now.Add(-time.Hour).Truncate(time.Millisecond)
+	startMillis := float64(start.UnixMilli())
+	startSeconds := float64(start.Unix())
+	tss := []prompb.TimeSeries{
+		series("http_requests_total", 10, "method", "GET"),
+		series("http_requests_created", startSeconds, "method", "GET"),
+		series("latency_bucket", 1, "le", "1"),
+		series("latency_bucket", 2, "le", "+Inf"),
+		series("latency_count", 2),
+		series("latency_created", startMillis),
+		series("orphan_created", startSeconds),
+	}
+
+	got, err := FromTimeSeries(tss, testSettings())
+	require.NoError(t, err)
+	require.Equal(t, 1, got.ResourceMetrics().Len())
+	metrics := got.ResourceMetrics().At(0).ScopeMetrics().At(0).Metrics()
+	require.Equal(t, 3, metrics.Len())
+
+	counter := metrics.At(0)
+	assert.Equal(t, "http_requests_total", counter.Name())
+	require.Equal(t, pmetric.MetricTypeSum, counter.Type())
+	assert.Equal(t, pcommon.NewTimestampFromTime(start.Truncate(time.Second)), counter.Sum().DataPoints().At(0).StartTimestamp())
+
+	histogram := metrics.At(1)
+	assert.Equal(t, "latency", histogram.Name())
+	require.Equal(t, pmetric.MetricTypeHistogram, histogram.Type())
+	assert.Equal(t, pcommon.NewTimestampFromTime(start), histogram.Histogram().DataPoints().At(0).StartTimestamp())
+
+	// A `_created` series without a parent series is kept as is.
+	orphan := metrics.At(2)
+	assert.Equal(t, "orphan_created", orphan.Name())
+}
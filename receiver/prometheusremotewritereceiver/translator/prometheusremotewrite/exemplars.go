@@ -0,0 +1,88 @@
+// Copyright  The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package prometheusremotewrite // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/prometheusremotewritereceiver/translator/prometheusremotewrite"
+
+import (
+	"encoding/hex"
+
+	"github.com/prometheus/prometheus/prompb"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+)
+
+const (
+	traceIDKey = "trace_id"
+	spanIDKey  = "span_id"
+)
+
+type exemplarPoint interface {
+	Timestamp() pcommon.Timestamp
+	Exemplars() pmetric.ExemplarSlice
+}
+
+// attachExemplars adds the exemplars of a series to the data points converted
+// from it, and returns the number of exemplars added. Since exemplars are not
+// tied to a sample, each one goes to the first point at or after its
+// timestamp, or to the last point.
+func attachExemplars[T exemplarPoint](exemplars []prompb.Exemplar, points []T) int {
+	if len(points) == 0 {
+		return 0
+	}
+	for _, e := range exemplars {
+		ts := timestampFromMillis(e.Timestamp)
+		point := points[len(points)-1]
+		for _, p := range points {
+			if p.Timestamp() >= ts {
+				point = p
+				break
+			}
+		}
+		putExemplar(e, point.Exemplars().AppendEmpty())
+	}
+	return len(exemplars)
+}
+
+// putExemplar converts a Prometheus exemplar. The `trace_id` and `span_id`
+// labels written by the exporter become the trace and span IDs; other labels,
+// and IDs which cannot be decoded, are kept as filtered attributes.
+func putExemplar(e prompb.Exemplar, dst pmetric.Exemplar) {
+	dst.SetDoubleValue(e.Value)
+	dst.SetTimestamp(timestampFromMillis(e.Timestamp))
+	for _, l := range e.Labels {
+		switch l.Name {
+		case traceIDKey:
+			var id pcommon.TraceID
+			if decodeID(l.Value, id[:]) {
+				dst.SetTraceID(id)
+				continue
+			}
+		case spanIDKey:
+			var id pcommon.SpanID
+			if decodeID(l.Value, id[:]) {
+				dst.SetSpanID(id)
+				continue
+			}
+		}
+		dst.FilteredAttributes().PutStr(l.Name, l.Value)
+	}
+}
+
+func decodeID(s string, dst []byte) bool {
+	if hex.DecodedLen(len(s)) != len(dst) {
+		return false
+	}
+	_, err := hex.Decode(dst, []byte(s))
+	return err == nil
+}
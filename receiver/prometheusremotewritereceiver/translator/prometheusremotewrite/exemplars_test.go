@@ -0,0 +1,114 @@
+// Copyright  The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package prometheusremotewrite
+
+import (
+	"testing"
+
+	"github.com/prometheus/prometheus/prompb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+
+	exportertranslator "github.com/open-telemetry/opentelemetry-collector-contrib/pkg/translator/prometheusremotewrite"
+)
+
+var (
+	testTraceID = pcommon.TraceID([16]byte{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16})
+	testSpanID  = pcommon.SpanID([8]byte{1, 2, 3, 4, 5, 6, 7, 8})
+)
+
+func TestExemplarRoundTrip(t *testing.T) {
+	md := pmetric.NewMetrics()
+	metrics := md.ResourceMetrics().AppendEmpty().ScopeMetrics().AppendEmpty().Metrics()
+
+	counter := metrics.AppendEmpty()
+	counter.SetName("http_requests_total")
+	counter.SetEmptySum().SetIsMonotonic(true)
+	counter.Sum().SetAggregationTemporality(pmetric.AggregationTemporalityCumulative)
+	dp := counter.Sum().DataPoints().AppendEmpty()
+	dp.SetTimestamp(pcommon.Timestamp(nowMillis * 1e6))
+	dp.SetDoubleValue(10)
+	e := dp.Exemplars().AppendEmpty()
+	e.SetTimestamp(pcommon.Timestamp((nowMillis - 10) * 1e6))
+	e.SetDoubleValue(1)
+	e.SetTraceID(testTraceID)
+	e.SetSpanID(testSpanID)
+	e.FilteredAttributes().PutStr("user", "alice")
+
+	hist := metrics.AppendEmpty()
+	hist.SetName("latency")
+	hist.SetEmptyHistogram().SetAggregationTemporality(pmetric.AggregationTemporalityCumulative)
+	hdp := hist.Histogram().DataPoints().AppendEmpty()
+	hdp.SetTimestamp(pcommon.Timestamp(nowMillis * 1e6))
+	hdp.ExplicitBounds().FromRaw([]float64{1})
+	hdp.BucketCounts().FromRaw([]uint64{1, 1})
+	hdp.SetCount(2)
+	he := hdp.Exemplars().AppendEmpty()
+	he.SetTimestamp(pcommon.Timestamp(nowMillis * 1e6))
+	he.SetDoubleValue(0.5)
+	he.SetTraceID(testTraceID)
+
+	tsMap, err := exportertranslator.FromMetrics(md, exportertranslator.Settings{DisableTargetInfo: true})
+	require.NoError(t, err)
+	var tss []prompb.TimeSeries
+	for _, ts := range tsMap {
+		tss = append(tss, *ts)
+	}
+
+	got, err := FromTimeSeries(tss, testSettings())
+	require.NoError(t, err)
+	gotMetrics := got.ResourceMetrics().At(0).ScopeMetrics().At(0).Metrics()
+	require.Equal(t, 2, gotMetrics.Len())
+	for i := 0; i < gotMetrics.Len(); i++ {
+		m := gotMetrics.At(i)
+		switch m.Name() {
+		case "http_requests_total":
+			require.Equal(t, 1, m.Sum().DataPoints().At(0).Exemplars().Len())
+			gotExemplar := m.Sum().DataPoints().At(0).Exemplars().At(0)
+			assert.Equal(t, testTraceID, gotExemplar.TraceID())
+			assert.Equal(t, testSpanID, gotExemplar.SpanID())
+			assert.Equal(t, 1.0, gotExemplar.DoubleValue())
+			assert.Equal(t, e.Timestamp(), gotExemplar.Timestamp())
+			assert.Equal(t, map[string]any{"user": "alice"}, gotExemplar.FilteredAttributes().AsRaw())
+		case "latency":
+			require.Equal(t, 1, m.Histogram().DataPoints().At(0).Exemplars().Len())
+			gotExemplar := m.Histogram().DataPoints().At(0).Exemplars().At(0)
+			assert.Equal(t, testTraceID, gotExemplar.TraceID())
+			assert.True(t, gotExemplar.SpanID().IsEmpty())
+			assert.Equal(t, 0.5, gotExemplar.DoubleValue())
+		}
+	}
+}
+
+func TestAttachExemplars(t *testing.T) {
+	ts := series("queue_depth", 1)
+	ts.Samples = append(ts.Samples, prompb.Sample{Value: 2, Timestamp: nowMillis + 1000})
+	ts.Exemplars = []prompb.Exemplar{
+		{Value: 1, Timestamp: nowMillis + 500, Labels: []prompb.Label{{Name: traceIDKey, Value: "not-hex"}}},
+		{Value: 2, Timestamp: nowMillis + 2000},
+	}
+
+	got, err := FromTimeSeries([]prompb.TimeSeries{ts}, testSettings())
+	require.NoError(t, err)
+	points := got.ResourceMetrics().At(0).ScopeMetrics().At(0).Metrics().At(0).Gauge().DataPoints()
+	require.Equal(t, 2, points.Len())
+	assert.Equal(t, 0, points.At(0).Exemplars().Len())
+	require.Equal(t, 2, points.At(1).Exemplars().Len())
+	invalid := points.At(1).Exemplars().At(0)
+	assert.True(t, invalid.TraceID().IsEmpty())
+	assert.Equal(t, map[string]any{traceIDKey: "not-hex"}, invalid.FilteredAttributes().AsRaw())
+}
@@ -0,0 +1,90 @@
+// Copyright  The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package prometheusremotewrite // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/prometheusremotewritereceiver/translator/prometheusremotewrite"
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// expiringCache keeps what senders announce separately from the samples it
+// applies to. Entries expire when they are not put again within the TTL, and
+// the least recently put ones are evicted once the cache is full. Zero values
+// disable the bound and the expiry.
+type expiringCache[K comparable, V any] struct {
+	maxEntries int
+	ttl        time.Duration
+
+	mu      sync.Mutex
+	entries map[K]*list.Element
+	// lru orders the entries from the least to the most recently put.
+	lru *list.List
+}
+
+type cacheEntry[K comparable, V any] struct {
+	key     K
+	value   V
+	updated time.Time
+}
+
+func newExpiringCache[K comparable, V any](maxEntries int, ttl time.Duration) *expiringCache[K, V] {
+	return &expiringCache[K, V]{
+		maxEntries: maxEntries,
+		ttl:        ttl,
+		entries:    map[K]*list.Element{},
+		lru:        list.New(),
+	}
+}
+
+// put sets the value of key, updated at now.
+func (c *expiringCache[K, V]) put(key K, value V, now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if elem, ok := c.entries[key]; ok {
+		entry := elem.Value.(*cacheEntry[K, V])
+		entry.value = value
+		entry.updated = now
+		c.lru.MoveToBack(elem)
+		return
+	}
+	c.entries[key] = c.lru.PushBack(&cacheEntry[K, V]{key: key, value: value, updated: now})
+	for c.maxEntries > 0 && c.lru.Len() > c.maxEntries {
+		c.remove(c.lru.Front())
+	}
+}
+
+// get returns the value of key unless it expired before now.
+func (c *expiringCache[K, V]) get(key K, now time.Time) (V, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	elem, ok := c.entries[key]
+	if !ok {
+		var zero V
+		return zero, false
+	}
+	entry := elem.Value.(*cacheEntry[K, V])
+	if c.ttl > 0 && now.Sub(entry.updated) > c.ttl {
+		c.remove(elem)
+		var zero V
+		return zero, false
+	}
+	return entry.value, true
+}
+
+func (c *expiringCache[K, V]) remove(elem *list.Element) {
+	delete(c.entries, elem.Value.(*cacheEntry[K, V]).key)
+	c.lru.Remove(elem)
+}
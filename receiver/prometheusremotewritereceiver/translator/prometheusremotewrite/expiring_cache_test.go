@@ -0,0 +1,53 @@
+// Copyright  The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package prometheusremotewrite
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExpiringCache_Bounded(t *testing.T) {
+	now := time.Now()
+	cache := newExpiringCache[string, int](2, 0)
+	cache.put("first", 1, now)
+	cache.put("second", 2, now)
+	// Putting first again makes second the least recently put entry.
+	cache.put("first", 1, now)
+	cache.put("third", 3, now)
+
+	_, ok := cache.get("first", now)
+	assert.True(t, ok)
+	_, ok = cache.get("second", now)
+	assert.False(t, ok)
+	v, ok := cache.get("third", now)
+	assert.True(t, ok)
+	assert.Equal(t, 3, v)
+}
+
+func TestExpiringCache_Expiry(t *testing.T) {
+	now := time.Now()
+	cache := newExpiringCache[string, int](0, time.Minute)
+	cache.put("old", 1, now.Add(-2*time.Minute))
+	cache.put("recent", 2, now.Add(-30*time.Second))
+
+	_, ok := cache.get("old", now)
+	assert.False(t, ok)
+	_, ok = cache.get("recent", now)
+	assert.True(t, ok)
+	assert.Equal(t, 1, cache.lru.Len())
+}
@@ -0,0 +1,366 @@
+// Copyright  The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package prometheusremotewrite // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/prometheusremotewritereceiver/translator/prometheusremotewrite"
+
+import (
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/prometheus/prometheus/model/value"
+	"github.com/prometheus/prometheus/prompb"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+)
+
+const (
+	sumStr      = "_sum"
+	countStr    = "_count"
+	bucketStr   = "_bucket"
+	leStr       = "le"
+	quantileStr = "quantile"
+)
+
+// classicFamilies records which base names in a WriteRequest belong to classic
+// histograms or summaries. A family is recognized by its `_bucket` series with
+// an `le` label or its series with a `quantile` label, or by the metadata sent
+// for its base name.
+type classicFamilies struct {
+	histograms map[string]bool
+	summaries  map[string]bool
+}
+
+func findClassicFamilies(tss []prompb.TimeSeries, settings Settings) classicFamilies {
+	families := classicFamilies{
+		histograms: map[string]bool{},
+		summaries:  map[string]bool{},
+	}
+	for _, ts := range tss {
+		name, err := finalName(ts.Labels)
+		if err != nil {
+			continue
+		}
+		if strings.HasSuffix(name, bucketStr) && hasLabel(ts.Labels, leStr) {
+			families.histograms[strings.TrimSuffix(name, bucketStr)] = true
+			continue
+		} else if hasLabel(ts.Labels, quantileStr) {
+			families.summaries[name] = true
+			continue
+		}
+		for _, suffix := range []string{sumStr, countStr} {
+			if !strings.HasSuffix(name, suffix) {
+				continue
+			}
+			base := strings.TrimSuffix(name, suffix)
+			if md, ok := settings.lookupMetadata(base); ok {
+				switch md.Type {
+				case prompb.MetricMetadata_HISTOGRAM:
+					families.histograms[base] = true
+				case prompb.MetricMetadata_SUMMARY:
+					families.summaries[base] = true
+				}
+			}
+		}
+	}
+	return families
+}
+
+// classicPart is one of the series that make up a classic histogram or summary.
+type classicPart int
+
+const (
+	partBucket classicPart = iota
+	partQuantile
+	partSum
+	partCount
+)
+
+// match reports whether the series named name is part of a known histogram or
+// summary family, and returns the family's base name, type and the part the
+// series contributes.
+func (f classicFamilies) match(name string, labels []prompb.Label) (string, pmetric.MetricType, classicPart, bool) {
+	switch {
+	case strings.HasSuffix(name, bucketStr) && f.histograms[strings.TrimSuffix(name, bucketStr)] && hasLabel(labels, leStr):
+		return strings.TrimSuffix(name, bucketStr), pmetric.MetricTypeHistogram, partBucket, true
+	case f.summaries[name] && hasLabel(labels, quantileStr):
+		return name, pmetric.MetricTypeSummary, partQuantile, true
+	}
+	for _, suffix := range []string{sumStr, countStr} {
+		if !strings.HasSuffix(name, suffix) {
+			continue
+		}
+		part := partSum
+		if suffix == countStr {
+			part = partCount
+		}
+		base := strings.TrimSuffix(name, suffix)
+		if f.histograms[base] {
+			return base, pmetric.MetricTypeHistogram, part, true
+		}
+		if f.summaries[base] {
+			return base, pmetric.MetricTypeSummary, part, true
+		}
+	}
+	return "", pmetric.MetricTypeEmpty, 0, false
+}
+
+// classicPoint accumulates the samples of one histogram or summary data point.
+type classicPoint struct {
+	timestamp int64
+	bounds    map[float64]float64
+	quantiles map[float64]float64
+	sum       float64
+	count     float64
+	hasSum    bool
+	hasCount  bool
+	// stale is set when any series of the point sent a staleness marker.
+	stale      bool
+	outOfOrder bool
+}
+
+// classicGroup gathers all series of one histogram or summary sharing the same
+// label set, ignoring `le` and `quantile`.
+type classicGroup struct {
+	name       string
+	metricType pmetric.MetricType
+	labels     []prompb.Label
+	points     map[int64]*classicPoint
+	// exemplars of the bucket series, summaries cannot hold exemplars.
+	exemplars []prompb.Exemplar
+}
+
+func newClassicGroup(name string, metricType pmetric.MetricType, labels []prompb.Label) *classicGroup {
+	var groupLabels []prompb.Label
+	for _, l := range labels {
+		if l.Name == nameStr || l.Name == leStr || l.Name == quantileStr {
+			continue
+		}
+		groupLabels = append(groupLabels, l)
+	}
+	return &classicGroup{
+		name:       name,
+		metricType: metricType,
+		labels:     groupLabels,
+		points:     map[int64]*classicPoint{},
+	}
+}
+
+func (g *classicGroup) point(timestamp int64) *classicPoint {
+	p, ok := g.points[timestamp]
+	if !ok {
+		p = &classicPoint{
+			timestamp: timestamp,
+			bounds:    map[float64]float64{},
+			quantiles: map[float64]float64{},
+		}
+		g.points[timestamp] = p
+	}
+	return p
+}
+
+// add records a sample belonging to part. The `le` and `quantile` labels must
+// have been checked with validClassicLabels beforehand.
+func (g *classicGroup) add(part classicPart, labels []prompb.Label, s prompb.Sample, outOfOrder bool) {
+	p := g.point(s.Timestamp)
+	p.stale = p.stale || value.IsStaleNaN(s.Value)
+	p.outOfOrder = p.outOfOrder || outOfOrder
+	switch part {
+	case partBucket:
+		bound, _ := strconv.ParseFloat(labelValue(labels, leStr), 64)
+		p.bounds[bound] = s.Value
+	case partQuantile:
+		quantile, _ := strconv.ParseFloat(labelValue(labels, quantileStr), 64)
+		p.quantiles[quantile] = s.Value
+	case partSum:
+		p.sum, p.hasSum = s.Value, true
+	case partCount:
+		p.count, p.hasCount = s.Value, true
+	}
+}
+
+// validClassicLabels reports whether the `le` or `quantile` label a part
+// relies on can be parsed as a float.
+func validClassicLabels(part classicPart, labels []prompb.Label) bool {
+	var err error
+	switch part {
+	case partBucket:
+		_, err = strconv.ParseFloat(labelValue(labels, leStr), 64)
+	case partQuantile:
+		_, err = strconv.ParseFloat(labelValue(labels, quantileStr), 64)
+	}
+	return err == nil
+}
+
+// sortedPoints returns the group's data points in timestamp order.
+func (g *classicGroup) sortedPoints() []*classicPoint {
+	points := make([]*classicPoint, 0, len(g.points))
+	for _, p := range g.points {
+		points = append(points, p)
+	}
+	sort.Slice(points, func(i, j int) bool { return points[i].timestamp < points[j].timestamp })
+	return points
+}
+
+// appendTo adds the points of the group to pm, and returns the number of
+// exemplars added.
+func (g *classicGroup) appendTo(pm pmetric.Metric, startTimestamp pcommon.Timestamp) int {
+	switch g.metricType {
+	case pmetric.MetricTypeHistogram:
+		if pm.Type() != pmetric.MetricTypeHistogram {
+			pm.SetEmptyHistogram().SetAggregationTemporality(pmetric.AggregationTemporalityCumulative)
+		}
+		hist := pm.Histogram()
+		points := make([]pmetric.HistogramDataPoint, 0, len(g.points))
+		for _, p := range g.sortedPoints() {
+			dp := hist.DataPoints().AppendEmpty()
+			dp.SetStartTimestamp(startTimestamp)
+			dp.SetTimestamp(timestampFromMillis(p.timestamp))
+			g.putAttributes(dp.Attributes())
+			putOutOfOrder(dp.Attributes(), p.outOfOrder)
+			dp.SetFlags(p.flags())
+			p.toHistogramDataPoint(dp)
+			points = append(points, dp)
+		}
+		return attachExemplars(g.exemplars, points)
+	case pmetric.MetricTypeSummary:
+		if pm.Type() != pmetric.MetricTypeSummary {
+			pm.SetEmptySummary()
+		}
+		summary := pm.Summary()
+		for _, p := range g.sortedPoints() {
+			dp := summary.DataPoints().AppendEmpty()
+			dp.SetStartTimestamp(startTimestamp)
+			dp.SetTimestamp(timestampFromMillis(p.timestamp))
+			g.putAttributes(dp.Attributes())
+			putOutOfOrder(dp.Attributes(), p.outOfOrder)
+			dp.SetFlags(p.flags())
+			p.toSummaryDataPoint(dp)
+		}
+	}
+	return 0
+}
+
+func (g *classicGroup) putAttributes(attrs pcommon.Map) {
+	attrs.PutStr("key_name", g.name)
+	for _, l := range g.labels {
+		if isResourceLabel(l.Name) {
+			continue
+		}
+		attrs.PutStr(l.Name, l.Value)
+	}
+}
+
+func (p *classicPoint) flags() pmetric.DataPointFlags {
+	return pmetric.DefaultDataPointFlags.WithNoRecordedValue(p.stale)
+}
+
+// toHistogramDataPoint converts the cumulative `le` buckets into OTLP explicit
+// bucket counts. The +Inf bucket falls back to `_count` when it is missing.
+func (p *classicPoint) toHistogramDataPoint(dp pmetric.HistogramDataPoint) {
+	bounds := make([]float64, 0, len(p.bounds))
+	for bound := range p.bounds {
+		if !math.IsInf(bound, 1) {
+			bounds = append(bounds, bound)
+		}
+	}
+	sort.Float64s(bounds)
+
+	total, ok := p.bounds[math.Inf(1)]
+	if !ok {
+		switch {
+		case p.hasCount:
+			total = p.count
+		case len(bounds) > 0:
+			total = p.bounds[bounds[len(bounds)-1]]
+		}
+	}
+
+	counts := make([]uint64, 0, len(bounds)+1)
+	var previous float64
+	for _, bound := range bounds {
+		counts = append(counts, bucketDelta(p.bounds[bound], previous))
+		previous = math.Max(previous, p.bounds[bound])
+	}
+	counts = append(counts, bucketDelta(total, previous))
+
+	dp.ExplicitBounds().FromRaw(bounds)
+	dp.BucketCounts().FromRaw(counts)
+	if p.hasCount {
+		dp.SetCount(uint64(p.count))
+	} else {
+		dp.SetCount(uint64(total))
+	}
+	if p.hasSum {
+		dp.SetSum(p.sum)
+	}
+}
+
+func (p *classicPoint) toSummaryDataPoint(dp pmetric.SummaryDataPoint) {
+	quantiles := make([]float64, 0, len(p.quantiles))
+	for q := range p.quantiles {
+		quantiles = append(quantiles, q)
+	}
+	sort.Float64s(quantiles)
+	for _, q := range quantiles {
+		qv := dp.QuantileValues().AppendEmpty()
+		qv.SetQuantile(q)
+		qv.SetValue(p.quantiles[q])
+	}
+	if p.hasCount {
+		dp.SetCount(uint64(p.count))
+	}
+	if p.hasSum {
+		dp.SetSum(p.sum)
+	}
+}
+
+// bucketDelta returns the number of observations between two cumulative
+// bucket counts, treating inconsistent (decreasing) buckets as empty.
+func bucketDelta(cumulative, previous float64) uint64 {
+	if cumulative <= previous || math.IsNaN(cumulative) {
+		return 0
+	}
+	return uint64(cumulative - previous)
+}
+
+func hasLabel(labels []prompb.Label, name string) bool {
+	for _, l := range labels {
+		if l.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func labelValue(labels []prompb.Label, name string) string {
+	for _, l := range labels {
+		if l.Name == name {
+			return l.Value
+		}
+	}
+	return ""
+}
+
+// labelsSignature returns a key identifying labels, ignoring the order in
+// which they were sent.
+func labelsSignature(name string, labels []prompb.Label) string {
+	pairs := make([]string, 0, len(labels))
+	for _, l := range labels {
+		pairs = append(pairs, l.Name+"\xff"+l.Value)
+	}
+	sort.Strings(pairs)
+	return name + "\xfe" + strings.Join(pairs, "\xfe")
+}
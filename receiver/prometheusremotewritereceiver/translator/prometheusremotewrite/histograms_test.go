@@ -0,0 +1,124 @@
+// Copyright  The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package prometheusremotewrite
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/prometheus/prompb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.uber.org/zap"
+)
+
+var (
+	now       = time.Now()
+	nowMillis = now.UnixNano() / int64(time.Millisecond)
+)
+
+func testSettings() Settings {
+	return Settings{
+		Logger:        *zap.NewNop(),
+		TimeThreshold: 24,
+	}
+}
+
+func series(name string, value float64, labels ...string) prompb.TimeSeries {
+	ts := prompb.TimeSeries{
+		Labels:  []prompb.Label{{Name: nameStr, Value: name}},
+		Samples: []prompb.Sample{{Value: value, Timestamp: nowMillis}},
+	}
+	for i := 0; i < len(labels); i += 2 {
+		ts.Labels = append(ts.Labels, prompb.Label{Name: labels[i], Value: labels[i+1]})
+	}
+	return ts
+}
+
+func TestFromTimeSeries_ClassicHistogram(t *testing.T) {
+	tss := []prompb.TimeSeries{
+		series("http_request_duration_seconds_bucket", 2, "method", "GET", "le", "0.1"),
+		series("http_request_duration_seconds_bucket", 5, "le", "0.5", "method", "GET"),
+		series("http_request_duration_seconds_bucket", 6, "method", "GET", "le", "+Inf"),
+		series("http_request_duration_seconds_sum", 1.5, "method", "GET"),
+		series("http_request_duration_seconds_count", 6, "method", "GET"),
+		series("http_request_duration_seconds_bucket", 1, "method", "POST", "le", "0.1"),
+		series("http_request_duration_seconds_bucket", 1, "method", "POST", "le", "0.5"),
+		series("http_request_duration_seconds_count", 3, "method", "POST"),
+	}
+
+	got, err := FromTimeSeries(tss, testSettings())
+	require.NoError(t, err)
+	require.Equal(t, 1, got.ResourceMetrics().Len())
+	require.Equal(t, 1, got.ResourceMetrics().At(0).ScopeMetrics().At(0).Metrics().Len())
+
+	hist := got.ResourceMetrics().At(0).ScopeMetrics().At(0).Metrics().At(0)
+	assert.Equal(t, "http_request_duration_seconds", hist.Name())
+	assert.Equal(t, "seconds", hist.Unit())
+	require.Equal(t, pmetric.MetricTypeHistogram, hist.Type())
+	assert.Equal(t, pmetric.AggregationTemporalityCumulative, hist.Histogram().AggregationTemporality())
+	require.Equal(t, 2, hist.Histogram().DataPoints().Len())
+	dp := hist.Histogram().DataPoints().At(0)
+	assert.Equal(t, []float64{0.1, 0.5}, dp.ExplicitBounds().AsRaw())
+	assert.Equal(t, []uint64{2, 3, 1}, dp.BucketCounts().AsRaw())
+	assert.Equal(t, uint64(6), dp.Count())
+	assert.Equal(t, 1.5, dp.Sum())
+	assert.Equal(t, map[string]any{"key_name": "http_request_duration_seconds", "method": "GET"}, dp.Attributes().AsRaw())
+
+	post := hist.Histogram().DataPoints().At(1)
+	assert.Equal(t, []uint64{1, 0, 2}, post.BucketCounts().AsRaw())
+	assert.Equal(t, uint64(3), post.Count())
+	assert.False(t, post.HasSum())
+}
+
+func TestFromTimeSeries_Summary(t *testing.T) {
+	tss := []prompb.TimeSeries{
+		series("rpc_latency_seconds", 0.2, "quantile", "0.99"),
+		series("rpc_latency_seconds", 0.05, "quantile", "0.5"),
+		series("rpc_latency_seconds_sum", 12),
+		series("rpc_latency_seconds_count", 100),
+		series("process_open_fds", 7),
+	}
+
+	got, err := FromTimeSeries(tss, testSettings())
+	require.NoError(t, err)
+	require.Equal(t, 1, got.ResourceMetrics().Len())
+	metrics := got.ResourceMetrics().At(0).ScopeMetrics().At(0).Metrics()
+	require.Equal(t, 2, metrics.Len())
+
+	gauge := metrics.At(0)
+	assert.Equal(t, "process_open_fds", gauge.Name())
+	assert.Equal(t, pmetric.MetricTypeGauge, gauge.Type())
+
+	summary := metrics.At(1)
+	assert.Equal(t, "rpc_latency_seconds", summary.Name())
+	require.Equal(t, pmetric.MetricTypeSummary, summary.Type())
+	dp := summary.Summary().DataPoints().At(0)
+	assert.Equal(t, uint64(100), dp.Count())
+	assert.Equal(t, 12.0, dp.Sum())
+	require.Equal(t, 2, dp.QuantileValues().Len())
+	assert.Equal(t, 0.5, dp.QuantileValues().At(0).Quantile())
+	assert.Equal(t, 0.05, dp.QuantileValues().At(0).Value())
+	assert.Equal(t, 0.99, dp.QuantileValues().At(1).Quantile())
+	assert.Equal(t, 0.2, dp.QuantileValues().At(1).Value())
+}
+
+func TestFromTimeSeries_SumWithoutFamilyStaysSum(t *testing.T) {
+	got, err := FromTimeSeries([]prompb.TimeSeries{series("jobs_processed_sum", 4)}, testSettings())
+	require.NoError(t, err)
+	require.Equal(t, 1, got.ResourceMetrics().Len())
+	assert.Equal(t, pmetric.MetricTypeSum, got.ResourceMetrics().At(0).ScopeMetrics().At(0).Metrics().At(0).Type())
+}
@@ -0,0 +1,78 @@
+// Copyright  The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package prometheusremotewrite // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/prometheusremotewritereceiver/translator/prometheusremotewrite"
+
+import (
+	"strings"
+	"time"
+
+	"github.com/prometheus/prometheus/prompb"
+)
+
+const totalStr = "_total"
+
+// MetadataCache keeps the metric metadata received from remote write senders.
+// Prometheus sends metadata periodically and separately from the samples, so
+// it has to be remembered across requests. Entries are kept per tenant, expire
+// when they are not sent again within the TTL, and the least recently updated
+// ones are evicted once the cache is full.
+type MetadataCache struct {
+	cache *expiringCache[metadataKey, prompb.MetricMetadata]
+}
+
+type metadataKey struct {
+	tenant string
+	family string
+}
+
+// NewMetadataCache creates an empty MetadataCache holding at most maxEntries
+// entries, each for ttl after its last update.
+func NewMetadataCache(maxEntries int, ttl time.Duration) *MetadataCache {
+	return &MetadataCache{cache: newExpiringCache[metadataKey, prompb.MetricMetadata](maxEntries, ttl)}
+}
+
+// Update records the metadata of tenant, replacing any previous entry of the
+// same metric family.
+func (c *MetadataCache) Update(tenant string, metadata []prompb.MetricMetadata) {
+	now := time.Now()
+	for _, md := range metadata {
+		if md.MetricFamilyName == "" {
+			continue
+		}
+		c.cache.put(metadataKey{tenant: tenant, family: md.MetricFamilyName}, md, now)
+	}
+}
+
+// Get returns the metadata of the metric family named name sent by tenant.
+func (c *MetadataCache) Get(tenant, name string) (prompb.MetricMetadata, bool) {
+	return c.cache.get(metadataKey{tenant: tenant, family: name}, time.Now())
+}
+
+// lookupMetadata returns the metadata describing the series or family named
+// name. Counters may be announced with or without their `_total` suffix.
+func (settings Settings) lookupMetadata(name string) (prompb.MetricMetadata, bool) {
+	if settings.Metadata == nil {
+		return prompb.MetricMetadata{}, false
+	}
+	if md, ok := settings.Metadata.Get(settings.Tenant, name); ok {
+		return md, true
+	}
+	if strings.HasSuffix(name, totalStr) {
+		if md, ok := settings.Metadata.Get(settings.Tenant, strings.TrimSuffix(name, totalStr)); ok && md.Type == prompb.MetricMetadata_COUNTER {
+			return md, true
+		}
+	}
+	return prompb.MetricMetadata{}, false
+}
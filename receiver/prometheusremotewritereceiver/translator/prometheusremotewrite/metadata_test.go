@@ -0,0 +1,125 @@
+// Copyright  The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package prometheusremotewrite
+
+import (
+	"testing"
+
+	"github.com/prometheus/prometheus/prompb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+)
+
+func TestFromTimeSeries_Metadata(t *testing.T) {
+	cache := NewMetadataCache(0, 0)
+	cache.Update("", []prompb.MetricMetadata{
+		{Type: prompb.MetricMetadata_COUNTER, MetricFamilyName: "http_requests", Help: "Number of HTTP requests."},
+		{Type: prompb.MetricMetadata_GAUGE, MetricFamilyName: "queue_items_total", Help: "Items currently queued."},
+		{Type: prompb.MetricMetadata_COUNTER, MetricFamilyName: "cpu_time", Unit: "s"},
+		{Type: prompb.MetricMetadata_HISTOGRAM, MetricFamilyName: "request_size_bytes", Help: "Request sizes."},
+		{Type: prompb.MetricMetadata_UNKNOWN, MetricFamilyName: ""},
+	})
+	settings := testSettings()
+	settings.Metadata = cache
+
+	tests := []struct {
+		name        string
+		series      []prompb.TimeSeries
+		metricType  pmetric.MetricType
+		unit        string
+		description string
+	}{
+		{
+			name:        "counter announced without _total",
+			series:      []prompb.TimeSeries{series("http_requests_total", 3)},
+			metricType:  pmetric.MetricTypeSum,
+			description: "Number of HTTP requests.",
+		},
+		{
+			name:        "gauge with counter-like suffix",
+			series:      []prompb.TimeSeries{series("queue_items_total", 3)},
+			metricType:  pmetric.MetricTypeGauge,
+			description: "Items currently queued.",
+		},
+		{
+			name:       "unit from metadata",
+			series:     []prompb.TimeSeries{series("cpu_time", 3)},
+			metricType: pmetric.MetricTypeSum,
+			unit:       "s",
+		},
+		{
+			name: "histogram without buckets",
+			series: []prompb.TimeSeries{
+				series("request_size_bytes_sum", 2048),
+				series("request_size_bytes_count", 2),
+			},
+			metricType:  pmetric.MetricTypeHistogram,
+			unit:        "bytes",
+			description: "Request sizes.",
+		},
+		{
+			name:       "fall back to suffixes",
+			series:     []prompb.TimeSeries{series("disk_read_bytes_total", 3)},
+			metricType: pmetric.MetricTypeSum,
+			unit:       "bytes",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := FromTimeSeries(tt.series, settings)
+			require.NoError(t, err)
+			require.Equal(t, 1, got.ResourceMetrics().Len())
+			metric := got.ResourceMetrics().At(0).ScopeMetrics().At(0).Metrics().At(0)
+			assert.Equal(t, tt.metricType, metric.Type())
+			assert.Equal(t, tt.unit, metric.Unit())
+			assert.Equal(t, tt.description, metric.Description())
+		})
+	}
+}
+
+func TestMetadataCache_Update(t *testing.T) {
+	cache := NewMetadataCache(0, 0)
+	cache.Update("", []prompb.MetricMetadata{{Type: prompb.MetricMetadata_GAUGE, MetricFamilyName: "up"}})
+	cache.Update("", nil)
+
+	md, ok := cache.Get("", "up")
+	require.True(t, ok)
+	assert.Equal(t, prompb.MetricMetadata_GAUGE, md.Type)
+
+	cache.Update("", []prompb.MetricMetadata{{Type: prompb.MetricMetadata_COUNTER, MetricFamilyName: "up", Help: "changed"}})
+	md, ok = cache.Get("", "up")
+	require.True(t, ok)
+	assert.Equal(t, prompb.MetricMetadata_COUNTER, md.Type)
+	assert.Equal(t, "changed", md.Help)
+
+	_, ok = cache.Get("", "missing")
+	assert.False(t, ok)
+}
+
+func TestMetadataCache_Tenants(t *testing.T) {
+	cache := NewMetadataCache(0, 0)
+	cache.Update("a", []prompb.MetricMetadata{{Type: prompb.MetricMetadata_COUNTER, MetricFamilyName: "jobs"}})
+	cache.Update("b", []prompb.MetricMetadata{{Type: prompb.MetricMetadata_GAUGE, MetricFamilyName: "jobs"}})
+
+	md, ok := cache.Get("a", "jobs")
+	require.True(t, ok)
+	assert.Equal(t, prompb.MetricMetadata_COUNTER, md.Type)
+	md, ok = cache.Get("b", "jobs")
+	require.True(t, ok)
+	assert.Equal(t, prompb.MetricMetadata_GAUGE, md.Type)
+	_, ok = cache.Get("c", "jobs")
+	assert.False(t, ok)
+}
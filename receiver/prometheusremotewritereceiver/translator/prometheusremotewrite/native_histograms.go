@@ -0,0 +1,157 @@
+// Copyright  The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package prometheusremotewrite // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/prometheusremotewritereceiver/translator/prometheusremotewrite"
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/prometheus/prometheus/model/value"
+	"github.com/prometheus/prometheus/prompb"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.uber.org/zap"
+)
+
+const (
+	minNativeHistogramSchema = -4
+	maxNativeHistogramSchema = 8
+)
+
+// addNativeHistograms converts the native histograms of a series into
+// exponential histogram data points of pm. Histograms which cannot be
+// converted are dropped.
+func addNativeHistograms(pm pmetric.Metric, ts prompb.TimeSeries, startTimestamp pcommon.Timestamp, settings Settings) {
+	if pm.Type() != pmetric.MetricTypeExponentialHistogram {
+		pm.SetEmptyExponentialHistogram().SetAggregationTemporality(pmetric.AggregationTemporalityCumulative)
+	}
+	hist := pm.ExponentialHistogram()
+	seriesID := settings.seriesID(ts.Labels)
+	points := make([]pmetric.ExponentialHistogramDataPoint, 0, len(ts.Histograms))
+	for _, h := range ts.Histograms {
+		check := settings.checkSample(pm.Name(), seriesID, h.Timestamp)
+		if !check.keep {
+			continue
+		}
+		// Gauge histograms describe a current distribution rather than an
+		// accumulation, which OTLP can only express with delta temporality.
+		if h.ResetHint == prompb.Histogram_GAUGE {
+			hist.SetAggregationTemporality(pmetric.AggregationTemporalityDelta)
+		}
+		dp := pmetric.NewExponentialHistogramDataPoint()
+		dp.SetStartTimestamp(startTimestamp)
+		if err := nativeToExponentialHistogram(h, dp); err != nil {
+			settings.Logger.Debug("Dropping native histogram", zap.String("metric name", pm.Name()), zap.Error(err))
+			continue
+		}
+		settings.acceptHistogram(ts.Labels, h, check)
+		putSeriesAttributes(dp.Attributes(), ts.Labels)
+		putOutOfOrder(dp.Attributes(), check.outOfOrder)
+		if value.IsStaleNaN(h.Sum) {
+			dp.SetFlags(pmetric.DefaultDataPointFlags.WithNoRecordedValue(true))
+		}
+		points = append(points, hist.DataPoints().AppendEmpty())
+		dp.MoveTo(points[len(points)-1])
+	}
+	if len(ts.Samples) == 0 {
+		// Series with both samples and histograms keep their exemplars on the samples.
+		settings.acceptExemplars(attachExemplars(ts.Exemplars, points))
+	}
+}
+
+// nativeToExponentialHistogram translates a Prometheus native histogram into
+// an OTel exponential histogram data point. It is the inverse of the exporter's
+// exponentialToNativeHistogram.
+func nativeToExponentialHistogram(h prompb.Histogram, dp pmetric.ExponentialHistogramDataPoint) error {
+	if h.Schema < minNativeHistogramSchema || h.Schema > maxNativeHistogramSchema {
+		return fmt.Errorf("cannot convert native to exponential histogram, schema must be within [%d, %d], was %d",
+			minNativeHistogramSchema, maxNativeHistogramSchema, h.Schema)
+	}
+	dp.SetTimestamp(timestampFromMillis(h.Timestamp))
+	if h.ResetHint == prompb.Histogram_YES {
+		// A reset starts a new cumulative series at this sample.
+		dp.SetStartTimestamp(dp.Timestamp())
+	}
+	dp.SetScale(h.Schema)
+	dp.SetZeroThreshold(h.ZeroThreshold)
+	dp.SetSum(h.Sum)
+
+	if h.IsFloatHistogram() {
+		dp.SetCount(floatToCount(h.GetCountFloat()))
+		dp.SetZeroCount(floatToCount(h.GetZeroCountFloat()))
+		convertFloatBuckets(h.PositiveSpans, h.PositiveCounts, dp.Positive())
+		convertFloatBuckets(h.NegativeSpans, h.NegativeCounts, dp.Negative())
+		return nil
+	}
+	dp.SetCount(h.GetCountInt())
+	dp.SetZeroCount(h.GetZeroCountInt())
+	convertDeltaBuckets(h.PositiveSpans, h.PositiveDeltas, dp.Positive())
+	convertDeltaBuckets(h.NegativeSpans, h.NegativeDeltas, dp.Negative())
+	return nil
+}
+
+// convertDeltaBuckets expands the delta encoded counts of an integer native
+// histogram into the dense OTel bucket layout.
+func convertDeltaBuckets(spans []prompb.BucketSpan, deltas []int64, buckets pmetric.ExponentialHistogramDataPointBuckets) {
+	counts := make([]float64, len(deltas))
+	var count int64
+	for i, delta := range deltas {
+		count += delta
+		counts[i] = float64(count)
+	}
+	convertFloatBuckets(spans, counts, buckets)
+}
+
+// convertFloatBuckets translates the Prometheus sparse bucket representation
+// into the OTel dense one. Prometheus bucket index i covers (base^(i-1), base^i]
+// whereas OTel bucket index i covers (base^i, base^(i+1)], hence the offset is
+// shifted by one.
+func convertFloatBuckets(spans []prompb.BucketSpan, counts []float64, buckets pmetric.ExponentialHistogramDataPointBuckets) {
+	if len(spans) == 0 || len(counts) == 0 {
+		return
+	}
+	var (
+		dense []uint64
+		idx   int32
+		first = spans[0].Offset
+		i     int
+	)
+	for n, span := range spans {
+		if n == 0 {
+			idx = span.Offset
+		} else {
+			idx += span.Offset
+		}
+		for pos := int32(len(dense)) + first; pos < idx; pos++ {
+			dense = append(dense, 0)
+		}
+		for l := uint32(0); l < span.Length && i < len(counts); l++ {
+			dense = append(dense, floatToCount(counts[i]))
+			i++
+			idx++
+		}
+	}
+	buckets.SetOffset(first - 1)
+	buckets.BucketCounts().FromRaw(dense)
+}
+
+// floatToCount converts a float histogram count to an integer one. Prometheus
+// allows non-integer counts in float histograms, OTLP does not.
+func floatToCount(v float64) uint64 {
+	if v <= 0 || math.IsNaN(v) {
+		return 0
+	}
+	return uint64(math.Round(v))
+}
@@ -0,0 +1,213 @@
+// Copyright  The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package prometheusremotewrite
+
+import (
+	"testing"
+
+	"github.com/prometheus/prometheus/prompb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+
+	exportertranslator "github.com/open-telemetry/opentelemetry-collector-contrib/pkg/translator/prometheusremotewrite"
+)
+
+func TestNativeHistogramRoundTrip(t *testing.T) {
+	tests := []struct {
+		name     string
+		scale    int32
+		zero     uint64
+		positive []uint64
+		posOff   int32
+		negative []uint64
+		negOff   int32
+	}{
+		{
+			name:     "positive buckets",
+			scale:    2,
+			positive: []uint64{1, 2, 3},
+			posOff:   0,
+		},
+		{
+			name:     "gaps and negative offset",
+			scale:    0,
+			zero:     4,
+			positive: []uint64{5, 0, 0, 0, 0, 1},
+			posOff:   -3,
+			negative: []uint64{2, 0, 7},
+			negOff:   1,
+		},
+		{
+			name:     "negative scale",
+			scale:    -2,
+			zero:     1,
+			negative: []uint64{3},
+			negOff:   5,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			md := pmetric.NewMetrics()
+			metric := md.ResourceMetrics().AppendEmpty().ScopeMetrics().AppendEmpty().Metrics().AppendEmpty()
+			metric.SetName("latency")
+			hist := metric.SetEmptyExponentialHistogram()
+			hist.SetAggregationTemporality(pmetric.AggregationTemporalityCumulative)
+			want := hist.DataPoints().AppendEmpty()
+			want.SetTimestamp(pcommon.Timestamp(nowMillis * 1e6))
+			want.SetScale(tt.scale)
+			want.SetZeroCount(tt.zero)
+			want.Positive().SetOffset(tt.posOff)
+			want.Positive().BucketCounts().FromRaw(tt.positive)
+			want.Negative().SetOffset(tt.negOff)
+			want.Negative().BucketCounts().FromRaw(tt.negative)
+			count := tt.zero
+			for _, c := range append(append([]uint64{}, tt.positive...), tt.negative...) {
+				count += c
+			}
+			want.SetCount(count)
+			want.SetSum(42)
+			want.Attributes().PutStr("method", "GET")
+
+			tsMap, err := exportertranslator.FromMetrics(md, exportertranslator.Settings{DisableTargetInfo: true})
+			require.NoError(t, err)
+			var tss []prompb.TimeSeries
+			for _, ts := range tsMap {
+				tss = append(tss, *ts)
+			}
+			require.Len(t, tss, 1)
+
+			got, err := FromTimeSeries(tss, testSettings())
+			require.NoError(t, err)
+			require.Equal(t, 1, got.ResourceMetrics().Len())
+			gotMetric := got.ResourceMetrics().At(0).ScopeMetrics().At(0).Metrics().At(0)
+			assert.Equal(t, "latency", gotMetric.Name())
+			require.Equal(t, pmetric.MetricTypeExponentialHistogram, gotMetric.Type())
+			assert.Equal(t, pmetric.AggregationTemporalityCumulative, gotMetric.ExponentialHistogram().AggregationTemporality())
+			require.Equal(t, 1, gotMetric.ExponentialHistogram().DataPoints().Len())
+			dp := gotMetric.ExponentialHistogram().DataPoints().At(0)
+
+			assert.Equal(t, want.Timestamp(), dp.Timestamp())
+			assert.Equal(t, want.Scale(), dp.Scale())
+			assert.Equal(t, want.Count(), dp.Count())
+			assert.Equal(t, want.Sum(), dp.Sum())
+			assert.Equal(t, want.ZeroCount(), dp.ZeroCount())
+			assert.Equal(t, want.Positive().BucketCounts().AsRaw(), dp.Positive().BucketCounts().AsRaw())
+			assert.Equal(t, want.Negative().BucketCounts().AsRaw(), dp.Negative().BucketCounts().AsRaw())
+			if len(tt.positive) > 0 {
+				assert.Equal(t, want.Positive().Offset(), dp.Positive().Offset())
+			}
+			if len(tt.negative) > 0 {
+				assert.Equal(t, want.Negative().Offset(), dp.Negative().Offset())
+			}
+			assert.Equal(t, map[string]any{"key_name": "latency", "method": "GET"}, dp.Attributes().AsRaw())
+		})
+	}
+}
+
+func TestNativeToExponentialHistogram(t *testing.T) {
+	tests := []struct {
+		name        string
+		histogram   prompb.Histogram
+		wantErr     bool
+		wantCount   uint64
+		wantZero    uint64
+		wantOffset  int32
+		wantBuckets []uint64
+		wantReset   bool
+	}{
+		{
+			name: "float histogram",
+			histogram: prompb.Histogram{
+				Count:          &prompb.Histogram_CountFloat{CountFloat: 7.5},
+				ZeroCount:      &prompb.Histogram_ZeroCountFloat{ZeroCountFloat: 1},
+				ZeroThreshold:  0.001,
+				Schema:         1,
+				PositiveSpans:  []prompb.BucketSpan{{Offset: 2, Length: 2}, {Offset: 1, Length: 1}},
+				PositiveCounts: []float64{1, 2.6, 3},
+				Timestamp:      nowMillis,
+			},
+			wantCount:   8,
+			wantZero:    1,
+			wantOffset:  1,
+			wantBuckets: []uint64{1, 3, 0, 3},
+		},
+		{
+			name: "integer histogram with counter reset",
+			histogram: prompb.Histogram{
+				Count:          &prompb.Histogram_CountInt{CountInt: 6},
+				ZeroCount:      &prompb.Histogram_ZeroCountInt{ZeroCountInt: 0},
+				Schema:         3,
+				PositiveSpans:  []prompb.BucketSpan{{Offset: -1, Length: 3}},
+				PositiveDeltas: []int64{1, 2, -2},
+				ResetHint:      prompb.Histogram_YES,
+				Timestamp:      nowMillis,
+			},
+			wantCount:   6,
+			wantOffset:  -2,
+			wantBuckets: []uint64{1, 3, 1},
+			wantReset:   true,
+		},
+		{
+			name: "unsupported schema",
+			histogram: prompb.Histogram{
+				Count:  &prompb.Histogram_CountInt{CountInt: 1},
+				Schema: 9,
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dp := pmetric.NewExponentialHistogramDataPoint()
+			err := nativeToExponentialHistogram(tt.histogram, dp)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantCount, dp.Count())
+			assert.Equal(t, tt.wantZero, dp.ZeroCount())
+			assert.Equal(t, tt.histogram.ZeroThreshold, dp.ZeroThreshold())
+			assert.Equal(t, tt.wantOffset, dp.Positive().Offset())
+			assert.Equal(t, tt.wantBuckets, dp.Positive().BucketCounts().AsRaw())
+			assert.Equal(t, 0, dp.Negative().BucketCounts().Len())
+			if tt.wantReset {
+				assert.Equal(t, dp.Timestamp(), dp.StartTimestamp())
+			} else {
+				assert.Zero(t, dp.StartTimestamp())
+			}
+		})
+	}
+}
+
+func TestFromTimeSeries_GaugeHistogram(t *testing.T) {
+	ts := prompb.TimeSeries{
+		Labels: []prompb.Label{{Name: nameStr, Value: "queue_wait"}},
+		Histograms: []prompb.Histogram{{
+			Count:     &prompb.Histogram_CountInt{CountInt: 0},
+			ZeroCount: &prompb.Histogram_ZeroCountInt{ZeroCountInt: 0},
+			ResetHint: prompb.Histogram_GAUGE,
+			Timestamp: nowMillis,
+		}},
+	}
+	got, err := FromTimeSeries([]prompb.TimeSeries{ts}, testSettings())
+	require.NoError(t, err)
+	metric := got.ResourceMetrics().At(0).ScopeMetrics().At(0).Metrics().At(0)
+	require.Equal(t, pmetric.MetricTypeExponentialHistogram, metric.Type())
+	assert.Equal(t, pmetric.AggregationTemporalityDelta, metric.ExponentialHistogram().AggregationTemporality())
+	assert.Equal(t, 1, metric.ExponentialHistogram().DataPoints().Len())
+}
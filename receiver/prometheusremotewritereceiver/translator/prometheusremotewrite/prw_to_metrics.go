@@ -12,17 +12,19 @@
 // See the License for the specific language governing permissions and
 // limitations under the License.
 
-// Simply copied from opentelemetry-collector-contrib/pkg/translator/prometheusremotewrite/prw_to_metrics.go. DO NOT EDIT.
+// Originally copied from opentelemetry-collector-contrib/pkg/translator/prometheusremotewrite/prw_to_metrics.go.
 
-package prometheusremotewrite // import "github.com/open-telemetry/opentelemetry-collector-contrib/pkg/translator/prometheusremotewrite"
+package prometheusremotewrite // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/prometheusremotewritereceiver/translator/prometheusremotewrite"
 
 import (
 	"fmt"
+	"strings"
 	"time"
 
 	"errors"
 	"regexp"
 
+	"github.com/prometheus/prometheus/model/value"
 	"github.com/prometheus/prometheus/prompb"
 	"go.opentelemetry.io/collector/pdata/pcommon"
 	"go.opentelemetry.io/collector/pdata/pmetric"
@@ -39,75 +41,237 @@ type Settings struct {
 	ExportCreatedMetric bool
 	AddMetricSuffixes   bool
 	SendMetadata        bool
+	// Tenant is the tenant which sent the series. Metadata, `target_info`
+	// series and the series tracked for out-of-order detection are kept apart
+	// for each tenant.
+	Tenant string
+	// Metadata holds the metric metadata known to the receiver. When it is nil
+	// or has no entry for a metric, the type and unit are guessed from the name.
+	Metadata *MetadataCache
+	// TargetInfo remembers the `target_info` series of each target across
+	// requests. When it is nil, only the `target_info` series of the same
+	// request are used.
+	TargetInfo *TargetInfoCache
+	// FutureThreshold drops samples further ahead of the current time, 0
+	// disables the check.
+	FutureThreshold time.Duration
+	// OutOfOrder is applied to samples older than the last sample of their
+	// series, which Series remembers across requests.
+	OutOfOrder OutOfOrderPolicy
+	Series     *SeriesTracker
+	// Accepted, when set, collects the samples converted into data points.
+	Accepted *AcceptedSeries
 }
 
 const nameStr = "__name__"
 
 var reg = regexp.MustCompile(`(\w+)_(\w+)_(\w+)\z`)
 
+// FromTimeSeries converts the series of a remote write request into metrics.
+// Series belonging to a classic histogram or summary (`_bucket`, `_sum`,
+// `_count` and `quantile` series sharing a base name and label set) are
+// reassembled into a single Histogram or Summary and native histograms become
+// ExponentialHistograms; every other series becomes its own Gauge or Sum.
+// `_created` series set the start timestamp of the series they belong to.
+// Series are grouped into one ResourceMetrics per `job` and `instance`, whose
+// `target_info` series labels, from this or an earlier request, become
+// resource attributes.
 func FromTimeSeries(tss []prompb.TimeSeries, settings Settings) (pmetric.Metrics, error) {
 	pms := pmetric.NewMetrics()
+	resources := newResourceBuilder(pms)
+	families := findClassicFamilies(tss, settings)
+	created := findCreatedTimestamps(tss)
+	groups := map[string]*classicGroup{}
+	var groupOrder []*classicGroup
+	var createdSeries, targetInfos []prompb.TimeSeries
 	for _, ts := range tss {
-		empty := pms.ResourceMetrics().AppendEmpty().ScopeMetrics().AppendEmpty().Metrics().AppendEmpty()
-		pm := pmetric.NewMetric()
 		metricName, err := finalName(ts.Labels)
 		if err != nil {
 			return pms, err
 		}
-		pm.SetName(metricName)
-		settings.Logger.Debug("Metric name", zap.String("metric_name", pm.Name()))
-		match := reg.FindStringSubmatch(metricName)
-		metricsType := ""
-		if len(match) > 1 {
-			lastSuffixInMetricName := match[len(match)-1]
-			if IsValidSuffix(lastSuffixInMetricName) {
-				metricsType = lastSuffixInMetricName
-				if len(match) > 2 {
-					secondSuffixInMetricName := match[len(match)-2]
-					if IsValidUnit(secondSuffixInMetricName) {
-						pm.SetUnit(secondSuffixInMetricName)
+		if base, metricType, part, ok := families.match(metricName, ts.Labels); ok {
+			if validClassicLabels(part, ts.Labels) {
+				seriesID := settings.seriesID(ts.Labels)
+				g := newClassicGroup(base, metricType, ts.Labels)
+				sig := labelsSignature(base, g.labels)
+				if existing, found := groups[sig]; found {
+					g = existing
+				} else {
+					groups[sig] = g
+					groupOrder = append(groupOrder, g)
+				}
+				for _, s := range ts.Samples {
+					check := settings.checkSample(metricName, seriesID, s.Timestamp)
+					if !check.keep {
+						continue
 					}
+					settings.acceptSample(ts.Labels, s, check)
+					g.add(part, ts.Labels, s, check.outOfOrder)
 				}
-			} else if IsValidUnit(lastSuffixInMetricName) {
-				pm.SetUnit(lastSuffixInMetricName)
+				g.exemplars = append(g.exemplars, ts.Exemplars...)
+				continue
 			}
+			settings.Logger.Debug("Invalid bucket or quantile label, keeping series as is", zap.String("metric_name", metricName))
+		}
+		if settings.isTargetInfo(metricName) {
+			targetInfos = append(targetInfos, ts)
+			continue
 		}
-		settings.Logger.Debug("Metric unit", zap.String("metric name", pm.Name()), zap.String("metric_unit", pm.Unit()))
-		for _, s := range ts.Samples {
-			ppoint := pmetric.NewNumberDataPoint()
-			ppoint.SetDoubleValue(s.Value)
-			ppoint.SetTimestamp(pcommon.NewTimestampFromTime(time.Unix(0, s.Timestamp*int64(time.Millisecond))))
-			if ppoint.Timestamp().AsTime().Before(time.Now().Add(-time.Duration(settings.TimeThreshold) * time.Hour)) {
-				settings.Logger.Debug("Metric older than the threshold", zap.String("metric name", pm.Name()), zap.Time("metric_timestamp", ppoint.Timestamp().AsTime()))
+		if len(ts.Histograms) > 0 {
+			hm := resources.metric(ts.Labels, nativeHistogramKind, metricName)
+			describeMetric(hm, metricName, settings)
+			addNativeHistograms(hm, ts, created.lookup(metricName, ts.Labels), settings)
+			if len(ts.Samples) == 0 {
 				continue
 			}
-			for _, l := range ts.Labels {
-				labelName := l.Name
-				if l.Name == nameStr {
-					labelName = "key_name"
+		}
+		if strings.HasSuffix(metricName, createdSuffix) {
+			// Emitted below unless it holds the start time of another series.
+			createdSeries = append(createdSeries, ts)
+			continue
+		}
+		addNumberSeries(resources, ts, metricName, created.lookup(metricName, ts.Labels), settings)
+	}
+	for _, g := range groupOrder {
+		if len(g.points) == 0 {
+			continue
+		}
+		pm := resources.metric(g.labels, classicKind, g.name)
+		describeMetric(pm, g.name, settings)
+		settings.acceptExemplars(g.appendTo(pm, created.lookup(g.name, g.labels)))
+		settings.Logger.Debug("Reassembled classic metric",
+			zap.String("metric_name", pm.Name()),
+			zap.String("metric_type", pm.Type().String()),
+			zap.Int("data_points", len(g.points)),
+		)
+	}
+	for _, ts := range createdSeries {
+		metricName, _ := finalName(ts.Labels)
+		if !created.consumed(metricName, ts.Labels) {
+			addNumberSeries(resources, ts, metricName, 0, settings)
+		}
+	}
+	resources.addTargetInfos(targetInfos, settings)
+	return pms, nil
+}
+
+// addNumberSeries converts the samples of a series into a Gauge or Sum.
+func addNumberSeries(resources *resourceBuilder, ts prompb.TimeSeries, metricName string, startTimestamp pcommon.Timestamp, settings Settings) {
+	pm := resources.metric(ts.Labels, numberKind, metricName)
+	settings.Logger.Debug("Metric name", zap.String("metric_name", pm.Name()))
+	isCumulative := describeMetric(pm, metricName, settings)
+	settings.Logger.Debug("Metric unit", zap.String("metric name", pm.Name()), zap.String("metric_unit", pm.Unit()))
+	seriesID := settings.seriesID(ts.Labels)
+	points := make([]pmetric.NumberDataPoint, 0, len(ts.Samples))
+	for _, s := range ts.Samples {
+		check := settings.checkSample(pm.Name(), seriesID, s.Timestamp)
+		if !check.keep {
+			continue
+		}
+		settings.acceptSample(ts.Labels, s, check)
+		var ppoint pmetric.NumberDataPoint
+		switch {
+		case isCumulative && pm.Type() != pmetric.MetricTypeSum:
+			pm.SetEmptySum()
+			pm.Sum().SetIsMonotonic(true)
+			pm.Sum().SetAggregationTemporality(pmetric.AggregationTemporalityCumulative)
+			fallthrough
+		case isCumulative:
+			ppoint = pm.Sum().DataPoints().AppendEmpty()
+			ppoint.SetStartTimestamp(startTimestamp)
+		case pm.Type() != pmetric.MetricTypeGauge:
+			pm.SetEmptyGauge()
+			fallthrough
+		default:
+			ppoint = pm.Gauge().DataPoints().AppendEmpty()
+		}
+		ppoint.SetDoubleValue(s.Value)
+		ppoint.SetTimestamp(timestampFromMillis(s.Timestamp))
+		putSeriesAttributes(ppoint.Attributes(), ts.Labels)
+		putOutOfOrder(ppoint.Attributes(), check.outOfOrder)
+		if value.IsStaleNaN(s.Value) {
+			ppoint.SetFlags(pmetric.DefaultDataPointFlags.WithNoRecordedValue(true))
+		}
+		settings.Logger.Debug("Metric sample",
+			zap.String("metric_name", pm.Name()),
+			zap.String("metric_unit", pm.Unit()),
+			zap.Float64("metric_value", ppoint.DoubleValue()),
+			zap.Time("metric_timestamp", ppoint.Timestamp().AsTime()),
+			zap.String("metric_labels", fmt.Sprintf("%#v", ppoint.Attributes())),
+		)
+		points = append(points, ppoint)
+	}
+	settings.acceptExemplars(attachExemplars(ts.Exemplars, points))
+}
+
+// putSeriesAttributes copies the labels of a series into attrs, keeping the
+// metric name as `key_name`. The `job` and `instance` labels identify the
+// resource and are left out.
+func putSeriesAttributes(attrs pcommon.Map, labels []prompb.Label) {
+	for _, l := range labels {
+		if isResourceLabel(l.Name) {
+			continue
+		}
+		labelName := l.Name
+		if l.Name == nameStr {
+			labelName = "key_name"
+		}
+		attrs.PutStr(labelName, l.Value)
+	}
+}
+
+// describeMetric sets the unit and description of pm and reports whether the
+// series named metricName is a counter. Metadata sent by Prometheus takes
+// precedence; the name suffixes are only used when no metadata is known.
+func describeMetric(pm pmetric.Metric, metricName string, settings Settings) bool {
+	metricsType, unit := typeAndUnitFromName(metricName)
+	md, ok := settings.lookupMetadata(metricName)
+	if !ok {
+		pm.SetUnit(unit)
+		return IsValidCumulativeSuffix(metricsType)
+	}
+	if md.Unit != "" {
+		unit = md.Unit
+	}
+	pm.SetUnit(unit)
+	pm.SetDescription(md.Help)
+	return md.Type == prompb.MetricMetadata_COUNTER
+}
+
+// typeAndUnitFromName guesses the metric type suffix and the unit of a metric
+// from the last two underscore separated parts of its name.
+func typeAndUnitFromName(metricName string) (metricsType string, unit string) {
+	match := reg.FindStringSubmatch(metricName)
+	if len(match) > 1 {
+		lastSuffixInMetricName := match[len(match)-1]
+		if IsValidSuffix(lastSuffixInMetricName) {
+			metricsType = lastSuffixInMetricName
+			if len(match) > 2 {
+				secondSuffixInMetricName := match[len(match)-2]
+				if IsValidUnit(secondSuffixInMetricName) {
+					unit = secondSuffixInMetricName
 				}
-				ppoint.Attributes().PutStr(labelName, l.Value)
-			}
-			if IsValidCumulativeSuffix(metricsType) {
-				pm.SetEmptySum()
-				pm.Sum().SetIsMonotonic(true)
-				pm.Sum().SetAggregationTemporality(pmetric.AggregationTemporalityCumulative)
-				ppoint.CopyTo(pm.Sum().DataPoints().AppendEmpty())
-			} else {
-				pm.SetEmptyGauge()
-				ppoint.CopyTo(pm.Gauge().DataPoints().AppendEmpty())
 			}
-			settings.Logger.Debug("Metric sample",
-				zap.String("metric_name", pm.Name()),
-				zap.String("metric_unit", pm.Unit()),
-				zap.Float64("metric_value", ppoint.DoubleValue()),
-				zap.Time("metric_timestamp", ppoint.Timestamp().AsTime()),
-				zap.String("metric_labels", fmt.Sprintf("%#v", ppoint.Attributes())),
-			)
+		} else if IsValidUnit(lastSuffixInMetricName) {
+			unit = lastSuffixInMetricName
 		}
-		pm.MoveTo(empty)
 	}
-	return pms, nil
+	return metricsType, unit
+}
+
+func timestampFromMillis(ms int64) pcommon.Timestamp {
+	return pcommon.NewTimestampFromTime(time.Unix(0, ms*int64(time.Millisecond)))
+}
+
+func isOlderThanThreshold(ms int64, settings Settings) bool {
+	return timestampFromMillis(ms).AsTime().Before(time.Now().Add(-time.Duration(settings.TimeThreshold) * time.Hour))
+}
+
+func (settings Settings) isTargetInfo(metricName string) bool {
+	if settings.Namespace != "" && metricName == settings.Namespace+"_"+targetInfoName {
+		return true
+	}
+	return metricName == targetInfoName
 }
 
 func finalName(labels []prompb.Label) (ret string, err error) {
@@ -0,0 +1,185 @@
+// Copyright  The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package prometheusremotewrite // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/prometheusremotewritereceiver/translator/prometheusremotewrite"
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/prompb"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	conventions "go.opentelemetry.io/collector/semconv/v1.6.1"
+	"go.uber.org/zap"
+)
+
+const targetInfoName = "target_info"
+
+// metricKind separates metrics sharing a name but converted differently, such
+// as the samples and native histograms of a series.
+type metricKind int
+
+const (
+	numberKind metricKind = iota
+	nativeHistogramKind
+	classicKind
+)
+
+// target identifies the scrape target a series comes from.
+type target struct {
+	job      string
+	instance string
+}
+
+func targetOf(labels []prompb.Label) target {
+	var t target
+	for _, l := range labels {
+		switch l.Name {
+		case model.JobLabel:
+			t.job = l.Value
+		case model.InstanceLabel:
+			t.instance = l.Value
+		}
+	}
+	return t
+}
+
+// putAttributes sets the resource attributes identifying the target. It is the
+// inverse of the exporter, which sets `job` to `service.namespace/service.name`
+// and `instance` to `service.instance.id`.
+func (t target) putAttributes(attrs pcommon.Map) {
+	if t.job != "" {
+		if namespace, name, ok := strings.Cut(t.job, "/"); ok {
+			attrs.PutStr(conventions.AttributeServiceNamespace, namespace)
+			attrs.PutStr(conventions.AttributeServiceName, name)
+		} else {
+			attrs.PutStr(conventions.AttributeServiceName, t.job)
+		}
+	}
+	if t.instance != "" {
+		attrs.PutStr(conventions.AttributeServiceInstanceID, t.instance)
+	}
+}
+
+type metricKey struct {
+	target target
+	kind   metricKind
+	name   string
+}
+
+// resourceBuilder groups the metrics of a request by target, one
+// ResourceMetrics per target and one Metric per name within it.
+type resourceBuilder struct {
+	pms       pmetric.Metrics
+	resources map[target]pmetric.ResourceMetrics
+	metrics   map[metricKey]pmetric.Metric
+}
+
+func newResourceBuilder(pms pmetric.Metrics) *resourceBuilder {
+	return &resourceBuilder{
+		pms:       pms,
+		resources: map[target]pmetric.ResourceMetrics{},
+		metrics:   map[metricKey]pmetric.Metric{},
+	}
+}
+
+// metric returns the metric named name of the target of labels, creating it
+// and its resource when needed.
+func (b *resourceBuilder) metric(labels []prompb.Label, kind metricKind, name string) pmetric.Metric {
+	t := targetOf(labels)
+	key := metricKey{target: t, kind: kind, name: name}
+	if pm, ok := b.metrics[key]; ok {
+		return pm
+	}
+	rm, ok := b.resources[t]
+	if !ok {
+		rm = b.pms.ResourceMetrics().AppendEmpty()
+		t.putAttributes(rm.Resource().Attributes())
+		rm.ScopeMetrics().AppendEmpty()
+		b.resources[t] = rm
+	}
+	pm := rm.ScopeMetrics().At(0).Metrics().AppendEmpty()
+	pm.SetName(name)
+	b.metrics[key] = pm
+	return pm
+}
+
+// TargetInfoCache keeps the labels of the `target_info` series of each target.
+// Prometheus spreads series over shards and batches, so the `target_info` of a
+// target rarely comes in the same request as its other series. Entries are kept
+// per tenant and expire like the entries of MetadataCache.
+type TargetInfoCache struct {
+	cache *expiringCache[targetInfoKey, []prompb.Label]
+}
+
+type targetInfoKey struct {
+	tenant string
+	target target
+}
+
+// NewTargetInfoCache creates an empty TargetInfoCache holding at most
+// maxTargets targets, each for ttl after its last `target_info` sample.
+func NewTargetInfoCache(maxTargets int, ttl time.Duration) *TargetInfoCache {
+	return &TargetInfoCache{cache: newExpiringCache[targetInfoKey, []prompb.Label](maxTargets, ttl)}
+}
+
+// addTargetInfos copies the labels of the `target_info` series onto the
+// resources of their targets. With a TargetInfoCache, the labels are
+// remembered and also copied onto the resources of later requests.
+func (b *resourceBuilder) addTargetInfos(targetInfos []prompb.TimeSeries, settings Settings) {
+	if settings.TargetInfo == nil {
+		for _, ts := range targetInfos {
+			rm, ok := b.resources[targetOf(ts.Labels)]
+			if !ok {
+				settings.Logger.Debug("Dropping target_info without series from the same target", zap.String("metric_labels", fmt.Sprintf("%v", ts.Labels)))
+				continue
+			}
+			putTargetInfo(rm.Resource().Attributes(), ts.Labels)
+		}
+		return
+	}
+	now := time.Now()
+	for _, ts := range targetInfos {
+		labels := make([]prompb.Label, len(ts.Labels))
+		copy(labels, ts.Labels)
+		key := targetInfoKey{tenant: settings.Tenant, target: targetOf(labels)}
+		settings.TargetInfo.cache.put(key, labels, now)
+	}
+	for t, rm := range b.resources {
+		if labels, ok := settings.TargetInfo.cache.get(targetInfoKey{tenant: settings.Tenant, target: t}, now); ok {
+			putTargetInfo(rm.Resource().Attributes(), labels)
+		}
+	}
+}
+
+// putTargetInfo copies the labels of a `target_info` series, other than those
+// identifying the target, into attrs.
+func putTargetInfo(attrs pcommon.Map, labels []prompb.Label) {
+	for _, l := range labels {
+		switch l.Name {
+		case nameStr, model.JobLabel, model.InstanceLabel:
+		default:
+			attrs.PutStr(l.Name, l.Value)
+		}
+	}
+}
+
+// isResourceLabel reports whether a label is moved to the resource rather
+// than kept as a data point attribute.
+func isResourceLabel(name string) bool {
+	return name == model.JobLabel || name == model.InstanceLabel
+}
@@ -0,0 +1,132 @@
+// Copyright  The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package prometheusremotewrite
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/prometheus/prometheus/prompb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+
+	exportertranslator "github.com/open-telemetry/opentelemetry-collector-contrib/pkg/translator/prometheusremotewrite"
+)
+
+func TestFromTimeSeries_Resources(t *testing.T) {
+	tss := []prompb.TimeSeries{
+		series("up", 1, "job", "shop/api", "instance", "host-1:8080"),
+		series("up", 1, "job", "worker", "instance", "host-2:8080"),
+		series("queue_depth", 3, "job", "worker", "instance", "host-2:8080", "queue", "emails"),
+		series("target_info", 1, "job", "worker", "instance", "host-2:8080", "host_name", "host-2"),
+		series("target_info", 1, "job", "unknown", "instance", "host-3:8080", "host_name", "host-3"),
+		series("untargeted", 5),
+	}
+
+	got, err := FromTimeSeries(tss, testSettings())
+	require.NoError(t, err)
+	require.Equal(t, 3, got.ResourceMetrics().Len())
+
+	api := got.ResourceMetrics().At(0)
+	assert.Equal(t, map[string]any{
+		"service.namespace":   "shop",
+		"service.name":        "api",
+		"service.instance.id": "host-1:8080",
+	}, api.Resource().Attributes().AsRaw())
+	up := api.ScopeMetrics().At(0).Metrics().At(0)
+	assert.Equal(t, map[string]any{"key_name": "up"}, up.Gauge().DataPoints().At(0).Attributes().AsRaw())
+
+	worker := got.ResourceMetrics().At(1)
+	assert.Equal(t, map[string]any{
+		"service.name":        "worker",
+		"service.instance.id": "host-2:8080",
+		"host_name":           "host-2",
+	}, worker.Resource().Attributes().AsRaw())
+	require.Equal(t, 2, worker.ScopeMetrics().At(0).Metrics().Len())
+	assert.Equal(t, "queue_depth", worker.ScopeMetrics().At(0).Metrics().At(1).Name())
+
+	assert.Equal(t, 0, got.ResourceMetrics().At(2).Resource().Attributes().Len())
+}
+
+func TestFromTimeSeries_TargetInfoAcrossRequests(t *testing.T) {
+	settings := testSettings()
+	settings.TargetInfo = NewTargetInfoCache(0, 0)
+	settings.Tenant = "a"
+
+	got, err := FromTimeSeries([]prompb.TimeSeries{
+		series("target_info", 1, "job", "worker", "instance", "host-2:8080", "host_name", "host-2"),
+	}, settings)
+	require.NoError(t, err)
+	assert.Equal(t, 0, got.ResourceMetrics().Len())
+
+	got, err = FromTimeSeries([]prompb.TimeSeries{
+		series("up", 1, "job", "worker", "instance", "host-2:8080"),
+	}, settings)
+	require.NoError(t, err)
+	require.Equal(t, 1, got.ResourceMetrics().Len())
+	assert.Equal(t, map[string]any{
+		"service.name":        "worker",
+		"service.instance.id": "host-2:8080",
+		"host_name":           "host-2",
+	}, got.ResourceMetrics().At(0).Resource().Attributes().AsRaw())
+
+	settings.Tenant = "b"
+	got, err = FromTimeSeries([]prompb.TimeSeries{
+		series("up", 1, "job", "worker", "instance", "host-2:8080"),
+	}, settings)
+	require.NoError(t, err)
+	require.Equal(t, 1, got.ResourceMetrics().Len())
+	assert.Equal(t, map[string]any{
+		"service.name":        "worker",
+		"service.instance.id": "host-2:8080",
+	}, got.ResourceMetrics().At(0).Resource().Attributes().AsRaw())
+}
+
+func TestResourceRoundTrip(t *testing.T) {
+	md := pmetric.NewMetrics()
+	rm := md.ResourceMetrics().AppendEmpty()
+	rm.Resource().Attributes().PutStr("service.namespace", "shop")
+	rm.Resource().Attributes().PutStr("service.name", "api")
+	rm.Resource().Attributes().PutStr("service.instance.id", "host-1")
+	rm.Resource().Attributes().PutStr("region", "eu")
+	metrics := rm.ScopeMetrics().AppendEmpty().Metrics()
+	for _, method := range []string{"GET", "POST"} {
+		m := metrics.AppendEmpty()
+		m.SetName("inflight")
+		dp := m.SetEmptyGauge().DataPoints().AppendEmpty()
+		dp.SetTimestamp(pcommon.Timestamp(nowMillis * 1e6))
+		dp.SetDoubleValue(1)
+		dp.Attributes().PutStr("method", method)
+	}
+
+	tsMap, err := exportertranslator.FromMetrics(md, exportertranslator.Settings{})
+	require.NoError(t, err)
+	var tss []prompb.TimeSeries
+	for _, ts := range tsMap {
+		tss = append(tss, *ts)
+	}
+	sort.Slice(tss, func(i, j int) bool { return labelsSignature("", tss[i].Labels) < labelsSignature("", tss[j].Labels) })
+
+	got, err := FromTimeSeries(tss, testSettings())
+	require.NoError(t, err)
+	require.Equal(t, 1, got.ResourceMetrics().Len())
+	assert.Equal(t, rm.Resource().Attributes().AsRaw(), got.ResourceMetrics().At(0).Resource().Attributes().AsRaw())
+	gotMetrics := got.ResourceMetrics().At(0).ScopeMetrics().At(0).Metrics()
+	require.Equal(t, 1, gotMetrics.Len())
+	assert.Equal(t, "inflight", gotMetrics.At(0).Name())
+	assert.Equal(t, 2, gotMetrics.At(0).Gauge().DataPoints().Len())
+}
@@ -0,0 +1,133 @@
+// Copyright  The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package prometheusremotewrite // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/prometheusremotewritereceiver/translator/prometheusremotewrite"
+
+import (
+	"sync"
+	"time"
+
+	"github.com/prometheus/prometheus/prompb"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.uber.org/zap"
+)
+
+// OutOfOrderPolicy tells what to do with a sample older than the last sample
+// received for its series.
+type OutOfOrderPolicy string
+
+const (
+	// OutOfOrderPass keeps out-of-order samples as any other sample.
+	OutOfOrderPass OutOfOrderPolicy = "pass"
+	// OutOfOrderDrop drops out-of-order samples.
+	OutOfOrderDrop OutOfOrderPolicy = "drop"
+	// OutOfOrderFlag keeps out-of-order samples and sets the outOfOrderAttribute
+	// attribute on their data points.
+	OutOfOrderFlag OutOfOrderPolicy = "flag"
+)
+
+// outOfOrderAttribute marks data points converted from out-of-order samples.
+const outOfOrderAttribute = "prometheus.out_of_order"
+
+// seriesPruneInterval is how often series which stopped sending are forgotten.
+const seriesPruneInterval = time.Minute
+
+// SeriesTracker remembers the timestamp of the last sample received for each
+// series, to detect out-of-order samples across requests.
+type SeriesTracker struct {
+	mu        sync.Mutex
+	last      map[string]int64
+	lastPrune time.Time
+}
+
+// NewSeriesTracker creates an empty SeriesTracker.
+func NewSeriesTracker() *SeriesTracker {
+	return &SeriesTracker{
+		last:      map[string]int64{},
+		lastPrune: time.Now(),
+	}
+}
+
+// observe records a sample of series at ms and reports whether it is older
+// than the last sample of the series.
+func (t *SeriesTracker) observe(series string, ms int64) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if last, ok := t.last[series]; ok && ms < last {
+		return true
+	}
+	t.last[series] = ms
+	return false
+}
+
+// Prune forgets the series whose last sample is older than before. It does
+// nothing when called again within a minute, so it can be called for every
+// request.
+func (t *SeriesTracker) Prune(before time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if time.Since(t.lastPrune) < seriesPruneInterval {
+		return
+	}
+	t.lastPrune = time.Now()
+	ms := before.UnixMilli()
+	for series, last := range t.last {
+		if last < ms {
+			delete(t.last, series)
+		}
+	}
+}
+
+// sampleCheck is the outcome of checking the timestamp of a sample.
+type sampleCheck struct {
+	keep       bool
+	outOfOrder bool
+}
+
+// seriesID identifies a series of the tenant for out-of-order detection. It
+// is empty when out-of-order samples are passed through, so tracking can be
+// skipped.
+func (settings Settings) seriesID(labels []prompb.Label) string {
+	if settings.Series == nil || settings.OutOfOrder == "" || settings.OutOfOrder == OutOfOrderPass {
+		return ""
+	}
+	return labelsSignature(settings.Tenant, labels)
+}
+
+// checkSample applies the time threshold, the future threshold and the
+// out-of-order policy to a sample of the series identified by seriesID.
+func (settings Settings) checkSample(metricName, seriesID string, ms int64) sampleCheck {
+	if isOlderThanThreshold(ms, settings) {
+		settings.Logger.Debug("Metric older than the threshold", zap.String("metric name", metricName), zap.Time("metric_timestamp", timestampFromMillis(ms).AsTime()))
+		return sampleCheck{}
+	}
+	if settings.FutureThreshold > 0 && timestampFromMillis(ms).AsTime().After(time.Now().Add(settings.FutureThreshold)) {
+		settings.Logger.Debug("Metric too far in the future", zap.String("metric name", metricName), zap.Time("metric_timestamp", timestampFromMillis(ms).AsTime()))
+		return sampleCheck{}
+	}
+	if seriesID == "" || !settings.Series.observe(seriesID, ms) {
+		return sampleCheck{keep: true}
+	}
+	if settings.OutOfOrder == OutOfOrderDrop {
+		settings.Logger.Debug("Dropping out-of-order sample", zap.String("metric name", metricName), zap.Time("metric_timestamp", timestampFromMillis(ms).AsTime()))
+		return sampleCheck{}
+	}
+	return sampleCheck{keep: true, outOfOrder: true}
+}
+
+func putOutOfOrder(attrs pcommon.Map, outOfOrder bool) {
+	if outOfOrder {
+		attrs.PutBool(outOfOrderAttribute, true)
+	}
+}
@@ -0,0 +1,137 @@
+// Copyright  The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package prometheusremotewrite
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/prometheus/prometheus/model/value"
+	"github.com/prometheus/prometheus/prompb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+)
+
+func TestFromTimeSeries_StaleMarkers(t *testing.T) {
+	stale := math.Float64frombits(value.StaleNaN)
+	tss := []prompb.TimeSeries{
+		series("queue_depth", stale),
+		series("latency_bucket", stale, "le", "+Inf"),
+		series("latency_count", stale),
+		{
+			Labels:     []prompb.Label{{Name: nameStr, Value: "native"}},
+			Histograms: []prompb.Histogram{{Sum: stale, Timestamp: nowMillis}},
+		},
+	}
+
+	got, err := FromTimeSeries(tss, testSettings())
+	require.NoError(t, err)
+	metrics := got.ResourceMetrics().At(0).ScopeMetrics().At(0).Metrics()
+	require.Equal(t, 3, metrics.Len())
+	for i := 0; i < metrics.Len(); i++ {
+		m := metrics.At(i)
+		var flags pmetric.DataPointFlags
+		switch m.Type() {
+		case pmetric.MetricTypeGauge:
+			flags = m.Gauge().DataPoints().At(0).Flags()
+		case pmetric.MetricTypeHistogram:
+			flags = m.Histogram().DataPoints().At(0).Flags()
+		case pmetric.MetricTypeExponentialHistogram:
+			flags = m.ExponentialHistogram().DataPoints().At(0).Flags()
+		default:
+			t.Fatalf("unexpected metric type %v", m.Type())
+		}
+		assert.True(t, flags.NoRecordedValue(), m.Name())
+	}
+}
+
+func TestFromTimeSeries_FutureThreshold(t *testing.T) {
+	ts := series("queue_depth", 1)
+	ts.Samples = append(ts.Samples, prompb.Sample{Value: 2, Timestamp: now.Add(time.Hour).UnixMilli()})
+	settings := testSettings()
+	settings.FutureThreshold = time.Minute
+
+	got, err := FromTimeSeries([]prompb.TimeSeries{ts}, settings)
+	require.NoError(t, err)
+	points := got.ResourceMetrics().At(0).ScopeMetrics().At(0).Metrics().At(0).Gauge().DataPoints()
+	require.Equal(t, 1, points.Len())
+	assert.Equal(t, 1.0, points.At(0).DoubleValue())
+}
+
+func TestFromTimeSeries_OutOfOrder(t *testing.T) {
+	tests := []struct {
+		policy      OutOfOrderPolicy
+		wantPoints  int
+		wantFlagged bool
+	}{
+		{policy: OutOfOrderPass, wantPoints: 2},
+		{policy: OutOfOrderDrop, wantPoints: 1},
+		{policy: OutOfOrderFlag, wantPoints: 2, wantFlagged: true},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.policy), func(t *testing.T) {
+			settings := testSettings()
+			settings.OutOfOrder = tt.policy
+			settings.Series = NewSeriesTracker()
+
+			_, err := FromTimeSeries([]prompb.TimeSeries{series("queue_depth", 1)}, settings)
+			require.NoError(t, err)
+
+			late := series("queue_depth", 2)
+			late.Samples = []prompb.Sample{{Value: 2, Timestamp: nowMillis - 1000}, {Value: 3, Timestamp: nowMillis + 1000}}
+			got, err := FromTimeSeries([]prompb.TimeSeries{late}, settings)
+			require.NoError(t, err)
+			points := got.ResourceMetrics().At(0).ScopeMetrics().At(0).Metrics().At(0).Gauge().DataPoints()
+			require.Equal(t, tt.wantPoints, points.Len())
+			_, flagged := points.At(0).Attributes().Get(outOfOrderAttribute)
+			assert.Equal(t, tt.wantFlagged, flagged)
+			_, flagged = points.At(points.Len() - 1).Attributes().Get(outOfOrderAttribute)
+			assert.False(t, flagged)
+		})
+	}
+}
+
+func TestFromTimeSeries_OutOfOrderTenants(t *testing.T) {
+	settings := testSettings()
+	settings.OutOfOrder = OutOfOrderDrop
+	settings.Series = NewSeriesTracker()
+
+	settings.Tenant = "a"
+	_, err := FromTimeSeries([]prompb.TimeSeries{series("queue_depth", 1)}, settings)
+	require.NoError(t, err)
+
+	settings.Tenant = "b"
+	older := series("queue_depth", 2)
+	older.Samples[0].Timestamp = nowMillis - 1000
+	got, err := FromTimeSeries([]prompb.TimeSeries{older}, settings)
+	require.NoError(t, err)
+	assert.Equal(t, 1, got.DataPointCount())
+}
+
+func TestSeriesTrackerPrune(t *testing.T) {
+	tracker := NewSeriesTracker()
+	tracker.observe("a", 1000)
+	tracker.observe("b", 5000)
+
+	// Pruning is rate limited.
+	tracker.Prune(time.UnixMilli(2000))
+	assert.Len(t, tracker.last, 2)
+
+	tracker.lastPrune = time.Now().Add(-seriesPruneInterval)
+	tracker.Prune(time.UnixMilli(2000))
+	assert.Equal(t, map[string]int64{"b": 5000}, tracker.last)
+}
@@ -22,6 +22,12 @@ type Config struct {
 	EnableSimpleTags      bool                             `mapstructure:"enable_simple_tags"`
 	IsMonotonicCounter    bool                             `mapstructure:"is_monotonic_counter"`
 	TimerHistogramMapping []protocol.TimerHistogramMapping `mapstructure:"timer_histogram_mapping"`
+	// SetExactCountThreshold is the number of unique values of a set counted
+	// exactly in an interval, above which the count is estimated.
+	SetExactCountThreshold int `mapstructure:"set_exact_count_threshold"`
+	// CardinalityLimit is the maximum number of series aggregated in an
+	// interval, 0 for no limit.
+	CardinalityLimit int `mapstructure:"cardinality_limit"`
 }
 
 func (c *Config) Validate() error {
@@ -31,6 +37,14 @@ func (c *Config) Validate() error {
 		errs = multierr.Append(errs, fmt.Errorf("aggregation_interval must be a positive duration"))
 	}
 
+	if c.SetExactCountThreshold < 0 {
+		errs = multierr.Append(errs, fmt.Errorf("set_exact_count_threshold can't be negative"))
+	}
+
+	if c.CardinalityLimit < 0 {
+		errs = multierr.Append(errs, fmt.Errorf("cardinality_limit can't be negative"))
+	}
+
 	var TimerHistogramMappingMissingObjectName bool
 	for _, eachMap := range c.TimerHistogramMapping {
 
@@ -42,7 +56,7 @@ func (c *Config) Validate() error {
 		switch eachMap.StatsdType {
 		case protocol.TimingTypeName, protocol.TimingAltTypeName, protocol.HistogramTypeName, protocol.DistributionTypeName:
 			// do nothing
-		case protocol.CounterTypeName, protocol.GaugeTypeName:
+		case protocol.CounterTypeName, protocol.GaugeTypeName, protocol.SetTypeName:
 			fallthrough
 		default:
 			errs = multierr.Append(errs, fmt.Errorf("statsd_type is not a supported mapping for histogram and timing metrics: %s", eachMap.StatsdType))
@@ -41,7 +41,9 @@ func TestLoadConfig(t *testing.T) {
 					Endpoint:  "localhost:12345",
 					Transport: confignet.TransportTypeUDP6,
 				},
-				AggregationInterval: 70 * time.Second,
+				AggregationInterval:    70 * time.Second,
+				SetExactCountThreshold: 500,
+				CardinalityLimit:       10000,
 				TimerHistogramMapping: []protocol.TimerHistogramMapping{
 					{
 						StatsdType:   "histogram",
@@ -94,6 +96,8 @@ func TestValidate(t *testing.T) {
 		statsdTypeNotSupportErr        = "statsd_type is not a supported mapping for histogram and timing metrics: %s"
 		observerTypeNotSupportErr      = "observer_type is not supported for histogram and timing metrics: %s"
 		invalidHistogramErr            = "histogram configuration requires observer_type: histogram"
+		negativeSetThresholdErr        = "set_exact_count_threshold can't be negative"
+		negativeCardinalityLimitErr    = "cardinality_limit can't be negative"
 	)
 
 	tests := []test{
@@ -137,6 +141,32 @@ func TestValidate(t *testing.T) {
 			},
 			expectedErr: fmt.Sprintf(statsdTypeNotSupportErr, "abc"),
 		},
+		{
+			name: "SetStatsdTypeNotSupport",
+			cfg: &Config{
+				AggregationInterval: 10,
+				TimerHistogramMapping: []protocol.TimerHistogramMapping{
+					{StatsdType: "set", ObserverType: "gauge"},
+				},
+			},
+			expectedErr: fmt.Sprintf(statsdTypeNotSupportErr, "set"),
+		},
+		{
+			name: "negativeSetExactCountThreshold",
+			cfg: &Config{
+				AggregationInterval:    10,
+				SetExactCountThreshold: -1,
+			},
+			expectedErr: negativeSetThresholdErr,
+		},
+		{
+			name: "negativeCardinalityLimit",
+			cfg: &Config{
+				AggregationInterval: 10,
+				CardinalityLimit:    -1,
+			},
+			expectedErr: negativeCardinalityLimitErr,
+		},
 		{
 			name: "ObserverTypeNotSupport",
 			cfg: &Config{
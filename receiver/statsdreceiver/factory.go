@@ -12,6 +12,7 @@ import (
 	"go.opentelemetry.io/collector/consumer"
 	"go.opentelemetry.io/collector/receiver"
 
+	"github.com/open-telemetry/opentelemetry-collector-contrib/internal/sharedcomponent"
 	"github.com/open-telemetry/opentelemetry-collector-contrib/receiver/statsdreceiver/internal/metadata"
 	"github.com/open-telemetry/opentelemetry-collector-contrib/receiver/statsdreceiver/internal/protocol"
 )
@@ -21,6 +22,8 @@ const (
 	defaultAggregationInterval = 60 * time.Second
 	defaultEnableMetricType    = false
 	defaultIsMonotonicCounter  = false
+
+	defaultSetExactCountThreshold = 1000
 )
 
 var (
@@ -33,6 +36,7 @@ func NewFactory() receiver.Factory {
 		metadata.Type,
 		createDefaultConfig,
 		receiver.WithMetrics(createMetricsReceiver, metadata.MetricsStability),
+		receiver.WithLogs(createLogsReceiver, metadata.LogsStability),
 	)
 }
 
@@ -42,10 +46,11 @@ func createDefaultConfig() component.Config {
 			Endpoint:  defaultBindEndpoint,
 			Transport: confignet.TransportTypeUDP,
 		},
-		AggregationInterval:   defaultAggregationInterval,
-		EnableMetricType:      defaultEnableMetricType,
-		IsMonotonicCounter:    defaultIsMonotonicCounter,
-		TimerHistogramMapping: defaultTimerHistogramMapping,
+		AggregationInterval:    defaultAggregationInterval,
+		EnableMetricType:       defaultEnableMetricType,
+		IsMonotonicCounter:     defaultIsMonotonicCounter,
+		TimerHistogramMapping:  defaultTimerHistogramMapping,
+		SetExactCountThreshold: defaultSetExactCountThreshold,
 	}
 }
 
@@ -55,6 +60,41 @@ func createMetricsReceiver(
 	cfg component.Config,
 	consumer consumer.Metrics,
 ) (receiver.Metrics, error) {
-	c := cfg.(*Config)
-	return newReceiver(params, *c, consumer)
+	r, err := getOrCreateReceiver(params, cfg.(*Config))
+	if err != nil {
+		return nil, err
+	}
+	r.Unwrap().(*statsdReceiver).nextConsumer = consumer
+	return r, nil
+}
+
+// createLogsReceiver creates a receiver of the DogStatsD events and service
+// checks, sharing the server of the metrics receiver of the configuration.
+func createLogsReceiver(
+	_ context.Context,
+	params receiver.CreateSettings,
+	cfg component.Config,
+	consumer consumer.Logs,
+) (receiver.Logs, error) {
+	r, err := getOrCreateReceiver(params, cfg.(*Config))
+	if err != nil {
+		return nil, err
+	}
+	r.Unwrap().(*statsdReceiver).logsConsumer = consumer
+	return r, nil
+}
+
+// getOrCreateReceiver returns the receiver shared by the metrics and logs
+// receivers of cfg. The receiver is created before being shared, so that a
+// failed creation isn't kept for the next one.
+func getOrCreateReceiver(params receiver.CreateSettings, cfg *Config) (*sharedcomponent.SharedComponent, error) {
+	rcv, err := newReceiver(params, *cfg, nil)
+	if err != nil {
+		return nil, err
+	}
+	return receivers.GetOrAdd(cfg, func() component.Component {
+		return rcv
+	}), nil
 }
+
+var receivers = sharedcomponent.NewSharedComponents()
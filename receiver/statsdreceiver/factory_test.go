@@ -5,12 +5,16 @@ package statsdreceiver
 
 import (
 	"context"
+	"errors"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"go.opentelemetry.io/collector/component/componenttest"
 	"go.opentelemetry.io/collector/consumer/consumertest"
 	"go.opentelemetry.io/collector/receiver/receivertest"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/metric/noop"
 )
 
 func TestCreateDefaultConfig(t *testing.T) {
@@ -29,3 +33,52 @@ func TestCreateReceiver(t *testing.T) {
 	assert.NoError(t, err)
 	assert.NotNil(t, tReceiver, "receiver creation failed")
 }
+
+func TestCreateLogsReceiver(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.NetAddr.Endpoint = "localhost:0" // Endpoint is required, not going to be used here.
+
+	params := receivertest.NewNopCreateSettings()
+	tReceiver, err := createLogsReceiver(context.Background(), params, cfg, consumertest.NewNop())
+	assert.NoError(t, err)
+	assert.NotNil(t, tReceiver, "receiver creation failed")
+}
+
+// failingMeterProvider makes the creation of the receiver fail.
+type failingMeterProvider struct {
+	noop.MeterProvider
+}
+
+func (failingMeterProvider) Meter(string, ...metric.MeterOption) metric.Meter {
+	return failingMeter{}
+}
+
+type failingMeter struct {
+	noop.Meter
+}
+
+func (failingMeter) Int64Counter(string, ...metric.Int64CounterOption) (metric.Int64Counter, error) {
+	return nil, errors.New("failing meter")
+}
+
+func TestCreateReceiverFailure(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.NetAddr.Endpoint = "localhost:0"
+
+	params := receivertest.NewNopCreateSettings()
+	params.MeterProvider = failingMeterProvider{}
+	// The failed creation isn't shared with the next one.
+	for i := 0; i < 2; i++ {
+		_, err := createMetricsReceiver(context.Background(), params, cfg, consumertest.NewNop())
+		require.Error(t, err)
+		_, err = createLogsReceiver(context.Background(), params, cfg, consumertest.NewNop())
+		require.Error(t, err)
+	}
+
+	params = receivertest.NewNopCreateSettings()
+	mReceiver, err := createMetricsReceiver(context.Background(), params, cfg, consumertest.NewNop())
+	require.NoError(t, err)
+	lReceiver, err := createLogsReceiver(context.Background(), params, cfg, consumertest.NewNop())
+	require.NoError(t, err)
+	assert.Same(t, mReceiver, lReceiver)
+}
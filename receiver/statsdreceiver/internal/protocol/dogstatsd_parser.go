@@ -0,0 +1,249 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package protocol // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/statsdreceiver/internal/protocol"
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/collector/client"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+	semconv "go.opentelemetry.io/collector/semconv/v1.22.0"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// The DogStatsD events and service checks are converted to log records, see
+// https://docs.datadoghq.com/developers/dogstatsd/datagram_shell/?tab=events
+// and https://docs.datadoghq.com/developers/dogstatsd/datagram_shell/?tab=servicechecks
+const (
+	eventPrefix        = "_e{"
+	serviceCheckPrefix = "_sc|"
+
+	eventTitleAttribute          = "dogstatsd.event.title"
+	eventAggregationKeyAttribute = "dogstatsd.event.aggregation_key"
+	eventPriorityAttribute       = "dogstatsd.event.priority"
+	eventSourceTypeAttribute     = "dogstatsd.event.source_type_name"
+	eventAlertTypeAttribute      = "dogstatsd.event.alert_type"
+	serviceCheckNameAttribute    = "dogstatsd.service_check.name"
+	serviceCheckStatusAttribute  = "dogstatsd.service_check.status"
+)
+
+// serviceCheckStatuses are the names of the service check statuses, by value.
+var serviceCheckStatuses = []string{"ok", "warning", "critical", "unknown"}
+
+type logRecords struct {
+	addr    net.Addr
+	records plog.LogRecordSlice
+}
+
+// isLogMessage returns true if the line is a DogStatsD event or service check.
+func isLogMessage(line string) bool {
+	return strings.HasPrefix(line, eventPrefix) || strings.HasPrefix(line, serviceCheckPrefix)
+}
+
+func (p *StatsDParser) resetLogs() {
+	p.logsByAddress = make(map[netAddr]*logRecords)
+}
+
+// aggregateLog adds the log record of a DogStatsD event or service check.
+func (p *StatsDParser) aggregateLog(line string, addr net.Addr) error {
+	var record plog.LogRecord
+	var err error
+	if strings.HasPrefix(line, eventPrefix) {
+		record, err = parseEventMessage(line, p.enableSimpleTags)
+	} else {
+		record, err = parseServiceCheckMessage(line, p.enableSimpleTags)
+	}
+	if err != nil {
+		return err
+	}
+	record.SetObservedTimestamp(pcommon.NewTimestampFromTime(timeNowFunc()))
+
+	addrKey := newNetAddr(addr)
+	logs, ok := p.logsByAddress[addrKey]
+	if !ok {
+		logs = &logRecords{addr: addr, records: plog.NewLogRecordSlice()}
+		p.logsByAddress[addrKey] = logs
+	}
+	record.MoveTo(logs.records.AppendEmpty())
+	return nil
+}
+
+// GetLogs gets the log records of the DogStatsD events and service checks
+// preparing for flushing and reset them.
+func (p *StatsDParser) GetLogs() []BatchLogs {
+	batchLogs := make([]BatchLogs, 0, len(p.logsByAddress))
+	for _, logs := range p.logsByAddress {
+		batch := BatchLogs{
+			Info: client.Info{
+				Addr: logs.addr,
+			},
+			Logs: plog.NewLogs(),
+		}
+		sl := batch.Logs.ResourceLogs().AppendEmpty().ScopeLogs().AppendEmpty()
+		p.setVersionAndNameScope(sl.Scope())
+		logs.records.MoveAndAppendTo(sl.LogRecords())
+		batchLogs = append(batchLogs, batch)
+	}
+	p.resetLogs()
+	return batchLogs
+}
+
+// parseEventMessage parses a DogStatsD event, e.g.
+// "_e{5,4}:title|text|d:1656581400|h:host|k:key|p:low|s:source|t:error|#key:value".
+func parseEventMessage(line string, enableSimpleTags bool) (plog.LogRecord, error) {
+	record := plog.NewLogRecord()
+
+	header, rest, ok := strings.Cut(strings.TrimPrefix(line, eventPrefix), "}:")
+	if !ok {
+		return record, fmt.Errorf("invalid event format: %s", line)
+	}
+	titleLenStr, textLenStr, ok := strings.Cut(header, ",")
+	if !ok {
+		return record, fmt.Errorf("invalid event format: %s", line)
+	}
+	titleLen, err := strconv.Atoi(titleLenStr)
+	if err != nil || titleLen <= 0 {
+		return record, fmt.Errorf("invalid event title length: %s", titleLenStr)
+	}
+	textLen, err := strconv.Atoi(textLenStr)
+	if err != nil || textLen < 0 {
+		return record, fmt.Errorf("invalid event text length: %s", textLenStr)
+	}
+	if len(rest) < titleLen+1+textLen || rest[titleLen] != '|' {
+		return record, fmt.Errorf("event title and text don't match their lengths: %s", line)
+	}
+	title := rest[:titleLen]
+	text := strings.ReplaceAll(rest[titleLen+1:titleLen+1+textLen], "\\n", "\n")
+	rest = rest[titleLen+1+textLen:]
+
+	record.Body().SetStr(text)
+	record.Attributes().PutStr(eventTitleAttribute, title)
+	alertType := "info"
+	var kvs []attribute.KeyValue
+	if rest != "" {
+		if rest[0] != '|' {
+			return record, fmt.Errorf("event title and text don't match their lengths: %s", line)
+		}
+		for _, part := range strings.Split(rest[1:], "|") {
+			switch {
+			case strings.HasPrefix(part, "k:"):
+				record.Attributes().PutStr(eventAggregationKeyAttribute, strings.TrimPrefix(part, "k:"))
+			case strings.HasPrefix(part, "p:"):
+				priority := strings.TrimPrefix(part, "p:")
+				if priority != "normal" && priority != "low" {
+					return record, fmt.Errorf("invalid event priority: %s", priority)
+				}
+				record.Attributes().PutStr(eventPriorityAttribute, priority)
+			case strings.HasPrefix(part, "s:"):
+				record.Attributes().PutStr(eventSourceTypeAttribute, strings.TrimPrefix(part, "s:"))
+			case strings.HasPrefix(part, "t:"):
+				alertType = strings.TrimPrefix(part, "t:")
+				switch alertType {
+				case "error", "warning", "info", "success":
+				default:
+					return record, fmt.Errorf("invalid event alert type: %s", alertType)
+				}
+			default:
+				partKVs, err := parseLogMessagePart(record, part, enableSimpleTags)
+				if err != nil {
+					return record, err
+				}
+				kvs = append(kvs, partKVs...)
+			}
+		}
+	}
+	record.Attributes().PutStr(eventAlertTypeAttribute, alertType)
+	record.SetSeverityText(alertType)
+	switch alertType {
+	case "error":
+		record.SetSeverityNumber(plog.SeverityNumberError)
+	case "warning":
+		record.SetSeverityNumber(plog.SeverityNumberWarn)
+	default:
+		record.SetSeverityNumber(plog.SeverityNumberInfo)
+	}
+	putAttributes(record.Attributes(), kvs)
+	return record, nil
+}
+
+// parseServiceCheckMessage parses a DogStatsD service check, e.g.
+// "_sc|name|2|d:1656581400|h:host|#key:value|m:message".
+func parseServiceCheckMessage(line string, enableSimpleTags bool) (plog.LogRecord, error) {
+	record := plog.NewLogRecord()
+
+	// The message is always the last part, and may contain '|'.
+	line, message, _ := strings.Cut(line, "|m:")
+	parts := strings.Split(line, "|")
+	if len(parts) < 3 {
+		return record, fmt.Errorf("invalid service check format: %s", line)
+	}
+	name := parts[1]
+	if name == "" {
+		return record, fmt.Errorf("empty service check name")
+	}
+	status, err := strconv.Atoi(parts[2])
+	if err != nil || status < 0 || status >= len(serviceCheckStatuses) {
+		return record, fmt.Errorf("invalid service check status: %s", parts[2])
+	}
+
+	message = strings.ReplaceAll(message, "\\n", "\n")
+	record.Body().SetStr(strings.ReplaceAll(message, "m\\:", "m:"))
+	record.Attributes().PutStr(serviceCheckNameAttribute, name)
+	record.Attributes().PutStr(serviceCheckStatusAttribute, serviceCheckStatuses[status])
+	record.SetSeverityText(serviceCheckStatuses[status])
+	switch status {
+	case 0:
+		record.SetSeverityNumber(plog.SeverityNumberInfo)
+	case 1:
+		record.SetSeverityNumber(plog.SeverityNumberWarn)
+	case 2:
+		record.SetSeverityNumber(plog.SeverityNumberError)
+	}
+
+	var kvs []attribute.KeyValue
+	for _, part := range parts[3:] {
+		partKVs, err := parseLogMessagePart(record, part, enableSimpleTags)
+		if err != nil {
+			return record, err
+		}
+		kvs = append(kvs, partKVs...)
+	}
+	putAttributes(record.Attributes(), kvs)
+	return record, nil
+}
+
+// parseLogMessagePart parses the parts common to events and service checks,
+// setting the timestamp and returning the attributes of the other parts.
+func parseLogMessagePart(record plog.LogRecord, part string, enableSimpleTags bool) ([]attribute.KeyValue, error) {
+	switch {
+	case strings.HasPrefix(part, "d:"):
+		timestampStr := strings.TrimPrefix(part, "d:")
+		timestampSeconds, err := strconv.ParseInt(timestampStr, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid timestamp: %s", timestampStr)
+		}
+		record.SetTimestamp(pcommon.NewTimestampFromTime(time.Unix(timestampSeconds, 0)))
+		return nil, nil
+	case strings.HasPrefix(part, "h:"):
+		return []attribute.KeyValue{attribute.String(semconv.AttributeHostName, strings.TrimPrefix(part, "h:"))}, nil
+	case strings.HasPrefix(part, "#"):
+		return parseTags(strings.TrimPrefix(part, "#"), enableSimpleTags)
+	case strings.HasPrefix(part, "c:"):
+		return parseContainerID(strings.TrimPrefix(part, "c:")), nil
+	case strings.HasPrefix(part, "e:"):
+		return parseExternalData(strings.TrimPrefix(part, "e:")), nil
+	}
+	return nil, fmt.Errorf("unrecognized message part: %s", part)
+}
+
+func putAttributes(dest pcommon.Map, kvs []attribute.KeyValue) {
+	for _, kv := range kvs {
+		dest.PutStr(string(kv.Key), kv.Value.AsString())
+	}
+}
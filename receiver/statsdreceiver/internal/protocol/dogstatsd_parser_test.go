@@ -0,0 +1,205 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package protocol
+
+import (
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+	semconv "go.opentelemetry.io/collector/semconv/v1.22.0"
+)
+
+func Test_ParseEventMessage(t *testing.T) {
+	tests := []struct {
+		name         string
+		input        string
+		wantBody     string
+		wantSeverity plog.SeverityNumber
+		wantTime     pcommon.Timestamp
+		wantAttrs    map[string]any
+		err          error
+	}{
+		{
+			name:         "title and text",
+			input:        "_e{5,4}:title|text",
+			wantBody:     "text",
+			wantSeverity: plog.SeverityNumberInfo,
+			wantAttrs: map[string]any{
+				eventTitleAttribute:     "title",
+				eventAlertTypeAttribute: "info",
+			},
+		},
+		{
+			name:         "all fields",
+			input:        "_e{5,11}:title|line\\nline2|d:1656581400|h:host|k:key|p:low|s:source|t:error|#key:value|c:abc123",
+			wantBody:     "line\nline2",
+			wantSeverity: plog.SeverityNumberError,
+			wantTime:     pcommon.Timestamp(1656581400 * time.Second),
+			wantAttrs: map[string]any{
+				eventTitleAttribute:          "title",
+				eventAggregationKeyAttribute: "key",
+				eventPriorityAttribute:       "low",
+				eventSourceTypeAttribute:     "source",
+				eventAlertTypeAttribute:      "error",
+				semconv.AttributeHostName:    "host",
+				semconv.AttributeContainerID: "abc123",
+				"key":                        "value",
+			},
+		},
+		{
+			name:         "title with separator",
+			input:        "_e{7,0}:ti|t:le||t:warning",
+			wantSeverity: plog.SeverityNumberWarn,
+			wantAttrs: map[string]any{
+				eventTitleAttribute:     "ti|t:le",
+				eventAlertTypeAttribute: "warning",
+			},
+		},
+		{
+			name:  "invalid header",
+			input: "_e{5}:title|text",
+			err:   errors.New("invalid event format: _e{5}:title|text"),
+		},
+		{
+			name:  "lengths mismatch",
+			input: "_e{5,6}:title|text",
+			err:   errors.New("event title and text don't match their lengths: _e{5,6}:title|text"),
+		},
+		{
+			name:  "invalid alert type",
+			input: "_e{5,4}:title|text|t:fatal",
+			err:   errors.New("invalid event alert type: fatal"),
+		},
+		{
+			name:  "unrecognized part",
+			input: "_e{5,4}:title|text|x:y",
+			err:   errors.New("unrecognized message part: x:y"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseEventMessage(tt.input, false)
+			if tt.err != nil {
+				assert.Equal(t, tt.err, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantBody, got.Body().Str())
+			assert.Equal(t, tt.wantSeverity, got.SeverityNumber())
+			assert.Equal(t, tt.wantTime, got.Timestamp())
+			assert.Equal(t, tt.wantAttrs, got.Attributes().AsRaw())
+		})
+	}
+}
+
+func Test_ParseServiceCheckMessage(t *testing.T) {
+	tests := []struct {
+		name         string
+		input        string
+		wantBody     string
+		wantSeverity plog.SeverityNumber
+		wantTime     pcommon.Timestamp
+		wantAttrs    map[string]any
+		err          error
+	}{
+		{
+			name:         "name and status",
+			input:        "_sc|check|0",
+			wantSeverity: plog.SeverityNumberInfo,
+			wantAttrs: map[string]any{
+				serviceCheckNameAttribute:   "check",
+				serviceCheckStatusAttribute: "ok",
+			},
+		},
+		{
+			name:         "all fields",
+			input:        "_sc|check|2|d:1656581400|h:host|#key:value|e:pu-1234|m:failed | m\\: retrying\\n",
+			wantBody:     "failed | m: retrying\n",
+			wantSeverity: plog.SeverityNumberError,
+			wantTime:     pcommon.Timestamp(1656581400 * time.Second),
+			wantAttrs: map[string]any{
+				serviceCheckNameAttribute:   "check",
+				serviceCheckStatusAttribute: "critical",
+				semconv.AttributeHostName:   "host",
+				semconv.AttributeK8SPodUID:  "1234",
+				"key":                       "value",
+			},
+		},
+		{
+			name:         "unknown status",
+			input:        "_sc|check|3",
+			wantSeverity: plog.SeverityNumberUnspecified,
+			wantAttrs: map[string]any{
+				serviceCheckNameAttribute:   "check",
+				serviceCheckStatusAttribute: "unknown",
+			},
+		},
+		{
+			name:  "missing status",
+			input: "_sc|check",
+			err:   errors.New("invalid service check format: _sc|check"),
+		},
+		{
+			name:  "invalid status",
+			input: "_sc|check|4",
+			err:   errors.New("invalid service check status: 4"),
+		},
+		{
+			name:  "empty name",
+			input: "_sc||0",
+			err:   errors.New("empty service check name"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseServiceCheckMessage(tt.input, false)
+			if tt.err != nil {
+				assert.Equal(t, tt.err, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantBody, got.Body().Str())
+			assert.Equal(t, tt.wantSeverity, got.SeverityNumber())
+			assert.Equal(t, tt.wantTime, got.Timestamp())
+			assert.Equal(t, tt.wantAttrs, got.Attributes().AsRaw())
+		})
+	}
+}
+
+func TestStatsDParser_GetLogs(t *testing.T) {
+	timeNowFunc = func() time.Time {
+		return time.Unix(711, 0)
+	}
+	addr := &net.UnixAddr{Name: "/run/statsd.sock", Net: "unixgram"}
+
+	p := &StatsDParser{}
+	require.NoError(t, p.Initialize(false, false, false, nil, 0, 0))
+	require.NoError(t, p.Aggregate("test.metric:42|c", addr))
+	require.NoError(t, p.Aggregate("_e{5,4}:title|text", addr))
+	require.NoError(t, p.Aggregate("_sc|check|1", addr))
+	assert.Error(t, p.Aggregate("_sc|check", addr))
+
+	// The metrics and the logs are flushed independently.
+	require.Len(t, p.GetMetrics(), 1)
+	batches := p.GetLogs()
+	require.Len(t, batches, 1)
+	assert.Equal(t, addr, batches[0].Info.Addr)
+	require.Equal(t, 1, batches[0].Logs.ResourceLogs().Len())
+	sl := batches[0].Logs.ResourceLogs().At(0).ScopeLogs().At(0)
+	assert.Equal(t, receiverName, sl.Scope().Name())
+	require.Equal(t, 2, sl.LogRecords().Len())
+	assert.Equal(t, "text", sl.LogRecords().At(0).Body().Str())
+	assert.Equal(t, pcommon.Timestamp(711*time.Second), sl.LogRecords().At(0).ObservedTimestamp())
+	assert.Equal(t, plog.SeverityNumberWarn, sl.LogRecords().At(1).SeverityNumber())
+
+	assert.Empty(t, p.GetLogs())
+}
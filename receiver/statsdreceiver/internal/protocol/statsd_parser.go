@@ -11,6 +11,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/axiomhq/hyperloglog"
 	"github.com/lightstep/go-expohisto/structure"
 	"go.opentelemetry.io/collector/client"
 	"go.opentelemetry.io/collector/component"
@@ -23,6 +24,10 @@ import (
 var (
 	errEmptyMetricName  = errors.New("empty metric name")
 	errEmptyMetricValue = errors.New("empty metric value")
+
+	// overflowAttributes are the attributes of the series getting the
+	// values of the series above the cardinality limit.
+	overflowAttributes = attribute.NewSet(attribute.Bool(overflowAttribute, true))
 )
 
 type (
@@ -32,13 +37,15 @@ type (
 )
 
 const (
-	tagMetricType = "metric_type"
+	tagMetricType     = "metric_type"
+	overflowAttribute = "otel.metric.overflow"
 
 	CounterType      MetricType = "c"
 	GaugeType        MetricType = "g"
 	HistogramType    MetricType = "h"
 	TimingType       MetricType = "ms"
 	DistributionType MetricType = "d"
+	SetType          MetricType = "s"
 
 	CounterTypeName      TypeName = "counter"
 	GaugeTypeName        TypeName = "gauge"
@@ -46,6 +53,7 @@ const (
 	TimingTypeName       TypeName = "timing"
 	TimingAltTypeName    TypeName = "timer"
 	DistributionTypeName TypeName = "distribution"
+	SetTypeName          TypeName = "set"
 
 	GaugeObserver     ObserverType = "gauge"
 	SummaryObserver   ObserverType = "summary"
@@ -85,7 +93,17 @@ type StatsDParser struct {
 	timerEvents          ObserverCategory
 	histogramEvents      ObserverCategory
 	lastIntervalTime     time.Time
-	BuildInfo            component.BuildInfo
+	logsByAddress        map[netAddr]*logRecords
+	// setExactCountThreshold is the number of unique values of a set
+	// counted exactly, above which they are estimated.
+	setExactCountThreshold int
+	// cardinalityLimit is the maximum number of series per interval, 0 if
+	// unlimited. The series above the limit are moved to an overflow series
+	// of their metric.
+	cardinalityLimit    int
+	seriesCount         int
+	overflowSeriesCount int
+	BuildInfo           component.BuildInfo
 }
 
 type instruments struct {
@@ -94,7 +112,10 @@ type instruments struct {
 	counters               map[statsDMetricDescription]pmetric.ScopeMetrics
 	summaries              map[statsDMetricDescription]summaryMetric
 	histograms             map[statsDMetricDescription]histogramMetric
+	sets                   map[statsDMetricDescription]*setMetric
 	timersAndDistributions []pmetric.ScopeMetrics
+	// series are the series of the interval, tracked for the cardinality limit.
+	series map[statsDMetricDescription]struct{}
 }
 
 func newInstruments(addr net.Addr) *instruments {
@@ -104,6 +125,8 @@ func newInstruments(addr net.Addr) *instruments {
 		counters:   make(map[statsDMetricDescription]pmetric.ScopeMetrics),
 		summaries:  make(map[statsDMetricDescription]summaryMetric),
 		histograms: make(map[statsDMetricDescription]histogramMetric),
+		sets:       make(map[statsDMetricDescription]*setMetric),
+		series:     make(map[statsDMetricDescription]struct{}),
 	}
 }
 
@@ -123,9 +146,47 @@ type histogramMetric struct {
 	agg *histogramStructure
 }
 
+// setMetric counts the unique values of a set, exactly up to a threshold
+// and with a HyperLogLog sketch above it.
+type setMetric struct {
+	threshold int
+	values    map[string]struct{}
+	sketch    *hyperloglog.Sketch
+}
+
+func newSetMetric(threshold int) *setMetric {
+	return &setMetric{
+		threshold: threshold,
+		values:    make(map[string]struct{}),
+	}
+}
+
+func (s *setMetric) add(value string) {
+	if s.sketch != nil {
+		s.sketch.Insert([]byte(value))
+		return
+	}
+	s.values[value] = struct{}{}
+	if len(s.values) > s.threshold {
+		s.sketch = hyperloglog.New14()
+		for v := range s.values {
+			s.sketch.Insert([]byte(v))
+		}
+		s.values = nil
+	}
+}
+
+func (s *setMetric) count() uint64 {
+	if s.sketch != nil {
+		return s.sketch.Estimate()
+	}
+	return uint64(len(s.values))
+}
+
 type statsDMetric struct {
 	description statsDMetricDescription
 	asFloat     float64
+	setValue    string
 	addition    bool
 	unit        string
 	sampleRate  float64
@@ -150,6 +211,8 @@ func (t MetricType) FullName() TypeName {
 		return HistogramTypeName
 	case DistributionType:
 		return DistributionTypeName
+	case SetType:
+		return SetTypeName
 	}
 	return TypeName(fmt.Sprintf("unknown(%s)", t))
 }
@@ -157,16 +220,21 @@ func (t MetricType) FullName() TypeName {
 func (p *StatsDParser) resetState(when time.Time) {
 	p.lastIntervalTime = when
 	p.instrumentsByAddress = make(map[netAddr]*instruments)
+	p.seriesCount = 0
+	p.overflowSeriesCount = 0
 }
 
-func (p *StatsDParser) Initialize(enableMetricType bool, enableSimpleTags bool, isMonotonicCounter bool, sendTimerHistogram []TimerHistogramMapping) error {
+func (p *StatsDParser) Initialize(enableMetricType bool, enableSimpleTags bool, isMonotonicCounter bool, sendTimerHistogram []TimerHistogramMapping, setExactCountThreshold int, cardinalityLimit int) error {
 	p.resetState(timeNowFunc())
+	p.resetLogs()
 
 	p.histogramEvents = defaultObserverCategory
 	p.timerEvents = defaultObserverCategory
 	p.enableMetricType = enableMetricType
 	p.enableSimpleTags = enableSimpleTags
 	p.isMonotonicCounter = isMonotonicCounter
+	p.setExactCountThreshold = setExactCountThreshold
+	p.cardinalityLimit = cardinalityLimit
 	// Note: validation occurs in ("../".Config).validate()
 	for _, eachMap := range sendTimerHistogram {
 		switch eachMap.StatsdType {
@@ -176,7 +244,7 @@ func (p *StatsDParser) Initialize(enableMetricType bool, enableSimpleTags bool,
 		case TimingTypeName, TimingAltTypeName:
 			p.timerEvents.method = eachMap.ObserverType
 			p.timerEvents.histogramConfig = expoHistogramConfig(eachMap.Histogram)
-		case CounterTypeName, GaugeTypeName:
+		case CounterTypeName, GaugeTypeName, SetTypeName:
 		}
 	}
 	return nil
@@ -242,6 +310,13 @@ func (p *StatsDParser) GetMetrics() []BatchMetrics {
 			)
 		}
 
+		for desc, setMetric := range instrument.sets {
+			ilm := rm.ScopeMetrics().AppendEmpty()
+			p.setVersionAndNameScope(ilm.Scope())
+
+			buildSetMetric(desc, setMetric, now, ilm)
+		}
+
 		batchMetrics = append(batchMetrics, batch)
 	}
 	p.resetState(now)
@@ -267,13 +342,17 @@ func (p *StatsDParser) observerCategoryFor(t MetricType) ObserverCategory {
 		return p.histogramEvents
 	case TimingType:
 		return p.timerEvents
-	case CounterType, GaugeType:
+	case CounterType, GaugeType, SetType:
 	}
 	return defaultObserverCategory
 }
 
 // Aggregate for each metric line.
 func (p *StatsDParser) Aggregate(line string, addr net.Addr) error {
+	if isLogMessage(line) {
+		return p.aggregateLog(line, addr)
+	}
+
 	parsedMetric, err := parseMessageToMetric(line, p.enableMetricType, p.enableSimpleTags)
 	if err != nil {
 		return err
@@ -286,6 +365,10 @@ func (p *StatsDParser) Aggregate(line string, addr net.Addr) error {
 		p.instrumentsByAddress[addrKey] = instrument
 	}
 
+	if !p.admitSeries(instrument, &parsedMetric.description) {
+		return fmt.Errorf("cardinality limit of %d series reached, dropped metric %s", p.cardinalityLimit, parsedMetric.description.name)
+	}
+
 	switch parsedMetric.description.metricType {
 	case GaugeType:
 		_, ok := instrument.gauges[parsedMetric.description]
@@ -348,11 +431,48 @@ func (p *StatsDParser) Aggregate(line string, addr net.Addr) error {
 		case DisableObserver:
 			// No action.
 		}
+
+	case SetType:
+		set, ok := instrument.sets[parsedMetric.description]
+		if !ok {
+			set = newSetMetric(p.setExactCountThreshold)
+			instrument.sets[parsedMetric.description] = set
+		}
+		set.add(parsedMetric.setValue)
 	}
 
 	return nil
 }
 
+// admitSeries checks the cardinality limit for the series of a metric. Once
+// the limit is reached, the new series are moved to the overflow series of
+// their metric, which are limited to the same number. It returns false if
+// the metric must be dropped.
+func (p *StatsDParser) admitSeries(instrument *instruments, desc *statsDMetricDescription) bool {
+	if p.cardinalityLimit <= 0 {
+		return true
+	}
+	if _, ok := instrument.series[*desc]; ok {
+		return true
+	}
+	if p.seriesCount < p.cardinalityLimit {
+		instrument.series[*desc] = struct{}{}
+		p.seriesCount++
+		return true
+	}
+
+	desc.attrs = overflowAttributes
+	if _, ok := instrument.series[*desc]; ok {
+		return true
+	}
+	if p.overflowSeriesCount < p.cardinalityLimit {
+		instrument.series[*desc] = struct{}{}
+		p.overflowSeriesCount++
+		return true
+	}
+	return false
+}
+
 func parseMessageToMetric(line string, enableMetricType bool, enableSimpleTags bool) (statsDMetric, error) {
 	result := statsDMetric{}
 
@@ -380,7 +500,7 @@ func parseMessageToMetric(line string, enableMetricType bool, enableSimpleTags b
 
 	inType := MetricType(parts[1])
 	switch inType {
-	case CounterType, GaugeType, HistogramType, TimingType, DistributionType:
+	case CounterType, GaugeType, HistogramType, TimingType, DistributionType, SetType:
 		result.description.metricType = inType
 	default:
 		return result, fmt.Errorf("unsupported metric type: %s", inType)
@@ -393,6 +513,10 @@ func parseMessageToMetric(line string, enableMetricType bool, enableSimpleTags b
 	for _, part := range additionalParts {
 		switch {
 		case strings.HasPrefix(part, "@"):
+			// Each value of a set is counted once, sampling can't be compensated.
+			if inType == SetType {
+				return result, fmt.Errorf("SET metrics don't support a sample rate")
+			}
 			sampleRateStr := strings.TrimPrefix(part, "@")
 
 			f, err := strconv.ParseFloat(sampleRateStr, 64)
@@ -402,44 +526,15 @@ func parseMessageToMetric(line string, enableMetricType bool, enableSimpleTags b
 
 			result.sampleRate = f
 		case strings.HasPrefix(part, "#"):
-			tagsStr := strings.TrimPrefix(part, "#")
-
-			// handle an empty tag set
-			// where the tags part was still sent (some clients do this)
-			if len(tagsStr) == 0 {
-				continue
-			}
-
-			tagSets := strings.Split(tagsStr, ",")
-
-			for _, tagSet := range tagSets {
-				tagParts := strings.SplitN(tagSet, ":", 2)
-				k := tagParts[0]
-				if k == "" {
-					return result, fmt.Errorf("invalid tag format: %q", tagSet)
-				}
-
-				// support both simple tags (w/o value) and dimension tags (w/ value).
-				// dogstatsd notably allows simple tags.
-				var v string
-				if len(tagParts) == 2 {
-					v = tagParts[1]
-				}
-
-				if v == "" && !enableSimpleTags {
-					return result, fmt.Errorf("invalid tag format: %q", tagSet)
-				}
-
-				kvs = append(kvs, attribute.String(k, v))
+			tags, err := parseTags(strings.TrimPrefix(part, "#"), enableSimpleTags)
+			if err != nil {
+				return result, err
 			}
+			kvs = append(kvs, tags...)
 		case strings.HasPrefix(part, "c:"):
-			// As per DogStatD protocol v1.2:
-			// https://docs.datadoghq.com/developers/dogstatsd/datagram_shell/?tab=metrics#dogstatsd-protocol-v12
-			containerID := strings.TrimPrefix(part, "c:")
-
-			if containerID != "" {
-				kvs = append(kvs, attribute.String(semconv.AttributeContainerID, containerID))
-			}
+			kvs = append(kvs, parseContainerID(strings.TrimPrefix(part, "c:"))...)
+		case strings.HasPrefix(part, "e:"):
+			kvs = append(kvs, parseExternalData(strings.TrimPrefix(part, "e:"))...)
 		case strings.HasPrefix(part, "T"):
 			// As per DogStatD protocol v1.3:
 			// https://docs.datadoghq.com/developers/dogstatsd/datagram_shell/?tab=metrics#dogstatsd-protocol-v13
@@ -458,10 +553,16 @@ func parseMessageToMetric(line string, enableMetricType bool, enableSimpleTags b
 			return result, fmt.Errorf("unrecognized message part: %s", part)
 		}
 	}
-	var err error
-	result.asFloat, err = strconv.ParseFloat(valueStr, 64)
-	if err != nil {
-		return result, fmt.Errorf("parse metric value string: %s", valueStr)
+	if inType == SetType {
+		// The values of sets are counted, they don't need to be numbers.
+		result.addition = false
+		result.setValue = valueStr
+	} else {
+		var err error
+		result.asFloat, err = strconv.ParseFloat(valueStr, 64)
+		if err != nil {
+			return result, fmt.Errorf("parse metric value string: %s", valueStr)
+		}
 	}
 
 	// add metric_type dimension for all metrics
@@ -478,6 +579,71 @@ func parseMessageToMetric(line string, enableMetricType bool, enableSimpleTags b
 	return result, nil
 }
 
+// parseTags parses the tags part of a message, e.g. "key:value,key2:value2".
+func parseTags(tagsStr string, enableSimpleTags bool) ([]attribute.KeyValue, error) {
+	// handle an empty tag set
+	// where the tags part was still sent (some clients do this)
+	if len(tagsStr) == 0 {
+		return nil, nil
+	}
+
+	var kvs []attribute.KeyValue
+	for _, tagSet := range strings.Split(tagsStr, ",") {
+		tagParts := strings.SplitN(tagSet, ":", 2)
+		k := tagParts[0]
+		if k == "" {
+			return nil, fmt.Errorf("invalid tag format: %q", tagSet)
+		}
+
+		// support both simple tags (w/o value) and dimension tags (w/ value).
+		// dogstatsd notably allows simple tags.
+		var v string
+		if len(tagParts) == 2 {
+			v = tagParts[1]
+		}
+
+		if v == "" && !enableSimpleTags {
+			return nil, fmt.Errorf("invalid tag format: %q", tagSet)
+		}
+
+		kvs = append(kvs, attribute.String(k, v))
+	}
+	return kvs, nil
+}
+
+// parseContainerID parses the container ID part of a DogStatsD message.
+func parseContainerID(containerID string) []attribute.KeyValue {
+	// As per DogStatD protocol v1.2:
+	// https://docs.datadoghq.com/developers/dogstatsd/datagram_shell/?tab=metrics#dogstatsd-protocol-v12
+	// Newer clients prefix the container ID with "ci-", or send the inode of
+	// the cgroup of the container prefixed with "in-", which can only be
+	// resolved on the host of the client.
+	if strings.HasPrefix(containerID, "in-") {
+		return nil
+	}
+	containerID = strings.TrimPrefix(containerID, "ci-")
+	if containerID == "" {
+		return nil
+	}
+	return []attribute.KeyValue{attribute.String(semconv.AttributeContainerID, containerID)}
+}
+
+// parseExternalData parses the external data part of a DogStatsD message,
+// which identifies the Kubernetes pod and container of the client when its
+// container ID isn't available, e.g. "it-false,cn-app,pu-<pod uid>".
+func parseExternalData(data string) []attribute.KeyValue {
+	var kvs []attribute.KeyValue
+	for _, item := range strings.Split(data, ",") {
+		switch {
+		case strings.HasPrefix(item, "pu-") && len(item) > 3:
+			kvs = append(kvs, attribute.String(semconv.AttributeK8SPodUID, item[3:]))
+		case strings.HasPrefix(item, "cn-") && len(item) > 3:
+			kvs = append(kvs, attribute.String(semconv.AttributeK8SContainerName, item[3:]))
+		}
+	}
+	return kvs
+}
+
 type netAddr struct {
 	Network string
 	String  string
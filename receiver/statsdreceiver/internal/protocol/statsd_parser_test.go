@@ -71,6 +71,24 @@ func Test_ParseMessageToMetric(t *testing.T) {
 				false,
 				"c", 0, nil, nil, 0),
 		},
+		{
+			name:  "set",
+			input: "test.users:alice|s|#key:value",
+			wantMetric: func() statsDMetric {
+				m := testStatsDMetric(
+					"test.users",
+					0,
+					false,
+					"s", 0, []string{"key"}, []string{"value"}, 0)
+				m.setValue = "alice"
+				return m
+			}(),
+		},
+		{
+			name:  "set with sample rate",
+			input: "test.users:alice|s|@0.1",
+			err:   errors.New("SET metrics don't support a sample rate"),
+		},
 		{
 			name:  "integer counter",
 			input: "test.metric:42|c",
@@ -118,6 +136,48 @@ func Test_ParseMessageToMetric(t *testing.T) {
 				0,
 			),
 		},
+		{
+			name:  "counter metric with prefixed container ID",
+			input: "test.metric:42|c|c:ci-abc123",
+			wantMetric: testStatsDMetric(
+				"test.metric",
+				42,
+				false,
+				"c",
+				0,
+				[]string{semconv.AttributeContainerID},
+				[]string{"abc123"},
+				0,
+			),
+		},
+		{
+			name:  "counter metric with cgroup inode",
+			input: "test.metric:42|c|c:in-1234",
+			wantMetric: testStatsDMetric(
+				"test.metric",
+				42,
+				false,
+				"c",
+				0,
+				nil,
+				nil,
+				0,
+			),
+		},
+		{
+			name:  "counter metric with external data",
+			input: "test.metric:42|c|e:it-false,cn-app,pu-75a2b6d5",
+			wantMetric: testStatsDMetric(
+				"test.metric",
+				42,
+				false,
+				"c",
+				0,
+				[]string{semconv.AttributeK8SContainerName, semconv.AttributeK8SPodUID},
+				[]string{"app", "75a2b6d5"},
+				0,
+			),
+		},
 		{
 			name:  "counter metric with timestamp",
 			input: "test.metric:42|c|T1656581400",
@@ -1042,7 +1102,7 @@ func TestStatsDParser_Aggregate(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			var err error
 			p := &StatsDParser{}
-			assert.NoError(t, p.Initialize(false, false, false, []TimerHistogramMapping{{StatsdType: "timer", ObserverType: "gauge"}, {StatsdType: "histogram", ObserverType: "gauge"}}))
+			assert.NoError(t, p.Initialize(false, false, false, []TimerHistogramMapping{{StatsdType: "timer", ObserverType: "gauge"}, {StatsdType: "histogram", ObserverType: "gauge"}}, 0, 0))
 			p.lastIntervalTime = time.Unix(611, 0)
 			addr, _ := net.ResolveUDPAddr("udp", "1.2.3.4:5678")
 			addrKey := newNetAddr(addr)
@@ -1151,7 +1211,7 @@ func TestStatsDParser_AggregateByAddress(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			p := &StatsDParser{}
-			assert.NoError(t, p.Initialize(true, false, false, []TimerHistogramMapping{{StatsdType: "timer", ObserverType: "gauge"}, {StatsdType: "histogram", ObserverType: "gauge"}}))
+			assert.NoError(t, p.Initialize(true, false, false, []TimerHistogramMapping{{StatsdType: "timer", ObserverType: "gauge"}, {StatsdType: "histogram", ObserverType: "gauge"}}, 0, 0))
 			p.lastIntervalTime = time.Unix(611, 0)
 			for i, addr := range tt.addresses {
 				for _, line := range tt.input[i] {
@@ -1259,7 +1319,7 @@ func TestStatsDParser_AggregateWithMetricType(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			var err error
 			p := &StatsDParser{}
-			assert.NoError(t, p.Initialize(true, false, false, []TimerHistogramMapping{{StatsdType: "timer", ObserverType: "gauge"}, {StatsdType: "histogram", ObserverType: "gauge"}}))
+			assert.NoError(t, p.Initialize(true, false, false, []TimerHistogramMapping{{StatsdType: "timer", ObserverType: "gauge"}, {StatsdType: "histogram", ObserverType: "gauge"}}, 0, 0))
 			p.lastIntervalTime = time.Unix(611, 0)
 			addr, _ := net.ResolveUDPAddr("udp", "1.2.3.4:5678")
 			addrKey := newNetAddr(addr)
@@ -1329,7 +1389,7 @@ func TestStatsDParser_AggregateWithIsMonotonicCounter(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			var err error
 			p := &StatsDParser{}
-			assert.NoError(t, p.Initialize(false, false, true, []TimerHistogramMapping{{StatsdType: "timer", ObserverType: "gauge"}, {StatsdType: "histogram", ObserverType: "gauge"}}))
+			assert.NoError(t, p.Initialize(false, false, true, []TimerHistogramMapping{{StatsdType: "timer", ObserverType: "gauge"}, {StatsdType: "histogram", ObserverType: "gauge"}}, 0, 0))
 			p.lastIntervalTime = time.Unix(611, 0)
 			addr, _ := net.ResolveUDPAddr("udp", "1.2.3.4:5678")
 			addrKey := newNetAddr(addr)
@@ -1451,7 +1511,7 @@ func TestStatsDParser_AggregateTimerWithSummary(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			var err error
 			p := &StatsDParser{}
-			assert.NoError(t, p.Initialize(false, false, false, []TimerHistogramMapping{{StatsdType: "timer", ObserverType: "summary"}, {StatsdType: "histogram", ObserverType: "summary"}}))
+			assert.NoError(t, p.Initialize(false, false, false, []TimerHistogramMapping{{StatsdType: "timer", ObserverType: "summary"}, {StatsdType: "histogram", ObserverType: "summary"}}, 0, 0))
 			addr, _ := net.ResolveUDPAddr("udp", "1.2.3.4:5678")
 			addrKey := newNetAddr(addr)
 			for _, line := range tt.input {
@@ -1468,7 +1528,7 @@ func TestStatsDParser_AggregateTimerWithSummary(t *testing.T) {
 
 func TestStatsDParser_Initialize(t *testing.T) {
 	p := &StatsDParser{}
-	assert.NoError(t, p.Initialize(true, false, false, []TimerHistogramMapping{{StatsdType: "timer", ObserverType: "gauge"}, {StatsdType: "histogram", ObserverType: "gauge"}}))
+	assert.NoError(t, p.Initialize(true, false, false, []TimerHistogramMapping{{StatsdType: "timer", ObserverType: "gauge"}, {StatsdType: "histogram", ObserverType: "gauge"}}, 0, 0))
 	teststatsdDMetricdescription := statsDMetricDescription{
 		name:       "test",
 		metricType: "g",
@@ -1487,7 +1547,7 @@ func TestStatsDParser_Initialize(t *testing.T) {
 
 func TestStatsDParser_GetMetricsWithMetricType(t *testing.T) {
 	p := &StatsDParser{}
-	assert.NoError(t, p.Initialize(true, false, false, []TimerHistogramMapping{{StatsdType: "timer", ObserverType: "gauge"}, {StatsdType: "histogram", ObserverType: "gauge"}}))
+	assert.NoError(t, p.Initialize(true, false, false, []TimerHistogramMapping{{StatsdType: "timer", ObserverType: "gauge"}, {StatsdType: "histogram", ObserverType: "gauge"}}, 0, 0))
 	instrument := newInstruments(nil)
 	instrument.gauges[testDescription("statsdTestMetric1", "g",
 		[]string{"mykey", "metric_type"}, []string{"myvalue", "gauge"})] = buildGaugeMetric(
@@ -1600,7 +1660,7 @@ func TestStatsDParser_Mappings(t *testing.T) {
 		t.Run(tc.name, func(t *testing.T) {
 			p := &StatsDParser{}
 
-			assert.NoError(t, p.Initialize(false, false, false, tc.mapping))
+			assert.NoError(t, p.Initialize(false, false, false, tc.mapping, 0, 0))
 
 			addr, _ := net.ResolveUDPAddr("udp", "1.2.3.4:5678")
 			assert.NoError(t, p.Aggregate("H:10|h", addr))
@@ -1639,6 +1699,7 @@ func TestStatsDParser_ScopeIsIncluded(t *testing.T) {
 			{StatsdType: "timer", ObserverType: "summary"},
 			{StatsdType: "histogram", ObserverType: "histogram"},
 		},
+		0, 0,
 	)
 	require.NoError(t, err)
 	require.NoError(t, p.Aggregate("test.metric:1|c", testAddress))
@@ -1904,7 +1965,7 @@ func TestStatsDParser_AggregateTimerWithHistogram(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			var err error
 			p := &StatsDParser{}
-			assert.NoError(t, p.Initialize(false, false, false, tt.mapping))
+			assert.NoError(t, p.Initialize(false, false, false, tt.mapping, 0, 0))
 			addr, _ := net.ResolveUDPAddr("udp", "1.2.3.4:5678")
 			for _, line := range tt.input {
 				err = p.Aggregate(line, addr)
@@ -1915,3 +1976,73 @@ func TestStatsDParser_AggregateTimerWithHistogram(t *testing.T) {
 		})
 	}
 }
+
+func TestStatsDParser_AggregateSets(t *testing.T) {
+	p := &StatsDParser{}
+	assert.NoError(t, p.Initialize(false, false, false, nil, 2, 0))
+	addr, _ := net.ResolveUDPAddr("udp", "1.2.3.4:5678")
+	for _, line := range []string{
+		"test.users:alice|s",
+		"test.users:bob|s",
+		"test.users:alice|s",
+		"test.ips:10.0.0.1|s",
+		"test.ips:10.0.0.2|s",
+		"test.ips:10.0.0.3|s",
+		"test.ips:10.0.0.1|s",
+	} {
+		require.NoError(t, p.Aggregate(line, addr))
+	}
+
+	instrument := p.instrumentsByAddress[newNetAddr(addr)]
+	users := instrument.sets[statsDMetricDescription{name: "test.users", metricType: SetType}]
+	require.NotNil(t, users)
+	assert.Nil(t, users.sketch)
+	assert.Equal(t, uint64(2), users.count())
+	// Above the threshold the count is estimated.
+	ips := instrument.sets[statsDMetricDescription{name: "test.ips", metricType: SetType}]
+	require.NotNil(t, ips)
+	assert.NotNil(t, ips.sketch)
+	assert.Equal(t, uint64(3), ips.count())
+
+	metrics := p.GetMetrics()[0].Metrics
+	require.Equal(t, 2, metrics.MetricCount())
+	counts := map[string]int64{}
+	sms := metrics.ResourceMetrics().At(0).ScopeMetrics()
+	for i := 0; i < sms.Len(); i++ {
+		metric := sms.At(i).Metrics().At(0)
+		require.Equal(t, pmetric.MetricTypeGauge, metric.Type())
+		counts[metric.Name()] = metric.Gauge().DataPoints().At(0).IntValue()
+	}
+	assert.Equal(t, map[string]int64{"test.users": 2, "test.ips": 3}, counts)
+}
+
+func TestStatsDParser_CardinalityLimit(t *testing.T) {
+	p := &StatsDParser{}
+	assert.NoError(t, p.Initialize(false, false, false, nil, 0, 2))
+	addr, _ := net.ResolveUDPAddr("udp", "1.2.3.4:5678")
+	for _, line := range []string{
+		"test.requests:1|c|#path:/a",
+		"test.requests:1|c|#path:/b",
+		"test.requests:1|c|#path:/a",
+		"test.requests:1|c|#path:/c",
+		"test.requests:1|c|#path:/d",
+		"test.temperature:20|g|#room:kitchen",
+	} {
+		require.NoError(t, p.Aggregate(line, addr))
+	}
+	// The overflow series are limited too.
+	assert.EqualError(t, p.Aggregate("test.users:alice|s|#path:/a", addr),
+		"cardinality limit of 2 series reached, dropped metric test.users")
+
+	instrument := p.instrumentsByAddress[newNetAddr(addr)]
+	assert.Len(t, instrument.counters, 3)
+	overflow := statsDMetricDescription{name: "test.requests", metricType: CounterType, attrs: overflowAttributes}
+	require.Contains(t, instrument.counters, overflow)
+	assert.Equal(t, int64(2), instrument.counters[overflow].Metrics().At(0).Sum().DataPoints().At(0).IntValue())
+	assert.Contains(t, instrument.gauges, statsDMetricDescription{name: "test.temperature", metricType: GaugeType, attrs: overflowAttributes})
+
+	// The limit applies to each interval.
+	p.GetMetrics()
+	require.NoError(t, p.Aggregate("test.users:alice|s|#path:/a", addr))
+	assert.Contains(t, p.instrumentsByAddress[newNetAddr(addr)].sets, testDescription("test.users", SetType, []string{"path"}, []string{"/a"}))
+}
@@ -4,6 +4,7 @@
 package client // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/statsdreceiver/internal/transport/client"
 
 import (
+	"encoding/binary"
 	"fmt"
 	"io"
 	"net"
@@ -45,7 +46,7 @@ func (s *StatsD) connect() error {
 		if err != nil {
 			return err
 		}
-	case "tcp":
+	case "tcp", "unix", "unixgram":
 		var err error
 		s.conn, err = net.Dial(s.transport, s.address)
 		if err != nil {
@@ -70,7 +71,18 @@ func (s *StatsD) Disconnect() error {
 
 // SendMetric sends the input metric to the StatsD connection.
 func (s *StatsD) SendMetric(metric Metric) error {
-	_, err := io.Copy(s.conn, strings.NewReader(metric.String()))
+	return s.SendMessage(metric.String())
+}
+
+// SendMessage sends a raw StatsD message to the StatsD connection. The
+// messages of unix stream sockets are prefixed by their length.
+func (s *StatsD) SendMessage(msg string) error {
+	if s.transport == "unix" {
+		if err := binary.Write(s.conn, binary.LittleEndian, uint32(len(msg))); err != nil {
+			return fmt.Errorf("send metric on test client: %w", err)
+		}
+	}
+	_, err := io.Copy(s.conn, strings.NewReader(msg))
 	if err != nil {
 		return fmt.Errorf("send metric on test client: %w", err)
 	}
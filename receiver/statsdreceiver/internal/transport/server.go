@@ -6,8 +6,6 @@ package transport // import "github.com/open-telemetry/opentelemetry-collector-c
 import (
 	"errors"
 	"net"
-
-	"go.opentelemetry.io/collector/consumer"
 )
 
 var errNilListenAndServeParameters = errors.New("no parameter of ListenAndServe can be nil")
@@ -19,7 +17,6 @@ type Server interface {
 	// on the specific transport, and prepares the message to be processed by
 	// the Parser and passed to the next consumer.
 	ListenAndServe(
-		mc consumer.Metrics,
 		r Reporter,
 		transferChan chan<- Metric,
 	) error
@@ -6,6 +6,8 @@ package transport
 import (
 	"io"
 	"net"
+	"os"
+	"path/filepath"
 	"runtime"
 	"sync"
 	"testing"
@@ -13,7 +15,6 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
-	"go.opentelemetry.io/collector/consumer/consumertest"
 
 	"github.com/open-telemetry/opentelemetry-collector-contrib/internal/common/testutil"
 	"github.com/open-telemetry/opentelemetry-collector-contrib/receiver/statsdreceiver/internal/transport/client"
@@ -41,6 +42,20 @@ func Test_Server_ListenAndServe(t *testing.T) {
 			buildServerFn:     NewTCPServer,
 			buildClientFn:     client.NewStatsD,
 		},
+		{
+			name:              "unixgram",
+			transport:         UnixGram,
+			getFreeEndpointFn: getSocketPath,
+			buildServerFn:     NewUDPServer,
+			buildClientFn:     client.NewStatsD,
+		},
+		{
+			name:              "unix",
+			transport:         Unix,
+			getFreeEndpointFn: getSocketPath,
+			buildServerFn:     NewTCPServer,
+			buildClientFn:     client.NewStatsD,
+		},
 	}
 
 	for _, tt := range tests {
@@ -52,8 +67,6 @@ func Test_Server_ListenAndServe(t *testing.T) {
 			require.NoError(t, err)
 			require.NotNil(t, srv)
 
-			mc := new(consumertest.MetricsSink)
-			require.NoError(t, err)
 			mr := NewMockReporter(1)
 			transferChan := make(chan Metric, 10)
 
@@ -61,7 +74,7 @@ func Test_Server_ListenAndServe(t *testing.T) {
 			wgListenAndServe.Add(1)
 			go func() {
 				defer wgListenAndServe.Done()
-				assert.Error(t, srv.ListenAndServe(mc, mr, transferChan))
+				assert.Error(t, srv.ListenAndServe(mr, transferChan))
 			}()
 
 			runtime.Gosched()
@@ -90,10 +103,34 @@ func Test_Server_ListenAndServe(t *testing.T) {
 
 			wgListenAndServe.Wait()
 			assert.Equal(t, 1, len(transferChan))
+			if tt.transport.IsUnixTransport() {
+				assert.NoFileExists(t, addr)
+			}
 		})
 	}
 }
 
+func getSocketPath(t testing.TB, _ string) string {
+	return filepath.Join(t.TempDir(), "statsd.sock")
+}
+
+func Test_Server_StaleSocket(t *testing.T) {
+	addr := getSocketPath(t, "unixgram")
+	ln, err := net.ListenPacket("unixgram", addr)
+	require.NoError(t, err)
+	require.NoError(t, ln.Close())
+	require.FileExists(t, addr)
+
+	srv, err := NewUDPServer(UnixGram, addr)
+	require.NoError(t, err)
+	require.NoError(t, srv.Close())
+
+	// Only sockets are removed.
+	require.NoError(t, os.WriteFile(addr, nil, 0600))
+	_, err = NewTCPServer(Unix, addr)
+	assert.ErrorContains(t, err, "is not a socket")
+}
+
 func testFreeEndpoint(t *testing.T, transport string, address string) {
 	t.Helper()
 
@@ -11,8 +11,6 @@ import (
 	"net"
 	"strings"
 	"sync"
-
-	"go.opentelemetry.io/collector/consumer"
 )
 
 var errTCPServerDone = errors.New("server stopped")
@@ -28,7 +26,8 @@ type tcpServer struct {
 // Ensure that Server is implemented on TCP Server.
 var _ Server = (*tcpServer)(nil)
 
-// NewTCPServer creates a transport.Server using TCP as its transport.
+// NewTCPServer creates a transport.Server using TCP or a unix stream socket
+// as its transport.
 func NewTCPServer(transport Transport, address string) (Server, error) {
 	var tsrv tcpServer
 	var err error
@@ -37,6 +36,12 @@ func NewTCPServer(transport Transport, address string) (Server, error) {
 		return nil, fmt.Errorf("NewTCPServer with %s: %w", transport.String(), ErrUnsupportedStreamTransport)
 	}
 
+	if transport.IsUnixTransport() {
+		if err = removeStaleSocket(address); err != nil {
+			return nil, fmt.Errorf("starting to listen %s socket: %w", transport.String(), err)
+		}
+	}
+
 	tsrv.transport = transport
 	tsrv.listener, err = net.Listen(transport.String(), address)
 	if err != nil {
@@ -48,8 +53,8 @@ func NewTCPServer(transport Transport, address string) (Server, error) {
 }
 
 // ListenAndServe starts the server ready to receive metrics.
-func (t *tcpServer) ListenAndServe(nextConsumer consumer.Metrics, reporter Reporter, transferChan chan<- Metric) error {
-	if nextConsumer == nil || reporter == nil {
+func (t *tcpServer) ListenAndServe(reporter Reporter, transferChan chan<- Metric) error {
+	if reporter == nil {
 		return errNilListenAndServeParameters
 	}
 
@@ -70,7 +75,11 @@ LOOP:
 		select {
 		case conn := <-connChan:
 			t.wg.Add(1)
-			go t.handleConn(conn, transferChan)
+			if t.transport.IsUnixTransport() {
+				go t.handleFramedConn(conn, transferChan)
+			} else {
+				go t.handleConn(conn, transferChan)
+			}
 		case <-t.stopChan:
 			break LOOP
 		}
@@ -106,6 +115,27 @@ func (t *tcpServer) handleConn(c net.Conn, transferChan chan<- Metric) {
 	}
 }
 
+// handleFramedConn is helper that reads the messages of a unix stream
+// socket, prefixed by their length, and splits them line by line to be
+// parsed upstream.
+func (t *tcpServer) handleFramedConn(c net.Conn, transferChan chan<- Metric) {
+	defer t.wg.Done()
+	payload := make([]byte, 4096)
+	for {
+		frame, err := readFrame(c, payload)
+		if err != nil {
+			t.reporter.OnDebugf("%s transport (%s) Error reading payload: %v", t.transport, c.LocalAddr(), err)
+			return
+		}
+		for _, line := range strings.Split(string(frame), "\n") {
+			line = strings.TrimSpace(line)
+			if line != "" {
+				transferChan <- Metric{line, c.LocalAddr()}
+			}
+		}
+	}
+}
+
 // Close closes the server.
 func (t *tcpServer) Close() error {
 	close(t.stopChan)
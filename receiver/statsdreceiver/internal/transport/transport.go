@@ -21,6 +21,10 @@ const (
 	TCP  Transport = "tcp"
 	TCP4 Transport = "tcp4"
 	TCP6 Transport = "tcp6"
+	// Unix is a unix stream socket, whose messages are framed by their
+	// length like the DogStatsD unix socket stream mode.
+	Unix     Transport = "unix"
+	UnixGram Transport = "unixgram"
 )
 
 // NewTransport creates a Transport based on the transport string or returns an empty Transport.
@@ -31,6 +35,8 @@ func NewTransport(ts string) Transport {
 		return trans
 	case TCP, TCP4, TCP6:
 		return trans
+	case Unix, UnixGram:
+		return trans
 	}
 	return Transport("")
 }
@@ -38,7 +44,7 @@ func NewTransport(ts string) Transport {
 // String casts the transport to a String if the Transport is supported. Return an empty Transport overwise.
 func (trans Transport) String() string {
 	switch trans {
-	case UDP, UDP4, UDP6, TCP, TCP4, TCP6:
+	case UDP, UDP4, UDP6, TCP, TCP4, TCP6, Unix, UnixGram:
 		return string(trans)
 	}
 	return ""
@@ -47,7 +53,7 @@ func (trans Transport) String() string {
 // IsPacketTransport returns true if the transport is packet based.
 func (trans Transport) IsPacketTransport() bool {
 	switch trans {
-	case UDP, UDP4, UDP6:
+	case UDP, UDP4, UDP6, UnixGram:
 		return true
 	}
 	return false
@@ -56,7 +62,16 @@ func (trans Transport) IsPacketTransport() bool {
 // IsStreamTransport returns true if the transport is stream based.
 func (trans Transport) IsStreamTransport() bool {
 	switch trans {
-	case TCP, TCP4, TCP6:
+	case TCP, TCP4, TCP6, Unix:
+		return true
+	}
+	return false
+}
+
+// IsUnixTransport returns true if the transport is a unix socket.
+func (trans Transport) IsUnixTransport() bool {
+	switch trans {
+	case Unix, UnixGram:
 		return true
 	}
 	return false
@@ -9,25 +9,32 @@ import (
 	"fmt"
 	"io"
 	"net"
+	"os"
 	"strings"
-
-	"go.opentelemetry.io/collector/consumer"
 )
 
 type udpServer struct {
 	packetConn net.PacketConn
 	transport  Transport
+	address    string
 }
 
 // Ensure that Server is implemented on UDP Server.
 var _ (Server) = (*udpServer)(nil)
 
-// NewUDPServer creates a transport.Server using UDP as its transport.
+// NewUDPServer creates a transport.Server using UDP or a unix datagram socket
+// as its transport.
 func NewUDPServer(transport Transport, address string) (Server, error) {
 	if !transport.IsPacketTransport() {
 		return nil, fmt.Errorf("NewUDPServer with %s: %w", transport.String(), ErrUnsupportedPacketTransport)
 	}
 
+	if transport.IsUnixTransport() {
+		if err := removeStaleSocket(address); err != nil {
+			return nil, fmt.Errorf("starting to listen %s socket: %w", transport.String(), err)
+		}
+	}
+
 	conn, err := net.ListenPacket(transport.String(), address)
 	if err != nil {
 		return nil, fmt.Errorf("starting to listen %s socket: %w", transport.String(), err)
@@ -36,22 +43,26 @@ func NewUDPServer(transport Transport, address string) (Server, error) {
 	return &udpServer{
 		packetConn: conn,
 		transport:  transport,
+		address:    address,
 	}, nil
 }
 
 // ListenAndServe starts the server ready to receive metrics.
 func (u *udpServer) ListenAndServe(
-	nextConsumer consumer.Metrics,
 	reporter Reporter,
 	transferChan chan<- Metric,
 ) error {
-	if nextConsumer == nil || reporter == nil {
+	if reporter == nil {
 		return errNilListenAndServeParameters
 	}
 
 	buf := make([]byte, 65527) // max size for udp packet body (assuming ipv6)
 	for {
 		n, addr, err := u.packetConn.ReadFrom(buf)
+		if addr == nil {
+			// The clients of unix datagram sockets are usually unnamed.
+			addr = u.packetConn.LocalAddr()
+		}
 		if n > 0 {
 			bufCopy := make([]byte, n)
 			copy(bufCopy, buf)
@@ -75,7 +86,15 @@ func (u *udpServer) ListenAndServe(
 
 // Close closes the server.
 func (u *udpServer) Close() error {
-	return u.packetConn.Close()
+	err := u.packetConn.Close()
+	if u.transport.IsUnixTransport() {
+		// Unlike the unix stream listeners, the unix datagram sockets don't
+		// remove their file when closed.
+		if rmErr := os.Remove(u.address); rmErr != nil && !errors.Is(rmErr, os.ErrNotExist) && err == nil {
+			err = rmErr
+		}
+	}
+	return err
 }
 
 // handlePacket is helper that parses the buffer and split it line by line to be parsed upstream.
@@ -0,0 +1,52 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package transport // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/statsdreceiver/internal/transport"
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+)
+
+// maxUnixFrameSize is the maximum size of a message of a unix stream socket.
+const maxUnixFrameSize = 8 * 1024 * 1024
+
+// removeStaleSocket removes the socket file left at path by a previous run,
+// which would prevent listening on it. Other files are kept.
+func removeStaleSocket(path string) error {
+	info, err := os.Lstat(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if info.Mode()&fs.ModeSocket == 0 {
+		return fmt.Errorf("%s exists and is not a socket", path)
+	}
+	return os.Remove(path)
+}
+
+// readFrame reads a message of a unix stream socket, prefixed by its length
+// as a little-endian uint32 like in the DogStatsD protocol.
+func readFrame(r io.Reader, buf []byte) ([]byte, error) {
+	var size uint32
+	if err := binary.Read(r, binary.LittleEndian, &size); err != nil {
+		return nil, err
+	}
+	if size > maxUnixFrameSize {
+		return nil, fmt.Errorf("message of %d bytes exceeds the maximum of %d bytes", size, maxUnixFrameSize)
+	}
+	if int(size) > cap(buf) {
+		buf = make([]byte, size)
+	}
+	buf = buf[:size]
+	if _, err := io.ReadFull(r, buf); err != nil {
+		return nil, err
+	}
+	return buf, nil
+}
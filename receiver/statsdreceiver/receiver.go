@@ -22,9 +22,13 @@ import (
 	"github.com/open-telemetry/opentelemetry-collector-contrib/receiver/statsdreceiver/internal/transport"
 )
 
-var _ receiver.Metrics = (*statsdReceiver)(nil)
+var (
+	_ receiver.Metrics = (*statsdReceiver)(nil)
+	_ receiver.Logs    = (*statsdReceiver)(nil)
+)
 
-// statsdReceiver implements the receiver.Metrics for StatsD protocol.
+// statsdReceiver implements the receiver.Metrics for StatsD protocol, and the
+// receiver.Logs for the DogStatsD events and service checks.
 type statsdReceiver struct {
 	settings receiver.CreateSettings
 	config   *Config
@@ -33,15 +37,18 @@ type statsdReceiver struct {
 	reporter     transport.Reporter
 	parser       protocol.Parser
 	nextConsumer consumer.Metrics
+	logsConsumer consumer.Logs
 	cancel       context.CancelFunc
 }
 
 // newReceiver creates the StatsD receiver with the given parameters.
+// The metrics and logs receivers of a configuration share a receiver, whose
+// consumers are set by the factory.
 func newReceiver(
 	set receiver.CreateSettings,
 	config Config,
 	nextConsumer consumer.Metrics,
-) (receiver.Metrics, error) {
+) (*statsdReceiver, error) {
 
 	if config.NetAddr.Endpoint == "" {
 		config.NetAddr.Endpoint = "localhost:8125"
@@ -65,12 +72,11 @@ func newReceiver(
 }
 
 func buildTransportServer(config Config) (transport.Server, error) {
-	// TODO: Add unix socket transport implementations
 	trans := transport.NewTransport(strings.ToLower(string(config.NetAddr.Transport)))
 	switch trans {
-	case transport.UDP, transport.UDP4, transport.UDP6:
+	case transport.UDP, transport.UDP4, transport.UDP6, transport.UnixGram:
 		return transport.NewUDPServer(trans, config.NetAddr.Endpoint)
-	case transport.TCP, transport.TCP4, transport.TCP6:
+	case transport.TCP, transport.TCP4, transport.TCP6, transport.Unix:
 		return transport.NewTCPServer(trans, config.NetAddr.Endpoint)
 	}
 
@@ -92,12 +98,14 @@ func (r *statsdReceiver) Start(ctx context.Context, _ component.Host) error {
 		r.config.EnableSimpleTags,
 		r.config.IsMonotonicCounter,
 		r.config.TimerHistogramMapping,
+		r.config.SetExactCountThreshold,
+		r.config.CardinalityLimit,
 	)
 	if err != nil {
 		return err
 	}
 	go func() {
-		if err := r.server.ListenAndServe(r.nextConsumer, r.reporter, transferChan); err != nil {
+		if err := r.server.ListenAndServe(r.reporter, transferChan); err != nil {
 			if !errors.Is(err, net.ErrClosed) {
 				r.settings.TelemetrySettings.ReportStatus(component.NewFatalErrorEvent(err))
 			}
@@ -109,12 +117,26 @@ func (r *statsdReceiver) Start(ctx context.Context, _ component.Host) error {
 			case <-ticker.C:
 				batchMetrics := r.parser.GetMetrics()
 				for _, batch := range batchMetrics {
+					if r.nextConsumer == nil {
+						break
+					}
 					batchCtx := client.NewContext(ctx, batch.Info)
 
 					if err := r.Flush(batchCtx, batch.Metrics, r.nextConsumer); err != nil {
 						r.reporter.OnDebugf("Error flushing metrics", zap.Error(err))
 					}
 				}
+				batchLogs := r.parser.GetLogs()
+				for _, batch := range batchLogs {
+					if r.logsConsumer == nil {
+						break
+					}
+					batchCtx := client.NewContext(ctx, batch.Info)
+
+					if err := r.logsConsumer.ConsumeLogs(batchCtx, batch.Logs); err != nil {
+						r.reporter.OnDebugf("Error flushing logs", zap.Error(err))
+					}
+				}
 			case metric := <-transferChan:
 				if err := r.parser.Aggregate(metric.Raw, metric.Addr); err != nil {
 					r.reporter.OnDebugf("Error aggregating metric", zap.Error(err))
@@ -6,6 +6,7 @@ package statsdreceiver
 import (
 	"context"
 	"errors"
+	"path/filepath"
 	"testing"
 	"time"
 
@@ -15,6 +16,7 @@ import (
 	"go.opentelemetry.io/collector/config/confignet"
 	"go.opentelemetry.io/collector/consumer"
 	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.opentelemetry.io/collector/pdata/plog"
 	"go.opentelemetry.io/collector/pdata/pmetric"
 	"go.opentelemetry.io/collector/receiver/receivertest"
 
@@ -63,9 +65,8 @@ func TestStatsdReceiver_ShutdownBeforeStart(t *testing.T) {
 	ctx := context.Background()
 	cfg := createDefaultConfig().(*Config)
 	nextConsumer := consumertest.NewNop()
-	rcv, err := newReceiver(receivertest.NewNopCreateSettings(), *cfg, nextConsumer)
+	r, err := newReceiver(receivertest.NewNopCreateSettings(), *cfg, nextConsumer)
 	assert.NoError(t, err)
-	r := rcv.(*statsdReceiver)
 	assert.NoError(t, r.Shutdown(ctx))
 }
 
@@ -73,9 +74,8 @@ func TestStatsdReceiver_Flush(t *testing.T) {
 	ctx := context.Background()
 	cfg := createDefaultConfig().(*Config)
 	nextConsumer := consumertest.NewNop()
-	rcv, err := newReceiver(receivertest.NewNopCreateSettings(), *cfg, nextConsumer)
+	r, err := newReceiver(receivertest.NewNopCreateSettings(), *cfg, nextConsumer)
 	assert.NoError(t, err)
-	r := rcv.(*statsdReceiver)
 	var metrics = pmetric.NewMetrics()
 	assert.Nil(t, r.Flush(ctx, metrics, nextConsumer))
 	assert.NoError(t, r.Start(ctx, componenttest.NewNopHost()))
@@ -113,9 +113,8 @@ func Test_statsdreceiver_EndToEnd(t *testing.T) {
 			cfg := tt.configFn()
 			cfg.NetAddr.Endpoint = tt.addr
 			sink := new(consumertest.MetricsSink)
-			rcv, err := newReceiver(receivertest.NewNopCreateSettings(), *cfg, sink)
+			r, err := newReceiver(receivertest.NewNopCreateSettings(), *cfg, sink)
 			require.NoError(t, err)
-			r := rcv.(*statsdReceiver)
 
 			mr := transport.NewMockReporter(1)
 			r.reporter = mr
@@ -163,3 +162,45 @@ func Test_statsdreceiver_EndToEnd(t *testing.T) {
 		})
 	}
 }
+
+func Test_statsdreceiver_EndToEndUnixgramLogs(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.NetAddr = confignet.AddrConfig{
+		Endpoint:  filepath.Join(t.TempDir(), "statsd.sock"),
+		Transport: "unixgram",
+	}
+	cfg.AggregationInterval = 100 * time.Millisecond
+
+	// The metrics and logs receivers share the server.
+	factory := NewFactory()
+	metricsSink := new(consumertest.MetricsSink)
+	logsSink := new(consumertest.LogsSink)
+	metricsRcv, err := factory.CreateMetricsReceiver(context.Background(), receivertest.NewNopCreateSettings(), cfg, metricsSink)
+	require.NoError(t, err)
+	logsRcv, err := factory.CreateLogsReceiver(context.Background(), receivertest.NewNopCreateSettings(), cfg, logsSink)
+	require.NoError(t, err)
+	require.Same(t, metricsRcv, logsRcv)
+
+	require.NoError(t, metricsRcv.Start(context.Background(), componenttest.NewNopHost()))
+	require.NoError(t, logsRcv.Start(context.Background(), componenttest.NewNopHost()))
+	defer func() {
+		assert.NoError(t, metricsRcv.Shutdown(context.Background()))
+		assert.NoError(t, logsRcv.Shutdown(context.Background()))
+	}()
+
+	statsdClient, err := client.NewStatsD("unixgram", cfg.NetAddr.Endpoint)
+	require.NoError(t, err)
+	defer func() {
+		assert.NoError(t, statsdClient.Disconnect())
+	}()
+	require.NoError(t, statsdClient.SendMessage("test.metric:42|c|c:abc123\n_e{5,4}:title|text|t:warning\n"))
+
+	assert.Eventually(t, func() bool {
+		return len(metricsSink.AllMetrics()) > 0 && logsSink.LogRecordCount() > 0
+	}, 5*time.Second, 10*time.Millisecond)
+	metric := metricsSink.AllMetrics()[0].ResourceMetrics().At(0).ScopeMetrics().At(0).Metrics().At(0)
+	assert.Equal(t, "test.metric", metric.Name())
+	record := logsSink.AllLogs()[0].ResourceLogs().At(0).ScopeLogs().At(0).LogRecords().At(0)
+	assert.Equal(t, "text", record.Body().Str())
+	assert.Equal(t, plog.SeverityNumberWarn, record.SeverityNumber())
+}